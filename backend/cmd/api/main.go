@@ -13,8 +13,12 @@ import (
 	"github.com/goldenkiwi/autoparc/internal/database"
 	"github.com/goldenkiwi/autoparc/internal/handlers"
 	"github.com/goldenkiwi/autoparc/internal/middleware"
+	"github.com/goldenkiwi/autoparc/internal/models"
 	"github.com/goldenkiwi/autoparc/internal/repository"
 	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/goldenkiwi/autoparc/pkg/breached"
+	"github.com/goldenkiwi/autoparc/pkg/mailer"
+	"github.com/goldenkiwi/autoparc/pkg/oidc"
 )
 
 func main() {
@@ -43,24 +47,82 @@ func main() {
 	garageRepo := repository.NewGarageRepository(db.DB)
 	accidentRepo := repository.NewAccidentRepository(db.DB)
 	accidentPhotoRepo := repository.NewAccidentPhotoRepository(db.DB)
+	photoUploadRepo := repository.NewPhotoUploadRepository(db.DB)
 	repairRepo := repository.NewRepairRepository(db.DB)
+	documentRepo := repository.NewDocumentRepository(db.DB)
+	repairBillingRepo := repository.NewRepairBillingRepository(db.DB)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db.DB)
+	accountTokenRepo := repository.NewAccountTokenRepository(db.DB)
+	twoFactorRepo := repository.NewTwoFactorRepository(db.DB)
+	apiTokenRepo := repository.NewAPITokenRepository(db.DB)
+	searchRepo := repository.NewSearchRepository(db.DB)
+	leaseRepo := repository.NewLeaseRepository(db.DB)
+	fuelRepo := repository.NewFuelRepository(db.DB)
+	fineRepo := repository.NewTrafficFineRepository(db.DB)
+
+	// Initialize mailer
+	var mail mailer.Mailer
+	if cfg.Mail.SMTPHost != "" {
+		mail = mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     cfg.Mail.SMTPHost,
+			Port:     cfg.Mail.SMTPPort,
+			Username: cfg.Mail.SMTPUsername,
+			Password: cfg.Mail.SMTPPassword,
+			From:     cfg.Mail.From,
+		})
+	} else {
+		log.Println("SMTP_HOST is not set, emails will not be delivered")
+		mail = mailer.NewMemoryMailer()
+	}
+
+	// Initialize password policy
+	var breachedList *breached.List
+	if cfg.Password.CheckBreached {
+		breachedList = breached.New()
+		if cfg.Password.BreachedListFile != "" {
+			if err := breachedList.LoadFile(cfg.Password.BreachedListFile); err != nil {
+				log.Fatalf("Failed to load breached password list: %v", err)
+			}
+		}
+		log.Printf("Breached password check enabled with %d passwords", breachedList.Len())
+	}
+	passwordPolicy := service.NewPasswordPolicy(userRepo, &cfg.Password, breachedList)
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, sessionRepo)
+	authService := service.NewAuthService(userRepo, sessionRepo, loginAttemptRepo, actionLogRepo, &cfg.Login, &cfg.Session, &cfg.TwoFactor, passwordPolicy)
 	carService := service.NewCarService(carRepo, insuranceRepo, actionLogRepo, accidentRepo, repairRepo)
 	insuranceService := service.NewInsuranceService(insuranceRepo)
-	employeeService := service.NewEmployeeService(userRepo, actionLogRepo)
+	employeeService := service.NewEmployeeService(userRepo, sessionRepo, actionLogRepo, passwordPolicy)
+	twoFactorService := service.NewTwoFactorService(userRepo, sessionRepo, twoFactorRepo, actionLogRepo, authService, &cfg.TwoFactor)
+	apiTokenService := service.NewAPITokenService(userRepo, apiTokenRepo, actionLogRepo)
+	accountService := service.NewAccountService(userRepo, sessionRepo, accountTokenRepo, actionLogRepo, passwordPolicy, mail, &cfg.Account)
 	operatorService := service.NewOperatorService(operatorRepo, carRepo, actionLogRepo)
+	repairBillingService := service.NewRepairBillingService(repairBillingRepo, repairRepo, garageRepo, actionLogRepo, &cfg.Repair)
+	searchService := service.NewSearchService(searchRepo)
+	leaseService := service.NewLeaseService(leaseRepo, carRepo, operatorRepo, actionLogRepo, &cfg.Lease)
+	fuelService := service.NewFuelService(fuelRepo, carRepo, operatorRepo, actionLogRepo, &cfg.Fuel)
+	lifecycleService := service.NewCarLifecycleService(carRepo, operatorRepo, accidentRepo, repairRepo, actionLogRepo)
+	fineService := service.NewTrafficFineService(fineRepo, carRepo, operatorRepo, userRepo, actionLogRepo, mail, &cfg.Fine)
+	documentService := service.NewDocumentService(documentRepo, carRepo, repairRepo, operatorRepo, accidentRepo, actionLogRepo, &cfg.Upload)
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(authService, &cfg.Session)
+	authHandler := handlers.NewAuthHandler(authService, twoFactorService, &cfg.Session)
 	carHandler := handlers.NewCarHandler(carService)
 	insuranceHandler := handlers.NewInsuranceHandler(insuranceService)
 	employeeHandler := handlers.NewEmployeeHandler(employeeService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	apiTokenHandler := handlers.NewAPITokenHandler(apiTokenService)
 	operatorHandler := handlers.NewOperatorHandler(operatorService)
 	garageHandler := handlers.NewGarageHandler(garageRepo)
-	accidentHandler := handlers.NewAccidentHandler(accidentRepo, accidentPhotoRepo)
+	accidentHandler := handlers.NewAccidentHandler(accidentRepo, accidentPhotoRepo, photoUploadRepo, &cfg.Upload)
 	repairHandler := handlers.NewRepairHandler(repairRepo)
+	repairBillingHandler := handlers.NewRepairBillingHandler(repairBillingService)
+	documentHandler := handlers.NewDocumentHandler(documentService, &cfg.Upload)
+	searchHandler := handlers.NewSearchHandler(searchService)
+	leaseHandler := handlers.NewLeaseHandler(leaseService)
+	fuelHandler := handlers.NewFuelHandler(fuelService, &cfg.Upload)
+	fineHandler := handlers.NewTrafficFineHandler(fineService)
+	lifecycleHandler := handlers.NewCarLifecycleHandler(lifecycleService)
 
 	// Create router
 	mux := http.NewServeMux()
@@ -82,21 +144,64 @@ func main() {
 
 	// Public routes
 	mux.HandleFunc("POST /api/v1/auth/login", authHandler.Login)
+	mux.HandleFunc("POST /api/v1/auth/invitations/accept", accountHandler.AcceptInvitation)
+	mux.HandleFunc("POST /api/v1/auth/password/forgot", accountHandler.ForgotPassword)
+	mux.HandleFunc("POST /api/v1/auth/password/reset", accountHandler.ResetPassword)
+
+	// Single sign-on, when configured
+	if cfg.OIDC.Enabled() {
+		provider := oidc.NewProvider(oidc.Config{
+			IssuerURL:    cfg.OIDC.IssuerURL,
+			ClientID:     cfg.OIDC.ClientID,
+			ClientSecret: cfg.OIDC.ClientSecret,
+			RedirectURL:  cfg.OIDC.RedirectURL,
+			Scopes:       cfg.OIDC.Scopes,
+		})
+		oidcService := service.NewOIDCService(userRepo, actionLogRepo, authService, provider, &cfg.OIDC)
+		oidcHandler := handlers.NewOIDCHandler(oidcService, &cfg.Session, &cfg.OIDC, &cfg.Account)
+
+		mux.HandleFunc("GET /api/v1/auth/oidc/login", oidcHandler.Login)
+		mux.HandleFunc("GET /api/v1/auth/oidc/callback", oidcHandler.Callback)
+		log.Printf("SSO enabled with issuer %s", cfg.OIDC.IssuerURL)
+	}
 
 	// Protected routes - Auth
 	authMux := http.NewServeMux()
 	authMux.HandleFunc("GET /api/v1/auth/me", authHandler.GetMe)
-	authMux.HandleFunc("POST /api/v1/auth/logout", authHandler.Logout)
+	authMux.HandleFunc("PUT /api/v1/auth/me", employeeHandler.UpdateMe)
+	authMux.HandleFunc("POST /api/v1/auth/me/password", employeeHandler.ChangeMyPassword)
+	authMux.HandleFunc("GET /api/v1/auth/sessions", authHandler.ListSessions)
+	authMux.HandleFunc("DELETE /api/v1/auth/sessions", authHandler.RevokeOtherSessions)
+	authMux.HandleFunc("DELETE /api/v1/auth/sessions/{id}", authHandler.RevokeSession)
+	authMux.HandleFunc("DELETE /api/v1/auth/2fa", authHandler.DisableTwoFactor)
+	authMux.HandleFunc("GET /api/v1/auth/api-tokens", apiTokenHandler.ListAPITokens)
+	authMux.HandleFunc("POST /api/v1/auth/api-tokens", apiTokenHandler.CreateAPIToken)
+	authMux.HandleFunc("DELETE /api/v1/auth/api-tokens/{id}", apiTokenHandler.RevokeAPIToken)
+
+	// Routes also reachable by sessions awaiting the second factor
+	twoFactorMux := http.NewServeMux()
+	twoFactorMux.HandleFunc("POST /api/v1/auth/2fa/setup", authHandler.SetupTwoFactor)
+	twoFactorMux.HandleFunc("POST /api/v1/auth/2fa/enable", authHandler.EnableTwoFactor)
+	twoFactorMux.HandleFunc("POST /api/v1/auth/2fa/verify", authHandler.VerifyTwoFactor)
+	twoFactorMux.HandleFunc("POST /api/v1/auth/logout", authHandler.Logout)
 
 	// Protected routes - Cars
 	authMux.HandleFunc("GET /api/v1/cars", carHandler.GetCars)
 	authMux.HandleFunc("POST /api/v1/cars", carHandler.CreateCar)
+	authMux.HandleFunc("GET /api/v1/cars/decode-vin", carHandler.DecodeVIN)
 	authMux.HandleFunc("GET /api/v1/cars/{id}", carHandler.GetCar)
 	authMux.HandleFunc("PUT /api/v1/cars/{id}", carHandler.UpdateCar)
 	authMux.HandleFunc("DELETE /api/v1/cars/{id}", carHandler.DeleteCar)
 	authMux.HandleFunc("POST /api/v1/cars/{id}/assign", operatorHandler.AssignOperator)
 	authMux.HandleFunc("POST /api/v1/cars/{id}/unassign", operatorHandler.UnassignOperator)
 	authMux.HandleFunc("GET /api/v1/cars/{id}/assignment-history", operatorHandler.GetCarAssignmentHistory)
+	authMux.HandleFunc("GET /api/v1/cars/{id}/documents", documentHandler.ListEntityDocuments(models.EntityTypeCar, "/api/v1/cars/"))
+	authMux.HandleFunc("GET /api/v1/cars/{id}/leases", leaseHandler.ListCarLeases)
+	authMux.HandleFunc("GET /api/v1/cars/{id}/fuel-transactions", fuelHandler.ListCarFuelTransactions)
+	authMux.HandleFunc("GET /api/v1/cars/{id}/fines", fineHandler.GetCarFines)
+	authMux.HandleFunc("GET /api/v1/cars/{id}/lifecycle", lifecycleHandler.ListEvents)
+	authMux.HandleFunc("POST /api/v1/cars/{id}/lifecycle", lifecycleHandler.RecordEvent)
+	authMux.HandleFunc("GET /api/v1/cars/{id}/timeline", lifecycleHandler.GetTimeline)
 
 	// Protected routes - Insurance
 	authMux.HandleFunc("GET /api/v1/insurance-companies", insuranceHandler.GetInsuranceCompanies)
@@ -104,10 +209,15 @@ func main() {
 	// Protected routes - Employees
 	authMux.HandleFunc("GET /api/v1/employees", employeeHandler.GetEmployees)
 	authMux.HandleFunc("POST /api/v1/employees", employeeHandler.CreateEmployee)
+	authMux.HandleFunc("POST /api/v1/employees/invitations", accountHandler.InviteEmployee)
 	authMux.HandleFunc("GET /api/v1/employees/{id}", employeeHandler.GetEmployee)
 	authMux.HandleFunc("PUT /api/v1/employees/{id}", employeeHandler.UpdateEmployee)
 	authMux.HandleFunc("POST /api/v1/employees/{id}/change-password", employeeHandler.ChangePassword)
 	authMux.HandleFunc("DELETE /api/v1/employees/{id}", employeeHandler.DeleteEmployee)
+	authMux.HandleFunc("POST /api/v1/employees/{id}/unlock", authHandler.UnlockEmployee)
+	authMux.HandleFunc("DELETE /api/v1/employees/{id}/sessions", authHandler.RevokeEmployeeSessions)
+	authMux.HandleFunc("POST /api/v1/employees/{id}/invitation", accountHandler.ResendInvitation)
+	authMux.HandleFunc("DELETE /api/v1/employees/{id}/2fa", authHandler.ResetTwoFactor)
 
 	// Protected routes - Operators
 	authMux.HandleFunc("GET /api/v1/operators", operatorHandler.GetOperators)
@@ -116,6 +226,7 @@ func main() {
 	authMux.HandleFunc("PUT /api/v1/operators/{id}", operatorHandler.UpdateOperator)
 	authMux.HandleFunc("DELETE /api/v1/operators/{id}", operatorHandler.DeleteOperator)
 	authMux.HandleFunc("GET /api/v1/operators/{id}/assignment-history", operatorHandler.GetOperatorAssignmentHistory)
+	authMux.HandleFunc("GET /api/v1/operators/{id}/documents", documentHandler.ListEntityDocuments(models.EntityTypeOperator, "/api/v1/operators/"))
 
 	// Protected routes - Garages
 	authMux.HandleFunc("GET /api/v1/garages", garageHandler.ListGarages)
@@ -132,9 +243,15 @@ func main() {
 	authMux.HandleFunc("DELETE /api/v1/accidents/{id}", accidentHandler.DeleteAccident)
 	authMux.HandleFunc("PATCH /api/v1/accidents/{id}/status", accidentHandler.UpdateAccidentStatus)
 	authMux.HandleFunc("POST /api/v1/accidents/{id}/photos", accidentHandler.UploadPhoto)
+	authMux.HandleFunc("POST /api/v1/accidents/{id}/photos/batch", accidentHandler.UploadPhotosBatch)
+	authMux.HandleFunc("POST /api/v1/accidents/{id}/photos/uploads", accidentHandler.CreatePhotoUpload)
+	authMux.HandleFunc("HEAD /api/v1/accidents/{id}/photos/uploads/{upload_id}", accidentHandler.GetPhotoUploadOffset)
+	authMux.HandleFunc("PATCH /api/v1/accidents/{id}/photos/uploads/{upload_id}", accidentHandler.AppendPhotoUpload)
+	authMux.HandleFunc("DELETE /api/v1/accidents/{id}/photos/uploads/{upload_id}", accidentHandler.DeletePhotoUpload)
 	authMux.HandleFunc("GET /api/v1/accidents/{id}/photos", accidentHandler.GetPhotos)
 	authMux.HandleFunc("GET /api/v1/accidents/{id}/photos/{photo_id}", accidentHandler.GetPhoto)
 	authMux.HandleFunc("DELETE /api/v1/accidents/{id}/photos/{photo_id}", accidentHandler.DeletePhoto)
+	authMux.HandleFunc("GET /api/v1/accidents/{id}/documents", documentHandler.ListEntityDocuments(models.EntityTypeAccident, "/api/v1/accidents/"))
 
 	// Protected routes - Repairs
 	authMux.HandleFunc("GET /api/v1/repairs", repairHandler.ListRepairs)
@@ -143,26 +260,114 @@ func main() {
 	authMux.HandleFunc("PUT /api/v1/repairs/{id}", repairHandler.UpdateRepair)
 	authMux.HandleFunc("DELETE /api/v1/repairs/{id}", repairHandler.DeleteRepair)
 	authMux.HandleFunc("PATCH /api/v1/repairs/{id}/status", repairHandler.UpdateRepairStatus)
+	authMux.HandleFunc("GET /api/v1/repairs/over-quote", repairBillingHandler.GetOverQuoteRepairs)
+	authMux.HandleFunc("GET /api/v1/repairs/costs", repairBillingHandler.GetRepairCosts)
+	authMux.HandleFunc("GET /api/v1/repairs/{id}/quotes", repairBillingHandler.GetQuotes)
+	authMux.HandleFunc("POST /api/v1/repairs/{id}/quotes", repairBillingHandler.CreateQuote)
+	authMux.HandleFunc("PATCH /api/v1/repairs/{id}/quotes/{quote_id}/status", repairBillingHandler.UpdateQuoteStatus)
+	authMux.HandleFunc("DELETE /api/v1/repairs/{id}/quotes/{quote_id}", repairBillingHandler.DeleteQuote)
+	authMux.HandleFunc("GET /api/v1/repairs/{id}/invoice", repairBillingHandler.GetInvoice)
+	authMux.HandleFunc("POST /api/v1/repairs/{id}/invoice/lines", repairBillingHandler.AddInvoiceLine)
+	authMux.HandleFunc("DELETE /api/v1/repairs/{id}/invoice/lines/{line_id}", repairBillingHandler.DeleteInvoiceLine)
+	authMux.HandleFunc("GET /api/v1/repairs/{id}/documents", documentHandler.ListEntityDocuments(models.EntityTypeRepair, "/api/v1/repairs/"))
+
+	// Protected routes - Lease contracts
+	authMux.HandleFunc("GET /api/v1/leases", leaseHandler.ListLeases)
+	authMux.HandleFunc("POST /api/v1/leases", leaseHandler.CreateLease)
+	authMux.HandleFunc("GET /api/v1/leases/expiring", leaseHandler.GetExpiringLeases)
+	authMux.HandleFunc("GET /api/v1/leases/{id}", leaseHandler.GetLease)
+	authMux.HandleFunc("PUT /api/v1/leases/{id}", leaseHandler.UpdateLease)
+	authMux.HandleFunc("DELETE /api/v1/leases/{id}", leaseHandler.DeleteLease)
+	authMux.HandleFunc("POST /api/v1/leases/{id}/mileage", leaseHandler.RecordMileage)
+	authMux.HandleFunc("POST /api/v1/leases/{id}/return", leaseHandler.ReturnLease)
+
+	// Protected routes - Fuel cards and transactions
+	authMux.HandleFunc("GET /api/v1/fuel-cards", fuelHandler.ListFuelCards)
+	authMux.HandleFunc("POST /api/v1/fuel-cards", fuelHandler.CreateFuelCard)
+	authMux.HandleFunc("GET /api/v1/fuel-cards/{id}", fuelHandler.GetFuelCard)
+	authMux.HandleFunc("PUT /api/v1/fuel-cards/{id}", fuelHandler.UpdateFuelCard)
+	authMux.HandleFunc("DELETE /api/v1/fuel-cards/{id}", fuelHandler.DeleteFuelCard)
+	authMux.HandleFunc("GET /api/v1/fuel-transactions", fuelHandler.ListFuelTransactions)
+	authMux.HandleFunc("POST /api/v1/fuel-transactions/import", fuelHandler.ImportFuelTransactions)
+
+	// Protected routes - Traffic fines
+	authMux.HandleFunc("GET /api/v1/fines", fineHandler.ListFines)
+	authMux.HandleFunc("POST /api/v1/fines", fineHandler.CreateFine)
+	authMux.HandleFunc("GET /api/v1/fines/due", fineHandler.GetDueFines)
+	authMux.HandleFunc("GET /api/v1/fines/designation-export", fineHandler.ExportDesignations)
+	authMux.HandleFunc("GET /api/v1/fines/{id}", fineHandler.GetFine)
+	authMux.HandleFunc("PUT /api/v1/fines/{id}", fineHandler.UpdateFine)
+	authMux.HandleFunc("DELETE /api/v1/fines/{id}", fineHandler.DeleteFine)
+	authMux.HandleFunc("POST /api/v1/fines/{id}/designate", fineHandler.DesignateDriver)
+
+	// Protected routes - Documents
+	authMux.HandleFunc("GET /api/v1/documents", documentHandler.ListDocuments)
+	authMux.HandleFunc("POST /api/v1/documents", documentHandler.UploadDocument)
+	authMux.HandleFunc("GET /api/v1/documents/{id}", documentHandler.GetDocument)
+	authMux.HandleFunc("DELETE /api/v1/documents/{id}", documentHandler.DeleteDocument)
+	authMux.HandleFunc("GET /api/v1/documents/{id}/download", documentHandler.DownloadDocument)
+	authMux.HandleFunc("GET /api/v1/documents/{id}/versions", documentHandler.GetDocumentVersions)
+	authMux.HandleFunc("POST /api/v1/documents/{id}/versions", documentHandler.UploadDocumentVersion)
+
+	// Protected routes - API v2 lists, which all answer with the same
+	// paginated envelope. Everything else is still served by /api/v1 only.
+	authMux.HandleFunc("GET /api/v2/cars", carHandler.GetCars)
+	authMux.HandleFunc("GET /api/v2/cars/{id}/assignment-history", operatorHandler.GetCarAssignmentHistory)
+	authMux.HandleFunc("GET /api/v2/operators", operatorHandler.GetOperators)
+	authMux.HandleFunc("GET /api/v2/operators/{id}/assignment-history", operatorHandler.GetOperatorAssignmentHistory)
+	authMux.HandleFunc("GET /api/v2/garages", garageHandler.ListGarages)
+	authMux.HandleFunc("GET /api/v2/accidents", accidentHandler.ListAccidents)
+	authMux.HandleFunc("GET /api/v2/repairs", repairHandler.ListRepairs)
+
+	// Protected routes - Search
+	authMux.HandleFunc("GET /api/v1/search", searchHandler.Search)
 
 	// Apply auth middleware to protected routes
-	mux.Handle("/api/v1/auth/me", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
-	mux.Handle("/api/v1/auth/logout", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
-	mux.Handle("/api/v1/cars", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
-	mux.Handle("/api/v1/cars/", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
-	mux.Handle("/api/v1/insurance-companies", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/auth/me", middleware.PasswordChangeMiddleware(authService, cfg.Session.CookieName)(authMux))
+	mux.Handle("PUT /api/v1/auth/me", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/auth/me/password", middleware.PasswordChangeMiddleware(authService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/auth/logout", middleware.TwoFactorMiddleware(authService, cfg.Session.CookieName)(twoFactorMux))
+	mux.Handle("/api/v1/auth/2fa", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/auth/2fa/", middleware.TwoFactorMiddleware(authService, cfg.Session.CookieName)(twoFactorMux))
+	mux.Handle("/api/v1/auth/sessions", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/auth/sessions/", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/auth/api-tokens", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/auth/api-tokens/", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
+
+	// Resource routes also accept API tokens
+	mux.Handle("/api/v1/cars", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/cars/", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/insurance-companies", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	// Employee accounts are managed from a session only, never with an API
+	// token, since the routes reset passwords, 2FA and sessions
 	mux.Handle("/api/v1/employees", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
 	mux.Handle("/api/v1/employees/", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
-	mux.Handle("/api/v1/operators", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
-	mux.Handle("/api/v1/operators/", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
-	mux.Handle("/api/v1/garages", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
-	mux.Handle("/api/v1/garages/", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
-	mux.Handle("/api/v1/accidents", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
-	mux.Handle("/api/v1/accidents/", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
-	mux.Handle("/api/v1/repairs", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
-	mux.Handle("/api/v1/repairs/", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
+	// Employees with an expired password can still choose a new one
+	mux.Handle("POST /api/v1/employees/{id}/change-password", middleware.PasswordChangeMiddleware(authService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/operators", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/operators/", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/garages", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/garages/", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/accidents", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/accidents/", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/repairs", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/repairs/", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/leases", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/leases/", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/fuel-cards", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/fuel-cards/", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/fuel-transactions", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/fuel-transactions/", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/fines", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/fines/", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/documents", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v1/documents/", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	mux.Handle("/api/v2/", middleware.APIAuthMiddleware(authService, apiTokenService, cfg.Session.CookieName)(authMux))
+	// The search spans every resource, so it is not open to scoped API tokens
+	mux.Handle("/api/v1/search", middleware.AuthMiddleware(authService, cfg.Session.CookieName)(authMux))
 
 	// Apply global middleware
-	handler := middleware.Logger(middleware.CORS(cfg.Server.AllowedOrigins)(mux))
+	handler := middleware.Logger(middleware.CORS(cfg.Server.AllowedOrigins)(middleware.CSRF(cfg.Server.AllowedOrigins)(mux)))
 
 	// Create server
 	addr := fmt.Sprintf("%s:%s", cfg.Server.Addr, cfg.Server.Port)
@@ -173,6 +378,12 @@ func main() {
 		WriteTimeout: cfg.Server.WriteTimeout,
 	}
 
+	// Purge expired sessions in the background until shutdown
+	janitorCtx, stopJanitor := context.WithCancel(context.Background())
+	defer stopJanitor()
+	authService.StartSessionJanitor(janitorCtx)
+	fineService.StartDesignationReminders(janitorCtx)
+
 	// Start server in a goroutine
 	go func() {
 		log.Printf("Server starting on %s", addr)
@@ -188,6 +399,7 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopJanitor()
 
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
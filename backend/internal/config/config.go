@@ -4,14 +4,28 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+	// Embedded so time zones load on hosts without a zoneinfo database
+	_ "time/tzdata"
 )
 
 // Config holds all application configuration
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Session  SessionConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Session   SessionConfig
+	Upload    UploadConfig
+	Repair    RepairConfig
+	Lease     LeaseConfig
+	Fine      FineConfig
+	Fuel      FuelConfig
+	Login     LoginConfig
+	Mail      MailConfig
+	Account   AccountConfig
+	TwoFactor TwoFactorConfig
+	OIDC      OIDCConfig
+	Password  PasswordConfig
 }
 
 // ServerConfig holds server-related configuration
@@ -46,10 +60,158 @@ type SessionConfig struct {
 	CookieHTTPOnly bool
 	CookieSameSite string
 	CookiePath     string
+	// AbsoluteTimeout is the maximum lifetime of a session
+	AbsoluteTimeout time.Duration
+	// IdleTimeout expires sessions without activity; each request slides the
+	// expiry forward, never beyond the absolute timeout
+	IdleTimeout     time.Duration
+	CleanupInterval time.Duration
+}
+
+// UploadConfig holds file upload limits
+type UploadConfig struct {
+	MaxFileSize        int64
+	MaxFilesPerRequest int
+	ResumableExpiry    time.Duration
+}
+
+// RepairConfig holds repair billing settings
+type RepairConfig struct {
+	// QuoteOverrunPercent is the percentage by which an invoice may exceed
+	// the accepted quote before the repair is flagged
+	QuoteOverrunPercent float64
+}
+
+// LeaseConfig holds lease contract settings
+type LeaseConfig struct {
+	// ExpiryAlertDays is how many days before its end date a contract is
+	// reported as ending soon, unless the request asks otherwise
+	ExpiryAlertDays int
+}
+
+// FineConfig holds traffic fine settings
+type FineConfig struct {
+	// ReminderDays is how many days before its designation deadline a
+	// pending fine is reminded to the employee who recorded it
+	ReminderDays int
+	// ReminderInterval is how often reminders are sent; zero disables them
+	ReminderInterval time.Duration
+	// Location is the time zone offense times are written in, as on the
+	// notices
+	Location *time.Location
+}
+
+// FuelConfig holds fuel card settings
+type FuelConfig struct {
+	// Location is the time zone transaction times are written in on the
+	// provider statements
+	Location *time.Location
+}
+
+// LoginConfig holds brute-force protection settings for login
+type LoginConfig struct {
+	// MaxFailedAttempts is the number of consecutive failures that locks an
+	// account; zero disables the lockout
+	MaxFailedAttempts int
+	LockoutDuration   time.Duration
+	// ThrottleAfter is the number of failures allowed before delays apply
+	ThrottleAfter int
+	BaseDelay     time.Duration
+	MaxDelay      time.Duration
+	// IPMaxFailedAttempts is the number of failures from a single IP address
+	// within IPWindow after which its login attempts are rejected
+	IPMaxFailedAttempts int
+	IPWindow            time.Duration
+	// AttemptRetention is how long login attempts are kept for auditing.
+	// They are always kept for the lockout duration and the IP window.
+	AttemptRetention time.Duration
+}
+
+// MailConfig holds outgoing email settings. Without an SMTP host, emails are
+// kept in memory and never delivered.
+type MailConfig struct {
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+}
+
+// AccountConfig holds invitation and password reset settings
+type AccountConfig struct {
+	// AppBaseURL is the frontend URL used to build links sent by email
+	AppBaseURL       string
+	InvitationTTL    time.Duration
+	PasswordResetTTL time.Duration
+}
+
+// TwoFactorConfig holds TOTP two-factor authentication settings
+type TwoFactorConfig struct {
+	// Issuer is the name shown in authenticator apps
+	Issuer string
+	// RequiredRoles lists the roles that must enroll before using the API
+	RequiredRoles []string
+	// PendingTimeout bounds the time between the password and the code
+	PendingTimeout time.Duration
+}
+
+// OIDCConfig holds OpenID Connect single sign-on settings. SSO is disabled
+// unless an issuer and a client ID are set.
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback registered with the provider
+	RedirectURL string
+	Scopes      []string
+	// GroupsClaim is the ID token claim listing the groups of the user
+	GroupsClaim string
+	// AutoProvision creates unknown employees on their first SSO login
+	AutoProvision bool
+	// GroupRoles maps provider groups to roles, in order of precedence
+	GroupRoles []OIDCGroupRole
+	// DefaultRole is given to provisioned employees matching no group; when
+	// empty, only members of a mapped group are provisioned
+	DefaultRole string
+	// StateTimeout bounds the time spent on the provider login page
+	StateTimeout time.Duration
+}
+
+// PasswordConfig holds the password policy applied whenever a password is set
+type PasswordConfig struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	// CheckBreached rejects passwords found in the bundled list of common
+	// passwords, extended with BreachedListFile when set
+	CheckBreached    bool
+	BreachedListFile string
+	// HistorySize is the number of previous passwords that cannot be reused
+	HistorySize int
+	// MaxAge forces a change at the next login once a password is older;
+	// zero disables expiry
+	MaxAge time.Duration
+}
+
+// OIDCGroupRole maps a provider group to an employee role
+type OIDCGroupRole struct {
+	Group string
+	Role  string
+}
+
+// Enabled reports whether single sign-on is configured
+func (c *OIDCConfig) Enabled() bool {
+	return c.IssuerURL != "" && c.ClientID != ""
 }
 
 // Load reads configuration from environment variables
 func Load() (*Config, error) {
+	cookieMaxAge := getIntEnv("SESSION_COOKIE_MAX_AGE", 86400) // 24 hours
+	// Time zone of the dates entered or imported, unless set per feature
+	timeZone := getEnv("APP_TIME_ZONE", "Europe/Paris")
+
 	cfg := &Config{
 		Server: ServerConfig{
 			Addr:            getEnv("SERVER_ADDR", "0.0.0.0"),
@@ -73,11 +235,84 @@ func Load() (*Config, error) {
 		},
 		Session: SessionConfig{
 			CookieName:     getEnv("SESSION_COOKIE_NAME", "autoparc_session"),
-			CookieMaxAge:   getIntEnv("SESSION_COOKIE_MAX_AGE", 86400), // 24 hours
+			CookieMaxAge:   cookieMaxAge,
 			CookieSecure:   getBoolEnv("SESSION_COOKIE_SECURE", false),
 			CookieHTTPOnly: true, // Always true for security
 			CookieSameSite: getEnv("SESSION_COOKIE_SAMESITE", "Lax"),
 			CookiePath:     getEnv("SESSION_COOKIE_PATH", "/"),
+			// Sessions last as long as the cookie unless configured otherwise
+			AbsoluteTimeout: getDurationEnv("SESSION_ABSOLUTE_TIMEOUT", time.Duration(cookieMaxAge)*time.Second),
+			IdleTimeout:     getDurationEnv("SESSION_IDLE_TIMEOUT", 2*time.Hour),
+			CleanupInterval: getDurationEnv("SESSION_CLEANUP_INTERVAL", time.Hour),
+		},
+		Upload: UploadConfig{
+			MaxFileSize:        getInt64Env("UPLOAD_MAX_FILE_SIZE", 10<<20), // 10MB
+			MaxFilesPerRequest: getIntEnv("UPLOAD_MAX_FILES_PER_REQUEST", 20),
+			ResumableExpiry:    getDurationEnv("UPLOAD_RESUMABLE_EXPIRY", 24*time.Hour),
+		},
+		Repair: RepairConfig{
+			QuoteOverrunPercent: getFloatEnv("REPAIR_QUOTE_OVERRUN_PERCENT", 10),
+		},
+		Lease: LeaseConfig{
+			ExpiryAlertDays: getIntEnv("LEASE_EXPIRY_ALERT_DAYS", 60),
+		},
+		Fine: FineConfig{
+			ReminderDays:     getIntEnv("FINE_REMINDER_DAYS", 10),
+			ReminderInterval: getDurationEnv("FINE_REMINDER_INTERVAL", 24*time.Hour),
+			Location:         getLocationEnv("FINE_TIME_ZONE", timeZone),
+		},
+		Fuel: FuelConfig{
+			Location: getLocationEnv("FUEL_TIME_ZONE", timeZone),
+		},
+		Login: LoginConfig{
+			MaxFailedAttempts:   getIntEnv("LOGIN_MAX_FAILED_ATTEMPTS", 10),
+			LockoutDuration:     getDurationEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			ThrottleAfter:       getIntEnv("LOGIN_THROTTLE_AFTER", 3),
+			BaseDelay:           getDurationEnv("LOGIN_BASE_DELAY", time.Second),
+			MaxDelay:            getDurationEnv("LOGIN_MAX_DELAY", 30*time.Second),
+			IPMaxFailedAttempts: getIntEnv("LOGIN_IP_MAX_FAILED_ATTEMPTS", 50),
+			IPWindow:            getDurationEnv("LOGIN_IP_WINDOW", 15*time.Minute),
+			AttemptRetention:    getDurationEnv("LOGIN_ATTEMPT_RETENTION", 30*24*time.Hour),
+		},
+		Mail: MailConfig{
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			From:         getEnv("MAIL_FROM", "AutoParc <noreply@autoparc.fr>"),
+		},
+		Account: AccountConfig{
+			AppBaseURL:       getEnv("APP_BASE_URL", "http://localhost:5173"),
+			InvitationTTL:    getDurationEnv("ACCOUNT_INVITATION_TTL", 72*time.Hour),
+			PasswordResetTTL: getDurationEnv("ACCOUNT_PASSWORD_RESET_TTL", time.Hour),
+		},
+		TwoFactor: TwoFactorConfig{
+			Issuer:         getEnv("TWO_FACTOR_ISSUER", "AutoParc"),
+			RequiredRoles:  getListEnv("TWO_FACTOR_REQUIRED_ROLES"),
+			PendingTimeout: getDurationEnv("TWO_FACTOR_PENDING_TIMEOUT", 5*time.Minute),
+		},
+		OIDC: OIDCConfig{
+			IssuerURL:     getEnv("OIDC_ISSUER_URL", ""),
+			ClientID:      getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret:  getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:   getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/api/v1/auth/oidc/callback"),
+			Scopes:        strings.Fields(getEnv("OIDC_SCOPES", "email profile")),
+			GroupsClaim:   getEnv("OIDC_GROUPS_CLAIM", "groups"),
+			AutoProvision: getBoolEnv("OIDC_AUTO_PROVISION", false),
+			GroupRoles:    parseGroupRoles(getListEnv("OIDC_GROUP_ROLES")),
+			DefaultRole:   getEnv("OIDC_DEFAULT_ROLE", ""),
+			StateTimeout:  getDurationEnv("OIDC_STATE_TIMEOUT", 10*time.Minute),
+		},
+		Password: PasswordConfig{
+			MinLength:        getIntEnv("PASSWORD_MIN_LENGTH", 8),
+			RequireUppercase: getBoolEnv("PASSWORD_REQUIRE_UPPERCASE", true),
+			RequireLowercase: getBoolEnv("PASSWORD_REQUIRE_LOWERCASE", true),
+			RequireDigit:     getBoolEnv("PASSWORD_REQUIRE_DIGIT", true),
+			RequireSymbol:    getBoolEnv("PASSWORD_REQUIRE_SYMBOL", false),
+			CheckBreached:    getBoolEnv("PASSWORD_CHECK_BREACHED", true),
+			BreachedListFile: getEnv("PASSWORD_BREACHED_LIST_FILE", ""),
+			HistorySize:      getIntEnv("PASSWORD_HISTORY_SIZE", 5),
+			MaxAge:           getDurationEnv("PASSWORD_MAX_AGE", 0),
 		},
 	}
 
@@ -107,6 +342,50 @@ func getIntEnv(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getInt64Env retrieves a 64-bit integer environment variable or returns a default value
+func getInt64Env(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if intVal, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return intVal
+		}
+	}
+	return defaultValue
+}
+
+// getFloatEnv retrieves a float environment variable or returns a default value
+func getFloatEnv(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
+// getListEnv retrieves a comma-separated environment variable as a list
+func getListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// parseGroupRoles parses "group=role" pairs, ignoring malformed ones
+func parseGroupRoles(pairs []string) []OIDCGroupRole {
+	var groupRoles []OIDCGroupRole
+	for _, pair := range pairs {
+		group, role, ok := strings.Cut(pair, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if ok && group != "" && role != "" {
+			groupRoles = append(groupRoles, OIDCGroupRole{Group: group, Role: role})
+		}
+	}
+	return groupRoles
+}
+
 // getBoolEnv retrieves a boolean environment variable or returns a default value
 func getBoolEnv(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
@@ -127,6 +406,20 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+// getLocationEnv retrieves a time zone environment variable or returns a default value
+func getLocationEnv(key string, defaultValue string) *time.Location {
+	if value := os.Getenv(key); value != "" {
+		if location, err := time.LoadLocation(value); err == nil {
+			return location
+		}
+	}
+	location, err := time.LoadLocation(defaultValue)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
 // DSN returns the PostgreSQL connection string
 func (c *DatabaseConfig) DSN() string {
 	return fmt.Sprintf(
@@ -1,33 +1,49 @@
 package handlers
 
 import (
-	"bytes"
-	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"strings"
 
+	"github.com/goldenkiwi/autoparc/internal/config"
 	"github.com/goldenkiwi/autoparc/internal/middleware"
 	"github.com/goldenkiwi/autoparc/internal/models"
 	"github.com/goldenkiwi/autoparc/internal/repository"
-	"github.com/google/uuid"
+	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/goldenkiwi/autoparc/pkg/imaging"
 )
 
+// multipartOverhead is the allowance for multipart boundaries and form fields
+const multipartOverhead = 1 << 20 // 1MB
+
 type AccidentHandler struct {
 	accidentRepo      *repository.AccidentRepository
 	accidentPhotoRepo *repository.AccidentPhotoRepository
+	photoUploadRepo   *repository.PhotoUploadRepository
+	uploadConfig      *config.UploadConfig
 }
 
-func NewAccidentHandler(accidentRepo *repository.AccidentRepository, accidentPhotoRepo *repository.AccidentPhotoRepository) *AccidentHandler {
+func NewAccidentHandler(
+	accidentRepo *repository.AccidentRepository,
+	accidentPhotoRepo *repository.AccidentPhotoRepository,
+	photoUploadRepo *repository.PhotoUploadRepository,
+	uploadConfig *config.UploadConfig,
+) *AccidentHandler {
 	return &AccidentHandler{
 		accidentRepo:      accidentRepo,
 		accidentPhotoRepo: accidentPhotoRepo,
+		photoUploadRepo:   photoUploadRepo,
+		uploadConfig:      uploadConfig,
 	}
 }
 
-// ListAccidents handles GET /api/v1/accidents
+// ListAccidents handles GET /api/v1/accidents and GET /api/v2/accidents,
+// paginated by page number or cursor
 func (h *AccidentHandler) ListAccidents(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -39,7 +55,7 @@ func (h *AccidentHandler) ListAccidents(w http.ResponseWriter, r *http.Request)
 		filters["search"] = search
 	}
 
-	if carID := query.Get("car_id"); carID != "" {
+	if carID := queryValue(query, "car_id", "carId"); carID != "" {
 		filters["car_id"] = carID
 	}
 
@@ -47,24 +63,51 @@ func (h *AccidentHandler) ListAccidents(w http.ResponseWriter, r *http.Request)
 		filters["status"] = status
 	}
 
-	if page := query.Get("page"); page != "" {
-		filters["page"] = page
-	}
-
-	if limit := query.Get("limit"); limit != "" {
-		filters["limit"] = limit
+	page, limit, cursor, err := parsePageParams(query)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "Invalid cursor",
+		})
+		return
 	}
 
 	// Get accidents from repository
-	accidents, err := h.accidentRepo.FindAll(ctx, filters)
+	accidents, total, cursors, err := h.accidentRepo.FindPage(ctx, filters, cursor, page, limit)
 	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			respondJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "Invalid cursor",
+			})
+			return
+		}
 		respondJSON(w, http.StatusInternalServerError, map[string]string{
 			"error": "Failed to retrieve accidents",
 		})
 		return
 	}
 
-	respondJSON(w, http.StatusOK, accidents)
+	if isAPIV2(r) {
+		response := models.NewListResponse(accidents, limit, cursors)
+		if cursor == nil {
+			response.WithTotal(page, total)
+		}
+		respondJSON(w, http.StatusOK, response)
+		return
+	}
+
+	response := &models.AccidentListResponse{
+		Data:       accidents,
+		Limit:      limit,
+		NextCursor: cursors.Next,
+		PrevCursor: cursors.Prev,
+	}
+	if cursor == nil {
+		response.Total = total
+		response.Page = page
+		response.TotalPages = (total + limit - 1) / limit
+	}
+
+	respondJSON(w, http.StatusOK, response)
 }
 
 // GetAccident handles GET /api/v1/accidents/{id}
@@ -309,26 +352,15 @@ func (h *AccidentHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	accidentID := extractIDFromPath(r.URL.Path, "/api/v1/accidents/")
 
-	// Check if accident exists
-	_, err := h.accidentRepo.FindByID(ctx, accidentID)
-	if err != nil {
-		if err.Error() == "accident not found" {
-			respondJSON(w, http.StatusNotFound, map[string]string{
-				"error": "Accident not found",
-			})
-			return
-		}
-		respondJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": "Failed to retrieve accident",
-		})
+	if !h.ensureAccidentExists(w, r, accidentID) {
 		return
 	}
 
-	// Parse multipart form (max 10MB)
-	err = r.ParseMultipartForm(10 << 20) // 10MB
-	if err != nil {
+	// Cap the body at the configured file size plus room for the other form fields
+	r.Body = http.MaxBytesReader(w, r.Body, h.uploadConfig.MaxFileSize+multipartOverhead)
+	if err := r.ParseMultipartForm(h.uploadConfig.MaxFileSize); err != nil {
 		respondJSON(w, http.StatusBadRequest, map[string]string{
-			"error": "Failed to parse multipart form",
+			"error": fmt.Sprintf("Failed to parse multipart form (max file size %s)", models.FormatFileSize(h.uploadConfig.MaxFileSize)),
 		})
 		return
 	}
@@ -343,91 +375,92 @@ func (h *AccidentHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	// Validate file size (max 10MB)
-	if fileHeader.Size > 10*1024*1024 {
-		respondJSON(w, http.StatusBadRequest, map[string]string{
-			"error": "File size exceeds maximum allowed (10MB)",
+	// Get user from context
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	// Get description from form
+	description := r.FormValue("description")
+
+	photo, status, err := h.savePhoto(ctx, accidentID, fileHeader, stringPtr(description), user.ID)
+	if err != nil {
+		respondJSON(w, status, map[string]string{
+			"error": err.Error(),
 		})
 		return
 	}
 
-	// Validate MIME type
-	validMimeTypes := []string{"image/jpeg", "image/jpg", "image/png", "image/gif", "image/webp"}
-	mimeType := fileHeader.Header.Get("Content-Type")
-	isValid := false
-	for _, validType := range validMimeTypes {
-		if strings.EqualFold(mimeType, validType) {
-			isValid = true
-			break
-		}
+	respondJSON(w, http.StatusCreated, photoResponse(photo))
+}
+
+// UploadPhotosBatch handles POST /api/v1/accidents/{id}/photos/batch
+func (h *AccidentHandler) UploadPhotosBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	accidentID := extractIDFromPath(r.URL.Path, "/api/v1/accidents/")
+
+	if !h.ensureAccidentExists(w, r, accidentID) {
+		return
 	}
-	if !isValid {
+
+	maxFiles := int64(h.uploadConfig.MaxFilesPerRequest)
+	r.Body = http.MaxBytesReader(w, r.Body, maxFiles*(h.uploadConfig.MaxFileSize+multipartOverhead))
+	if err := r.ParseMultipartForm(h.uploadConfig.MaxFileSize); err != nil {
 		respondJSON(w, http.StatusBadRequest, map[string]string{
-			"error": "Invalid file type. Only images are allowed",
+			"error": "Failed to parse multipart form",
 		})
 		return
 	}
+	defer r.MultipartForm.RemoveAll()
 
-	// Read file data
-	fileData, err := io.ReadAll(file)
-	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": "Failed to read file",
+	files := r.MultipartForm.File["files"]
+	if len(files) == 0 {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "No file provided",
 		})
 		return
 	}
-
-	// Compress data with gzip
-	var buf bytes.Buffer
-	gzipWriter := gzip.NewWriter(&buf)
-	_, err = gzipWriter.Write(fileData)
-	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": "Failed to compress file",
+	if len(files) > h.uploadConfig.MaxFilesPerRequest {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("Too many files (max %d per request)", h.uploadConfig.MaxFilesPerRequest),
 		})
 		return
 	}
-	gzipWriter.Close()
 
-	// Get user from context
 	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
-	userID := user.ID
 
-	// Get description from form
-	description := r.FormValue("description")
+	// Descriptions are optional and matched to files by position
+	descriptions := r.MultipartForm.Value["descriptions"]
 
-	// Create photo record
-	photo := &models.AccidentPhoto{
-		ID:              uuid.New().String(),
-		AccidentID:      accidentID,
-		Filename:        fileHeader.Filename,
-		FileData:        buf.Bytes(),
-		FileSize:        int(fileHeader.Size),
-		MimeType:        mimeType,
-		CompressionType: "gzip",
-		Description:     stringPtr(description),
-		UploadedBy:      &userID,
+	response := models.BatchUploadResponse{
+		Results: make([]models.PhotoUploadResult, 0, len(files)),
 	}
+	for i, fileHeader := range files {
+		var description *string
+		if i < len(descriptions) {
+			description = stringPtr(descriptions[i])
+		}
 
-	if err := h.accidentPhotoRepo.Create(ctx, photo); err != nil {
-		respondJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": "Failed to save photo",
-		})
-		return
+		result := models.PhotoUploadResult{Filename: fileHeader.Filename}
+		photo, _, err := h.savePhoto(ctx, accidentID, fileHeader, description, user.ID)
+		if err != nil {
+			result.Error = err.Error()
+			response.Failed++
+		} else {
+			result.Success = true
+			result.Photo = photoMetadata(photo)
+			response.Uploaded++
+		}
+		response.Results = append(response.Results, result)
 	}
 
-	// Return photo metadata (without file data)
-	response := map[string]interface{}{
-		"id":          photo.ID,
-		"accident_id": photo.AccidentID,
-		"filename":    photo.Filename,
-		"file_size":   photo.FileSize,
-		"mime_type":   photo.MimeType,
-		"description": photo.Description,
-		"uploaded_at": photo.UploadedAt,
+	status := http.StatusCreated
+	switch {
+	case response.Uploaded == 0:
+		status = http.StatusBadRequest
+	case response.Failed > 0:
+		status = http.StatusMultiStatus
 	}
 
-	respondJSON(w, http.StatusCreated, response)
+	respondJSON(w, status, response)
 }
 
 // GetPhotos handles GET /api/v1/accidents/{id}/photos
@@ -476,10 +509,18 @@ func (h *AccidentHandler) GetPhoto(w http.ResponseWriter, r *http.Request) {
 	}
 	photoID := parts[2]
 
-	// Get photo with data
-	photo, err := h.accidentPhotoRepo.FindByID(ctx, photoID)
+	size, err := models.ParsePhotoSize(r.URL.Query().Get("size"))
 	if err != nil {
-		if err.Error() == "photo not found" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Get requested variant
+	variant, err := h.accidentPhotoRepo.FindVariant(ctx, photoID, size)
+	if err != nil {
+		if strings.Contains(err.Error(), "non trouvée") {
 			respondJSON(w, http.StatusNotFound, map[string]string{
 				"error": "Photo not found",
 			})
@@ -491,35 +532,14 @@ func (h *AccidentHandler) GetPhoto(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Decompress data if compressed
-	var fileData []byte
-	if photo.CompressionType == "gzip" {
-		reader, err := gzip.NewReader(bytes.NewReader(photo.FileData))
-		if err != nil {
-			respondJSON(w, http.StatusInternalServerError, map[string]string{
-				"error": "Failed to decompress photo",
-			})
-			return
-		}
-		defer reader.Close()
-
-		fileData, err = io.ReadAll(reader)
-		if err != nil {
-			respondJSON(w, http.StatusInternalServerError, map[string]string{
-				"error": "Failed to read decompressed photo",
-			})
-			return
-		}
-	} else {
-		fileData = photo.FileData
-	}
-
 	// Set appropriate headers
-	w.Header().Set("Content-Type", photo.MimeType)
-	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", photo.Filename))
-	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(fileData)))
+	w.Header().Set("Content-Type", variant.MimeType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", variant.Filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(variant.Data)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=86400")
 	w.WriteHeader(http.StatusOK)
-	w.Write(fileData)
+	w.Write(variant.Data)
 }
 
 // DeletePhoto handles DELETE /api/v1/accidents/{id}/photos/{photo_id}
@@ -571,6 +591,107 @@ func extractIDFromPath(path, prefix string) string {
 	return parts[0]
 }
 
+// ensureAccidentExists writes an error response and returns false when the accident cannot be found
+func (h *AccidentHandler) ensureAccidentExists(w http.ResponseWriter, r *http.Request, accidentID string) bool {
+	if _, err := h.accidentRepo.FindByID(r.Context(), accidentID); err != nil {
+		if err.Error() == "accident not found" {
+			respondJSON(w, http.StatusNotFound, map[string]string{
+				"error": "Accident not found",
+			})
+			return false
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve accident",
+		})
+		return false
+	}
+	return true
+}
+
+// savePhoto reads an uploaded file, enforces the size limit and stores it.
+// On failure it returns the HTTP status and a client-facing error.
+func (h *AccidentHandler) savePhoto(ctx context.Context, accidentID string, fileHeader *multipart.FileHeader, description *string, userID string) (*models.AccidentPhoto, int, error) {
+	if fileHeader.Size > h.uploadConfig.MaxFileSize {
+		return nil, http.StatusBadRequest, fmt.Errorf("File size exceeds maximum allowed (%s)", models.FormatFileSize(h.uploadConfig.MaxFileSize))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("Failed to read file")
+	}
+	defer file.Close()
+
+	fileData, err := io.ReadAll(file)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("Failed to read file")
+	}
+
+	return h.storePhoto(ctx, accidentID, fileHeader.Filename, fileData, description, userID)
+}
+
+// storePhoto sniffs the real type, strips EXIF, generates variants and saves
+// the photo. The client Content-Type header is never trusted.
+func (h *AccidentHandler) storePhoto(ctx context.Context, accidentID, filename string, fileData []byte, description *string, userID string) (*models.AccidentPhoto, int, error) {
+	processed, err := imaging.Process(fileData)
+	if err != nil {
+		switch {
+		case errors.Is(err, imaging.ErrUnsupportedFormat), errors.Is(err, imaging.ErrInvalidImage):
+			return nil, http.StatusBadRequest, errors.New("Invalid file type. Only images are allowed")
+		case errors.Is(err, imaging.ErrImageTooLarge):
+			return nil, http.StatusBadRequest, errors.New("Image dimensions exceed maximum allowed")
+		default:
+			return nil, http.StatusInternalServerError, errors.New("Failed to process image")
+		}
+	}
+
+	// Create photo record (the repository compresses the file data)
+	photo := service.NewAccidentPhoto(accidentID, filename, processed, description, userID)
+	if err := h.accidentPhotoRepo.Create(ctx, photo); err != nil {
+		return nil, http.StatusInternalServerError, errors.New("Failed to save photo")
+	}
+
+	return photo, http.StatusCreated, nil
+}
+
+// photoResponse returns photo metadata (without file data)
+func photoResponse(photo *models.AccidentPhoto) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          photo.ID,
+		"accident_id": photo.AccidentID,
+		"filename":    photo.Filename,
+		"file_size":   photo.FileSize,
+		"mime_type":   photo.MimeType,
+		"description": photo.Description,
+		"uploaded_at": photo.UploadedAt,
+		"width":       photo.Width,
+		"height":      photo.Height,
+		"taken_at":    photo.TakenAt,
+		"latitude":    photo.Latitude,
+		"longitude":   photo.Longitude,
+	}
+}
+
+// photoMetadata converts a stored photo into its metadata representation
+func photoMetadata(photo *models.AccidentPhoto) *models.AccidentPhotoMetadata {
+	return &models.AccidentPhotoMetadata{
+		ID:              photo.ID,
+		AccidentID:      photo.AccidentID,
+		Filename:        photo.Filename,
+		FileSize:        photo.FileSize,
+		MimeType:        photo.MimeType,
+		CompressionType: photo.CompressionType,
+		Description:     photo.Description,
+		UploadedAt:      photo.UploadedAt,
+		UploadedBy:      photo.UploadedBy,
+		Width:           photo.Width,
+		Height:          photo.Height,
+		TakenAt:         photo.TakenAt,
+		Latitude:        photo.Latitude,
+		Longitude:       photo.Longitude,
+		HasVariants:     len(photo.ThumbnailData) > 0,
+	}
+}
+
 func stringPtr(s string) *string {
 	if s == "" {
 		return nil
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/goldenkiwi/autoparc/internal/middleware"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/service"
+)
+
+// AccountHandler handles invitation and password reset HTTP requests
+type AccountHandler struct {
+	accountService *service.AccountService
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// InviteEmployee handles POST /api/v1/employees/invitations
+func (h *AccountHandler) InviteEmployee(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	if user.Role != models.RoleAdmin {
+		respondJSON(w, http.StatusForbidden, map[string]string{"error": "Accès refusé"})
+		return
+	}
+
+	var req models.InviteEmployeeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Corps de requête invalide"})
+		return
+	}
+
+	employee, err := h.accountService.InviteEmployee(r.Context(), req, user.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "email already exists") {
+			respondJSON(w, http.StatusConflict, map[string]string{"error": "Cet email existe déjà"})
+			return
+		}
+		if strings.Contains(err.Error(), "failed to send email") {
+			respondJSON(w, http.StatusBadGateway, map[string]string{"error": "L'employé a été créé mais l'invitation n'a pas pu être envoyée"})
+			return
+		}
+		if strings.Contains(err.Error(), "email") || strings.Contains(err.Error(), "required") {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec de l'invitation de l'employé"})
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, employee)
+}
+
+// ResendInvitation handles POST /api/v1/employees/{id}/invitation
+func (h *AccountHandler) ResendInvitation(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	if user.Role != models.RoleAdmin {
+		respondJSON(w, http.StatusForbidden, map[string]string{"error": "Accès refusé"})
+		return
+	}
+
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/employees/"), "/invitation")
+
+	if err := h.accountService.ResendInvitation(r.Context(), id, user.ID); err != nil {
+		if strings.Contains(err.Error(), "invalid employee ID format") {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Format d'ID invalide"})
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			respondJSON(w, http.StatusNotFound, map[string]string{"error": "Employé non trouvé"})
+			return
+		}
+		if strings.Contains(err.Error(), "failed to send email") {
+			respondJSON(w, http.StatusBadGateway, map[string]string{"error": "L'invitation n'a pas pu être envoyée"})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec de l'envoi de l'invitation"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Invitation envoyée"})
+}
+
+// AcceptInvitation handles POST /api/v1/auth/invitations/accept
+func (h *AccountHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var req models.SetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Corps de requête invalide"})
+		return
+	}
+
+	if err := h.accountService.AcceptInvitation(r.Context(), req); err != nil {
+		h.respondSetPasswordError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Mot de passe défini, vous pouvez vous connecter"})
+}
+
+// ForgotPassword handles POST /api/v1/auth/password/forgot
+func (h *AccountHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Corps de requête invalide"})
+		return
+	}
+
+	if err := h.accountService.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		if strings.Contains(err.Error(), "email") && !strings.Contains(err.Error(), "failed to send email") {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec de l'envoi du lien de réinitialisation"})
+		return
+	}
+
+	// Same response whether or not the address exists
+	respondJSON(w, http.StatusAccepted, map[string]string{"message": "Si cet email correspond à un compte, un lien de réinitialisation a été envoyé"})
+}
+
+// ResetPassword handles POST /api/v1/auth/password/reset
+func (h *AccountHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.SetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Corps de requête invalide"})
+		return
+	}
+
+	if err := h.accountService.ResetPassword(r.Context(), req); err != nil {
+		h.respondSetPasswordError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Mot de passe réinitialisé, vous pouvez vous connecter"})
+}
+
+func (h *AccountHandler) respondSetPasswordError(w http.ResponseWriter, err error) {
+	if strings.Contains(err.Error(), "invalid or expired token") {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Lien invalide ou expiré"})
+		return
+	}
+	if strings.Contains(err.Error(), "password must") {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec de la définition du mot de passe"})
+}
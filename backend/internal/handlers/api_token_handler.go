@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/goldenkiwi/autoparc/internal/middleware"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/service"
+)
+
+// APITokenHandler handles API token management HTTP requests. Tokens are
+// managed from a browser session only, never with another token.
+type APITokenHandler struct {
+	apiTokenService *service.APITokenService
+}
+
+// NewAPITokenHandler creates a new API token handler
+func NewAPITokenHandler(apiTokenService *service.APITokenService) *APITokenHandler {
+	return &APITokenHandler{
+		apiTokenService: apiTokenService,
+	}
+}
+
+// ListAPITokens handles GET /api/v1/auth/api-tokens
+func (h *APITokenHandler) ListAPITokens(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	tokens, err := h.apiTokenService.List(r.Context(), user.ID)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec de la récupération des jetons d'API"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"tokens": tokens})
+}
+
+// CreateAPIToken handles POST /api/v1/auth/api-tokens
+func (h *APITokenHandler) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	var req models.CreateAPITokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Corps de requête invalide"})
+		return
+	}
+
+	token, err := h.apiTokenService.Create(r.Context(), user.ID, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "scope") ||
+			strings.Contains(err.Error(), "must") {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec de la création du jeton d'API"})
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, models.CreateAPITokenResponse{APIToken: token, Token: token.Token})
+}
+
+// RevokeAPIToken handles DELETE /api/v1/auth/api-tokens/{id}
+func (h *APITokenHandler) RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/auth/api-tokens/")
+
+	if err := h.apiTokenService.Revoke(r.Context(), user.ID, id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondJSON(w, http.StatusNotFound, map[string]string{"error": "Jeton d'API non trouvé"})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec de la révocation du jeton d'API"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Jeton d'API révoqué"})
+}
@@ -2,25 +2,32 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/goldenkiwi/autoparc/internal/config"
 	"github.com/goldenkiwi/autoparc/internal/middleware"
 	"github.com/goldenkiwi/autoparc/internal/models"
 	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
 )
 
 // AuthHandler handles authentication-related HTTP requests
 type AuthHandler struct {
-	authService   *service.AuthService
-	sessionConfig *config.SessionConfig
+	authService      *service.AuthService
+	twoFactorService *service.TwoFactorService
+	sessionConfig    *config.SessionConfig
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(authService *service.AuthService, sessionConfig *config.SessionConfig) *AuthHandler {
+func NewAuthHandler(authService *service.AuthService, twoFactorService *service.TwoFactorService, sessionConfig *config.SessionConfig) *AuthHandler {
 	return &AuthHandler{
-		authService:   authService,
-		sessionConfig: sessionConfig,
+		authService:      authService,
+		twoFactorService: twoFactorService,
+		sessionConfig:    sessionConfig,
 	}
 }
 
@@ -33,40 +40,74 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get IP and user agent
-	ipAddress := r.RemoteAddr
+	ipAddress := utils.ClientIP(r)
 	userAgent := r.UserAgent()
 
 	user, session, err := h.authService.Login(r.Context(), req.Email, req.Password, ipAddress, userAgent)
 	if err != nil {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			retryAfter := int(math.Ceil(throttled.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			respondJSON(w, http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+			return
+		}
 		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
 		return
 	}
 
-	// Set session cookie
+	csrfToken := setSessionCookie(w, h.sessionConfig, session)
+
+	response := models.LoginResponse{User: user, CSRFToken: csrfToken, PasswordChangeRequired: session.PasswordChangeRequired}
+	if session.MFAPending {
+		response.TwoFactorRequired = user.TwoFactorEnabled
+		response.TwoFactorSetupRequired = !user.TwoFactorEnabled
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// setSessionCookie sets the session cookie and the CSRF header bound to the
+// session, and returns the CSRF token
+func setSessionCookie(w http.ResponseWriter, sessionConfig *config.SessionConfig, session *models.Session) string {
 	sameSite := http.SameSiteLaxMode
-	if h.sessionConfig.CookieSameSite == "Strict" {
+	if sessionConfig.CookieSameSite == "Strict" {
 		sameSite = http.SameSiteStrictMode
-	} else if h.sessionConfig.CookieSameSite == "None" {
+	} else if sessionConfig.CookieSameSite == "None" {
 		sameSite = http.SameSiteNoneMode
 	}
 
 	http.SetCookie(w, &http.Cookie{
-		Name:     h.sessionConfig.CookieName,
+		Name:     sessionConfig.CookieName,
 		Value:    session.SessionToken,
-		Path:     h.sessionConfig.CookiePath,
-		MaxAge:   h.sessionConfig.CookieMaxAge,
-		HttpOnly: h.sessionConfig.CookieHTTPOnly,
-		Secure:   h.sessionConfig.CookieSecure,
+		Path:     sessionConfig.CookiePath,
+		MaxAge:   sessionConfig.CookieMaxAge,
+		HttpOnly: sessionConfig.CookieHTTPOnly,
+		Secure:   sessionConfig.CookieSecure,
 		SameSite: sameSite,
 	})
 
-	respondJSON(w, http.StatusOK, models.LoginResponse{User: user})
+	csrfToken := utils.CSRFToken(session.SessionToken)
+	w.Header().Set(middleware.CSRFHeader, csrfToken)
+
+	return csrfToken
 }
 
 // GetMe handles GET /api/v1/auth/me
 func (h *AuthHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
-	respondJSON(w, http.StatusOK, map[string]interface{}{"user": user})
+	session := r.Context().Value(middleware.SessionContextKey).(*models.Session)
+
+	// Reissue the CSRF token so that a reloaded page can recover it
+	cookie, _ := r.Cookie(h.sessionConfig.CookieName)
+	csrfToken := utils.CSRFToken(cookie.Value)
+	w.Header().Set(middleware.CSRFHeader, csrfToken)
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user":                   user,
+		"csrfToken":              csrfToken,
+		"passwordChangeRequired": session.PasswordChangeRequired,
+	})
 }
 
 // Logout handles POST /api/v1/auth/logout
@@ -96,3 +137,223 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Logged out successfully"})
 }
+
+// UnlockEmployee handles POST /api/v1/employees/{id}/unlock
+func (h *AuthHandler) UnlockEmployee(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	if user.Role != models.RoleAdmin {
+		respondJSON(w, http.StatusForbidden, map[string]string{"error": "Accès refusé"})
+		return
+	}
+
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/employees/"), "/unlock")
+
+	if err := h.authService.UnlockAccount(r.Context(), id, user.ID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondJSON(w, http.StatusNotFound, map[string]string{"error": "Employé non trouvé"})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec du déverrouillage du compte"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Compte déverrouillé"})
+}
+
+// ListSessions handles GET /api/v1/auth/sessions
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	current := r.Context().Value(middleware.SessionContextKey).(*models.Session)
+
+	sessions, err := h.authService.ListSessions(r.Context(), user.ID, current.ID)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec de la récupération des sessions"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"sessions": sessions})
+}
+
+// RevokeOtherSessions handles DELETE /api/v1/auth/sessions
+func (h *AuthHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	current := r.Context().Value(middleware.SessionContextKey).(*models.Session)
+
+	revoked, err := h.authService.RevokeOtherSessions(r.Context(), user.ID, current.ID)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec de la révocation des sessions"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"revoked": revoked})
+}
+
+// RevokeSession handles DELETE /api/v1/auth/sessions/{id}
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/auth/sessions/")
+
+	if err := h.authService.RevokeSession(r.Context(), user.ID, id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondJSON(w, http.StatusNotFound, map[string]string{"error": "Session non trouvée"})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec de la révocation de la session"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Session révoquée"})
+}
+
+// RevokeEmployeeSessions handles DELETE /api/v1/employees/{id}/sessions
+func (h *AuthHandler) RevokeEmployeeSessions(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	if user.Role != models.RoleAdmin {
+		respondJSON(w, http.StatusForbidden, map[string]string{"error": "Accès refusé"})
+		return
+	}
+
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/employees/"), "/sessions")
+
+	revoked, err := h.authService.RevokeAllSessions(r.Context(), id, user.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondJSON(w, http.StatusNotFound, map[string]string{"error": "Employé non trouvé"})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec de la révocation des sessions"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"revoked": revoked})
+}
+
+// SetupTwoFactor handles POST /api/v1/auth/2fa/setup
+func (h *AuthHandler) SetupTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	setup, err := h.twoFactorService.Setup(r.Context(), user)
+	if err != nil {
+		if strings.Contains(err.Error(), "already enabled") {
+			respondJSON(w, http.StatusConflict, map[string]string{"error": "La double authentification est déjà activée"})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec de l'initialisation de la double authentification"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, setup)
+}
+
+// EnableTwoFactor handles POST /api/v1/auth/2fa/enable. When enrollment was
+// required at login, the session is promoted once 2FA is enabled.
+func (h *AuthHandler) EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	session := r.Context().Value(middleware.SessionContextKey).(*models.Session)
+
+	var req models.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Corps de requête invalide"})
+		return
+	}
+
+	codes, err := h.twoFactorService.Enable(r.Context(), user, req.Code)
+	if err != nil {
+		h.respondTwoFactorError(w, err)
+		return
+	}
+
+	if session.MFAPending {
+		promoted, err := h.authService.PromoteSession(r.Context(), session)
+		if err != nil {
+			respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec de la création de la session"})
+			return
+		}
+		setSessionCookie(w, h.sessionConfig, promoted)
+	}
+
+	respondJSON(w, http.StatusOK, models.TwoFactorEnableResponse{RecoveryCodes: codes})
+}
+
+// VerifyTwoFactor handles POST /api/v1/auth/2fa/verify
+func (h *AuthHandler) VerifyTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	session := r.Context().Value(middleware.SessionContextKey).(*models.Session)
+
+	var req models.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Corps de requête invalide"})
+		return
+	}
+
+	promoted, err := h.twoFactorService.Verify(r.Context(), user, session, req.Code)
+	if err != nil {
+		h.respondTwoFactorError(w, err)
+		return
+	}
+
+	csrfToken := setSessionCookie(w, h.sessionConfig, promoted)
+
+	respondJSON(w, http.StatusOK, models.LoginResponse{User: user, CSRFToken: csrfToken, PasswordChangeRequired: promoted.PasswordChangeRequired})
+}
+
+// DisableTwoFactor handles DELETE /api/v1/auth/2fa
+func (h *AuthHandler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	var req models.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Corps de requête invalide"})
+		return
+	}
+
+	if err := h.twoFactorService.Disable(r.Context(), user, req.Code); err != nil {
+		if strings.Contains(err.Error(), "required for this role") {
+			respondJSON(w, http.StatusForbidden, map[string]string{"error": "La double authentification est obligatoire pour votre rôle"})
+			return
+		}
+		h.respondTwoFactorError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Double authentification désactivée"})
+}
+
+// ResetTwoFactor handles DELETE /api/v1/employees/{id}/2fa
+func (h *AuthHandler) ResetTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	if user.Role != models.RoleAdmin {
+		respondJSON(w, http.StatusForbidden, map[string]string{"error": "Accès refusé"})
+		return
+	}
+
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/employees/"), "/2fa")
+
+	if err := h.twoFactorService.Reset(r.Context(), id, user.ID); err != nil {
+		if strings.Contains(err.Error(), "invalid employee ID format") {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Format d'ID invalide"})
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			respondJSON(w, http.StatusNotFound, map[string]string{"error": "Employé non trouvé"})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec de la réinitialisation de la double authentification"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Double authentification réinitialisée"})
+}
+
+func (h *AuthHandler) respondTwoFactorError(w http.ResponseWriter, err error) {
+	switch {
+	case strings.Contains(err.Error(), "invalid two-factor code"):
+		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Code invalide"})
+	case strings.Contains(err.Error(), "already enabled"):
+		respondJSON(w, http.StatusConflict, map[string]string{"error": "La double authentification est déjà activée"})
+	case strings.Contains(err.Error(), "not enabled"), strings.Contains(err.Error(), "not started"), strings.Contains(err.Error(), "already verified"):
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Double authentification non configurée"})
+	default:
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec de la vérification de la double authentification"})
+	}
+}
@@ -22,34 +22,98 @@ func NewCarHandler(carService *service.CarService) *CarHandler {
 	}
 }
 
-// GetCars handles GET /api/v1/cars
+// GetCars handles GET /api/v1/cars and GET /api/v2/cars. Besides search and pagination it
+// accepts vin, status (comma-separated or repeated), insuranceCompanyId, brand,
+// model, rentalStartFrom and rentalStartTo (YYYY-MM-DD), assigned,
+// department, hasOpenAccident and hasRepairInProgress. Pages are selected
+// either by page number or by one of the nextCursor and prevCursor of a
+// previous response, passed as cursor.
 func (h *CarHandler) GetCars(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
 	query := r.URL.Query()
 
 	filters := &models.CarFilters{
-		Search:    query.Get("search"),
-		Page:      parseIntQuery(query.Get("page"), 1),
-		Limit:     parseIntQuery(query.Get("limit"), 20),
-		SortBy:    query.Get("sortBy"),
-		SortOrder: query.Get("sortOrder"),
+		Search:             query.Get("search"),
+		VIN:                query.Get("vin"),
+		InsuranceCompanyID: query.Get("insuranceCompanyId"),
+		Brand:              strings.TrimSpace(query.Get("brand")),
+		Model:              strings.TrimSpace(query.Get("model")),
+		Department:         strings.TrimSpace(query.Get("department")),
+		Page:               parseIntQuery(query.Get("page"), 1),
+		Limit:              parseIntQuery(query.Get("limit"), 20),
+		SortBy:             query.Get("sortBy"),
+		SortOrder:          query.Get("sortOrder"),
 	}
 
 	// Parse status filter
-	if statusStr := query.Get("status"); statusStr != "" {
-		status := models.CarStatus(statusStr)
-		filters.Status = &status
+	for _, value := range query["status"] {
+		for _, status := range strings.Split(value, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				filters.Statuses = append(filters.Statuses, models.CarStatus(status))
+			}
+		}
+	}
+
+	var err error
+	if filters.RentalStartFrom, err = parseDateParam(query.Get("rentalStartFrom")); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid rentalStartFrom date (expected YYYY-MM-DD)"})
+		return
+	}
+	if filters.RentalStartTo, err = parseDateParam(query.Get("rentalStartTo")); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid rentalStartTo date (expected YYYY-MM-DD)"})
+		return
+	}
+
+	if filters.Cursor, err = models.DecodeCursor(query.Get("cursor")); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+		return
+	}
+
+	for name, target := range map[string]**bool{
+		"assigned":            &filters.Assigned,
+		"hasOpenAccident":     &filters.HasOpenAccident,
+		"hasRepairInProgress": &filters.HasRepairInProgress,
+	} {
+		if *target, err = parseBoolParam(query.Get(name)); err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid " + name + " value (expected true or false)"})
+			return
+		}
 	}
 
 	response, err := h.carService.GetCars(r.Context(), filters)
 	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
 		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve cars"})
 		return
 	}
 
+	if isAPIV2(r) {
+		list := models.NewListResponse(response.Cars, response.Limit, models.PageCursors{Next: response.NextCursor, Prev: response.PrevCursor})
+		if filters.Cursor == nil {
+			list.WithTotal(response.Page, response.TotalCount)
+		}
+		respondJSON(w, http.StatusOK, list)
+		return
+	}
+
 	respondJSON(w, http.StatusOK, response)
 }
 
+// DecodeVIN handles GET /api/v1/cars/decode-vin?vin=, returning the
+// manufacturer and region decoded from a VIN so forms can pre-fill the brand
+func (h *CarHandler) DecodeVIN(w http.ResponseWriter, r *http.Request) {
+	info, err := h.carService.DecodeVIN(r.URL.Query().Get("vin"))
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, info)
+}
+
 // GetCar handles GET /api/v1/cars/{id}
 func (h *CarHandler) GetCar(w http.ResponseWriter, r *http.Request) {
 	id := strings.TrimPrefix(r.URL.Path, "/api/v1/cars/")
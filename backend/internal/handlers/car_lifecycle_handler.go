@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/goldenkiwi/autoparc/internal/middleware"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/service"
+)
+
+// CarLifecycleHandler handles car lifecycle and timeline HTTP requests
+type CarLifecycleHandler struct {
+	lifecycleService *service.CarLifecycleService
+}
+
+// NewCarLifecycleHandler creates a new car lifecycle handler
+func NewCarLifecycleHandler(lifecycleService *service.CarLifecycleService) *CarLifecycleHandler {
+	return &CarLifecycleHandler{
+		lifecycleService: lifecycleService,
+	}
+}
+
+// ListEvents handles GET /api/v1/cars/{id}/lifecycle
+func (h *CarLifecycleHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	events, err := h.lifecycleService.GetEvents(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondCarLifecycleError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, events)
+}
+
+// RecordEvent handles POST /api/v1/cars/{id}/lifecycle
+func (h *CarLifecycleHandler) RecordEvent(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateCarLifecycleEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	event, err := h.lifecycleService.RecordEvent(r.Context(), r.PathValue("id"), &req, user.ID)
+	if err != nil {
+		respondCarLifecycleError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, event)
+}
+
+// GetTimeline handles GET /api/v1/cars/{id}/timeline
+func (h *CarLifecycleHandler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	timeline, err := h.lifecycleService.GetTimeline(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondCarLifecycleError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, timeline)
+}
+
+// respondCarLifecycleError maps car lifecycle service errors to HTTP responses
+func respondCarLifecycleError(w http.ResponseWriter, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": msg})
+	case strings.Contains(msg, "already"):
+		respondJSON(w, http.StatusConflict, map[string]string{"error": msg})
+	case strings.HasPrefix(msg, "failed"):
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to process car lifecycle request"})
+	default:
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+	}
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/middleware"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/service"
+)
+
+// dateLayout is the format of date-only query and form values
+const dateLayout = "2006-01-02"
+
+// DocumentHandler handles document-related HTTP requests
+type DocumentHandler struct {
+	documentService *service.DocumentService
+	uploadConfig    *config.UploadConfig
+}
+
+// NewDocumentHandler creates a new document handler
+func NewDocumentHandler(documentService *service.DocumentService, uploadConfig *config.UploadConfig) *DocumentHandler {
+	return &DocumentHandler{
+		documentService: documentService,
+		uploadConfig:    uploadConfig,
+	}
+}
+
+// ListDocuments handles GET /api/v1/documents
+func (h *DocumentHandler) ListDocuments(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filters := &models.DocumentFilters{
+		EntityType:   models.EntityType(query.Get("entity_type")),
+		EntityID:     query.Get("entity_id"),
+		DocumentType: models.DocumentType(query.Get("document_type")),
+	}
+
+	if expiringBefore := query.Get("expiring_before"); expiringBefore != "" {
+		date, err := time.Parse(dateLayout, expiringBefore)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid expiring_before date (expected YYYY-MM-DD)"})
+			return
+		}
+		filters.ExpiringBefore = &date
+	}
+
+	h.respondDocuments(w, r, filters)
+}
+
+// ListEntityDocuments returns a handler for GET /api/v1/{cars|repairs|operators|accidents}/{id}/documents
+func (h *DocumentHandler) ListEntityDocuments(entityType models.EntityType, prefix string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filters := &models.DocumentFilters{
+			EntityType:   entityType,
+			EntityID:     extractIDFromPath(r.URL.Path, prefix),
+			DocumentType: models.DocumentType(r.URL.Query().Get("document_type")),
+		}
+
+		h.respondDocuments(w, r, filters)
+	}
+}
+
+// GetDocument handles GET /api/v1/documents/{id}
+func (h *DocumentHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path, "/api/v1/documents/")
+
+	doc, err := h.documentService.GetDocument(r.Context(), id)
+	if err != nil {
+		respondDocumentError(w, err, "Failed to retrieve document")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, doc)
+}
+
+// DownloadDocument handles GET /api/v1/documents/{id}/download
+func (h *DocumentHandler) DownloadDocument(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path, "/api/v1/documents/")
+
+	doc, err := h.documentService.GetDocumentContent(r.Context(), id)
+	if err != nil {
+		respondDocumentError(w, err, "Failed to retrieve document")
+		return
+	}
+
+	disposition := "attachment"
+	if r.URL.Query().Get("inline") == "true" {
+		disposition = "inline"
+	}
+
+	w.Header().Set("Content-Type", doc.MimeType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=\"%s\"", disposition, doc.Filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(doc.FileData)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.WriteHeader(http.StatusOK)
+	w.Write(doc.FileData)
+}
+
+// GetDocumentVersions handles GET /api/v1/documents/{id}/versions
+func (h *DocumentHandler) GetDocumentVersions(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path, "/api/v1/documents/")
+
+	versions, err := h.documentService.GetDocumentVersions(r.Context(), id)
+	if err != nil {
+		respondDocumentError(w, err, "Failed to retrieve document versions")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, versions)
+}
+
+// UploadDocument handles POST /api/v1/documents
+func (h *DocumentHandler) UploadDocument(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.parseUploadForm(w, r)
+	if !ok {
+		return
+	}
+	req.EntityType = models.EntityType(r.FormValue("entity_type"))
+	req.EntityID = r.FormValue("entity_id")
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	doc, err := h.documentService.UploadDocument(r.Context(), req, user.ID)
+	if err != nil {
+		respondDocumentError(w, err, "")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, doc)
+}
+
+// UploadDocumentVersion handles POST /api/v1/documents/{id}/versions
+func (h *DocumentHandler) UploadDocumentVersion(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path, "/api/v1/documents/")
+
+	req, ok := h.parseUploadForm(w, r)
+	if !ok {
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	doc, err := h.documentService.UploadDocumentVersion(r.Context(), id, req, user.ID)
+	if err != nil {
+		respondDocumentError(w, err, "")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, doc)
+}
+
+// DeleteDocument handles DELETE /api/v1/documents/{id}
+func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromPath(r.URL.Path, "/api/v1/documents/")
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	if err := h.documentService.DeleteDocument(r.Context(), id, user.ID); err != nil {
+		respondDocumentError(w, err, "Failed to delete document")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Document deleted successfully"})
+}
+
+func (h *DocumentHandler) respondDocuments(w http.ResponseWriter, r *http.Request, filters *models.DocumentFilters) {
+	documents, err := h.documentService.GetDocuments(r.Context(), filters)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalide") {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve documents"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, documents)
+}
+
+// parseUploadForm reads the multipart fields shared by document uploads and new versions
+func (h *DocumentHandler) parseUploadForm(w http.ResponseWriter, r *http.Request) (*models.UploadDocumentRequest, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.uploadConfig.MaxFileSize+multipartOverhead)
+	if err := r.ParseMultipartForm(h.uploadConfig.MaxFileSize); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("Failed to parse multipart form (max file size %s)", models.FormatFileSize(h.uploadConfig.MaxFileSize)),
+		})
+		return nil, false
+	}
+
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "No file provided"})
+		return nil, false
+	}
+	defer file.Close()
+
+	fileData, err := io.ReadAll(file)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to read file"})
+		return nil, false
+	}
+
+	req := &models.UploadDocumentRequest{
+		DocumentType: models.DocumentType(r.FormValue("document_type")),
+		FileName:     fileHeader.Filename,
+		FileData:     fileData,
+		FileSize:     len(fileData),
+		Description:  stringPtr(r.FormValue("description")),
+	}
+
+	if expiryDate := r.FormValue("expiry_date"); expiryDate != "" {
+		date, err := time.Parse(dateLayout, expiryDate)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid expiry_date (expected YYYY-MM-DD)"})
+			return nil, false
+		}
+		req.ExpiryDate = &date
+	}
+
+	return req, true
+}
+
+// respondDocumentError maps document service errors to HTTP responses. An
+// empty fallback message exposes the service error as a bad request.
+func respondDocumentError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case strings.Contains(err.Error(), "non trouvé"):
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case fallback == "" && strings.HasPrefix(err.Error(), "échec"):
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to save document"})
+	case fallback == "":
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": fallback})
+	}
+}
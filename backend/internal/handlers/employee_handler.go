@@ -25,14 +25,18 @@ func NewEmployeeHandler(employeeService *service.EmployeeService) *EmployeeHandl
 
 // CreateEmployee handles POST /api/v1/employees
 func (h *EmployeeHandler) CreateEmployee(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	if user.Role != models.RoleAdmin {
+		respondJSON(w, http.StatusForbidden, map[string]string{"error": "Accès refusé"})
+		return
+	}
+
 	var req service.CreateEmployeeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Corps de requête invalide"})
 		return
 	}
 
-	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
-
 	employee, err := h.employeeService.CreateEmployee(r.Context(), req, user.ID)
 	if err != nil {
 		if strings.Contains(err.Error(), "email already exists") {
@@ -100,7 +104,14 @@ func (h *EmployeeHandler) GetEmployees(w http.ResponseWriter, r *http.Request) {
 }
 
 // UpdateEmployee handles PUT /api/v1/employees/{id}
+// Employees edit their own profile through PUT /api/v1/auth/me.
 func (h *EmployeeHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	if user.Role != models.RoleAdmin {
+		respondJSON(w, http.StatusForbidden, map[string]string{"error": "Accès refusé"})
+		return
+	}
+
 	id := strings.TrimPrefix(r.URL.Path, "/api/v1/employees/")
 
 	var req service.UpdateEmployeeRequest
@@ -109,8 +120,6 @@ func (h *EmployeeHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
-
 	employee, err := h.employeeService.UpdateEmployee(r.Context(), id, req, user.ID)
 	if err != nil {
 		if strings.Contains(err.Error(), "invalid employee ID format") {
@@ -153,36 +162,98 @@ func (h *EmployeeHandler) ChangePassword(w http.ResponseWriter, r *http.Request)
 
 	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
 
-	err := h.employeeService.ChangePassword(r.Context(), id, req, user.ID)
+	// Only admins may change the password of other employees, and a session
+	// opened with an expired password may only change that password
+	session, _ := r.Context().Value(middleware.SessionContextKey).(*models.Session)
+	if id != user.ID && (user.Role != models.RoleAdmin || (session != nil && session.PasswordChangeRequired)) {
+		respondJSON(w, http.StatusForbidden, map[string]string{"error": "Accès refusé"})
+		return
+	}
+
+	if err := h.employeeService.ChangePassword(r.Context(), id, req, user.ID); err != nil {
+		h.respondChangePasswordError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Mot de passe changé avec succès"})
+}
+
+// UpdateMe handles PUT /api/v1/auth/me
+func (h *EmployeeHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	var req models.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Corps de requête invalide"})
+		return
+	}
+
+	employee, err := h.employeeService.UpdateProfile(r.Context(), user.ID, req)
 	if err != nil {
-		if strings.Contains(err.Error(), "invalid employee ID format") {
-			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Format d'ID invalide"})
+		if strings.Contains(err.Error(), "invalid language") {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Langue non prise en charge"})
 			return
 		}
-		if strings.Contains(err.Error(), "current password is incorrect") {
-			respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Mot de passe actuel incorrect"})
+		if strings.Contains(err.Error(), "invalid page size") {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Taille de page non prise en charge"})
 			return
 		}
-		if strings.Contains(err.Error(), "password must") {
-			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
-			return
-		}
-		if strings.Contains(err.Error(), "not found") {
-			respondJSON(w, http.StatusNotFound, map[string]string{"error": "Employé non trouvé"})
-			return
-		}
-		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec du changement de mot de passe"})
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec de la mise à jour du profil"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, employee)
+}
+
+// ChangeMyPassword handles POST /api/v1/auth/me/password. The current
+// session stays open; the other ones are revoked.
+func (h *EmployeeHandler) ChangeMyPassword(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	session := r.Context().Value(middleware.SessionContextKey).(*models.Session)
+
+	var req service.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Corps de requête invalide"})
+		return
+	}
+
+	if err := h.employeeService.ChangeOwnPassword(r.Context(), user.ID, session.ID, req); err != nil {
+		h.respondChangePasswordError(w, err)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Mot de passe changé avec succès"})
 }
 
+func (h *EmployeeHandler) respondChangePasswordError(w http.ResponseWriter, err error) {
+	if strings.Contains(err.Error(), "invalid employee ID format") {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Format d'ID invalide"})
+		return
+	}
+	if strings.Contains(err.Error(), "current password is incorrect") {
+		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Mot de passe actuel incorrect"})
+		return
+	}
+	if strings.Contains(err.Error(), "password must") {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if strings.Contains(err.Error(), "not found") {
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Employé non trouvé"})
+		return
+	}
+	respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Échec du changement de mot de passe"})
+}
+
 // DeleteEmployee handles DELETE /api/v1/employees/{id}
 func (h *EmployeeHandler) DeleteEmployee(w http.ResponseWriter, r *http.Request) {
-	id := strings.TrimPrefix(r.URL.Path, "/api/v1/employees/")
-
 	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	if user.Role != models.RoleAdmin {
+		respondJSON(w, http.StatusForbidden, map[string]string{"error": "Accès refusé"})
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/employees/")
 
 	err := h.employeeService.DeleteEmployee(r.Context(), id, user.ID)
 	if err != nil {
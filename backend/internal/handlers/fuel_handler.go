@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/middleware"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/service"
+)
+
+// FuelHandler handles fuel card and fuel transaction HTTP requests
+type FuelHandler struct {
+	fuelService  *service.FuelService
+	uploadConfig *config.UploadConfig
+}
+
+// NewFuelHandler creates a new fuel handler
+func NewFuelHandler(fuelService *service.FuelService, uploadConfig *config.UploadConfig) *FuelHandler {
+	return &FuelHandler{
+		fuelService:  fuelService,
+		uploadConfig: uploadConfig,
+	}
+}
+
+// ListFuelCards handles GET /api/v1/fuel-cards?car_id=&operator_id=&status=&provider=
+func (h *FuelHandler) ListFuelCards(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filters := &models.FuelCardFilters{
+		CarID:      query.Get("car_id"),
+		OperatorID: query.Get("operator_id"),
+		Status:     models.FuelCardStatus(query.Get("status")),
+		Provider:   strings.TrimSpace(query.Get("provider")),
+	}
+
+	cards, err := h.fuelService.GetFuelCards(r.Context(), filters)
+	if err != nil {
+		respondFuelError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, cards)
+}
+
+// GetFuelCard handles GET /api/v1/fuel-cards/{id}
+func (h *FuelHandler) GetFuelCard(w http.ResponseWriter, r *http.Request) {
+	card, err := h.fuelService.GetFuelCard(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondFuelError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, card)
+}
+
+// CreateFuelCard handles POST /api/v1/fuel-cards
+func (h *FuelHandler) CreateFuelCard(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateFuelCardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	card, err := h.fuelService.CreateFuelCard(r.Context(), &req, user.ID)
+	if err != nil {
+		respondFuelError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, card)
+}
+
+// UpdateFuelCard handles PUT /api/v1/fuel-cards/{id}
+func (h *FuelHandler) UpdateFuelCard(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateFuelCardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	card, err := h.fuelService.UpdateFuelCard(r.Context(), r.PathValue("id"), &req, user.ID)
+	if err != nil {
+		respondFuelError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, card)
+}
+
+// DeleteFuelCard handles DELETE /api/v1/fuel-cards/{id}
+func (h *FuelHandler) DeleteFuelCard(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	if err := h.fuelService.DeleteFuelCard(r.Context(), r.PathValue("id"), user.ID); err != nil {
+		respondFuelError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Fuel card deleted successfully"})
+}
+
+// ListFuelTransactions handles
+// GET /api/v1/fuel-transactions?car_id=&card_id=&anomaly=&flagged=true&from=&to=
+func (h *FuelHandler) ListFuelTransactions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filters := &models.FuelTransactionFilters{
+		CarID:   query.Get("car_id"),
+		CardID:  query.Get("card_id"),
+		Anomaly: models.FuelAnomaly(query.Get("anomaly")),
+		Flagged: query.Get("flagged") == "true",
+	}
+
+	h.respondTransactions(w, r, filters)
+}
+
+// ListCarFuelTransactions handles GET /api/v1/cars/{id}/fuel-transactions?from=&to=
+func (h *FuelHandler) ListCarFuelTransactions(w http.ResponseWriter, r *http.Request) {
+	h.respondTransactions(w, r, &models.FuelTransactionFilters{CarID: r.PathValue("id")})
+}
+
+func (h *FuelHandler) respondTransactions(w http.ResponseWriter, r *http.Request, filters *models.FuelTransactionFilters) {
+	query := r.URL.Query()
+
+	var err error
+	if filters.From, err = parseDateParam(query.Get("from")); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid from date (expected YYYY-MM-DD)"})
+		return
+	}
+	if filters.To, err = parseDateParam(query.Get("to")); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid to date (expected YYYY-MM-DD)"})
+		return
+	}
+
+	transactions, err := h.fuelService.GetFuelTransactions(r.Context(), filters)
+	if err != nil {
+		respondFuelError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, transactions)
+}
+
+// ImportFuelTransactions handles POST /api/v1/fuel-transactions/import, a
+// multipart form with the provider CSV statement as "file"
+func (h *FuelHandler) ImportFuelTransactions(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.uploadConfig.MaxFileSize+multipartOverhead)
+	if err := r.ParseMultipartForm(h.uploadConfig.MaxFileSize); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("Failed to parse multipart form (max file size %s)", models.FormatFileSize(h.uploadConfig.MaxFileSize)),
+		})
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "No file provided"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to read file"})
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	result, err := h.fuelService.ImportTransactions(r.Context(), data, user.ID)
+	if err != nil {
+		respondFuelError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// respondFuelError maps fuel service errors to HTTP responses
+func respondFuelError(w http.ResponseWriter, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": msg})
+	case strings.Contains(msg, "already"):
+		respondJSON(w, http.StatusConflict, map[string]string{"error": msg})
+	case strings.HasPrefix(msg, "failed"):
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to process fuel request"})
+	default:
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -20,7 +21,8 @@ func NewGarageHandler(garageRepo *repository.GarageRepository) *GarageHandler {
 	}
 }
 
-// ListGarages handles GET /api/v1/garages
+// ListGarages handles GET /api/v1/garages, which returns every matching
+// garage, and GET /api/v2/garages, which paginates them
 func (h *GarageHandler) ListGarages(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -32,10 +34,15 @@ func (h *GarageHandler) ListGarages(w http.ResponseWriter, r *http.Request) {
 		filters["search"] = search
 	}
 
-	if isActive := query.Get("is_active"); isActive != "" {
+	if isActive := queryValue(query, "is_active", "isActive"); isActive != "" {
 		filters["is_active"] = isActive == "true"
 	}
 
+	if isAPIV2(r) {
+		h.respondGaragePage(w, r, filters)
+		return
+	}
+
 	if page := query.Get("page"); page != "" {
 		filters["page"] = page
 	}
@@ -56,6 +63,38 @@ func (h *GarageHandler) ListGarages(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, garages)
 }
 
+// respondGaragePage responds with the page of garages selected by the page,
+// limit and cursor parameters, in the /api/v2 list envelope
+func (h *GarageHandler) respondGaragePage(w http.ResponseWriter, r *http.Request, filters map[string]interface{}) {
+	page, limit, cursor, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "Invalid cursor",
+		})
+		return
+	}
+
+	garages, total, cursors, err := h.garageRepo.FindPage(r.Context(), filters, cursor, page, limit)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			respondJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "Invalid cursor",
+			})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve garages",
+		})
+		return
+	}
+
+	response := models.NewListResponse(garages, limit, cursors)
+	if cursor == nil {
+		response.WithTotal(page, total)
+	}
+	respondJSON(w, http.StatusOK, response)
+}
+
 // GetGarage handles GET /api/v1/garages/{id}
 func (h *GarageHandler) GetGarage(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
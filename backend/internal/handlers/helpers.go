@@ -3,7 +3,11 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
 )
 
 // respondJSON sends a JSON response with the specified status code
@@ -24,3 +28,55 @@ func parseIntQuery(value string, defaultValue int) int {
 	}
 	return parsed
 }
+
+// parseBoolParam parses an optional boolean query value
+func parseBoolParam(value string) (*bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// parsePageParams reads the page, limit and cursor parameters of a paginated
+// list. The limit defaults to 20 and is at most 100.
+func parsePageParams(query url.Values) (page, limit int, cursor *models.Cursor, err error) {
+	page = parseIntQuery(query.Get("page"), 1)
+	if page < 1 {
+		page = 1
+	}
+	limit = parseIntQuery(query.Get("limit"), 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	cursor, err = models.DecodeCursor(query.Get("cursor"))
+	return page, limit, cursor, err
+}
+
+// isPaginated reports whether the request asks for a page of a list that is
+// returned whole by default
+func isPaginated(r *http.Request) bool {
+	query := r.URL.Query()
+	return query.Has("page") || query.Has("limit") || query.Has("cursor")
+}
+
+// isAPIV2 reports whether the request came through an /api/v2 route. Lists
+// served there answer with the models.ListResponse envelope.
+func isAPIV2(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/api/v2/")
+}
+
+// queryValue returns the first non-empty of the given query parameters, so
+// that lists accept both their /api/v1 snake_case names and the camelCase
+// names used by /api/v2
+func queryValue(query url.Values, names ...string) string {
+	for _, name := range names {
+		if value := query.Get(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/goldenkiwi/autoparc/internal/middleware"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/service"
+)
+
+// LeaseHandler handles lease contract HTTP requests
+type LeaseHandler struct {
+	leaseService *service.LeaseService
+}
+
+// NewLeaseHandler creates a new lease handler
+func NewLeaseHandler(leaseService *service.LeaseService) *LeaseHandler {
+	return &LeaseHandler{
+		leaseService: leaseService,
+	}
+}
+
+// ListLeases handles GET /api/v1/leases?car_id=&status=&lessor=
+func (h *LeaseHandler) ListLeases(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filters := &models.LeaseFilters{
+		CarID:  query.Get("car_id"),
+		Status: models.LeaseStatus(query.Get("status")),
+		Lessor: strings.TrimSpace(query.Get("lessor")),
+	}
+
+	h.respondLeases(w, r, filters)
+}
+
+// ListCarLeases handles GET /api/v1/cars/{id}/leases
+func (h *LeaseHandler) ListCarLeases(w http.ResponseWriter, r *http.Request) {
+	h.respondLeases(w, r, &models.LeaseFilters{CarID: r.PathValue("id")})
+}
+
+func (h *LeaseHandler) respondLeases(w http.ResponseWriter, r *http.Request, filters *models.LeaseFilters) {
+	leases, err := h.leaseService.GetLeases(r.Context(), filters)
+	if err != nil {
+		respondLeaseError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, leases)
+}
+
+// GetExpiringLeases handles GET /api/v1/leases/expiring?days=N, listing the
+// active contracts ending within N days (the configured window by default)
+func (h *LeaseHandler) GetExpiringLeases(w http.ResponseWriter, r *http.Request) {
+	days := 0
+	if value := r.URL.Query().Get("days"); value != "" {
+		var err error
+		if days, err = strconv.Atoi(value); err != nil || days <= 0 {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid days value (expected a positive number)"})
+			return
+		}
+	}
+
+	leases, err := h.leaseService.GetExpiringLeases(r.Context(), days)
+	if err != nil {
+		respondLeaseError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, leases)
+}
+
+// GetLease handles GET /api/v1/leases/{id}
+func (h *LeaseHandler) GetLease(w http.ResponseWriter, r *http.Request) {
+	lease, err := h.leaseService.GetLease(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondLeaseError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, lease)
+}
+
+// CreateLease handles POST /api/v1/leases
+func (h *LeaseHandler) CreateLease(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateLeaseContractRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	lease, err := h.leaseService.CreateLease(r.Context(), &req, user.ID)
+	if err != nil {
+		respondLeaseError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, lease)
+}
+
+// UpdateLease handles PUT /api/v1/leases/{id}
+func (h *LeaseHandler) UpdateLease(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateLeaseContractRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	lease, err := h.leaseService.UpdateLease(r.Context(), r.PathValue("id"), &req, user.ID)
+	if err != nil {
+		respondLeaseError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, lease)
+}
+
+// RecordMileage handles POST /api/v1/leases/{id}/mileage
+func (h *LeaseHandler) RecordMileage(w http.ResponseWriter, r *http.Request) {
+	var req models.RecordMileageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	lease, err := h.leaseService.RecordMileage(r.Context(), r.PathValue("id"), &req, user.ID)
+	if err != nil {
+		respondLeaseError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, lease)
+}
+
+// ReturnLease handles POST /api/v1/leases/{id}/return
+func (h *LeaseHandler) ReturnLease(w http.ResponseWriter, r *http.Request) {
+	var req models.ReturnLeaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	lease, err := h.leaseService.ReturnLease(r.Context(), r.PathValue("id"), &req, user.ID)
+	if err != nil {
+		respondLeaseError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, lease)
+}
+
+// DeleteLease handles DELETE /api/v1/leases/{id}
+func (h *LeaseHandler) DeleteLease(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	if err := h.leaseService.DeleteLease(r.Context(), r.PathValue("id"), user.ID); err != nil {
+		respondLeaseError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Lease contract deleted successfully"})
+}
+
+// respondLeaseError maps lease service errors to HTTP responses
+func respondLeaseError(w http.ResponseWriter, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": msg})
+	case strings.Contains(msg, "already"):
+		respondJSON(w, http.StatusConflict, map[string]string{"error": msg})
+	case strings.HasPrefix(msg, "failed"):
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to process lease contract request"})
+	default:
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
+)
+
+const (
+	// oidcStateCookie keeps the login state while the browser is at the provider
+	oidcStateCookie = "autoparc_oidc"
+	oidcCookiePath  = "/api/v1/auth/oidc"
+)
+
+// OIDCHandler handles single sign-on HTTP requests. Both endpoints are
+// browser navigations, so they answer with redirects to the frontend.
+type OIDCHandler struct {
+	oidcService   *service.OIDCService
+	sessionConfig *config.SessionConfig
+	oidcConfig    *config.OIDCConfig
+	accountConfig *config.AccountConfig
+}
+
+// NewOIDCHandler creates a new OIDC handler
+func NewOIDCHandler(oidcService *service.OIDCService, sessionConfig *config.SessionConfig, oidcConfig *config.OIDCConfig, accountConfig *config.AccountConfig) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService:   oidcService,
+		sessionConfig: sessionConfig,
+		oidcConfig:    oidcConfig,
+		accountConfig: accountConfig,
+	}
+}
+
+// Login handles GET /api/v1/auth/oidc/login
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	authURL, state, err := h.oidcService.BeginLogin(r.Context())
+	if err != nil {
+		log.Printf("SSO login failed: %v", err)
+		h.redirectToLogin(w, r, "sso_unavailable")
+		return
+	}
+
+	// Lax so that the cookie comes back with the redirect from the provider
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    strings.Join([]string{state.State, state.Nonce, state.CodeVerifier}, "."),
+		Path:     oidcCookiePath,
+		MaxAge:   int(h.oidcConfig.StateTimeout.Seconds()),
+		HttpOnly: true,
+		Secure:   h.sessionConfig.CookieSecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback handles GET /api/v1/auth/oidc/callback
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	state := h.loginState(r)
+
+	// The state is single-use
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    "",
+		Path:     oidcCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.sessionConfig.CookieSecure,
+	})
+
+	query := r.URL.Query()
+	if providerError := query.Get("error"); providerError != "" {
+		log.Printf("SSO login refused by the provider: %s %s", providerError, query.Get("error_description"))
+		h.redirectToLogin(w, r, "sso_failed")
+		return
+	}
+
+	_, session, err := h.oidcService.CompleteLogin(r.Context(), state, query.Get("state"), query.Get("code"), utils.ClientIP(r), r.UserAgent())
+	if err != nil {
+		log.Printf("SSO login failed: %v", err)
+		reason := "sso_failed"
+		if strings.Contains(err.Error(), "no employee matches") || strings.Contains(err.Error(), "not allowed") {
+			reason = "sso_unknown_user"
+		}
+		h.redirectToLogin(w, r, reason)
+		return
+	}
+
+	setSessionCookie(w, h.sessionConfig, session)
+
+	if session.MFAPending {
+		http.Redirect(w, r, h.accountConfig.AppBaseURL+"/login?twoFactor=required", http.StatusFound)
+		return
+	}
+	http.Redirect(w, r, h.accountConfig.AppBaseURL+"/", http.StatusFound)
+}
+
+// loginState reads the state kept in the cookie set by Login
+func (h *OIDCHandler) loginState(r *http.Request) *service.OIDCLoginState {
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil {
+		return nil
+	}
+
+	parts := strings.Split(cookie.Value, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+
+	return &service.OIDCLoginState{State: parts[0], Nonce: parts[1], CodeVerifier: parts[2]}
+}
+
+func (h *OIDCHandler) redirectToLogin(w http.ResponseWriter, r *http.Request, reason string) {
+	http.Redirect(w, r, h.accountConfig.AppBaseURL+"/login?error="+reason, http.StatusFound)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -22,7 +23,9 @@ func NewOperatorHandler(operatorService *service.OperatorService) *OperatorHandl
 	}
 }
 
-// GetOperators handles GET /api/v1/operators
+// GetOperators handles GET /api/v1/operators and GET /api/v2/operators. Pages are selected either by
+// page number or by the next_cursor or prev_cursor of a previous response,
+// passed as cursor.
 func (h *OperatorHandler) GetOperators(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
 	query := r.URL.Query()
@@ -32,8 +35,8 @@ func (h *OperatorHandler) GetOperators(w http.ResponseWriter, r *http.Request) {
 		Department: query.Get("department"),
 		Page:       parseIntQuery(query.Get("page"), 1),
 		Limit:      parseIntQuery(query.Get("limit"), 20),
-		SortBy:     query.Get("sort_by"),
-		SortOrder:  query.Get("order"),
+		SortBy:     queryValue(query, "sort_by", "sortBy"),
+		SortOrder:  queryValue(query, "order", "sortOrder"),
 	}
 
 	// Parse isActive filter (support both snake_case and camelCase)
@@ -46,12 +49,32 @@ func (h *OperatorHandler) GetOperators(w http.ResponseWriter, r *http.Request) {
 		filters.IsActive = &isActive
 	}
 
+	cursor, err := models.DecodeCursor(query.Get("cursor"))
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+		return
+	}
+	filters.Cursor = cursor
+
 	response, err := h.operatorService.GetOperators(r.Context(), filters)
 	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+			return
+		}
 		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve operators"})
 		return
 	}
 
+	if isAPIV2(r) {
+		list := models.NewListResponse(models.NewOperatorListItems(response.Data), response.Limit, models.PageCursors{Next: response.NextCursor, Prev: response.PrevCursor})
+		if cursor == nil {
+			list.WithTotal(response.Page, response.Total)
+		}
+		respondJSON(w, http.StatusOK, list)
+		return
+	}
+
 	respondJSON(w, http.StatusOK, response)
 }
 
@@ -201,11 +224,17 @@ func (h *OperatorHandler) UnassignOperator(w http.ResponseWriter, r *http.Reques
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Operator unassigned successfully"})
 }
 
-// GetCarAssignmentHistory handles GET /api/v1/cars/{id}/assignment-history
+// GetCarAssignmentHistory handles GET /api/v1/cars/{id}/assignment-history.
+// On /api/v1 the whole history is returned as an array unless a page, limit
+// or cursor is given, in which case a paginated response is returned.
+// /api/v2 always paginates.
 func (h *OperatorHandler) GetCarAssignmentHistory(w http.ResponseWriter, r *http.Request) {
-	// Extract car ID from path
-	path := strings.TrimPrefix(r.URL.Path, "/api/v1/cars/")
-	carID := strings.TrimSuffix(path, "/assignment-history")
+	carID := r.PathValue("id")
+
+	if isPaginated(r) || isAPIV2(r) {
+		h.respondAssignmentHistoryPage(w, r, &models.AssignmentFilters{CarID: &carID})
+		return
+	}
 
 	history, err := h.operatorService.GetCarAssignmentHistory(r.Context(), carID)
 	if err != nil {
@@ -216,11 +245,15 @@ func (h *OperatorHandler) GetCarAssignmentHistory(w http.ResponseWriter, r *http
 	respondJSON(w, http.StatusOK, history)
 }
 
-// GetOperatorAssignmentHistory handles GET /api/v1/operators/{id}/assignment-history
+// GetOperatorAssignmentHistory handles GET /api/v1/operators/{id}/assignment-history,
+// paginated like GetCarAssignmentHistory
 func (h *OperatorHandler) GetOperatorAssignmentHistory(w http.ResponseWriter, r *http.Request) {
-	// Extract operator ID from path
-	path := strings.TrimPrefix(r.URL.Path, "/api/v1/operators/")
-	operatorID := strings.TrimSuffix(path, "/assignment-history")
+	operatorID := r.PathValue("id")
+
+	if isPaginated(r) || isAPIV2(r) {
+		h.respondAssignmentHistoryPage(w, r, &models.AssignmentFilters{OperatorID: &operatorID})
+		return
+	}
 
 	history, err := h.operatorService.GetOperatorAssignmentHistory(r.Context(), operatorID)
 	if err != nil {
@@ -230,3 +263,35 @@ func (h *OperatorHandler) GetOperatorAssignmentHistory(w http.ResponseWriter, r
 
 	respondJSON(w, http.StatusOK, history)
 }
+
+// respondAssignmentHistoryPage responds with the page of assignment history
+// selected by the page, limit and cursor parameters
+func (h *OperatorHandler) respondAssignmentHistoryPage(w http.ResponseWriter, r *http.Request, filters *models.AssignmentFilters) {
+	var err error
+	filters.Page, filters.Limit, filters.Cursor, err = parsePageParams(r.URL.Query())
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+		return
+	}
+
+	response, err := h.operatorService.GetAssignmentHistoryPage(r.Context(), filters)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve assignment history"})
+		return
+	}
+
+	if isAPIV2(r) {
+		list := models.NewListResponse(models.NewAssignmentListItems(response.Data), response.Limit, models.PageCursors{Next: response.NextCursor, Prev: response.PrevCursor})
+		if filters.Cursor == nil {
+			list.WithTotal(response.Page, response.Total)
+		}
+		respondJSON(w, http.StatusOK, list)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/middleware"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// Resumable uploads follow the core tus protocol: the client creates an
+// upload with its total length, then sends PATCH requests carrying chunks at
+// the current offset. After a network failure it asks for the offset with HEAD
+// and resumes from there.
+const (
+	tusVersion            = "1.0.0"
+	uploadChunkMediaType  = "application/offset+octet-stream"
+	headerTusResumable    = "Tus-Resumable"
+	headerUploadLength    = "Upload-Length"
+	headerUploadOffset    = "Upload-Offset"
+	headerUploadMetadata  = "Upload-Metadata"
+	headerUploadExpires   = "Upload-Expires"
+	uploadMetadataName    = "filename"
+	uploadMetadataComment = "description"
+)
+
+// CreatePhotoUpload handles POST /api/v1/accidents/{id}/photos/uploads
+func (h *AccidentHandler) CreatePhotoUpload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	accidentID := extractIDFromPath(r.URL.Path, "/api/v1/accidents/")
+	w.Header().Set(headerTusResumable, tusVersion)
+
+	if !h.ensureAccidentExists(w, r, accidentID) {
+		return
+	}
+
+	length, err := strconv.ParseInt(r.Header.Get(headerUploadLength), 10, 64)
+	if err != nil || length <= 0 {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "Invalid or missing Upload-Length header",
+		})
+		return
+	}
+	if length > h.uploadConfig.MaxFileSize {
+		respondJSON(w, http.StatusRequestEntityTooLarge, map[string]string{
+			"error": fmt.Sprintf("File size exceeds maximum allowed (%s)", models.FormatFileSize(h.uploadConfig.MaxFileSize)),
+		})
+		return
+	}
+
+	metadata, err := utils.ParseUploadMetadata(r.Header.Get(headerUploadMetadata))
+	if err != nil || strings.TrimSpace(metadata[uploadMetadataName]) == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "Upload-Metadata must contain a filename",
+		})
+		return
+	}
+
+	// Abandoned uploads are purged whenever a new one starts
+	_ = h.photoUploadRepo.DeleteExpired(ctx)
+
+	user := ctx.Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	now := time.Now()
+	upload := &models.PhotoUpload{
+		ID:           uuid.New().String(),
+		AccidentID:   accidentID,
+		Filename:     metadata[uploadMetadataName],
+		Description:  stringPtr(metadata[uploadMetadataComment]),
+		UploadLength: length,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(h.uploadConfig.ResumableExpiry),
+		CreatedBy:    &user.ID,
+	}
+
+	if err := h.photoUploadRepo.Create(ctx, upload); err != nil {
+		respondJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "Failed to create upload",
+		})
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/accidents/%s/photos/uploads/%s", accidentID, upload.ID))
+	w.Header().Set(headerUploadOffset, "0")
+	w.Header().Set(headerUploadExpires, upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	respondJSON(w, http.StatusCreated, upload)
+}
+
+// GetPhotoUploadOffset handles HEAD /api/v1/accidents/{id}/photos/uploads/{upload_id}
+func (h *AccidentHandler) GetPhotoUploadOffset(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(headerTusResumable, tusVersion)
+	w.Header().Set("Cache-Control", "no-store")
+
+	upload, ok := h.findPhotoUpload(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set(headerUploadOffset, strconv.FormatInt(upload.UploadOffset, 10))
+	w.Header().Set(headerUploadLength, strconv.FormatInt(upload.UploadLength, 10))
+	w.Header().Set(headerUploadExpires, upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusOK)
+}
+
+// AppendPhotoUpload handles PATCH /api/v1/accidents/{id}/photos/uploads/{upload_id}
+func (h *AccidentHandler) AppendPhotoUpload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set(headerTusResumable, tusVersion)
+
+	if r.Header.Get("Content-Type") != uploadChunkMediaType {
+		respondJSON(w, http.StatusUnsupportedMediaType, map[string]string{
+			"error": "Content-Type must be " + uploadChunkMediaType,
+		})
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get(headerUploadOffset), 10, 64)
+	if err != nil || offset < 0 {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "Invalid or missing Upload-Offset header",
+		})
+		return
+	}
+
+	upload, ok := h.findPhotoUpload(w, r)
+	if !ok {
+		return
+	}
+
+	if offset != upload.UploadOffset {
+		w.Header().Set(headerUploadOffset, strconv.FormatInt(upload.UploadOffset, 10))
+		respondJSON(w, http.StatusConflict, map[string]string{
+			"error": "Upload-Offset does not match the current offset",
+		})
+		return
+	}
+
+	// Read at most the remaining bytes, plus one to detect oversized chunks
+	remaining := upload.UploadLength - upload.UploadOffset
+	chunk, err := io.ReadAll(io.LimitReader(r.Body, remaining+1))
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "Failed to read chunk",
+		})
+		return
+	}
+	if int64(len(chunk)) > remaining {
+		respondJSON(w, http.StatusRequestEntityTooLarge, map[string]string{
+			"error": "Chunk exceeds the declared Upload-Length",
+		})
+		return
+	}
+
+	newOffset, err := h.photoUploadRepo.AppendChunk(ctx, upload.ID, offset, chunk)
+	if err != nil {
+		if err.Error() == "décalage d'upload invalide" {
+			respondJSON(w, http.StatusConflict, map[string]string{
+				"error": "Upload-Offset does not match the current offset",
+			})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "Failed to store chunk",
+		})
+		return
+	}
+	w.Header().Set(headerUploadOffset, strconv.FormatInt(newOffset, 10))
+
+	upload.UploadOffset = newOffset
+	if !upload.IsComplete() {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Last chunk received: process the image and turn it into a photo
+	data, err := h.photoUploadRepo.GetData(ctx, upload.ID)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "Failed to read upload",
+		})
+		return
+	}
+
+	user := ctx.Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	photo, status, err := h.storePhoto(ctx, upload.AccidentID, upload.Filename, data, upload.Description, user.ID)
+
+	// The upload cannot be resumed once complete, whatever the outcome
+	_ = h.photoUploadRepo.Delete(ctx, upload.ID)
+
+	if err != nil {
+		respondJSON(w, status, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, photoResponse(photo))
+}
+
+// DeletePhotoUpload handles DELETE /api/v1/accidents/{id}/photos/uploads/{upload_id}
+func (h *AccidentHandler) DeletePhotoUpload(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(headerTusResumable, tusVersion)
+
+	upload, ok := h.findPhotoUpload(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.photoUploadRepo.Delete(r.Context(), upload.ID); err != nil {
+		respondJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "Failed to delete upload",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findPhotoUpload loads the upload referenced by the URL and checks it belongs
+// to the accident in the path and was started by the caller. Uploads of other
+// employees are reported as not found. It writes the error response when not
+// found.
+func (h *AccidentHandler) findPhotoUpload(w http.ResponseWriter, r *http.Request) (*models.PhotoUpload, bool) {
+	// Extract IDs from path like /api/v1/accidents/{id}/photos/uploads/{upload_id}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/accidents/"), "/")
+	if len(parts) < 4 {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "Invalid URL",
+		})
+		return nil, false
+	}
+	accidentID, uploadID := parts[0], parts[3]
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+	upload, err := h.photoUploadRepo.FindByID(r.Context(), uploadID)
+	if err != nil || upload.AccidentID != accidentID || upload.CreatedBy == nil || *upload.CreatedBy != user.ID {
+		if err == nil || err.Error() == "upload non trouvé" {
+			respondJSON(w, http.StatusNotFound, map[string]string{
+				"error": "Upload not found",
+			})
+			return nil, false
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve upload",
+		})
+		return nil, false
+	}
+
+	return upload, true
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/middleware"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/service"
+)
+
+// RepairBillingHandler handles repair quote and invoice HTTP requests
+type RepairBillingHandler struct {
+	billingService *service.RepairBillingService
+}
+
+// NewRepairBillingHandler creates a new repair billing handler
+func NewRepairBillingHandler(billingService *service.RepairBillingService) *RepairBillingHandler {
+	return &RepairBillingHandler{
+		billingService: billingService,
+	}
+}
+
+// GetQuotes handles GET /api/v1/repairs/{id}/quotes
+func (h *RepairBillingHandler) GetQuotes(w http.ResponseWriter, r *http.Request) {
+	repairID := extractIDFromPath(r.URL.Path, "/api/v1/repairs/")
+
+	quotes, err := h.billingService.GetQuotes(r.Context(), repairID)
+	if err != nil {
+		respondBillingError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quotes)
+}
+
+// CreateQuote handles POST /api/v1/repairs/{id}/quotes
+func (h *RepairBillingHandler) CreateQuote(w http.ResponseWriter, r *http.Request) {
+	repairID := extractIDFromPath(r.URL.Path, "/api/v1/repairs/")
+
+	var req models.CreateRepairQuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	quote, err := h.billingService.CreateQuote(r.Context(), repairID, &req, user.ID)
+	if err != nil {
+		respondBillingError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, quote)
+}
+
+// UpdateQuoteStatus handles PATCH /api/v1/repairs/{id}/quotes/{quote_id}/status
+func (h *RepairBillingHandler) UpdateQuoteStatus(w http.ResponseWriter, r *http.Request) {
+	repairID, quoteID, ok := extractSubresourceIDs(w, r.URL.Path)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateQuoteStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	quote, err := h.billingService.UpdateQuoteStatus(r.Context(), repairID, quoteID, req.Status, user.ID)
+	if err != nil {
+		respondBillingError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quote)
+}
+
+// DeleteQuote handles DELETE /api/v1/repairs/{id}/quotes/{quote_id}
+func (h *RepairBillingHandler) DeleteQuote(w http.ResponseWriter, r *http.Request) {
+	repairID, quoteID, ok := extractSubresourceIDs(w, r.URL.Path)
+	if !ok {
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	if err := h.billingService.DeleteQuote(r.Context(), repairID, quoteID, user.ID); err != nil {
+		respondBillingError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Quote deleted successfully"})
+}
+
+// GetInvoice handles GET /api/v1/repairs/{id}/invoice
+func (h *RepairBillingHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	repairID := extractIDFromPath(r.URL.Path, "/api/v1/repairs/")
+
+	invoice, err := h.billingService.GetInvoice(r.Context(), repairID)
+	if err != nil {
+		respondBillingError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, invoice)
+}
+
+// AddInvoiceLine handles POST /api/v1/repairs/{id}/invoice/lines
+func (h *RepairBillingHandler) AddInvoiceLine(w http.ResponseWriter, r *http.Request) {
+	repairID := extractIDFromPath(r.URL.Path, "/api/v1/repairs/")
+
+	var req models.CreateInvoiceLineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	line, err := h.billingService.AddInvoiceLine(r.Context(), repairID, &req, user.ID)
+	if err != nil {
+		respondBillingError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, line)
+}
+
+// DeleteInvoiceLine handles DELETE /api/v1/repairs/{id}/invoice/lines/{line_id}
+func (h *RepairBillingHandler) DeleteInvoiceLine(w http.ResponseWriter, r *http.Request) {
+	// Path is /api/v1/repairs/{id}/invoice/lines/{line_id}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/repairs/"), "/")
+	if len(parts) < 4 {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid URL"})
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	if err := h.billingService.DeleteInvoiceLine(r.Context(), parts[0], parts[3], user.ID); err != nil {
+		respondBillingError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Invoice line deleted successfully"})
+}
+
+// GetOverQuoteRepairs handles GET /api/v1/repairs/over-quote
+func (h *RepairBillingHandler) GetOverQuoteRepairs(w http.ResponseWriter, r *http.Request) {
+	repairs, err := h.billingService.GetOverQuoteRepairs(r.Context())
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve repairs over quote"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, repairs)
+}
+
+// GetRepairCosts handles GET /api/v1/repairs/costs
+func (h *RepairBillingHandler) GetRepairCosts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filters := &models.RepairCostFilters{
+		GroupBy:  models.RepairCostGroupBy(query.Get("group_by")),
+		CarID:    query.Get("car_id"),
+		GarageID: query.Get("garage_id"),
+		Status:   query.Get("status"),
+	}
+	if filters.GroupBy == "" {
+		filters.GroupBy = models.RepairCostGroupByMonth
+	}
+
+	var err error
+	if filters.From, err = parseDateParam(query.Get("from")); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid from date (expected YYYY-MM-DD)"})
+		return
+	}
+	if filters.To, err = parseDateParam(query.Get("to")); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid to date (expected YYYY-MM-DD)"})
+		return
+	}
+
+	costs, err := h.billingService.GetRepairCosts(r.Context(), filters)
+	if err != nil {
+		respondBillingError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, costs)
+}
+
+// parseDateParam parses an optional YYYY-MM-DD query value
+func parseDateParam(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// extractSubresourceIDs extracts the repair and quote IDs from
+// /api/v1/repairs/{id}/quotes/{quote_id}[/...]
+func extractSubresourceIDs(w http.ResponseWriter, path string) (string, string, bool) {
+	parts := strings.Split(strings.TrimPrefix(path, "/api/v1/repairs/"), "/")
+	if len(parts) < 3 {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid URL"})
+		return "", "", false
+	}
+	return parts[0], parts[2], true
+}
+
+// respondBillingError maps billing service errors to HTTP responses
+func respondBillingError(w http.ResponseWriter, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "non trouvé"):
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": msg})
+	case strings.HasPrefix(msg, "échec"):
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to process billing request"})
+	default:
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+	}
+}
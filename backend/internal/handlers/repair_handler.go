@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -20,7 +21,8 @@ func NewRepairHandler(repairRepo *repository.RepairRepository) *RepairHandler {
 	}
 }
 
-// ListRepairs handles GET /api/v1/repairs
+// ListRepairs handles GET /api/v1/repairs and GET /api/v2/repairs, paginated
+// by page number or cursor
 func (h *RepairHandler) ListRepairs(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -32,19 +34,19 @@ func (h *RepairHandler) ListRepairs(w http.ResponseWriter, r *http.Request) {
 		filters["search"] = search
 	}
 
-	if carID := query.Get("car_id"); carID != "" {
+	if carID := queryValue(query, "car_id", "carId"); carID != "" {
 		filters["car_id"] = carID
 	}
 
-	if accidentID := query.Get("accident_id"); accidentID != "" {
+	if accidentID := queryValue(query, "accident_id", "accidentId"); accidentID != "" {
 		filters["accident_id"] = accidentID
 	}
 
-	if garageID := query.Get("garage_id"); garageID != "" {
+	if garageID := queryValue(query, "garage_id", "garageId"); garageID != "" {
 		filters["garage_id"] = garageID
 	}
 
-	if repairType := query.Get("repair_type"); repairType != "" {
+	if repairType := queryValue(query, "repair_type", "repairType"); repairType != "" {
 		filters["repair_type"] = repairType
 	}
 
@@ -52,24 +54,51 @@ func (h *RepairHandler) ListRepairs(w http.ResponseWriter, r *http.Request) {
 		filters["status"] = status
 	}
 
-	if page := query.Get("page"); page != "" {
-		filters["page"] = page
-	}
-
-	if limit := query.Get("limit"); limit != "" {
-		filters["limit"] = limit
+	page, limit, cursor, err := parsePageParams(query)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "Invalid cursor",
+		})
+		return
 	}
 
 	// Get repairs from repository
-	repairs, err := h.repairRepo.FindAll(ctx, filters)
+	repairs, total, cursors, err := h.repairRepo.FindPage(ctx, filters, cursor, page, limit)
 	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			respondJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "Invalid cursor",
+			})
+			return
+		}
 		respondJSON(w, http.StatusInternalServerError, map[string]string{
 			"error": "Failed to retrieve repairs",
 		})
 		return
 	}
 
-	respondJSON(w, http.StatusOK, repairs)
+	if isAPIV2(r) {
+		response := models.NewListResponse(repairs, limit, cursors)
+		if cursor == nil {
+			response.WithTotal(page, total)
+		}
+		respondJSON(w, http.StatusOK, response)
+		return
+	}
+
+	response := &models.RepairListResponse{
+		Data:       repairs,
+		Limit:      limit,
+		NextCursor: cursors.Next,
+		PrevCursor: cursors.Prev,
+	}
+	if cursor == nil {
+		response.Total = total
+		response.Page = page
+		response.TotalPages = (total + limit - 1) / limit
+	}
+
+	respondJSON(w, http.StatusOK, response)
 }
 
 // GetRepair handles GET /api/v1/repairs/{id}
@@ -198,7 +227,7 @@ func (h *RepairHandler) UpdateRepair(w http.ResponseWriter, r *http.Request) {
 		updates["end_date"] = *req.EndDate
 	}
 	if req.Cost != nil {
-		updates["cost"] = *req.Cost
+		repository.SetRepairCostUpdates(updates, req.Cost)
 	}
 	if req.InvoiceNumber != nil {
 		updates["invoice_number"] = *req.InvoiceNumber
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/service"
+)
+
+// SearchHandler handles the global search HTTP requests
+type SearchHandler struct {
+	searchService *service.SearchService
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(searchService *service.SearchService) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+// Search handles GET /api/v1/search?q=. It looks through cars, operators,
+// garages, accidents and repairs; type (comma-separated or repeated)
+// restricts the kinds of records returned and limit caps their number.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filters := &models.SearchFilters{
+		Query: query.Get("q"),
+		Limit: parseIntQuery(query.Get("limit"), 20),
+	}
+
+	for _, value := range query["type"] {
+		for _, resultType := range strings.Split(value, ",") {
+			if resultType = strings.TrimSpace(resultType); resultType != "" {
+				filters.Types = append(filters.Types, models.SearchResultType(resultType))
+			}
+		}
+	}
+
+	response, err := h.searchService.Search(r.Context(), filters)
+	if err != nil {
+		if strings.Contains(err.Error(), "search query must") || strings.Contains(err.Error(), "invalid type") {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to search"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
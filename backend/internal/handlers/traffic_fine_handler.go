@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/middleware"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/service"
+)
+
+// TrafficFineHandler handles traffic fine HTTP requests
+type TrafficFineHandler struct {
+	fineService *service.TrafficFineService
+}
+
+// NewTrafficFineHandler creates a new traffic fine handler
+func NewTrafficFineHandler(fineService *service.TrafficFineService) *TrafficFineHandler {
+	return &TrafficFineHandler{
+		fineService: fineService,
+	}
+}
+
+// ListFines handles GET /api/v1/fines?car_id=&operator_id=&status=
+func (h *TrafficFineHandler) ListFines(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filters := &models.TrafficFineFilters{
+		CarID:      query.Get("car_id"),
+		OperatorID: query.Get("operator_id"),
+		Status:     models.FineStatus(query.Get("status")),
+	}
+
+	fines, err := h.fineService.GetFines(r.Context(), filters)
+	if err != nil {
+		respondTrafficFineError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, fines)
+}
+
+// GetCarFines handles GET /api/v1/cars/{id}/fines
+func (h *TrafficFineHandler) GetCarFines(w http.ResponseWriter, r *http.Request) {
+	fines, err := h.fineService.GetFines(r.Context(), &models.TrafficFineFilters{CarID: r.PathValue("id")})
+	if err != nil {
+		respondTrafficFineError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, fines)
+}
+
+// GetDueFines handles GET /api/v1/fines/due?days=N, listing the pending
+// fines to designate within N days (the reminder window by default)
+func (h *TrafficFineHandler) GetDueFines(w http.ResponseWriter, r *http.Request) {
+	days := 0
+	if value := r.URL.Query().Get("days"); value != "" {
+		var err error
+		if days, err = strconv.Atoi(value); err != nil || days <= 0 {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid days value (expected a positive number)"})
+			return
+		}
+	}
+
+	fines, err := h.fineService.GetDueFines(r.Context(), days)
+	if err != nil {
+		respondTrafficFineError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, fines)
+}
+
+// ExportDesignations handles GET /api/v1/fines/designation-export?car_id=,
+// downloading the CSV of the pending fines whose driver is known
+func (h *TrafficFineHandler) ExportDesignations(w http.ResponseWriter, r *http.Request) {
+	filters := &models.TrafficFineFilters{CarID: r.URL.Query().Get("car_id")}
+
+	data, err := h.fineService.ExportDesignations(r.Context(), filters)
+	if err != nil {
+		respondTrafficFineError(w, err)
+		return
+	}
+
+	filename := fmt.Sprintf("designations-%s.csv", time.Now().Format(dateLayout))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// GetFine handles GET /api/v1/fines/{id}
+func (h *TrafficFineHandler) GetFine(w http.ResponseWriter, r *http.Request) {
+	fine, err := h.fineService.GetFine(r.Context(), r.PathValue("id"))
+	if err != nil {
+		respondTrafficFineError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, fine)
+}
+
+// CreateFine handles POST /api/v1/fines
+func (h *TrafficFineHandler) CreateFine(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateTrafficFineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	fine, err := h.fineService.CreateFine(r.Context(), &req, user.ID)
+	if err != nil {
+		respondTrafficFineError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, fine)
+}
+
+// UpdateFine handles PUT /api/v1/fines/{id}
+func (h *TrafficFineHandler) UpdateFine(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateTrafficFineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	fine, err := h.fineService.UpdateFine(r.Context(), r.PathValue("id"), &req, user.ID)
+	if err != nil {
+		respondTrafficFineError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, fine)
+}
+
+// DesignateDriver handles POST /api/v1/fines/{id}/designate
+func (h *TrafficFineHandler) DesignateDriver(w http.ResponseWriter, r *http.Request) {
+	var req models.DesignateDriverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	fine, err := h.fineService.DesignateDriver(r.Context(), r.PathValue("id"), &req, user.ID)
+	if err != nil {
+		respondTrafficFineError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, fine)
+}
+
+// DeleteFine handles DELETE /api/v1/fines/{id}
+func (h *TrafficFineHandler) DeleteFine(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+
+	if err := h.fineService.DeleteFine(r.Context(), r.PathValue("id"), user.ID); err != nil {
+		respondTrafficFineError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Traffic fine deleted successfully"})
+}
+
+// respondTrafficFineError maps traffic fine service errors to HTTP responses
+func respondTrafficFineError(w http.ResponseWriter, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": msg})
+	case strings.Contains(msg, "already"):
+		respondJSON(w, http.StatusConflict, map[string]string{"error": msg})
+	case strings.HasPrefix(msg, "failed"):
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to process traffic fine request"})
+	default:
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+	}
+}
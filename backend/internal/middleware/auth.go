@@ -3,8 +3,11 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"strings"
 
+	"github.com/goldenkiwi/autoparc/internal/models"
 	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
 )
 
 // contextKey is a custom type for context keys to avoid collisions
@@ -13,10 +16,40 @@ type contextKey string
 const (
 	// UserContextKey is the key for storing user in context
 	UserContextKey contextKey = "user"
+	// SessionContextKey is the key for storing the current session in context
+	SessionContextKey contextKey = "session"
+	// APITokenContextKey is the key for storing the API token in context when
+	// the request was authenticated by one instead of a session
+	APITokenContextKey contextKey = "api_token"
 )
 
-// AuthMiddleware validates session and adds user to context
+// authOptions lists the restricted sessions an endpoint accepts
+type authOptions struct {
+	allowPending        bool
+	allowPasswordChange bool
+}
+
+// AuthMiddleware validates session and adds user to context. Sessions still
+// awaiting the second factor or a password change are rejected.
 func AuthMiddleware(authService *service.AuthService, cookieName string) func(http.Handler) http.Handler {
+	return authenticate(authService, cookieName, authOptions{})
+}
+
+// TwoFactorMiddleware is like AuthMiddleware but also accepts sessions
+// awaiting the second factor. It protects the endpoints used to verify the
+// code or enroll, which handlers must restrict accordingly.
+func TwoFactorMiddleware(authService *service.AuthService, cookieName string) func(http.Handler) http.Handler {
+	return authenticate(authService, cookieName, authOptions{allowPending: true, allowPasswordChange: true})
+}
+
+// PasswordChangeMiddleware is like AuthMiddleware but also accepts sessions
+// opened with an expired password. It protects the endpoints needed to
+// choose a new one.
+func PasswordChangeMiddleware(authService *service.AuthService, cookieName string) func(http.Handler) http.Handler {
+	return authenticate(authService, cookieName, authOptions{allowPasswordChange: true})
+}
+
+func authenticate(authService *service.AuthService, cookieName string, opts authOptions) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get session cookie
@@ -27,15 +60,107 @@ func AuthMiddleware(authService *service.AuthService, cookieName string) func(ht
 			}
 
 			// Validate session
-			user, err := authService.ValidateSession(r.Context(), cookie.Value)
+			user, session, err := authService.AuthenticateSession(r.Context(), cookie.Value)
+			if err != nil {
+				http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+				return
+			}
+
+			if session.MFAPending && !opts.allowPending {
+				http.Error(w, `{"error":"Two-factor authentication required"}`, http.StatusUnauthorized)
+				return
+			}
+
+			if session.PasswordChangeRequired && !opts.allowPasswordChange {
+				http.Error(w, `{"error":"Password change required"}`, http.StatusForbidden)
+				return
+			}
+
+			// Cookies are sent by the browser on cross-site requests too, so
+			// state-changing requests must prove they know the CSRF token
+			if !isSafeMethod(r.Method) && !utils.CheckCSRFToken(cookie.Value, r.Header.Get(CSRFHeader)) {
+				http.Error(w, `{"error":"Invalid CSRF token"}`, http.StatusForbidden)
+				return
+			}
+
+			// Add user and session to context
+			ctx := context.WithValue(r.Context(), UserContextKey, user)
+			ctx = context.WithValue(ctx, SessionContextKey, session)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// APIAuthMiddleware accepts either a session cookie, like AuthMiddleware, or
+// an API token in an Authorization: Bearer header. API tokens are limited to
+// the resource their scopes cover, taken from the URL, and writes need a
+// write scope. No CSRF token is required for them since browsers never send
+// the header on their own.
+func APIAuthMiddleware(authService *service.AuthService, apiTokenService *service.APITokenService, cookieName string) func(http.Handler) http.Handler {
+	sessionAuth := authenticate(authService, cookieName, authOptions{})
+
+	return func(next http.Handler) http.Handler {
+		withSession := sessionAuth(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rawToken, ok := bearerToken(r)
+			if !ok {
+				withSession.ServeHTTP(w, r)
+				return
+			}
+
+			user, token, err := apiTokenService.Authenticate(r.Context(), rawToken, utils.ClientIP(r))
 			if err != nil {
 				http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
 				return
 			}
 
-			// Add user to context
+			for _, resource := range apiResources(r.URL.Path) {
+				if !token.Allows(resource, !isSafeMethod(r.Method)) {
+					http.Error(w, `{"error":"Insufficient scope"}`, http.StatusForbidden)
+					return
+				}
+			}
+
 			ctx := context.WithValue(r.Context(), UserContextKey, user)
+			ctx = context.WithValue(ctx, APITokenContextKey, token)
+			ctx = models.ContextWithAPIToken(ctx, token.ID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// bearerToken returns the token of an Authorization: Bearer header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// apiSubResources lists the resources exposed by routes nested under another
+// resource, e.g. /api/v1/cars/{id}/fines returns fines. A token needs scopes
+// on both the parent and the nested resources.
+var apiSubResources = map[string][]string{
+	"documents":          {"documents"},
+	"leases":             {"leases"},
+	"fuel-transactions":  {"fuel-transactions"},
+	"fines":              {"fines"},
+	"assignment-history": {"operators"},
+	"timeline":           {"operators", "accidents", "repairs"},
+}
+
+// apiResources returns the resources addressed by an API path, e.g. "cars"
+// and "documents" for /api/v1/cars/{id}/documents
+func apiResources(path string) []string {
+	parts := strings.SplitN(strings.TrimPrefix(path, "/api/"), "/", 5)
+	if len(parts) < 2 {
+		return []string{""}
+	}
+	resources := []string{parts[1]}
+	if len(parts) >= 4 {
+		resources = append(resources, apiSubResources[parts[3]]...)
+	}
+	return resources
+}
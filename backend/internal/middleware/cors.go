@@ -22,8 +22,9 @@ func CORS(allowedOrigins []string) func(http.Handler) http.Handler {
 			if allowed {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
 				w.Header().Set("Access-Control-Allow-Credentials", "true")
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+				w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-CSRF-Token, Tus-Resumable, Upload-Length, Upload-Offset, Upload-Metadata")
+				w.Header().Set("Access-Control-Expose-Headers", "Location, Retry-After, X-CSRF-Token, Tus-Resumable, Upload-Length, Upload-Offset, Upload-Expires")
 			}
 
 			// Handle preflight requests
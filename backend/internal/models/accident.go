@@ -65,6 +65,18 @@ type UpdateAccidentStatusRequest struct {
 	Status AccidentStatus `json:"status" binding:"required"`
 }
 
+// AccidentListResponse represents a paginated list of accidents. Total, Page and
+// TotalPages are only set when paginating by page number.
+type AccidentListResponse struct {
+	Data       []*Accident `json:"data"`
+	Total      int         `json:"total"`
+	Page       int         `json:"page"`
+	Limit      int         `json:"limit"`
+	TotalPages int         `json:"total_pages"`
+	NextCursor *string     `json:"next_cursor"`
+	PrevCursor *string     `json:"prev_cursor"`
+}
+
 // Validate validates the CreateAccidentRequest
 func (r *CreateAccidentRequest) Validate() error {
 	if r.CarID == "" {
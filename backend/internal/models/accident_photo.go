@@ -2,35 +2,79 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 )
 
 // AccidentPhoto represents a photo of an accident
 type AccidentPhoto struct {
-	ID              string    `json:"id"`
-	AccidentID      string    `json:"accidentId"`
-	Filename        string    `json:"filename"`
-	FileData        []byte    `json:"-"` // Not included in JSON
-	FileSize        int       `json:"fileSize"`
-	MimeType        string    `json:"mimeType"`
-	CompressionType string    `json:"compressionType"`
-	Description     *string   `json:"description,omitempty"`
-	UploadedAt      time.Time `json:"uploadedAt"`
-	UploadedBy      *string   `json:"uploadedBy,omitempty"`
+	ID              string     `json:"id"`
+	AccidentID      string     `json:"accidentId"`
+	Filename        string     `json:"filename"`
+	FileData        []byte     `json:"-"` // Not included in JSON
+	FileSize        int        `json:"fileSize"`
+	MimeType        string     `json:"mimeType"`
+	CompressionType string     `json:"compressionType"`
+	Description     *string    `json:"description,omitempty"`
+	UploadedAt      time.Time  `json:"uploadedAt"`
+	UploadedBy      *string    `json:"uploadedBy,omitempty"`
+	Width           *int       `json:"width,omitempty"`
+	Height          *int       `json:"height,omitempty"`
+	TakenAt         *time.Time `json:"takenAt,omitempty"`
+	Latitude        *float64   `json:"latitude,omitempty"`
+	Longitude       *float64   `json:"longitude,omitempty"`
+	ThumbnailData   []byte     `json:"-"`
+	MediumData      []byte     `json:"-"`
+	VariantMimeType *string    `json:"-"`
 }
 
 // AccidentPhotoMetadata represents photo metadata without binary data
 type AccidentPhotoMetadata struct {
-	ID              string    `json:"id"`
-	AccidentID      string    `json:"accidentId"`
-	Filename        string    `json:"filename"`
-	FileSize        int       `json:"fileSize"`
-	MimeType        string    `json:"mimeType"`
-	CompressionType string    `json:"compressionType"`
-	Description     *string   `json:"description,omitempty"`
-	UploadedAt      time.Time `json:"uploadedAt"`
-	UploadedBy      *string   `json:"uploadedBy,omitempty"`
+	ID              string     `json:"id"`
+	AccidentID      string     `json:"accidentId"`
+	Filename        string     `json:"filename"`
+	FileSize        int        `json:"fileSize"`
+	MimeType        string     `json:"mimeType"`
+	CompressionType string     `json:"compressionType"`
+	Description     *string    `json:"description,omitempty"`
+	UploadedAt      time.Time  `json:"uploadedAt"`
+	UploadedBy      *string    `json:"uploadedBy,omitempty"`
+	Width           *int       `json:"width,omitempty"`
+	Height          *int       `json:"height,omitempty"`
+	TakenAt         *time.Time `json:"takenAt,omitempty"`
+	Latitude        *float64   `json:"latitude,omitempty"`
+	Longitude       *float64   `json:"longitude,omitempty"`
+	HasVariants     bool       `json:"hasVariants"`
+}
+
+// PhotoSize represents a stored variant of a photo
+type PhotoSize string
+
+const (
+	PhotoSizeOriginal PhotoSize = "original"
+	PhotoSizeMedium   PhotoSize = "medium"
+	PhotoSizeThumb    PhotoSize = "thumb"
+)
+
+// PhotoVariant represents the binary content of one photo variant
+type PhotoVariant struct {
+	PhotoID  string
+	Filename string
+	MimeType string
+	Data     []byte
+}
+
+// ParsePhotoSize parses the size query parameter, defaulting to the original
+func ParsePhotoSize(value string) (PhotoSize, error) {
+	switch PhotoSize(value) {
+	case "", PhotoSizeOriginal:
+		return PhotoSizeOriginal, nil
+	case PhotoSizeMedium, PhotoSizeThumb:
+		return PhotoSize(value), nil
+	default:
+		return "", errors.New("taille de photo invalide. Tailles acceptées: thumb, medium, original")
+	}
 }
 
 // UploadPhotoRequest represents the request to upload a photo
@@ -44,7 +88,6 @@ type UploadPhotoRequest struct {
 }
 
 const (
-	MaxPhotoSize        = 5 * 1024 * 1024 // 5MB
 	CompressionTypeGzip = "gzip"
 )
 
@@ -56,43 +99,112 @@ var allowedMimeTypes = map[string]bool{
 	"image/gif":  true,
 }
 
-// Validate validates the UploadPhotoRequest
-func (r *UploadPhotoRequest) Validate() error {
+// Validate validates the UploadPhotoRequest against the configured maximum file size
+func (r *UploadPhotoRequest) Validate(maxSize int64) error {
 	if r.AccidentID == "" {
 		return errors.New("l'identifiant de l'accident est requis")
 	}
 
-	if r.FileData == nil || len(r.FileData) == 0 {
-		return errors.New("le fichier est requis")
-	}
-	
-	if r.FileSize > MaxPhotoSize {
-		return errors.New("la taille du fichier ne peut pas dépasser 5MB")
+	if err := validateFileSize(r.FileData, r.FileSize, maxSize); err != nil {
+		return err
 	}
-	
-	if r.FileSize <= 0 {
-		return errors.New("le fichier est vide")
-	}
-	
+
 	// Validate MIME type
 	if !allowedMimeTypes[r.MimeType] {
 		return errors.New("type de fichier non supporté. Types acceptés: JPEG, PNG, WebP, GIF")
 	}
-	
-	// Validate filename
-	if r.FileName == "" {
+
+	return validateFileName(r.FileName, photoExtensions)
+}
+
+// photoExtensions lists the file extensions accepted for photos
+var photoExtensions = map[string]bool{
+	"jpg":  true,
+	"jpeg": true,
+	"png":  true,
+	"webp": true,
+	"gif":  true,
+}
+
+// validateFileSize checks that an uploaded file is present and within maxSize
+func validateFileSize(data []byte, size int, maxSize int64) error {
+	if len(data) == 0 {
+		return errors.New("le fichier est requis")
+	}
+
+	if int64(size) > maxSize {
+		return fmt.Errorf("la taille du fichier ne peut pas dépasser %s", FormatFileSize(maxSize))
+	}
+
+	if size <= 0 {
+		return errors.New("le fichier est vide")
+	}
+
+	return nil
+}
+
+// validateFileName checks that the filename is present and has an allowed extension
+func validateFileName(name string, allowedExtensions map[string]bool) error {
+	if name == "" {
 		return errors.New("le nom du fichier est requis")
 	}
-	
+
 	// Additional security check for file extension
-	ext := strings.ToLower(r.FileName[strings.LastIndex(r.FileName, ".")+1:])
-	if ext != "jpg" && ext != "jpeg" && ext != "png" && ext != "webp" && ext != "gif" {
+	ext := strings.ToLower(name[strings.LastIndex(name, ".")+1:])
+	if !allowedExtensions[ext] {
 		return errors.New("extension de fichier non supportée")
 	}
-	
+
 	return nil
 }
 
+// PhotoUpload represents an in-progress resumable photo upload
+type PhotoUpload struct {
+	ID           string    `json:"id"`
+	AccidentID   string    `json:"accidentId"`
+	Filename     string    `json:"filename"`
+	Description  *string   `json:"description,omitempty"`
+	UploadLength int64     `json:"uploadLength"`
+	UploadOffset int64     `json:"uploadOffset"`
+	CreatedAt    time.Time `json:"createdAt"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	CreatedBy    *string   `json:"createdBy,omitempty"`
+}
+
+// IsComplete reports whether all bytes of the upload have been received
+func (u *PhotoUpload) IsComplete() bool {
+	return u.UploadOffset >= u.UploadLength
+}
+
+// PhotoUploadResult is the per-file outcome of a batch upload
+type PhotoUploadResult struct {
+	Filename string                 `json:"filename"`
+	Success  bool                   `json:"success"`
+	Photo    *AccidentPhotoMetadata `json:"photo,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// BatchUploadResponse is the response of a batch photo upload
+type BatchUploadResponse struct {
+	Results  []PhotoUploadResult `json:"results"`
+	Uploaded int                 `json:"uploaded"`
+	Failed   int                 `json:"failed"`
+}
+
+// FormatFileSize formats a byte count for error messages (e.g. 10MB)
+func FormatFileSize(size int64) string {
+	switch {
+	case size >= 1<<20 && size%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", size>>20)
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%dKB", size>>10)
+	default:
+		return fmt.Sprintf("%d octets", size)
+	}
+}
+
 // IsValidMimeType checks if the MIME type is allowed
 func IsValidMimeType(mimeType string) bool {
 	return allowedMimeTypes[mimeType]
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// AccountTokenPurpose is what an account token can be used for
+type AccountTokenPurpose string
+
+const (
+	AccountTokenPurposeInvitation    AccountTokenPurpose = "invitation"
+	AccountTokenPurposePasswordReset AccountTokenPurpose = "password_reset"
+)
+
+// AccountToken is a single-use token sent by email to let an employee set
+// their password
+type AccountToken struct {
+	ID        string              `json:"id"`
+	UserID    string              `json:"userId"`
+	Purpose   AccountTokenPurpose `json:"purpose"`
+	Token     string              `json:"-"` // Raw token, only known when created
+	TokenHash string              `json:"-"`
+	ExpiresAt time.Time           `json:"expiresAt"`
+	UsedAt    *time.Time          `json:"usedAt,omitempty"`
+	CreatedBy *string             `json:"createdBy,omitempty"`
+	CreatedAt time.Time           `json:"createdAt"`
+}
+
+// InviteEmployeeRequest represents the request to invite an employee, who
+// then chooses their own password
+type InviteEmployeeRequest struct {
+	Email     string `json:"email"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Role      string `json:"role"`
+}
+
+// ForgotPasswordRequest represents the request to receive a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// SetPasswordRequest represents the request to set a password with a token
+// received by email
+type SetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
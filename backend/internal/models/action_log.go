@@ -9,11 +9,28 @@ import (
 type ActionType string
 
 const (
-	ActionTypeCreate       ActionType = "create"
-	ActionTypeUpdate       ActionType = "update"
-	ActionTypeDelete       ActionType = "delete"
-	ActionTypeStatusChange ActionType = "status_change"
-	ActionTypePhotoUpload  ActionType = "photo_upload"
+	ActionTypeCreate             ActionType = "create"
+	ActionTypeUpdate             ActionType = "update"
+	ActionTypeDelete             ActionType = "delete"
+	ActionTypeStatusChange       ActionType = "status_change"
+	ActionTypePhotoUpload        ActionType = "photo_upload"
+	ActionTypeLoginFailed        ActionType = "login_failed"
+	ActionTypeLock               ActionType = "lock"
+	ActionTypeUnlock             ActionType = "unlock"
+	ActionTypeRevokeSessions     ActionType = "revoke_sessions"
+	ActionTypeInvite             ActionType = "invite"
+	ActionTypeInvitationAccepted ActionType = "invitation_accepted"
+	ActionTypePasswordReset      ActionType = "password_reset"
+	ActionTypeTwoFactorEnable    ActionType = "2fa_enable"
+	ActionTypeTwoFactorDisable   ActionType = "2fa_disable"
+	ActionTypeTwoFactorReset     ActionType = "2fa_reset"
+	ActionTypeRecoveryCodeUsed   ActionType = "recovery_code_used"
+	ActionTypeAPITokenCreate     ActionType = "api_token_create"
+	ActionTypeAPITokenRevoke     ActionType = "api_token_revoke"
+	ActionTypeSSOLink            ActionType = "sso_link"
+	ActionTypeLeaseReturn        ActionType = "lease_return"
+	ActionTypeFineDesignation    ActionType = "fine_designation"
+	ActionTypeLifecycleEvent     ActionType = "lifecycle_event"
 )
 
 // EntityType represents the type of entity
@@ -27,6 +44,10 @@ const (
 	EntityTypeGarage                 EntityType = "garage"
 	EntityTypeAccident               EntityType = "accident"
 	EntityTypeRepair                 EntityType = "repair"
+	EntityTypeDocument               EntityType = "document"
+	EntityTypeLeaseContract          EntityType = "lease_contract"
+	EntityTypeFuelCard               EntityType = "fuel_card"
+	EntityTypeTrafficFine            EntityType = "traffic_fine"
 )
 
 // ActionLog represents an audit log entry
@@ -36,6 +57,7 @@ type ActionLog struct {
 	EntityID    string          `json:"entityId"`
 	ActionType  ActionType      `json:"actionType"`
 	PerformedBy string          `json:"performedBy"`
+	APITokenID  *string         `json:"apiTokenId,omitempty"`
 	Changes     json.RawMessage `json:"changes"`
 	Timestamp   time.Time       `json:"timestamp"`
 }
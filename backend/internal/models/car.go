@@ -13,30 +13,41 @@ const (
 	CarStatusRetired     CarStatus = "retired"
 )
 
-// Car represents a car in the fleet
+// Car represents a car in the fleet. TankCapacity is in litres.
 type Car struct {
-	ID                 string            `json:"id"`
-	LicensePlate       string            `json:"licensePlate"`
-	Brand              string            `json:"brand"`
-	Model              string            `json:"model"`
-	GreyCardNumber     string            `json:"greyCardNumber"`
-	InsuranceCompanyID string            `json:"insuranceCompanyId"`
-	RentalStartDate    time.Time         `json:"rentalStartDate"`
-	Status             CarStatus         `json:"status"`
-	CreatedAt          time.Time         `json:"createdAt"`
-	UpdatedAt          time.Time         `json:"updatedAt"`
-	CreatedBy          string            `json:"createdBy"`
-	InsuranceCompany   *InsuranceCompany `json:"insuranceCompany,omitempty"`
-	Accidents          []*Accident       `json:"accidents,omitempty"`
-	Repairs            []*Repair         `json:"repairs,omitempty"`
+	ID                 string    `json:"id"`
+	LicensePlate       string    `json:"licensePlate"`
+	PlateCountry       string    `json:"plateCountry"`
+	VIN                *string   `json:"vin,omitempty"`
+	Brand              string    `json:"brand"`
+	Model              string    `json:"model"`
+	GreyCardNumber     string    `json:"greyCardNumber"`
+	FuelType           *FuelType `json:"fuelType,omitempty"`
+	TankCapacity       *int      `json:"tankCapacity,omitempty"`
+	InsuranceCompanyID string    `json:"insuranceCompanyId"`
+	RentalStartDate    time.Time `json:"rentalStartDate"`
+	Status             CarStatus `json:"status"`
+	// ImmobilizationReason is set while the car is in maintenance
+	ImmobilizationReason *ImmobilizationReason `json:"immobilizationReason,omitempty"`
+	CreatedAt            time.Time             `json:"createdAt"`
+	UpdatedAt            time.Time             `json:"updatedAt"`
+	CreatedBy            string                `json:"createdBy"`
+	InsuranceCompany     *InsuranceCompany     `json:"insuranceCompany,omitempty"`
+	Accidents            []*Accident           `json:"accidents,omitempty"`
+	Repairs              []*Repair             `json:"repairs,omitempty"`
 }
 
-// CreateCarRequest represents the request to create a new car
+// CreateCarRequest represents the request to create a new car. VIN is
+// optional; when given without a Brand, the brand is decoded from it.
 type CreateCarRequest struct {
 	LicensePlate       string    `json:"licensePlate"`
+	PlateCountry       string    `json:"plateCountry,omitempty"`
+	VIN                string    `json:"vin,omitempty"`
 	Brand              string    `json:"brand"`
 	Model              string    `json:"model"`
 	GreyCardNumber     string    `json:"greyCardNumber"`
+	FuelType           *FuelType `json:"fuelType,omitempty"`
+	TankCapacity       *int      `json:"tankCapacity,omitempty"`
 	InsuranceCompanyID string    `json:"insuranceCompanyId"`
 	RentalStartDate    time.Time `json:"rentalStartDate"`
 	Status             CarStatus `json:"status"`
@@ -47,26 +58,63 @@ type UpdateCarRequest struct {
 	Brand              *string    `json:"brand,omitempty"`
 	Model              *string    `json:"model,omitempty"`
 	GreyCardNumber     *string    `json:"greyCardNumber,omitempty"`
+	VIN                *string    `json:"vin,omitempty"`
+	FuelType           *FuelType  `json:"fuelType,omitempty"`
+	TankCapacity       *int       `json:"tankCapacity,omitempty"`
 	InsuranceCompanyID *string    `json:"insuranceCompanyId,omitempty"`
 	RentalStartDate    *time.Time `json:"rentalStartDate,omitempty"`
 	Status             *CarStatus `json:"status,omitempty"`
 }
 
-// CarFilters represents filters for car queries
+// IsValid checks if the car status is one of the known statuses
+func (s CarStatus) IsValid() bool {
+	switch s {
+	case CarStatusActive, CarStatusMaintenance, CarStatusRetired:
+		return true
+	}
+	return false
+}
+
+// CarFilters represents filters for car queries. Filters combine with AND;
+// nil pointers and empty values are ignored.
 type CarFilters struct {
-	Status    *CarStatus
-	Search    string
+	Status *CarStatus
+	// Statuses matches any of the given statuses
+	Statuses []CarStatus
+	Search   string
+	// VIN matches exactly, once normalized
+	VIN string
+
+	InsuranceCompanyID string
+	// Brand and Model match exactly, ignoring case
+	Brand string
+	Model string
+	// RentalStartFrom and RentalStartTo bound the rental start date, inclusive
+	RentalStartFrom *time.Time
+	RentalStartTo   *time.Time
+	// Assigned filters cars with or without a current operator
+	Assigned *bool
+	// Department matches the department of the current operator
+	Department          string
+	HasOpenAccident     *bool
+	HasRepairInProgress *bool
+
+	// Cursor, when set, selects the page next to it instead of Page
+	Cursor    *Cursor
 	Page      int
 	Limit     int
 	SortBy    string
 	SortOrder string
 }
 
-// CarListResponse represents a paginated list of cars
+// CarListResponse represents a paginated list of cars. TotalCount, Page and
+// TotalPages are only set when paginating by page number.
 type CarListResponse struct {
-	Cars       []*Car `json:"cars"`
-	TotalCount int    `json:"totalCount"`
-	Page       int    `json:"page"`
-	Limit      int    `json:"limit"`
-	TotalPages int    `json:"totalPages"`
+	Cars       []*Car  `json:"cars"`
+	TotalCount int     `json:"totalCount"`
+	Page       int     `json:"page"`
+	Limit      int     `json:"limit"`
+	TotalPages int     `json:"totalPages"`
+	NextCursor *string `json:"nextCursor"`
+	PrevCursor *string `json:"prevCursor"`
 }
@@ -0,0 +1,224 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/pkg/money"
+)
+
+// CarEventType represents a step in the life of a car
+type CarEventType string
+
+const (
+	// CarEventAcquisition: the car was bought or leased
+	CarEventAcquisition CarEventType = "acquisition"
+	// CarEventCommissioning: the car was put into service
+	CarEventCommissioning CarEventType = "commissioning"
+	// CarEventImmobilization: the car is off the road, for the given reason
+	CarEventImmobilization CarEventType = "immobilization"
+	// CarEventRelease: the immobilized car is back in service
+	CarEventRelease CarEventType = "release"
+	// CarEventSale: the car was sold
+	CarEventSale CarEventType = "sale"
+	// CarEventReturn: the car was returned to its lessor
+	CarEventReturn CarEventType = "return"
+	// CarEventRetirement: the car left the fleet for any other reason
+	CarEventRetirement CarEventType = "retirement"
+)
+
+// IsValid checks if the event type is one of the known types
+func (t CarEventType) IsValid() bool {
+	switch t {
+	case CarEventAcquisition, CarEventCommissioning, CarEventImmobilization, CarEventRelease,
+		CarEventSale, CarEventReturn, CarEventRetirement:
+		return true
+	}
+	return false
+}
+
+// ResultingStatus returns the status of a car after the event. Acquisition
+// keeps the current status.
+func (t CarEventType) ResultingStatus(current CarStatus) CarStatus {
+	switch t {
+	case CarEventCommissioning, CarEventRelease:
+		return CarStatusActive
+	case CarEventImmobilization:
+		return CarStatusMaintenance
+	case CarEventSale, CarEventReturn, CarEventRetirement:
+		return CarStatusRetired
+	}
+	return current
+}
+
+// CheckTransition checks that the event can happen to a car with the given
+// status. An immobilized car can be immobilized again to change the reason,
+// and a retired car can be put back into service.
+func (t CarEventType) CheckTransition(current CarStatus) error {
+	switch t {
+	case CarEventImmobilization:
+		if current == CarStatusRetired {
+			return errors.New("a retired car cannot be immobilized")
+		}
+	case CarEventRelease:
+		if current != CarStatusMaintenance {
+			return errors.New("only an immobilized car can be released")
+		}
+	case CarEventSale, CarEventReturn, CarEventRetirement:
+		if current == CarStatusRetired {
+			return errors.New("car is already retired")
+		}
+	}
+	return nil
+}
+
+// hasPrice tells whether events of the type carry a price and a counterparty
+func (t CarEventType) hasPrice() bool {
+	return t == CarEventAcquisition || t == CarEventSale || t == CarEventReturn
+}
+
+// ImmobilizationReason represents why a car is off the road
+type ImmobilizationReason string
+
+const (
+	ImmobilizationReasonAccident        ImmobilizationReason = "accident"
+	ImmobilizationReasonBreakdown       ImmobilizationReason = "breakdown"
+	ImmobilizationReasonWaitingForParts ImmobilizationReason = "waiting_for_parts"
+	ImmobilizationReasonOther           ImmobilizationReason = "other"
+)
+
+// IsValid checks if the reason is one of the known reasons
+func (r ImmobilizationReason) IsValid() bool {
+	switch r {
+	case ImmobilizationReasonAccident, ImmobilizationReasonBreakdown,
+		ImmobilizationReasonWaitingForParts, ImmobilizationReasonOther:
+		return true
+	}
+	return false
+}
+
+// CarLifecycleEvent records a step in the life of a car and the status
+// change it caused. Price is the purchase price of an acquisition or the
+// disposal price of a sale or return, and Counterparty the seller, buyer
+// or lessor.
+type CarLifecycleEvent struct {
+	ID             string                `json:"id"`
+	CarID          string                `json:"carId"`
+	Type           CarEventType          `json:"type"`
+	EventDate      time.Time             `json:"eventDate"`
+	PreviousStatus CarStatus             `json:"previousStatus"`
+	Status         CarStatus             `json:"status"`
+	Reason         *ImmobilizationReason `json:"reason,omitempty"`
+	AccidentID     *string               `json:"accidentId,omitempty"`
+	Price          *money.Money          `json:"price,omitempty"`
+	Counterparty   *string               `json:"counterparty,omitempty"`
+	Notes          *string               `json:"notes,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	CreatedBy      *string               `json:"createdBy,omitempty"`
+}
+
+// StatusChanged tells whether the event changed the status of the car
+func (e *CarLifecycleEvent) StatusChanged() bool {
+	return e.PreviousStatus != e.Status
+}
+
+// StatusChangeEvent returns the event recording a plain change of status,
+// as made by editing the car: leaving the maintenance status releases the
+// car, and entering it immobilizes the car for an unspecified reason
+func StatusChangeEvent(current, status CarStatus) CarEventType {
+	switch status {
+	case CarStatusMaintenance:
+		return CarEventImmobilization
+	case CarStatusRetired:
+		return CarEventRetirement
+	}
+	if current == CarStatusMaintenance {
+		return CarEventRelease
+	}
+	return CarEventCommissioning
+}
+
+// CreateCarLifecycleEventRequest represents the request to record a step in
+// the life of a car. The date defaults to today.
+type CreateCarLifecycleEventRequest struct {
+	Type         CarEventType          `json:"type"`
+	EventDate    *time.Time            `json:"eventDate,omitempty"`
+	Reason       *ImmobilizationReason `json:"reason,omitempty"`
+	AccidentID   *string               `json:"accidentId,omitempty"`
+	Price        *money.Money          `json:"price,omitempty"`
+	Counterparty *string               `json:"counterparty,omitempty"`
+	Notes        *string               `json:"notes,omitempty"`
+}
+
+// Validate checks that the request carries the details its type needs, and
+// only those
+func (r *CreateCarLifecycleEventRequest) Validate() error {
+	if !r.Type.IsValid() {
+		return errors.New("invalid event type. Must be: acquisition, commissioning, immobilization, release, sale, return, or retirement")
+	}
+
+	if r.Type == CarEventImmobilization {
+		if r.Reason == nil {
+			return errors.New("immobilization reason is required")
+		}
+		if !r.Reason.IsValid() {
+			return errors.New("invalid immobilization reason. Must be: accident, breakdown, waiting_for_parts, or other")
+		}
+	} else if r.Reason != nil {
+		return errors.New("a reason is only given for an immobilization")
+	}
+	if r.AccidentID != nil && (r.Reason == nil || *r.Reason != ImmobilizationReasonAccident) {
+		return errors.New("an accident is only given for an immobilization after an accident")
+	}
+
+	if !r.Type.hasPrice() {
+		if r.Price != nil || r.Counterparty != nil {
+			return errors.New("a price and a counterparty are only given for an acquisition, a sale or a return")
+		}
+		return nil
+	}
+	if r.Type == CarEventSale && r.Price == nil {
+		return errors.New("sale price is required")
+	}
+	if r.Price != nil {
+		if r.Price.IsNegative() {
+			return errors.New("price cannot be negative")
+		}
+		if !money.HasMaxPlaces(r.Price.Amount, amountPlaces) {
+			return errors.New("price cannot have more than 2 decimal places")
+		}
+		if r.Price.Currency != "" {
+			if err := money.ValidateCurrency(r.Price.Currency); err != nil {
+				return errors.New("invalid currency")
+			}
+		}
+	}
+	if r.Counterparty != nil && strings.TrimSpace(*r.Counterparty) == "" {
+		return errors.New("counterparty cannot be empty")
+	}
+	return nil
+}
+
+// CarTimelineEntryType represents the kind of a car timeline entry
+type CarTimelineEntryType string
+
+const (
+	CarTimelineLifecycle       CarTimelineEntryType = "lifecycle"
+	CarTimelineAssignmentStart CarTimelineEntryType = "assignment_start"
+	CarTimelineAssignmentEnd   CarTimelineEntryType = "assignment_end"
+	CarTimelineAccident        CarTimelineEntryType = "accident"
+	CarTimelineRepairStart     CarTimelineEntryType = "repair_start"
+	CarTimelineRepairEnd       CarTimelineEntryType = "repair_end"
+)
+
+// CarTimelineEntry is an entry of the history of a car. EntityID is the ID
+// of the lifecycle event, assignment, accident or repair it comes from, and
+// Details that record.
+type CarTimelineEntry struct {
+	Date     time.Time            `json:"date"`
+	Type     CarTimelineEntryType `json:"type"`
+	Summary  string               `json:"summary"`
+	EntityID string               `json:"entityId"`
+	Details  interface{}          `json:"details"`
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// DocumentType represents the kind of document attached to an entity
+type DocumentType string
+
+const (
+	DocumentTypeRegistrationCertificate DocumentType = "registration_certificate"
+	DocumentTypeInsuranceCertificate    DocumentType = "insurance_certificate"
+	DocumentTypeTechnicalInspection     DocumentType = "technical_inspection"
+	DocumentTypeInvoice                 DocumentType = "invoice"
+	DocumentTypeQuote                   DocumentType = "quote"
+	DocumentTypeDrivingLicence          DocumentType = "driving_licence"
+	DocumentTypeIdentityDocument        DocumentType = "identity_document"
+	DocumentTypeOther                   DocumentType = "other"
+)
+
+// IsValid checks if the document type is valid
+func (t DocumentType) IsValid() bool {
+	switch t {
+	case DocumentTypeRegistrationCertificate, DocumentTypeInsuranceCertificate,
+		DocumentTypeTechnicalInspection, DocumentTypeInvoice, DocumentTypeQuote,
+		DocumentTypeDrivingLicence, DocumentTypeIdentityDocument, DocumentTypeOther:
+		return true
+	}
+	return false
+}
+
+// MimeTypePDF is the MIME type of PDF documents
+const MimeTypePDF = "application/pdf"
+
+// documentEntityTypes lists the entities documents can be attached to
+var documentEntityTypes = map[EntityType]bool{
+	EntityTypeCar:      true,
+	EntityTypeRepair:   true,
+	EntityTypeOperator: true,
+	EntityTypeAccident: true,
+}
+
+// documentExtensions lists the file extensions accepted for documents
+var documentExtensions = map[string]bool{
+	"pdf":  true,
+	"jpg":  true,
+	"jpeg": true,
+	"png":  true,
+	"webp": true,
+	"gif":  true,
+}
+
+// IsValidDocumentEntityType checks if documents can be attached to the entity type
+func IsValidDocumentEntityType(entityType EntityType) bool {
+	return documentEntityTypes[entityType]
+}
+
+// IsValidDocumentMimeType checks if the MIME type is allowed for documents
+func IsValidDocumentMimeType(mimeType string) bool {
+	return mimeType == MimeTypePDF || allowedMimeTypes[mimeType]
+}
+
+// Document represents a versioned file attached to a car, repair, operator or accident
+type Document struct {
+	ID              string       `json:"id"`
+	DocumentGroupID string       `json:"documentGroupId"`
+	Version         int          `json:"version"`
+	EntityType      EntityType   `json:"entityType"`
+	EntityID        string       `json:"entityId"`
+	DocumentType    DocumentType `json:"documentType"`
+	Filename        string       `json:"filename"`
+	FileData        []byte       `json:"-"`
+	FileSize        int          `json:"fileSize"`
+	MimeType        string       `json:"mimeType"`
+	CompressionType string       `json:"compressionType"`
+	Description     *string      `json:"description,omitempty"`
+	ExpiryDate      *time.Time   `json:"expiryDate,omitempty"`
+	UploadedAt      time.Time    `json:"uploadedAt"`
+	UploadedBy      *string      `json:"uploadedBy,omitempty"`
+}
+
+// IsExpired reports whether the document expiry date is in the past
+func (d *Document) IsExpired(now time.Time) bool {
+	return d.ExpiryDate != nil && d.ExpiryDate.Before(now)
+}
+
+// DocumentFilters represents filters for listing documents
+type DocumentFilters struct {
+	EntityType     EntityType
+	EntityID       string
+	DocumentType   DocumentType
+	ExpiringBefore *time.Time
+}
+
+// UploadDocumentRequest represents a document upload, either a new document
+// or a new version of an existing one
+type UploadDocumentRequest struct {
+	EntityType   EntityType
+	EntityID     string
+	DocumentType DocumentType
+	FileName     string
+	FileData     []byte
+	FileSize     int
+	Description  *string
+	ExpiryDate   *time.Time
+}
+
+// Validate validates the UploadDocumentRequest against the configured maximum file size
+func (r *UploadDocumentRequest) Validate(maxSize int64) error {
+	if !IsValidDocumentEntityType(r.EntityType) {
+		return errors.New("type d'entité invalide. Types acceptés: car, repair, operator, accident")
+	}
+
+	if r.EntityID == "" {
+		return errors.New("l'identifiant de l'entité est requis")
+	}
+
+	if !r.DocumentType.IsValid() {
+		return errors.New("type de document invalide")
+	}
+
+	if err := validateFileSize(r.FileData, r.FileSize, maxSize); err != nil {
+		return err
+	}
+
+	return validateFileName(r.FileName, documentExtensions)
+}
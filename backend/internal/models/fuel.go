@@ -0,0 +1,279 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/shopspring/decimal"
+)
+
+// FuelType represents the fuel of a car or of a fuel transaction
+type FuelType string
+
+const (
+	FuelTypePetrol   FuelType = "petrol"
+	FuelTypeDiesel   FuelType = "diesel"
+	FuelTypeHybrid   FuelType = "hybrid"
+	FuelTypeElectric FuelType = "electric"
+	FuelTypeLPG      FuelType = "lpg"
+	// FuelTypeAdBlue and FuelTypeOther are only used by transactions
+	FuelTypeAdBlue FuelType = "adblue"
+	FuelTypeOther  FuelType = "other"
+)
+
+// IsValidForCar checks if the fuel type can be the fuel of a car
+func (f FuelType) IsValidForCar() bool {
+	switch f {
+	case FuelTypePetrol, FuelTypeDiesel, FuelTypeHybrid, FuelTypeElectric, FuelTypeLPG:
+		return true
+	}
+	return false
+}
+
+// Accepts reports whether a car running on f can take the product bought.
+// Hybrids run on petrol, LPG cars also on petrol, and AdBlue or other
+// products (washing, oil...) are never a mismatch.
+func (f FuelType) Accepts(product FuelType) bool {
+	switch product {
+	case FuelTypeAdBlue, FuelTypeOther:
+		return true
+	}
+	switch f {
+	case FuelTypeHybrid:
+		return product == FuelTypePetrol || product == FuelTypeElectric
+	case FuelTypeLPG:
+		return product == FuelTypeLPG || product == FuelTypePetrol
+	}
+	return f == product
+}
+
+// IsFuel reports whether the product is a liquid fuel, counted in the
+// consumption in litres
+func (f FuelType) IsFuel() bool {
+	return f == FuelTypePetrol || f == FuelTypeDiesel || f == FuelTypeLPG
+}
+
+// fuelProductLabels maps the product labels used by the card providers to
+// fuel types, matched on the upper-cased label with spaces removed
+var fuelProductLabels = []struct {
+	prefix string
+	fuel   FuelType
+}{
+	{"ADBLUE", FuelTypeAdBlue},
+	{"GAZOLE", FuelTypeDiesel},
+	{"GASOIL", FuelTypeDiesel},
+	{"DIESEL", FuelTypeDiesel},
+	{"B7", FuelTypeDiesel},
+	{"B10", FuelTypeDiesel},
+	{"XTL", FuelTypeDiesel},
+	{"HVO", FuelTypeDiesel},
+	{"SP95", FuelTypePetrol},
+	{"SP98", FuelTypePetrol},
+	{"E10", FuelTypePetrol},
+	{"E5", FuelTypePetrol},
+	{"E85", FuelTypePetrol},
+	{"SUPER", FuelTypePetrol},
+	{"SANSPLOMB", FuelTypePetrol},
+	{"PETROL", FuelTypePetrol},
+	{"ESSENCE", FuelTypePetrol},
+	{"GASOLINE", FuelTypePetrol},
+	{"GPL", FuelTypeLPG},
+	{"LPG", FuelTypeLPG},
+	{"ELECTRI", FuelTypeElectric},
+	{"RECHARGE", FuelTypeElectric},
+	{"KWH", FuelTypeElectric},
+}
+
+// ParseFuelProduct returns the fuel type of a provider product label, such
+// as "Gazole", "SP95-E10" or "GPL". Unknown labels are FuelTypeOther.
+func ParseFuelProduct(label string) FuelType {
+	key := strings.ToUpper(strings.Join(strings.Fields(label), ""))
+	key = strings.NewReplacer("-", "", "_", "").Replace(key)
+	for _, label := range fuelProductLabels {
+		if strings.HasPrefix(key, label.prefix) {
+			return label.fuel
+		}
+	}
+	return FuelTypeOther
+}
+
+// FuelCardStatus represents the status of a fuel card
+type FuelCardStatus string
+
+const (
+	FuelCardStatusActive    FuelCardStatus = "active"
+	FuelCardStatusBlocked   FuelCardStatus = "blocked"
+	FuelCardStatusCancelled FuelCardStatus = "cancelled"
+)
+
+// IsValid checks if the fuel card status is one of the known statuses
+func (s FuelCardStatus) IsValid() bool {
+	switch s {
+	case FuelCardStatusActive, FuelCardStatusBlocked, FuelCardStatusCancelled:
+		return true
+	}
+	return false
+}
+
+var fuelCardNumberRegex = regexp.MustCompile(`^[0-9A-Z]{4,32}$`)
+
+// NormalizeFuelCardNumber removes the spaces and dashes printed on cards
+func NormalizeFuelCardNumber(number string) string {
+	number = strings.NewReplacer(" ", "", "-", "").Replace(number)
+	return strings.ToUpper(strings.TrimSpace(number))
+}
+
+// ValidateFuelCardNumber checks a normalized card number
+func ValidateFuelCardNumber(number string) error {
+	if !fuelCardNumberRegex.MatchString(number) {
+		return errors.New("invalid card number (expected 4 to 32 letters or digits)")
+	}
+	return nil
+}
+
+// FuelCard represents a fleet fuel card. A card is given either to a car or
+// to an operator, who may then fill up any car assigned to them. PINs are
+// never stored.
+type FuelCard struct {
+	ID           string         `json:"id"`
+	CardNumber   string         `json:"cardNumber"`
+	Provider     string         `json:"provider"`
+	CarID        *string        `json:"carId,omitempty"`
+	OperatorID   *string        `json:"operatorId,omitempty"`
+	Status       FuelCardStatus `json:"status"`
+	ExpiryDate   *time.Time     `json:"expiryDate,omitempty"`
+	Notes        *string        `json:"notes,omitempty"`
+	CreatedAt    time.Time      `json:"createdAt"`
+	UpdatedAt    time.Time      `json:"updatedAt"`
+	CreatedBy    *string        `json:"createdBy,omitempty"`
+	LicensePlate *string        `json:"licensePlate,omitempty"`
+	OperatorName *string        `json:"operatorName,omitempty"`
+}
+
+// FuelCardFilters represents filters for fuel card queries
+type FuelCardFilters struct {
+	CarID      string
+	OperatorID string
+	Status     FuelCardStatus
+	Provider   string
+}
+
+// CreateFuelCardRequest represents the request to create a fuel card
+type CreateFuelCardRequest struct {
+	CardNumber string     `json:"cardNumber"`
+	Provider   string     `json:"provider"`
+	CarID      *string    `json:"carId,omitempty"`
+	OperatorID *string    `json:"operatorId,omitempty"`
+	ExpiryDate *time.Time `json:"expiryDate,omitempty"`
+	Notes      *string    `json:"notes,omitempty"`
+}
+
+// UpdateFuelCardRequest represents the request to update a fuel card. An
+// empty CarID or OperatorID takes the card back from its holder.
+type UpdateFuelCardRequest struct {
+	Provider   *string         `json:"provider,omitempty"`
+	CarID      *string         `json:"carId,omitempty"`
+	OperatorID *string         `json:"operatorId,omitempty"`
+	Status     *FuelCardStatus `json:"status,omitempty"`
+	ExpiryDate *time.Time      `json:"expiryDate,omitempty"`
+	Notes      *string         `json:"notes,omitempty"`
+}
+
+// FuelAnomaly flags a fuel transaction worth checking
+type FuelAnomaly string
+
+const (
+	// FuelAnomalyFuelTypeMismatch: the product does not match the car's fuel
+	FuelAnomalyFuelTypeMismatch FuelAnomaly = "fuel_type_mismatch"
+	// FuelAnomalyTankVolumeExceeded: more litres than the tank can hold
+	FuelAnomalyTankVolumeExceeded FuelAnomaly = "tank_volume_exceeded"
+	// FuelAnomalyUnassignedCar: the car had no operator at the time
+	FuelAnomalyUnassignedCar FuelAnomaly = "unassigned_car"
+	// FuelAnomalyUnmatchedCar: neither the card nor the plate gave a car
+	FuelAnomalyUnmatchedCar FuelAnomaly = "unmatched_car"
+)
+
+// IsValid checks if the anomaly is one of the known flags
+func (a FuelAnomaly) IsValid() bool {
+	switch a {
+	case FuelAnomalyFuelTypeMismatch, FuelAnomalyTankVolumeExceeded, FuelAnomalyUnassignedCar, FuelAnomalyUnmatchedCar:
+		return true
+	}
+	return false
+}
+
+// FuelTransaction represents a fuel card transaction imported from a
+// provider statement. The card number and plate are kept as imported.
+type FuelTransaction struct {
+	ID           string          `json:"id"`
+	CardNumber   string          `json:"cardNumber"`
+	Reference    string          `json:"reference"`
+	CardID       *string         `json:"cardId,omitempty"`
+	CarID        *string         `json:"carId,omitempty"`
+	OperatorID   *string         `json:"operatorId,omitempty"`
+	LicensePlate *string         `json:"licensePlate,omitempty"`
+	TransactedAt time.Time       `json:"transactedAt"`
+	Station      *string         `json:"station,omitempty"`
+	Product      string          `json:"product"`
+	FuelType     FuelType        `json:"fuelType"`
+	Volume       decimal.Decimal `json:"volume"`
+	Amount       money.Money     `json:"amount"`
+	Mileage      *int            `json:"mileage,omitempty"`
+	// Consumption is in litres per 100 km since the previous fill-up
+	Consumption *decimal.Decimal `json:"consumption,omitempty"`
+	Anomalies   []FuelAnomaly    `json:"anomalies"`
+	ImportedAt  time.Time        `json:"importedAt"`
+	ImportedBy  *string          `json:"importedBy,omitempty"`
+}
+
+// HasAnomaly reports whether the transaction is flagged with the anomaly
+func (t *FuelTransaction) HasAnomaly(anomaly FuelAnomaly) bool {
+	for _, a := range t.Anomalies {
+		if a == anomaly {
+			return true
+		}
+	}
+	return false
+}
+
+// ComputeConsumption returns the consumption in litres per 100 km between a
+// previous odometer reading and the fill-up, assuming the tank was filled
+// both times. It returns nil without a mileage or when it did not increase.
+func (t *FuelTransaction) ComputeConsumption(previousMileage int) *decimal.Decimal {
+	if t.Mileage == nil || *t.Mileage <= previousMileage {
+		return nil
+	}
+	distance := decimal.NewFromInt(int64(*t.Mileage - previousMileage))
+	consumption := t.Volume.Mul(decimal.NewFromInt(100)).Div(distance).Round(2)
+	return &consumption
+}
+
+// FuelTransactionFilters represents filters for fuel transaction queries.
+// From and To bound the transaction date, inclusive.
+type FuelTransactionFilters struct {
+	CarID   string
+	CardID  string
+	Anomaly FuelAnomaly
+	// Flagged selects the transactions with at least one anomaly
+	Flagged bool
+	From    *time.Time
+	To      *time.Time
+}
+
+// FuelImportLineError reports a statement line that could not be imported
+type FuelImportLineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// FuelImportResult summarizes the import of a provider statement. Lines
+// already imported are counted as duplicates and left untouched.
+type FuelImportResult struct {
+	Imported   int                   `json:"imported"`
+	Duplicates int                   `json:"duplicates"`
+	Flagged    int                   `json:"flagged"`
+	Errors     []FuelImportLineError `json:"errors"`
+}
@@ -0,0 +1,212 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/shopspring/decimal"
+)
+
+// LeaseStatus represents the status of a lease contract
+type LeaseStatus string
+
+const (
+	LeaseStatusActive   LeaseStatus = "active"
+	LeaseStatusReturned LeaseStatus = "returned"
+)
+
+// excessKmPricePlaces is the number of decimal places of the price per
+// excess kilometre
+const excessKmPricePlaces = 4
+
+// LeaseContract represents the lease contract of a car. Amounts are in the
+// currency of the monthly rent.
+type LeaseContract struct {
+	ID             string      `json:"id"`
+	CarID          string      `json:"carId"`
+	Lessor         string      `json:"lessor"`
+	ContractNumber string      `json:"contractNumber"`
+	StartDate      time.Time   `json:"startDate"`
+	EndDate        time.Time   `json:"endDate"`
+	MonthlyRent    money.Money `json:"monthlyRent"`
+	// ContractedMileage is the number of kilometres allowed over the whole contract
+	ContractedMileage int             `json:"contractedMileage"`
+	ExcessKmPrice     decimal.Decimal `json:"excessKmPrice"`
+	// StartMileage is the odometer reading when the car was delivered
+	StartMileage      int         `json:"startMileage"`
+	CurrentMileage    *int        `json:"currentMileage,omitempty"`
+	MileageRecordedAt *time.Time  `json:"mileageRecordedAt,omitempty"`
+	Status            LeaseStatus `json:"status"`
+	ReturnDate        *time.Time  `json:"returnDate,omitempty"`
+	ReturnMileage     *int        `json:"returnMileage,omitempty"`
+	Notes             *string     `json:"notes,omitempty"`
+	CreatedAt         time.Time   `json:"createdAt"`
+	UpdatedAt         time.Time   `json:"updatedAt"`
+	CreatedBy         *string     `json:"createdBy,omitempty"`
+	LicensePlate      string      `json:"licensePlate,omitempty"`
+	// MileageProjection is computed on read; nil until a mileage is recorded
+	MileageProjection *MileageProjection `json:"mileageProjection,omitempty"`
+}
+
+// MileageProjection estimates the mileage of a car at the end of its lease
+// from the kilometres driven so far, and the cost of the kilometres beyond
+// the contracted mileage. For returned cars it is the final mileage.
+type MileageProjection struct {
+	DrivenKm     int         `json:"drivenKm"`
+	ProjectedKm  int         `json:"projectedKm"`
+	ContractedKm int         `json:"contractedKm"`
+	ExcessKm     int         `json:"excessKm"`
+	ExcessCost   money.Money `json:"excessCost"`
+	Final        bool        `json:"final"`
+}
+
+// Validate checks the terms of the contract
+func (l *LeaseContract) Validate() error {
+	if strings.TrimSpace(l.Lessor) == "" {
+		return errors.New("lessor is required")
+	}
+	if strings.TrimSpace(l.ContractNumber) == "" {
+		return errors.New("contract number is required")
+	}
+	if l.StartDate.IsZero() || l.EndDate.IsZero() {
+		return errors.New("start and end dates are required")
+	}
+	if !l.EndDate.After(l.StartDate) {
+		return errors.New("invalid dates: end date must be after start date")
+	}
+	if l.MonthlyRent.IsNegative() {
+		return errors.New("monthly rent cannot be negative")
+	}
+	if !money.HasMaxPlaces(l.MonthlyRent.Amount, amountPlaces) {
+		return errors.New("monthly rent cannot have more than 2 decimal places")
+	}
+	if err := money.ValidateCurrency(l.MonthlyRent.Currency); err != nil {
+		return errors.New("invalid currency")
+	}
+	if l.ContractedMileage <= 0 {
+		return errors.New("contracted mileage must be positive")
+	}
+	if l.ExcessKmPrice.IsNegative() {
+		return errors.New("excess kilometre price cannot be negative")
+	}
+	if !money.HasMaxPlaces(l.ExcessKmPrice, excessKmPricePlaces) {
+		return errors.New("excess kilometre price cannot have more than 4 decimal places")
+	}
+	if l.StartMileage < 0 {
+		return errors.New("start mileage cannot be negative")
+	}
+	return nil
+}
+
+// DaysRemaining returns the number of days from today to the end date
+func (l *LeaseContract) DaysRemaining(today time.Time) int {
+	return daysBetween(today, l.EndDate)
+}
+
+// ProjectMileage computes the mileage projection of the contract. The
+// kilometres driven between the start date and the latest reading are
+// extrapolated linearly to the end date; a reading taken on or after the end
+// date, or the return mileage, is used as is. It returns nil when no mileage
+// has been recorded.
+func (l *LeaseContract) ProjectMileage() *MileageProjection {
+	var mileage int
+	var recordedAt time.Time
+	final := l.Status == LeaseStatusReturned && l.ReturnMileage != nil && l.ReturnDate != nil
+	switch {
+	case final:
+		mileage, recordedAt = *l.ReturnMileage, *l.ReturnDate
+	case l.CurrentMileage != nil && l.MileageRecordedAt != nil:
+		mileage, recordedAt = *l.CurrentMileage, *l.MileageRecordedAt
+	default:
+		return nil
+	}
+
+	driven := mileage - l.StartMileage
+	projected := driven
+	elapsed := daysBetween(l.StartDate, recordedAt)
+	duration := daysBetween(l.StartDate, l.EndDate)
+	if !final && elapsed > 0 && elapsed < duration {
+		projected = int(decimal.NewFromInt(int64(driven)).
+			Mul(decimal.NewFromInt(int64(duration))).
+			Div(decimal.NewFromInt(int64(elapsed))).
+			Round(0).IntPart())
+	}
+
+	excess := projected - l.ContractedMileage
+	if excess < 0 {
+		excess = 0
+	}
+
+	return &MileageProjection{
+		DrivenKm:     driven,
+		ProjectedKm:  projected,
+		ContractedKm: l.ContractedMileage,
+		ExcessKm:     excess,
+		ExcessCost:   money.New(l.ExcessKmPrice.Mul(decimal.NewFromInt(int64(excess))), l.MonthlyRent.Currency).Round(),
+		Final:        final,
+	}
+}
+
+// daysBetween returns the number of calendar days from one date to another
+func daysBetween(from, to time.Time) int {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// ExpiringLease is an active lease contract ending within the alert window
+type ExpiringLease struct {
+	*LeaseContract
+	DaysRemaining int `json:"daysRemaining"`
+}
+
+// LeaseFilters represents filters for lease contract queries
+type LeaseFilters struct {
+	CarID  string
+	Status LeaseStatus
+	Lessor string
+}
+
+// CreateLeaseContractRequest represents the request to create a lease contract
+type CreateLeaseContractRequest struct {
+	CarID             string          `json:"carId"`
+	Lessor            string          `json:"lessor"`
+	ContractNumber    string          `json:"contractNumber"`
+	StartDate         time.Time       `json:"startDate"`
+	EndDate           time.Time       `json:"endDate"`
+	MonthlyRent       money.Money     `json:"monthlyRent"`
+	ContractedMileage int             `json:"contractedMileage"`
+	ExcessKmPrice     decimal.Decimal `json:"excessKmPrice"`
+	StartMileage      int             `json:"startMileage"`
+	Notes             *string         `json:"notes,omitempty"`
+}
+
+// UpdateLeaseContractRequest represents the request to update the terms of
+// an active lease contract
+type UpdateLeaseContractRequest struct {
+	Lessor            *string          `json:"lessor,omitempty"`
+	ContractNumber    *string          `json:"contractNumber,omitempty"`
+	StartDate         *time.Time       `json:"startDate,omitempty"`
+	EndDate           *time.Time       `json:"endDate,omitempty"`
+	MonthlyRent       *money.Money     `json:"monthlyRent,omitempty"`
+	ContractedMileage *int             `json:"contractedMileage,omitempty"`
+	ExcessKmPrice     *decimal.Decimal `json:"excessKmPrice,omitempty"`
+	StartMileage      *int             `json:"startMileage,omitempty"`
+	Notes             *string          `json:"notes,omitempty"`
+}
+
+// RecordMileageRequest represents an odometer reading of a leased car. The
+// date defaults to today.
+type RecordMileageRequest struct {
+	Mileage int        `json:"mileage"`
+	Date    *time.Time `json:"date,omitempty"`
+}
+
+// ReturnLeaseRequest represents the return of a leased car to its lessor
+type ReturnLeaseRequest struct {
+	ReturnDate    time.Time `json:"returnDate"`
+	ReturnMileage int       `json:"returnMileage"`
+	Notes         *string   `json:"notes,omitempty"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginAttempt represents a login attempt, successful or not
+type LoginAttempt struct {
+	ID          string    `json:"id"`
+	Email       string    `json:"email"`
+	IPAddress   string    `json:"ipAddress"`
+	UserAgent   string    `json:"userAgent"`
+	Succeeded   bool      `json:"succeeded"`
+	AttemptedAt time.Time `json:"attemptedAt"`
+}
@@ -91,19 +91,24 @@ type OperatorFilters struct {
 	Search     string
 	Department string
 	IsActive   *bool
-	Page       int
-	Limit      int
-	SortBy     string
-	SortOrder  string
+	// Cursor, when set, selects the page next to it instead of Page
+	Cursor    *Cursor
+	Page      int
+	Limit     int
+	SortBy    string
+	SortOrder string
 }
 
-// OperatorListResponse represents a paginated list of operators
+// OperatorListResponse represents a paginated list of operators. Total, Page
+// and TotalPages are only set when paginating by page number.
 type OperatorListResponse struct {
 	Data       []OperatorWithCurrentCar `json:"data"`
 	Total      int                      `json:"total"`
 	Page       int                      `json:"page"`
 	Limit      int                      `json:"limit"`
 	TotalPages int                      `json:"total_pages"`
+	NextCursor *string                  `json:"next_cursor"`
+	PrevCursor *string                  `json:"prev_cursor"`
 }
 
 // AssignmentFilters represents filters for assignment queries
@@ -113,4 +118,106 @@ type AssignmentFilters struct {
 	Active     *bool
 	StartDate  *time.Time
 	EndDate    *time.Time
+	// Cursor, Page and Limit select a page of the history when paginated
+	Cursor *Cursor
+	Page   int
+	Limit  int
+}
+
+// AssignmentHistoryResponse represents a paginated assignment history. Total,
+// Page and TotalPages are only set when paginating by page number.
+type AssignmentHistoryResponse struct {
+	Data       []CarOperatorAssignment `json:"data"`
+	Total      int                     `json:"total"`
+	Page       int                     `json:"page"`
+	Limit      int                     `json:"limit"`
+	TotalPages int                     `json:"total_pages"`
+	NextCursor *string                 `json:"next_cursor"`
+	PrevCursor *string                 `json:"prev_cursor"`
+}
+
+// OperatorListItem is an operator as listed by /api/v2, named in camelCase
+// like every other /api/v2 list item
+type OperatorListItem struct {
+	ID             string              `json:"id"`
+	EmployeeNumber string              `json:"employeeNumber"`
+	FirstName      string              `json:"firstName"`
+	LastName       string              `json:"lastName"`
+	Email          *string             `json:"email,omitempty"`
+	Phone          *string             `json:"phone,omitempty"`
+	Department     *string             `json:"department,omitempty"`
+	IsActive       bool                `json:"isActive"`
+	CreatedAt      time.Time           `json:"createdAt"`
+	UpdatedAt      time.Time           `json:"updatedAt"`
+	CreatedBy      *string             `json:"createdBy,omitempty"`
+	CurrentCar     *CurrentCarListItem `json:"currentCar,omitempty"`
+}
+
+// CurrentCarListItem is the current car of an operator listed by /api/v2
+type CurrentCarListItem struct {
+	ID           string    `json:"id"`
+	LicensePlate string    `json:"licensePlate"`
+	Brand        string    `json:"brand"`
+	Model        string    `json:"model"`
+	Since        time.Time `json:"since"`
+}
+
+// AssignmentListItem is an assignment as listed by /api/v2
+type AssignmentListItem struct {
+	ID         string     `json:"id"`
+	CarID      string     `json:"carId"`
+	OperatorID string     `json:"operatorId"`
+	StartDate  time.Time  `json:"startDate"`
+	EndDate    *time.Time `json:"endDate,omitempty"`
+	Notes      *string    `json:"notes,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	CreatedBy  *string    `json:"createdBy,omitempty"`
+}
+
+// NewOperatorListItems converts operators to their /api/v2 list items
+func NewOperatorListItems(operators []OperatorWithCurrentCar) []OperatorListItem {
+	items := make([]OperatorListItem, len(operators))
+	for i, operator := range operators {
+		items[i] = OperatorListItem{
+			ID:             operator.ID,
+			EmployeeNumber: operator.EmployeeNumber,
+			FirstName:      operator.FirstName,
+			LastName:       operator.LastName,
+			Email:          operator.Email,
+			Phone:          operator.Phone,
+			Department:     operator.Department,
+			IsActive:       operator.IsActive,
+			CreatedAt:      operator.CreatedAt,
+			UpdatedAt:      operator.UpdatedAt,
+			CreatedBy:      operator.CreatedBy,
+		}
+		if car := operator.CurrentCar; car != nil {
+			items[i].CurrentCar = &CurrentCarListItem{
+				ID:           car.ID,
+				LicensePlate: car.LicensePlate,
+				Brand:        car.Brand,
+				Model:        car.Model,
+				Since:        car.Since,
+			}
+		}
+	}
+	return items
+}
+
+// NewAssignmentListItems converts assignments to their /api/v2 list items
+func NewAssignmentListItems(assignments []CarOperatorAssignment) []AssignmentListItem {
+	items := make([]AssignmentListItem, len(assignments))
+	for i, assignment := range assignments {
+		items[i] = AssignmentListItem{
+			ID:         assignment.ID,
+			CarID:      assignment.CarID,
+			OperatorID: assignment.OperatorID,
+			StartDate:  assignment.StartDate,
+			EndDate:    assignment.EndDate,
+			Notes:      assignment.Notes,
+			CreatedAt:  assignment.CreatedAt,
+			CreatedBy:  assignment.CreatedBy,
+		}
+	}
+	return items
 }
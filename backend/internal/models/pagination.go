@@ -0,0 +1,101 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor is returned for cursors that cannot be decoded or do not
+// belong to the list and sort order they are used with
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points at a row of a keyset paginated list. Clients only see it
+// encoded, as an opaque string.
+type Cursor struct {
+	// Sort is the ordering the cursor was issued for
+	Sort string `json:"s"`
+	// Values is the sort key of the row, the row ID last
+	Values []string `json:"v"`
+	// Before asks for the page preceding the row instead of the one after it
+	Before bool `json:"b,omitempty"`
+}
+
+// Encode returns the opaque form of the cursor
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor returned by Encode. An empty string is not a
+// cursor and gives nil.
+func DecodeCursor(value string) (*Cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort == "" || len(cursor.Values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// PageCursors are the cursors of the pages around a page of a list. They
+// are nil when there is no such page.
+type PageCursors struct {
+	Next *string
+	Prev *string
+}
+
+// ListResponse is the envelope of every list returned by the /api/v2 routes
+type ListResponse[T any] struct {
+	Data       []T        `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// Pagination describes the page of a ListResponse. Page, Total and
+// TotalPages are only set when paginating by page number; cursor pages are
+// not counted.
+type Pagination struct {
+	Limit      int     `json:"limit"`
+	Page       *int    `json:"page,omitempty"`
+	Total      *int    `json:"total,omitempty"`
+	TotalPages *int    `json:"totalPages,omitempty"`
+	NextCursor *string `json:"nextCursor"`
+	PrevCursor *string `json:"prevCursor"`
+}
+
+// NewListResponse wraps a page of a list read with the given limit
+func NewListResponse[T any](data []T, limit int, cursors PageCursors) *ListResponse[T] {
+	if data == nil {
+		data = []T{}
+	}
+	return &ListResponse[T]{
+		Data: data,
+		Pagination: Pagination{
+			Limit:      limit,
+			NextCursor: cursors.Next,
+			PrevCursor: cursors.Prev,
+		},
+	}
+}
+
+// WithTotal adds the page number and the totals of a list paginated by page
+// number
+func (l *ListResponse[T]) WithTotal(page, total int) *ListResponse[T] {
+	totalPages := 0
+	if l.Pagination.Limit > 0 {
+		totalPages = (total + l.Pagination.Limit - 1) / l.Pagination.Limit
+	}
+	l.Pagination.Page = &page
+	l.Pagination.Total = &total
+	l.Pagination.TotalPages = &totalPages
+	return l
+}
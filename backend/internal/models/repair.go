@@ -1,8 +1,11 @@
 package models
 
 import (
+	"encoding/json"
 	"errors"
 	"time"
+
+	"github.com/goldenkiwi/autoparc/pkg/money"
 )
 
 // RepairType represents the type of repair
@@ -34,7 +37,7 @@ type Repair struct {
 	Description   string       `json:"description"`
 	StartDate     time.Time    `json:"startDate"`
 	EndDate       *time.Time   `json:"endDate,omitempty"`
-	Cost          *float64     `json:"cost,omitempty"`
+	Cost          *money.Price `json:"costDetails,omitempty"`
 	Status        RepairStatus `json:"status"`
 	InvoiceNumber *string      `json:"invoiceNumber,omitempty"`
 	Notes         *string      `json:"notes,omitempty"`
@@ -46,6 +49,21 @@ type Repair struct {
 	Garage        *Garage      `json:"garage,omitempty"`
 }
 
+// MarshalJSON keeps cost the plain amount including VAT that the v1 API has
+// always returned; the full price is under costDetails
+func (r Repair) MarshalJSON() ([]byte, error) {
+	type repair Repair
+	var cost *json.Number
+	if r.Cost != nil {
+		amount := json.Number(r.Cost.InclVAT.StringFixed(money.Places))
+		cost = &amount
+	}
+	return json.Marshal(struct {
+		repair
+		Cost *json.Number `json:"cost,omitempty"`
+	}{repair(r), cost})
+}
+
 // CreateRepairRequest represents the request to create a new repair
 type CreateRepairRequest struct {
 	CarID         string        `json:"carId" binding:"required"`
@@ -55,7 +73,7 @@ type CreateRepairRequest struct {
 	Description   string        `json:"description" binding:"required"`
 	StartDate     time.Time     `json:"startDate" binding:"required"`
 	EndDate       *time.Time    `json:"endDate,omitempty"`
-	Cost          *float64      `json:"cost,omitempty"`
+	Cost          *money.Price  `json:"cost,omitempty"`
 	Status        *RepairStatus `json:"status,omitempty"`
 	InvoiceNumber *string       `json:"invoiceNumber,omitempty"`
 	Notes         *string       `json:"notes,omitempty"`
@@ -68,7 +86,7 @@ type UpdateRepairRequest struct {
 	Description   *string       `json:"description,omitempty"`
 	StartDate     *time.Time    `json:"startDate,omitempty"`
 	EndDate       *time.Time    `json:"endDate,omitempty"`
-	Cost          *float64      `json:"cost,omitempty"`
+	Cost          *money.Price  `json:"cost,omitempty"`
 	Status        *RepairStatus `json:"status,omitempty"`
 	InvoiceNumber *string       `json:"invoiceNumber,omitempty"`
 	Notes         *string       `json:"notes,omitempty"`
@@ -79,6 +97,18 @@ type UpdateRepairStatusRequest struct {
 	Status RepairStatus `json:"status" binding:"required"`
 }
 
+// RepairListResponse represents a paginated list of repairs. Total, Page and
+// TotalPages are only set when paginating by page number.
+type RepairListResponse struct {
+	Data       []*Repair `json:"data"`
+	Total      int       `json:"total"`
+	Page       int       `json:"page"`
+	Limit      int       `json:"limit"`
+	TotalPages int       `json:"total_pages"`
+	NextCursor *string   `json:"next_cursor"`
+	PrevCursor *string   `json:"prev_cursor"`
+}
+
 // Validate validates the CreateRepairRequest
 func (r *CreateRepairRequest) Validate() error {
 	if r.CarID == "" {
@@ -104,8 +134,8 @@ func (r *CreateRepairRequest) Validate() error {
 			return errors.New("la date de fin ne peut pas être avant la date de début")
 		}
 	}
-	if r.Cost != nil && *r.Cost < 0 {
-		return errors.New("le coût ne peut pas être négatif")
+	if err := validateRepairCost(r.Cost); err != nil {
+		return err
 	}
 	if r.Status != nil {
 		if err := ValidateRepairStatus(*r.Status); err != nil {
@@ -136,8 +166,8 @@ func (r *UpdateRepairRequest) Validate() error {
 			return errors.New("la date de fin ne peut pas être avant la date de début")
 		}
 	}
-	if r.Cost != nil && *r.Cost < 0 {
-		return errors.New("le coût ne peut pas être négatif")
+	if err := validateRepairCost(r.Cost); err != nil {
+		return err
 	}
 	if r.Status != nil {
 		if err := ValidateRepairStatus(*r.Status); err != nil {
@@ -180,3 +210,23 @@ func (r *Repair) CalculateDuration() *int {
 	days := int(r.EndDate.Sub(r.StartDate).Hours() / 24)
 	return &days
 }
+
+// validateRepairCost validates an optional repair cost
+func validateRepairCost(cost *money.Price) error {
+	if cost == nil {
+		return nil
+	}
+	if cost.ExclVAT.IsNegative() || cost.InclVAT.IsNegative() {
+		return errors.New("le coût ne peut pas être négatif")
+	}
+	if !money.HasMaxPlaces(cost.ExclVAT, amountPlaces) || !money.HasMaxPlaces(cost.InclVAT, amountPlaces) {
+		return errors.New("le coût ne peut pas avoir plus de 2 décimales")
+	}
+	if cost.VATRate.IsNegative() || cost.VATRate.GreaterThan(hundred) {
+		return errors.New("le taux de TVA doit être compris entre 0 et 100")
+	}
+	if err := money.ValidateCurrency(cost.Currency); err != nil {
+		return errors.New("devise invalide")
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/shopspring/decimal"
+)
+
+// QuoteStatus represents the status of a garage quote
+type QuoteStatus string
+
+const (
+	QuoteStatusPending  QuoteStatus = "pending"
+	QuoteStatusAccepted QuoteStatus = "accepted"
+	QuoteStatusRejected QuoteStatus = "rejected"
+)
+
+// InvoiceLineCategory separates parts from labour on an invoice
+type InvoiceLineCategory string
+
+const (
+	InvoiceLineCategoryParts  InvoiceLineCategory = "parts"
+	InvoiceLineCategoryLabour InvoiceLineCategory = "labour"
+)
+
+// Decimal places stored for amounts, quantities and unit prices
+const (
+	amountPlaces    = money.Places
+	quantityPlaces  = 3
+	unitPricePlaces = 4
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// RepairQuote represents a garage quote for a repair. The amount includes VAT
+// so it can be compared directly with the invoice total.
+type RepairQuote struct {
+	ID          string      `json:"id"`
+	RepairID    string      `json:"repairId"`
+	GarageID    string      `json:"garageId"`
+	QuoteNumber *string     `json:"quoteNumber,omitempty"`
+	QuoteDate   time.Time   `json:"quoteDate"`
+	Amount      money.Money `json:"amount"`
+	Status      QuoteStatus `json:"status"`
+	Notes       *string     `json:"notes,omitempty"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+	CreatedBy   *string     `json:"createdBy,omitempty"`
+	Garage      *Garage     `json:"garage,omitempty"`
+}
+
+// InvoiceLine represents a line of a repair invoice
+type InvoiceLine struct {
+	ID           string              `json:"id"`
+	RepairID     string              `json:"repairId"`
+	Description  string              `json:"description"`
+	Quantity     decimal.Decimal     `json:"quantity"`
+	UnitPrice    decimal.Decimal     `json:"unitPrice"`
+	VATRate      decimal.Decimal     `json:"vatRate"`
+	Category     InvoiceLineCategory `json:"category"`
+	Currency     string              `json:"currency"`
+	TotalExclVAT decimal.Decimal     `json:"totalExclVat"`
+	VATAmount    decimal.Decimal     `json:"vatAmount"`
+	TotalInclVAT decimal.Decimal     `json:"totalInclVat"`
+	CreatedAt    time.Time           `json:"createdAt"`
+	CreatedBy    *string             `json:"createdBy,omitempty"`
+}
+
+// ComputeTotals computes the line totals. Each amount is rounded to the cent
+// so that the invoice total is the sum of the printed line amounts.
+func (l *InvoiceLine) ComputeTotals() {
+	price := money.NewPriceExclVAT(l.Quantity.Mul(l.UnitPrice), l.VATRate, l.Currency)
+	l.TotalExclVAT = price.ExclVAT
+	l.VATAmount = price.VATAmount
+	l.TotalInclVAT = price.InclVAT
+}
+
+// InvoiceTotals holds the computed totals of a repair invoice
+type InvoiceTotals struct {
+	PartsExclVAT  money.Money `json:"partsExclVat"`
+	LabourExclVAT money.Money `json:"labourExclVat"`
+	TotalExclVAT  money.Money `json:"totalExclVat"`
+	VATAmount     money.Money `json:"vatAmount"`
+	TotalInclVAT  money.Money `json:"totalInclVat"`
+}
+
+// ComputeInvoiceTotals computes line totals and sums them by category. All
+// lines must share the same currency; an empty invoice is in EUR.
+func ComputeInvoiceTotals(lines []*InvoiceLine) (InvoiceTotals, error) {
+	currency := money.DefaultCurrency
+	if len(lines) > 0 {
+		currency = lines[0].Currency
+	}
+
+	var partsExclVAT, labourExclVAT, totalExclVAT, vatAmount, totalInclVAT decimal.Decimal
+	for _, line := range lines {
+		if line.Currency != currency {
+			return InvoiceTotals{}, errors.New("les lignes de facture doivent avoir la même devise")
+		}
+		line.ComputeTotals()
+		switch line.Category {
+		case InvoiceLineCategoryParts:
+			partsExclVAT = partsExclVAT.Add(line.TotalExclVAT)
+		case InvoiceLineCategoryLabour:
+			labourExclVAT = labourExclVAT.Add(line.TotalExclVAT)
+		}
+		totalExclVAT = totalExclVAT.Add(line.TotalExclVAT)
+		vatAmount = vatAmount.Add(line.VATAmount)
+		totalInclVAT = totalInclVAT.Add(line.TotalInclVAT)
+	}
+
+	return InvoiceTotals{
+		PartsExclVAT:  money.New(partsExclVAT, currency),
+		LabourExclVAT: money.New(labourExclVAT, currency),
+		TotalExclVAT:  money.New(totalExclVAT, currency),
+		VATAmount:     money.New(vatAmount, currency),
+		TotalInclVAT:  money.New(totalInclVAT, currency),
+	}, nil
+}
+
+// QuoteComparison compares the invoice total with the accepted quote
+type QuoteComparison struct {
+	QuoteAmount    money.Money     `json:"quoteAmount"`
+	InvoiceAmount  money.Money     `json:"invoiceAmount"`
+	Difference     money.Money     `json:"difference"`
+	DifferenceRate decimal.Decimal `json:"differencePercent"`
+	ThresholdRate  decimal.Decimal `json:"thresholdPercent"`
+	ExceedsQuote   bool            `json:"exceedsQuote"`
+}
+
+// CompareToQuote flags an invoice exceeding the quote by more than
+// thresholdPercent. Both amounts must be in the same currency.
+func CompareToQuote(quoteAmount, invoiceAmount money.Money, thresholdPercent decimal.Decimal) (QuoteComparison, error) {
+	difference, err := invoiceAmount.Sub(quoteAmount)
+	if err != nil {
+		return QuoteComparison{}, errors.New("le devis et la facture n'ont pas la même devise")
+	}
+
+	comparison := QuoteComparison{
+		QuoteAmount:   quoteAmount,
+		InvoiceAmount: invoiceAmount,
+		Difference:    difference,
+		ThresholdRate: thresholdPercent,
+	}
+
+	if quoteAmount.IsZero() {
+		comparison.ExceedsQuote = invoiceAmount.Amount.IsPositive()
+		return comparison, nil
+	}
+
+	comparison.DifferenceRate = difference.Amount.Mul(hundred).Div(quoteAmount.Amount).Round(amountPlaces)
+	comparison.ExceedsQuote = comparison.DifferenceRate.GreaterThan(thresholdPercent)
+	return comparison, nil
+}
+
+// RepairInvoice is the invoice of a repair with its totals and the comparison
+// with the accepted quote, if any
+type RepairInvoice struct {
+	RepairID        string           `json:"repairId"`
+	InvoiceNumber   *string          `json:"invoiceNumber,omitempty"`
+	Lines           []*InvoiceLine   `json:"lines"`
+	Totals          InvoiceTotals    `json:"totals"`
+	AcceptedQuote   *RepairQuote     `json:"acceptedQuote,omitempty"`
+	QuoteComparison *QuoteComparison `json:"quoteComparison,omitempty"`
+}
+
+// OverQuoteRepair is a repair whose invoice exceeds its accepted quote
+type OverQuoteRepair struct {
+	RepairID       string          `json:"repairId"`
+	CarID          string          `json:"carId"`
+	GarageID       string          `json:"garageId"`
+	Description    string          `json:"description"`
+	QuoteID        string          `json:"quoteId"`
+	QuoteAmount    money.Money     `json:"quoteAmount"`
+	InvoiceAmount  money.Money     `json:"invoiceAmount"`
+	DifferenceRate decimal.Decimal `json:"differencePercent"`
+}
+
+// CreateRepairQuoteRequest represents the request to add a quote to a repair
+type CreateRepairQuoteRequest struct {
+	GarageID    string      `json:"garageId"`
+	QuoteNumber *string     `json:"quoteNumber,omitempty"`
+	QuoteDate   time.Time   `json:"quoteDate"`
+	Amount      money.Money `json:"amount"`
+	Notes       *string     `json:"notes,omitempty"`
+}
+
+// Validate validates the CreateRepairQuoteRequest
+func (r *CreateRepairQuoteRequest) Validate() error {
+	if r.GarageID == "" {
+		return errors.New("l'identifiant du garage est requis")
+	}
+	if r.QuoteDate.IsZero() {
+		return errors.New("la date du devis est requise")
+	}
+	if r.Amount.IsNegative() {
+		return errors.New("le montant du devis ne peut pas être négatif")
+	}
+	if !money.HasMaxPlaces(r.Amount.Amount, amountPlaces) {
+		return errors.New("le montant du devis ne peut pas avoir plus de 2 décimales")
+	}
+	if err := money.ValidateCurrency(r.Amount.Currency); err != nil {
+		return errors.New("devise invalide")
+	}
+	return nil
+}
+
+// UpdateQuoteStatusRequest represents the request to accept or reject a quote
+type UpdateQuoteStatusRequest struct {
+	Status QuoteStatus `json:"status"`
+}
+
+// Validate validates the UpdateQuoteStatusRequest
+func (r *UpdateQuoteStatusRequest) Validate() error {
+	return ValidateQuoteStatus(r.Status)
+}
+
+// ValidateQuoteStatus validates the quote status
+func ValidateQuoteStatus(status QuoteStatus) error {
+	switch status {
+	case QuoteStatusPending, QuoteStatusAccepted, QuoteStatusRejected:
+		return nil
+	default:
+		return errors.New("statut de devis invalide")
+	}
+}
+
+// CreateInvoiceLineRequest represents the request to add an invoice line to a repair
+type CreateInvoiceLineRequest struct {
+	Description string              `json:"description"`
+	Quantity    decimal.Decimal     `json:"quantity"`
+	UnitPrice   decimal.Decimal     `json:"unitPrice"`
+	VATRate     decimal.Decimal     `json:"vatRate"`
+	Category    InvoiceLineCategory `json:"category"`
+	Currency    string              `json:"currency,omitempty"`
+}
+
+// Validate validates the CreateInvoiceLineRequest
+func (r *CreateInvoiceLineRequest) Validate() error {
+	if strings.TrimSpace(r.Description) == "" {
+		return errors.New("la description est requise")
+	}
+	if !r.Quantity.IsPositive() {
+		return errors.New("la quantité doit être positive")
+	}
+	if !money.HasMaxPlaces(r.Quantity, quantityPlaces) {
+		return errors.New("la quantité ne peut pas avoir plus de 3 décimales")
+	}
+	if r.UnitPrice.IsNegative() {
+		return errors.New("le prix unitaire ne peut pas être négatif")
+	}
+	if !money.HasMaxPlaces(r.UnitPrice, unitPricePlaces) {
+		return errors.New("le prix unitaire ne peut pas avoir plus de 4 décimales")
+	}
+	if r.VATRate.IsNegative() || r.VATRate.GreaterThan(hundred) {
+		return errors.New("le taux de TVA doit être compris entre 0 et 100")
+	}
+	switch r.Category {
+	case InvoiceLineCategoryParts, InvoiceLineCategoryLabour:
+	default:
+		return errors.New("catégorie invalide. Catégories acceptées: parts, labour")
+	}
+	if r.Currency != "" {
+		if err := money.ValidateCurrency(r.Currency); err != nil {
+			return errors.New("devise invalide")
+		}
+	}
+	return nil
+}
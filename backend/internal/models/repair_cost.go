@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/pkg/money"
+)
+
+// RepairCostGroupBy is the dimension repair costs are aggregated on
+type RepairCostGroupBy string
+
+const (
+	RepairCostGroupByCar        RepairCostGroupBy = "car"
+	RepairCostGroupByGarage     RepairCostGroupBy = "garage"
+	RepairCostGroupByMonth      RepairCostGroupBy = "month"
+	RepairCostGroupByRepairType RepairCostGroupBy = "repair_type"
+)
+
+// RepairCostFilters restricts the repairs included in a cost report
+type RepairCostFilters struct {
+	GroupBy  RepairCostGroupBy
+	CarID    string
+	GarageID string
+	Status   string
+	From     *time.Time
+	To       *time.Time
+}
+
+// Validate validates the RepairCostFilters
+func (f *RepairCostFilters) Validate() error {
+	switch f.GroupBy {
+	case RepairCostGroupByCar, RepairCostGroupByGarage, RepairCostGroupByMonth, RepairCostGroupByRepairType:
+	default:
+		return errors.New("regroupement invalide. Regroupements acceptés: car, garage, month, repair_type")
+	}
+	if f.Status != "" {
+		if err := ValidateRepairStatus(RepairStatus(f.Status)); err != nil {
+			return err
+		}
+	}
+	if f.From != nil && f.To != nil && f.To.Before(*f.From) {
+		return errors.New("la date de fin ne peut pas être avant la date de début")
+	}
+	return nil
+}
+
+// RepairCostSummary is the total cost of a group of repairs in one currency.
+// Amounts are summed in SQL so they match the accounting exactly.
+type RepairCostSummary struct {
+	Group        string      `json:"group"`
+	RepairCount  int         `json:"repairCount"`
+	TotalExclVAT money.Money `json:"totalExclVat"`
+	VATAmount    money.Money `json:"vatAmount"`
+	TotalInclVAT money.Money `json:"totalInclVat"`
+}
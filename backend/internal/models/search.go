@@ -0,0 +1,57 @@
+package models
+
+// SearchResultType is the kind of record a search result points to
+type SearchResultType string
+
+const (
+	SearchResultCar      SearchResultType = "car"
+	SearchResultOperator SearchResultType = "operator"
+	SearchResultGarage   SearchResultType = "garage"
+	SearchResultAccident SearchResultType = "accident"
+	SearchResultRepair   SearchResultType = "repair"
+)
+
+// SearchResultTypes lists every type the global search covers
+var SearchResultTypes = []SearchResultType{
+	SearchResultCar,
+	SearchResultOperator,
+	SearchResultGarage,
+	SearchResultAccident,
+	SearchResultRepair,
+}
+
+// IsValid checks if the search result type is known
+func (t SearchResultType) IsValid() bool {
+	for _, known := range SearchResultTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchResult is one record matching a global search. Highlight is an HTML
+// excerpt of the matched text: the text is escaped and the matching words
+// are wrapped in <mark></mark>, so it can be rendered as is.
+type SearchResult struct {
+	Type      SearchResultType `json:"type"`
+	ID        string           `json:"id"`
+	Title     string           `json:"title"`
+	Subtitle  string           `json:"subtitle"`
+	Highlight string           `json:"highlight"`
+	Rank      float64          `json:"rank"`
+	CarID     *string          `json:"carId,omitempty"` // Set for accidents and repairs
+}
+
+// SearchFilters represents the global search parameters
+type SearchFilters struct {
+	Query string
+	Types []SearchResultType
+	Limit int
+}
+
+// SearchResponse represents the global search results, best match first
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Results []SearchResult `json:"results"`
+}
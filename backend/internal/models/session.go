@@ -6,11 +6,31 @@ import (
 
 // Session represents a user session
 type Session struct {
-	ID           string    `json:"id"`
-	UserID       string    `json:"userId"`
-	SessionToken string    `json:"-"` // Never expose token in JSON
-	ExpiresAt    time.Time `json:"expiresAt"`
-	IPAddress    string    `json:"ipAddress"`
-	UserAgent    string    `json:"userAgent"`
-	CreatedAt    time.Time `json:"createdAt"`
+	ID                string    `json:"id"`
+	UserID            string    `json:"userId"`
+	SessionToken      string    `json:"-"` // Raw token, only known right after login
+	TokenHash         string    `json:"-"` // SHA-256 of the token, as stored
+	ExpiresAt         time.Time `json:"expiresAt"`
+	AbsoluteExpiresAt time.Time `json:"absoluteExpiresAt"`
+	LastSeenAt        time.Time `json:"lastSeenAt"`
+	IPAddress         string    `json:"ipAddress"`
+	UserAgent         string    `json:"userAgent"`
+	CreatedAt         time.Time `json:"createdAt"`
+	Current           bool      `json:"current"`
+	// MFAPending sessions have passed the password check but not the second
+	// factor yet; they only grant access to the 2FA endpoints
+	MFAPending bool `json:"mfaPending"`
+	// PasswordChangeRequired sessions were opened with an expired password;
+	// they only grant access to changing it
+	PasswordChangeRequired bool `json:"passwordChangeRequired"`
+}
+
+// SlidingExpiry returns the expiry of the session after activity at the
+// given time: now plus the idle timeout, capped at the absolute expiry
+func (s *Session) SlidingExpiry(now time.Time, idleTimeout time.Duration) time.Time {
+	expiresAt := now.Add(idleTimeout)
+	if idleTimeout <= 0 || expiresAt.After(s.AbsoluteExpiresAt) {
+		return s.AbsoluteExpiresAt
+	}
+	return expiresAt
 }
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/pkg/money"
+)
+
+// FineStatus represents the designation status of a traffic fine
+type FineStatus string
+
+const (
+	// FineStatusPending: the driver is still to be designated
+	FineStatusPending FineStatus = "pending"
+	// FineStatusDesignated: the driver was designated to ANTAI
+	FineStatusDesignated FineStatus = "designated"
+	// FineStatusPaid: the company paid the fine without designating anyone
+	FineStatusPaid FineStatus = "paid"
+	// FineStatusContested: the fine is contested
+	FineStatusContested FineStatus = "contested"
+)
+
+// IsValid checks if the fine status is one of the known statuses
+func (s FineStatus) IsValid() bool {
+	switch s {
+	case FineStatusPending, FineStatusDesignated, FineStatusPaid, FineStatusContested:
+		return true
+	}
+	return false
+}
+
+// DesignationPeriodDays is the number of days from the notice date within
+// which the driver must be designated
+const DesignationPeriodDays = 45
+
+var noticeNumberRegex = regexp.MustCompile(`^[0-9A-Z]{6,30}$`)
+
+// NormalizeNoticeNumber removes the spaces printed in notice numbers
+func NormalizeNoticeNumber(number string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(number), ""))
+}
+
+// TrafficFine represents a traffic fine received for a fleet car. Driver is
+// the operator holding the car at the time of the offense, when it could be
+// resolved from the assignments, or the one set by hand.
+type TrafficFine struct {
+	ID           string      `json:"id"`
+	NoticeNumber string      `json:"noticeNumber"`
+	CarID        string      `json:"carId"`
+	LicensePlate string      `json:"licensePlate"`
+	OffenseAt    time.Time   `json:"offenseAt"`
+	Location     string      `json:"location"`
+	Offense      *string     `json:"offense,omitempty"`
+	Amount       money.Money `json:"amount"`
+	// NoticeDate is the date the notice was sent
+	NoticeDate          time.Time   `json:"noticeDate"`
+	DesignationDeadline time.Time   `json:"designationDeadline"`
+	OperatorID          *string     `json:"operatorId,omitempty"`
+	Status              FineStatus  `json:"status"`
+	DesignatedAt        *time.Time  `json:"designatedAt,omitempty"`
+	RemindedAt          *time.Time  `json:"remindedAt,omitempty"`
+	Notes               *string     `json:"notes,omitempty"`
+	CreatedAt           time.Time   `json:"createdAt"`
+	UpdatedAt           time.Time   `json:"updatedAt"`
+	CreatedBy           *string     `json:"createdBy,omitempty"`
+	Driver              *FineDriver `json:"driver,omitempty"`
+}
+
+// FineDriver is the operator designated, or to designate, as the driver
+type FineDriver struct {
+	ID             string  `json:"id"`
+	EmployeeNumber string  `json:"employeeNumber"`
+	FirstName      string  `json:"firstName"`
+	LastName       string  `json:"lastName"`
+	Email          *string `json:"email,omitempty"`
+}
+
+// Validate checks the details of the fine
+func (f *TrafficFine) Validate() error {
+	if !noticeNumberRegex.MatchString(f.NoticeNumber) {
+		return errors.New("invalid notice number (expected 6 to 30 letters or digits)")
+	}
+	if f.OffenseAt.IsZero() {
+		return errors.New("offense date is required")
+	}
+	if strings.TrimSpace(f.Location) == "" {
+		return errors.New("location is required")
+	}
+	if f.Amount.IsNegative() {
+		return errors.New("amount cannot be negative")
+	}
+	if !money.HasMaxPlaces(f.Amount.Amount, amountPlaces) {
+		return errors.New("amount cannot have more than 2 decimal places")
+	}
+	if err := money.ValidateCurrency(f.Amount.Currency); err != nil {
+		return errors.New("invalid currency")
+	}
+	if daysBetween(f.OffenseAt, f.NoticeDate) < 0 {
+		return errors.New("invalid dates: notice date cannot be before the offense")
+	}
+	return nil
+}
+
+// DesignationDeadlineFor returns the last day to designate the driver of a
+// fine notified on the given date
+func DesignationDeadlineFor(noticeDate time.Time) time.Time {
+	return time.Date(noticeDate.Year(), noticeDate.Month(), noticeDate.Day()+DesignationPeriodDays, 0, 0, 0, 0, time.UTC)
+}
+
+// DaysRemaining returns the number of days from today to the designation
+// deadline
+func (f *TrafficFine) DaysRemaining(today time.Time) int {
+	return daysBetween(today, f.DesignationDeadline)
+}
+
+// DueFine is a pending fine whose designation deadline is near or passed
+type DueFine struct {
+	*TrafficFine
+	DaysRemaining int `json:"daysRemaining"`
+}
+
+// TrafficFineFilters represents filters for traffic fine queries.
+// DeadlineBefore selects the fines due on or before the date.
+type TrafficFineFilters struct {
+	CarID          string
+	OperatorID     string
+	Status         FineStatus
+	DeadlineBefore *time.Time
+	// NotReminded selects the fines whose reminder was not sent yet
+	NotReminded bool
+}
+
+// CreateTrafficFineRequest represents the request to record a fine. The car
+// is found by its plate and the notice date defaults to today.
+type CreateTrafficFineRequest struct {
+	NoticeNumber string      `json:"noticeNumber"`
+	LicensePlate string      `json:"licensePlate"`
+	OffenseAt    time.Time   `json:"offenseAt"`
+	Location     string      `json:"location"`
+	Offense      *string     `json:"offense,omitempty"`
+	Amount       money.Money `json:"amount"`
+	NoticeDate   *time.Time  `json:"noticeDate,omitempty"`
+	Notes        *string     `json:"notes,omitempty"`
+}
+
+// UpdateTrafficFineRequest represents the request to update a fine. An
+// empty OperatorID clears the driver of a fine not yet designated.
+type UpdateTrafficFineRequest struct {
+	Location   *string      `json:"location,omitempty"`
+	Offense    *string      `json:"offense,omitempty"`
+	Amount     *money.Money `json:"amount,omitempty"`
+	NoticeDate *time.Time   `json:"noticeDate,omitempty"`
+	OperatorID *string      `json:"operatorId,omitempty"`
+	Status     *FineStatus  `json:"status,omitempty"`
+	Notes      *string      `json:"notes,omitempty"`
+}
+
+// DesignateDriverRequest represents the designation of the driver of a
+// fine. The operator defaults to the driver of the fine and the date to now.
+type DesignateDriverRequest struct {
+	OperatorID   *string    `json:"operatorId,omitempty"`
+	DesignatedAt *time.Time `json:"designatedAt,omitempty"`
+}
@@ -0,0 +1,18 @@
+package models
+
+// TwoFactorSetup is returned when an employee starts enrolling in 2FA. The
+// provisioning URI is meant to be rendered as a QR code.
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+// TwoFactorCodeRequest carries a TOTP code or a recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// TwoFactorEnableResponse returns the recovery codes, which are only shown once
+type TwoFactorEnableResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
@@ -4,6 +4,9 @@ import (
 	"time"
 )
 
+// RoleAdmin is the role allowed to manage other employees
+const RoleAdmin = "admin"
+
 // AdministrativeEmployee represents an administrative employee in the system
 type AdministrativeEmployee struct {
 	ID           string     `json:"id"`
@@ -16,6 +19,48 @@ type AdministrativeEmployee struct {
 	CreatedAt    time.Time  `json:"createdAt"`
 	UpdatedAt    time.Time  `json:"updatedAt"`
 	LastLoginAt  *time.Time `json:"lastLoginAt,omitempty"`
+	LockedUntil  *time.Time `json:"lockedUntil,omitempty"`
+
+	TwoFactorEnabled bool `json:"twoFactorEnabled"`
+
+	// PasswordChangedAt drives password expiry
+	PasswordChangedAt time.Time `json:"passwordChangedAt"`
+
+	Preferences EmployeePreferences `json:"preferences"`
+
+	FailedLoginCount  int        `json:"-"`
+	LastFailedLoginAt *time.Time `json:"-"`
+}
+
+// EmployeePreferences holds the interface settings an employee chooses for
+// themselves
+type EmployeePreferences struct {
+	Language        string `json:"language"`
+	DefaultPageSize int    `json:"defaultPageSize"`
+}
+
+// Supported interface languages and page sizes
+var (
+	Languages = []string{"fr", "en"}
+	PageSizes = []int{10, 20, 50, 100}
+)
+
+// DefaultEmployeePreferences returns the preferences of new employees
+func DefaultEmployeePreferences() EmployeePreferences {
+	return EmployeePreferences{Language: "fr", DefaultPageSize: 20}
+}
+
+// UpdateProfileRequest represents the changes an employee makes to their own
+// profile. Empty fields are left unchanged.
+type UpdateProfileRequest struct {
+	FirstName   string               `json:"firstName"`
+	LastName    string               `json:"lastName"`
+	Preferences *EmployeePreferences `json:"preferences"`
+}
+
+// IsLocked reports whether the account is locked at the given time
+func (e *AdministrativeEmployee) IsLocked(now time.Time) bool {
+	return e.LockedUntil != nil && e.LockedUntil.After(now)
 }
 
 // LoginRequest represents the login request payload
@@ -26,5 +71,14 @@ type LoginRequest struct {
 
 // LoginResponse represents the login response
 type LoginResponse struct {
-	User *AdministrativeEmployee `json:"user"`
+	User      *AdministrativeEmployee `json:"user"`
+	CSRFToken string                  `json:"csrfToken"`
+	// TwoFactorRequired is set when the session awaits a TOTP or recovery code
+	TwoFactorRequired bool `json:"twoFactorRequired,omitempty"`
+	// TwoFactorSetupRequired is set when the role requires 2FA and the
+	// employee has not enrolled yet
+	TwoFactorSetupRequired bool `json:"twoFactorSetupRequired,omitempty"`
+	// PasswordChangeRequired is set when the password has expired; the
+	// session only allows changing it
+	PasswordChangeRequired bool `json:"passwordChangeRequired,omitempty"`
 }
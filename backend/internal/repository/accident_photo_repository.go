@@ -2,11 +2,9 @@ package repository
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"database/sql"
 	"fmt"
-	"io"
 
 	"github.com/goldenkiwi/autoparc/internal/models"
 )
@@ -24,20 +22,17 @@ func NewAccidentPhotoRepository(db *sql.DB) *AccidentPhotoRepository {
 // Create creates a new accident photo with gzip compression
 func (r *AccidentPhotoRepository) Create(ctx context.Context, photo *models.AccidentPhoto) error {
 	// Compress the file data with gzip
-	var compressed bytes.Buffer
-	gzipWriter := gzip.NewWriter(&compressed)
-	_, err := gzipWriter.Write(photo.FileData)
+	compressed, err := gzipCompress(photo.FileData)
 	if err != nil {
-		return fmt.Errorf("échec de la compression de la photo: %w", err)
-	}
-	if err := gzipWriter.Close(); err != nil {
-		return fmt.Errorf("échec de la fermeture du compresseur: %w", err)
+		return err
 	}
 
 	query := `
-		INSERT INTO accident_photos (id, accident_id, filename, file_data, file_size, 
-		                             mime_type, compression_type, description, uploaded_at, uploaded_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO accident_photos (id, accident_id, filename, file_data, file_size,
+		                             mime_type, compression_type, description, uploaded_at, uploaded_by,
+		                             width, height, taken_at, gps_latitude, gps_longitude,
+		                             thumbnail_data, medium_data, variant_mime_type)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 	`
 
 	_, err = r.db.ExecContext(
@@ -46,13 +41,21 @@ func (r *AccidentPhotoRepository) Create(ctx context.Context, photo *models.Acci
 		photo.ID,
 		photo.AccidentID,
 		photo.Filename,
-		compressed.Bytes(),
+		compressed,
 		photo.FileSize,
 		photo.MimeType,
 		models.CompressionTypeGzip,
 		photo.Description,
 		photo.UploadedAt,
 		photo.UploadedBy,
+		photo.Width,
+		photo.Height,
+		photo.TakenAt,
+		photo.Latitude,
+		photo.Longitude,
+		photo.ThumbnailData,
+		photo.MediumData,
+		photo.VariantMimeType,
 	)
 
 	if err != nil {
@@ -65,15 +68,16 @@ func (r *AccidentPhotoRepository) Create(ctx context.Context, photo *models.Acci
 // FindByID retrieves a photo by ID with decompression
 func (r *AccidentPhotoRepository) FindByID(ctx context.Context, id string) (*models.AccidentPhoto, error) {
 	query := `
-		SELECT id, accident_id, filename, file_data, file_size, mime_type, 
-		       compression_type, description, uploaded_at, uploaded_by
+		SELECT id, accident_id, filename, file_data, file_size, mime_type,
+		       compression_type, description, uploaded_at, uploaded_by,
+		       width, height, taken_at, gps_latitude, gps_longitude
 		FROM accident_photos
 		WHERE id = $1
 	`
 
 	var photo models.AccidentPhoto
 	var compressedData []byte
-	
+
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&photo.ID,
 		&photo.AccidentID,
@@ -85,6 +89,11 @@ func (r *AccidentPhotoRepository) FindByID(ctx context.Context, id string) (*mod
 		&photo.Description,
 		&photo.UploadedAt,
 		&photo.UploadedBy,
+		&photo.Width,
+		&photo.Height,
+		&photo.TakenAt,
+		&photo.Latitude,
+		&photo.Longitude,
 	)
 
 	if err == sql.ErrNoRows {
@@ -96,15 +105,16 @@ func (r *AccidentPhotoRepository) FindByID(ctx context.Context, id string) (*mod
 
 	// Decompress the data
 	if photo.CompressionType == models.CompressionTypeGzip {
-		reader, err := gzip.NewReader(bytes.NewReader(compressedData))
+		decompressed, err := gunzip(compressedData)
 		if err != nil {
 			return nil, fmt.Errorf("échec de la décompression de la photo: %w", err)
 		}
-		defer reader.Close()
-
-		decompressed, err := io.ReadAll(reader)
-		if err != nil {
-			return nil, fmt.Errorf("échec de la lecture de la photo décompressée: %w", err)
+		// Photos uploaded before image processing was added were compressed
+		// twice (once by the handler, once here)
+		if bytes.HasPrefix(decompressed, gzipMagic) {
+			if decompressed, err = gunzip(decompressed); err != nil {
+				return nil, fmt.Errorf("échec de la décompression de la photo: %w", err)
+			}
 		}
 		photo.FileData = decompressed
 	} else {
@@ -117,8 +127,10 @@ func (r *AccidentPhotoRepository) FindByID(ctx context.Context, id string) (*mod
 // FindByAccidentID retrieves all photos for an accident (metadata only)
 func (r *AccidentPhotoRepository) FindByAccidentID(ctx context.Context, accidentID string) ([]*models.AccidentPhotoMetadata, error) {
 	query := `
-		SELECT id, accident_id, filename, file_size, mime_type, 
-		       compression_type, description, uploaded_at, uploaded_by
+		SELECT id, accident_id, filename, file_size, mime_type,
+		       compression_type, description, uploaded_at, uploaded_by,
+		       width, height, taken_at, gps_latitude, gps_longitude,
+		       thumbnail_data IS NOT NULL
 		FROM accident_photos
 		WHERE accident_id = $1
 		ORDER BY uploaded_at DESC
@@ -143,6 +155,12 @@ func (r *AccidentPhotoRepository) FindByAccidentID(ctx context.Context, accident
 			&photo.Description,
 			&photo.UploadedAt,
 			&photo.UploadedBy,
+			&photo.Width,
+			&photo.Height,
+			&photo.TakenAt,
+			&photo.Latitude,
+			&photo.Longitude,
+			&photo.HasVariants,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("échec du scan de la photo: %w", err)
@@ -153,6 +171,53 @@ func (r *AccidentPhotoRepository) FindByAccidentID(ctx context.Context, accident
 	return photos, nil
 }
 
+// FindVariant retrieves a resized variant of a photo. Photos uploaded before
+// variants were generated fall back to the original.
+func (r *AccidentPhotoRepository) FindVariant(ctx context.Context, id string, size models.PhotoSize) (*models.PhotoVariant, error) {
+	var column string
+	switch size {
+	case models.PhotoSizeThumb:
+		column = "thumbnail_data"
+	case models.PhotoSizeMedium:
+		column = "medium_data"
+	default:
+		photo, err := r.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &models.PhotoVariant{PhotoID: photo.ID, Filename: photo.Filename, MimeType: photo.MimeType, Data: photo.FileData}, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, filename, %s, variant_mime_type
+		FROM accident_photos
+		WHERE id = $1
+	`, column)
+
+	var variant models.PhotoVariant
+	var mimeType sql.NullString
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&variant.PhotoID,
+		&variant.Filename,
+		&variant.Data,
+		&mimeType,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("photo non trouvée")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("échec de la recherche de la photo: %w", err)
+	}
+
+	if variant.Data == nil || !mimeType.Valid {
+		return r.FindVariant(ctx, id, models.PhotoSizeOriginal)
+	}
+	variant.MimeType = mimeType.String
+
+	return &variant, nil
+}
+
 // Delete deletes a photo
 func (r *AccidentPhotoRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM accident_photos WHERE id = $1`
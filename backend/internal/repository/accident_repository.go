@@ -93,52 +93,115 @@ func (r *AccidentRepository) FindByID(ctx context.Context, id string) (*models.A
 
 // FindAll retrieves all accidents with optional filters
 func (r *AccidentRepository) FindAll(ctx context.Context, filters map[string]interface{}) ([]*models.Accident, error) {
+	var args []interface{}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
 	query := `
 		SELECT id, car_id, accident_date, location, description, 
 		       damages_description, responsible_party, police_report_number, 
 		       insurance_claim_number, status, created_at, updated_at, created_by
 		FROM accidents
-		WHERE 1=1
-	`
+		WHERE ` + strings.Join(accidentConditions(filters, addArg), " AND ")
 
-	var args []interface{}
-	argCount := 1
+	// Add ordering
+	query += " ORDER BY accident_date DESC"
 
-	// Apply filters
-	if carID, ok := filters["car_id"].(string); ok && carID != "" {
-		query += fmt.Sprintf(" AND car_id = $%d", argCount)
-		args = append(args, carID)
-		argCount++
+	// Add pagination
+	if limit, ok := filters["limit"].(int); ok && limit > 0 {
+		query += " LIMIT " + addArg(limit)
 	}
 
-	if status, ok := filters["status"].(string); ok && status != "" {
-		query += fmt.Sprintf(" AND status = $%d", argCount)
-		args = append(args, status)
-		argCount++
+	if offset, ok := filters["offset"].(int); ok && offset > 0 {
+		query += " OFFSET " + addArg(offset)
 	}
 
-	if search, ok := filters["search"].(string); ok && search != "" {
-		searchPattern := "%" + strings.ToLower(search) + "%"
-		query += fmt.Sprintf(" AND (LOWER(location) LIKE $%d OR LOWER(description) LIKE $%d)", argCount, argCount)
-		args = append(args, searchPattern)
-		argCount++
+	return r.queryAccidents(ctx, query, args...)
+}
+
+// FindPage retrieves a page of accidents with optional filters, most recent
+// first. With a cursor it reads the page next to it; otherwise it reads the
+// page numbered page and also returns the total count.
+func (r *AccidentRepository) FindPage(ctx context.Context, filters map[string]interface{}, cursor *models.Cursor, page, limit int) ([]*models.Accident, int, models.PageCursors, error) {
+	var args []interface{}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
 	}
 
-	// Add ordering
-	query += " ORDER BY accident_date DESC"
+	where := accidentConditions(filters, addArg)
+	offset := 0
+	totalCount := 0
+	if cursor != nil {
+		condition, err := accidentKeyset.condition(cursor, addArg)
+		if err != nil {
+			return nil, 0, models.PageCursors{}, err
+		}
+		where = append(where, condition)
+	} else {
+		offset = (page - 1) * limit
+		count, err := r.Count(ctx, filters)
+		if err != nil {
+			return nil, 0, models.PageCursors{}, err
+		}
+		totalCount = count
+	}
 
-	// Add pagination
-	if limit, ok := filters["limit"].(int); ok && limit > 0 {
-		query += fmt.Sprintf(" LIMIT $%d", argCount)
-		args = append(args, limit)
-		argCount++
+	// One more row than asked tells whether there is a next page
+	query := fmt.Sprintf(`
+		SELECT id, car_id, accident_date, location, description, 
+		       damages_description, responsible_party, police_report_number, 
+		       insurance_claim_number, status, created_at, updated_at, created_by
+		FROM accidents
+		WHERE %s
+		ORDER BY %s
+		LIMIT %s OFFSET %s
+	`, strings.Join(where, " AND "), accidentKeyset.orderBy(cursor != nil && cursor.Before), addArg(limit+1), addArg(offset))
+
+	accidents, err := r.queryAccidents(ctx, query, args...)
+	if err != nil {
+		return nil, 0, models.PageCursors{}, err
 	}
 
-	if offset, ok := filters["offset"].(int); ok && offset > 0 {
-		query += fmt.Sprintf(" OFFSET $%d", argCount)
-		args = append(args, offset)
+	accidents, cursors := accidentKeyset.page(accidents, limit, cursor, offset)
+	return accidents, totalCount, cursors, nil
+}
+
+// accidentKeyset orders accident lists, most recent first
+var accidentKeyset = keyset[*models.Accident]{
+	name:    "accidentDate:desc",
+	columns: []keysetColumn{{expr: "accident_date", isTime: true}, {expr: "id"}},
+	desc:    true,
+	key: func(accident *models.Accident) []interface{} {
+		return []interface{}{accident.AccidentDate, accident.ID}
+	},
+}
+
+// accidentConditions returns the WHERE conditions for the car_id, status
+// and search filters, adding their values as query arguments
+func accidentConditions(filters map[string]interface{}, addArg func(interface{}) string) []string {
+	where := []string{"1=1"}
+
+	if carID, ok := filters["car_id"].(string); ok && carID != "" {
+		where = append(where, "car_id = "+addArg(carID))
+	}
+
+	if status, ok := filters["status"].(string); ok && status != "" {
+		where = append(where, "status = "+addArg(status))
 	}
 
+	if search, ok := filters["search"].(string); ok && search != "" {
+		searchPattern := addArg("%" + strings.ToLower(search) + "%")
+		where = append(where, fmt.Sprintf("(LOWER(location) LIKE %s OR LOWER(description) LIKE %s)", searchPattern, searchPattern))
+	}
+
+	return where
+}
+
+// queryAccidents runs a query selecting accident columns and scans the rows
+func (r *AccidentRepository) queryAccidents(ctx context.Context, query string, args ...interface{}) ([]*models.Accident, error) {
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("échec de la recherche des accidents: %w", err)
@@ -303,27 +366,13 @@ func (r *AccidentRepository) Delete(ctx context.Context, id string) error {
 
 // Count counts accidents with optional filters
 func (r *AccidentRepository) Count(ctx context.Context, filters map[string]interface{}) (int, error) {
-	query := "SELECT COUNT(*) FROM accidents WHERE 1=1"
 	var args []interface{}
-	argCount := 1
-
-	if carID, ok := filters["car_id"].(string); ok && carID != "" {
-		query += fmt.Sprintf(" AND car_id = $%d", argCount)
-		args = append(args, carID)
-		argCount++
-	}
-
-	if status, ok := filters["status"].(string); ok && status != "" {
-		query += fmt.Sprintf(" AND status = $%d", argCount)
-		args = append(args, status)
-		argCount++
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
 	}
 
-	if search, ok := filters["search"].(string); ok && search != "" {
-		searchPattern := "%" + strings.ToLower(search) + "%"
-		query += fmt.Sprintf(" AND (LOWER(location) LIKE $%d OR LOWER(description) LIKE $%d)", argCount, argCount)
-		args = append(args, searchPattern)
-	}
+	query := "SELECT COUNT(*) FROM accidents WHERE " + strings.Join(accidentConditions(filters, addArg), " AND ")
 
 	var count int
 	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
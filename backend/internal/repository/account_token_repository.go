@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
+)
+
+// AccountTokenRepository handles database operations for account tokens
+type AccountTokenRepository struct {
+	db *sql.DB
+}
+
+// NewAccountTokenRepository creates a new account token repository
+func NewAccountTokenRepository(db *sql.DB) *AccountTokenRepository {
+	return &AccountTokenRepository{db: db}
+}
+
+// Create stores a new account token. Only the hash of the token is stored.
+func (r *AccountTokenRepository) Create(ctx context.Context, token *models.AccountToken) error {
+	token.TokenHash = utils.HashSessionToken(token.Token)
+
+	query := `
+		INSERT INTO account_tokens (id, user_id, purpose, token_hash, expires_at, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		token.ID,
+		token.UserID,
+		token.Purpose,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedBy,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create account token: %w", err)
+	}
+
+	return nil
+}
+
+// Consume marks an unused, unexpired token as used and returns it. The check
+// and the update are a single statement so a token can only be used once.
+func (r *AccountTokenRepository) Consume(ctx context.Context, rawToken string, purpose models.AccountTokenPurpose) (*models.AccountToken, error) {
+	query := `
+		UPDATE account_tokens
+		SET used_at = $3
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > $3
+		RETURNING id, user_id, purpose, token_hash, expires_at, used_at, created_by, created_at
+	`
+
+	var token models.AccountToken
+	var usedAt sql.NullTime
+	var createdBy sql.NullString
+
+	err := r.db.QueryRowContext(ctx, query, utils.HashSessionToken(rawToken), purpose, time.Now()).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Purpose,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&usedAt,
+		&createdBy,
+		&token.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume account token: %w", err)
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	if createdBy.Valid {
+		token.CreatedBy = &createdBy.String
+	}
+
+	return &token, nil
+}
+
+// FindValid returns an unused, unexpired token without consuming it, to check
+// a request before the token is spent
+func (r *AccountTokenRepository) FindValid(ctx context.Context, rawToken string, purpose models.AccountTokenPurpose) (*models.AccountToken, error) {
+	query := `
+		SELECT id, user_id, purpose, token_hash, expires_at, created_at
+		FROM account_tokens
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > $3
+	`
+
+	var token models.AccountToken
+	err := r.db.QueryRowContext(ctx, query, utils.HashSessionToken(rawToken), purpose, time.Now()).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Purpose,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find account token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// InvalidateByUserID marks the unused tokens of a user for a purpose as used,
+// so that only the most recently sent link works
+func (r *AccountTokenRepository) InvalidateByUserID(ctx context.Context, userID string, purpose models.AccountTokenPurpose) error {
+	query := `UPDATE account_tokens SET used_at = $3 WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, query, userID, purpose, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to invalidate account tokens: %w", err)
+	}
+
+	return nil
+}
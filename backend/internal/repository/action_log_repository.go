@@ -18,11 +18,18 @@ func NewActionLogRepository(db *sql.DB) *ActionLogRepository {
 	return &ActionLogRepository{db: db}
 }
 
-// Create creates a new action log entry
+// Create creates a new action log entry. Actions performed through an API
+// token record that token, taken from the request context when not set.
 func (r *ActionLogRepository) Create(ctx context.Context, log *models.ActionLog) error {
+	if log.APITokenID == nil {
+		if tokenID, ok := models.APITokenIDFromContext(ctx); ok {
+			log.APITokenID = &tokenID
+		}
+	}
+
 	query := `
-		INSERT INTO action_logs (id, entity_type, entity_id, action_type, performed_by, changes, timestamp)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO action_logs (id, entity_type, entity_id, action_type, performed_by, api_token_id, changes, timestamp)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
 	_, err := r.db.ExecContext(
@@ -33,6 +40,7 @@ func (r *ActionLogRepository) Create(ctx context.Context, log *models.ActionLog)
 		log.EntityID,
 		log.ActionType,
 		log.PerformedBy,
+		log.APITokenID,
 		log.Changes,
 		log.Timestamp,
 	)
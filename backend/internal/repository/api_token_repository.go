@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
+)
+
+// APITokenRepository handles database operations for API tokens
+type APITokenRepository struct {
+	db *sql.DB
+}
+
+// NewAPITokenRepository creates a new API token repository
+func NewAPITokenRepository(db *sql.DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+const apiTokenColumns = `id, user_id, name, token_prefix, token_hash, scopes, expires_at,
+		       last_used_at, last_used_ip, revoked_at, created_at`
+
+// Create stores a new API token. Only the hash of the token is stored.
+func (r *APITokenRepository) Create(ctx context.Context, token *models.APIToken) error {
+	token.TokenHash = utils.HashSessionToken(token.Token)
+
+	query := `
+		INSERT INTO api_tokens (id, user_id, name, token_prefix, token_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		token.ID,
+		token.UserID,
+		token.Name,
+		token.TokenPrefix,
+		token.TokenHash,
+		strings.Join(token.Scopes, " "),
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create api token: %w", err)
+	}
+
+	return nil
+}
+
+// FindByToken finds an unrevoked, unexpired API token by its raw value. Like
+// sessions, the lookup is done on the hash and confirmed in constant time.
+func (r *APITokenRepository) FindByToken(ctx context.Context, rawToken string) (*models.APIToken, error) {
+	query := `
+		SELECT ` + apiTokenColumns + `
+		FROM api_tokens
+		WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+	`
+
+	token, err := scanAPIToken(r.db.QueryRowContext(ctx, query, utils.HashSessionToken(rawToken), time.Now()))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("api token not found or expired")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find api token: %w", err)
+	}
+
+	if !utils.CheckSessionTokenHash(rawToken, token.TokenHash) {
+		return nil, fmt.Errorf("api token not found or expired")
+	}
+
+	return token, nil
+}
+
+// FindByUserID returns the API tokens of a user, revoked ones included, most
+// recent first
+func (r *APITokenRepository) FindByUserID(ctx context.Context, userID string) ([]*models.APIToken, error) {
+	query := `
+		SELECT ` + apiTokenColumns + `
+		FROM api_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find api tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []*models.APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// Touch records the last use of an API token
+func (r *APITokenRepository) Touch(ctx context.Context, id string, lastUsedAt time.Time, ipAddress string) error {
+	query := `UPDATE api_tokens SET last_used_at = $2, last_used_ip = $3 WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, id, lastUsedAt, ipAddress)
+	if err != nil {
+		return fmt.Errorf("failed to touch api token: %w", err)
+	}
+
+	return nil
+}
+
+// Revoke revokes an API token of a user. Revoked tokens are kept so that
+// audit entries referencing them stay meaningful.
+func (r *APITokenRepository) Revoke(ctx context.Context, userID, id string) error {
+	query := `UPDATE api_tokens SET revoked_at = $3 WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to revoke api token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("api token not found")
+	}
+
+	return nil
+}
+
+func scanAPIToken(row rowScanner) (*models.APIToken, error) {
+	var token models.APIToken
+	var scopes string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	var lastUsedIP sql.NullString
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.TokenPrefix,
+		&token.TokenHash,
+		&scopes,
+		&expiresAt,
+		&lastUsedAt,
+		&lastUsedIP,
+		&revokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	token.Scopes = strings.Fields(scopes)
+	if expiresAt.Valid {
+		token.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	if lastUsedIP.Valid {
+		token.LastUsedIP = &lastUsedIP.String
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return &token, nil
+}
@@ -8,6 +8,9 @@ import (
 	"time"
 
 	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/goldenkiwi/autoparc/pkg/plates"
+	"github.com/shopspring/decimal"
 )
 
 // CarRepository handles database operations for cars
@@ -20,26 +23,59 @@ func NewCarRepository(db *sql.DB) *CarRepository {
 	return &CarRepository{db: db}
 }
 
+// execer is satisfied by *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
 // Create creates a new car in the database
 func (r *CarRepository) Create(ctx context.Context, car *models.Car) error {
+	return insertCar(ctx, r.db, car)
+}
+
+// CreateWithLifecycleEvent creates a new car together with the event that
+// starts its lifecycle, in one transaction
+func (r *CarRepository) CreateWithLifecycleEvent(ctx context.Context, car *models.Car, event *models.CarLifecycleEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertCar(ctx, tx, car); err != nil {
+		return err
+	}
+	if err := insertLifecycleEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertCar(ctx context.Context, db execer, car *models.Car) error {
 	query := `
-		INSERT INTO cars (id, license_plate, brand, model, grey_card_number, 
-		                  insurance_company_id, rental_start_date, status, 
-		                  created_at, updated_at, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO cars (id, license_plate, plate_country, vin, brand, model, grey_card_number, 
+		                  fuel_type, tank_capacity, insurance_company_id, rental_start_date, status, 
+		                  immobilization_reason, created_at, updated_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	`
 
-	_, err := r.db.ExecContext(
+	_, err := db.ExecContext(
 		ctx,
 		query,
 		car.ID,
 		car.LicensePlate,
+		car.PlateCountry,
+		car.VIN,
 		car.Brand,
 		car.Model,
 		car.GreyCardNumber,
+		car.FuelType,
+		car.TankCapacity,
 		car.InsuranceCompanyID,
 		car.RentalStartDate,
 		car.Status,
+		car.ImmobilizationReason,
 		car.CreatedAt,
 		car.UpdatedAt,
 		car.CreatedBy,
@@ -52,12 +88,44 @@ func (r *CarRepository) Create(ctx context.Context, car *models.Car) error {
 	return nil
 }
 
+// ExistsByVIN checks whether a car other than excludeID has the given VIN
+func (r *CarRepository) ExistsByVIN(ctx context.Context, vin string, excludeID string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM cars WHERE vin = $1 AND id::text <> $2)`
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, vin, excludeID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check VIN: %w", err)
+	}
+
+	return exists, nil
+}
+
+// FindIDByPlate retrieves the car with the given plate, compared without
+// separators, nil if there is none
+func (r *CarRepository) FindIDByPlate(ctx context.Context, compactPlate string) (*string, error) {
+	var carID string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id FROM cars
+		WHERE regexp_replace(license_plate, '[^[:alnum:]]', '', 'g') = $1
+		ORDER BY status = 'retired', created_at DESC
+		LIMIT 1
+	`, compactPlate).Scan(&carID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find car by plate: %w", err)
+	}
+
+	return &carID, nil
+}
+
 // FindByID retrieves a car by ID with its insurance company
 func (r *CarRepository) FindByID(ctx context.Context, id string) (*models.Car, error) {
 	query := `
-		SELECT c.id, c.license_plate, c.brand, c.model, c.grey_card_number, 
-		       c.insurance_company_id, c.rental_start_date, c.status, 
-		       c.created_at, c.updated_at, c.created_by,
+		SELECT c.id, c.license_plate, c.plate_country, c.vin, c.brand, c.model, c.grey_card_number, 
+		       c.fuel_type, c.tank_capacity, c.insurance_company_id, c.rental_start_date, c.status, 
+		       c.immobilization_reason, c.created_at, c.updated_at, c.created_by,
 		       i.id, i.name, i.contact_person, i.phone, i.email, i.address, 
 		       i.policy_number, i.is_active, i.created_at, i.updated_at, i.created_by
 		FROM cars c
@@ -71,12 +139,17 @@ func (r *CarRepository) FindByID(ctx context.Context, id string) (*models.Car, e
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&car.ID,
 		&car.LicensePlate,
+		&car.PlateCountry,
+		&car.VIN,
 		&car.Brand,
 		&car.Model,
 		&car.GreyCardNumber,
+		&car.FuelType,
+		&car.TankCapacity,
 		&car.InsuranceCompanyID,
 		&car.RentalStartDate,
 		&car.Status,
+		&car.ImmobilizationReason,
 		&car.CreatedAt,
 		&car.UpdatedAt,
 		&car.CreatedBy,
@@ -105,75 +178,123 @@ func (r *CarRepository) FindByID(ctx context.Context, id string) (*models.Car, e
 	return &car, nil
 }
 
-// FindAll retrieves cars with pagination and filters
-func (r *CarRepository) FindAll(ctx context.Context, filters *models.CarFilters) ([]*models.Car, int, error) {
+// FindAll retrieves cars with pagination and filters. With a cursor it
+// reads the page next to it and skips counting; otherwise it reads the
+// page numbered filters.Page and also returns the total count.
+func (r *CarRepository) FindAll(ctx context.Context, filters *models.CarFilters) ([]*models.Car, int, models.PageCursors, error) {
 	// Build WHERE clause
 	where := []string{"1=1"}
 	args := []interface{}{}
 	argCount := 0
 
-	if filters.Status != nil {
+	// addArg appends a query argument and returns its placeholder
+	addArg := func(value interface{}) string {
 		argCount++
-		where = append(where, fmt.Sprintf("c.status = $%d", argCount))
-		args = append(args, *filters.Status)
+		args = append(args, value)
+		return fmt.Sprintf("$%d", argCount)
+	}
+
+	statuses := append([]models.CarStatus{}, filters.Statuses...)
+	if filters.Status != nil {
+		statuses = append(statuses, *filters.Status)
+	}
+	if len(statuses) > 0 {
+		placeholders := make([]string, len(statuses))
+		for i, status := range statuses {
+			placeholders[i] = addArg(status)
+		}
+		where = append(where, fmt.Sprintf("c.status IN (%s)", strings.Join(placeholders, ", ")))
 	}
 
 	if filters.Search != "" {
-		argCount++
-		searchPattern := "%" + filters.Search + "%"
-		where = append(where, fmt.Sprintf("(c.license_plate ILIKE $%d OR c.brand ILIKE $%d OR c.model ILIKE $%d)", argCount, argCount, argCount))
-		args = append(args, searchPattern)
+		searchPattern := addArg("%" + filters.Search + "%")
+		condition := fmt.Sprintf("c.license_plate ILIKE %s OR c.brand ILIKE %s OR c.model ILIKE %s", searchPattern, searchPattern, searchPattern)
+		// Plates also match whatever their separators: "ab123" finds "AB-123-CD"
+		if compact := plates.Compact(filters.Search); compact != "" {
+			condition += fmt.Sprintf(" OR regexp_replace(c.license_plate, '[^[:alnum:]]', '', 'g') LIKE %s", addArg("%"+compact+"%"))
+		}
+		where = append(where, "("+condition+")")
 	}
 
-	whereClause := strings.Join(where, " AND ")
+	if filters.VIN != "" {
+		where = append(where, "c.vin = "+addArg(filters.VIN))
+	}
 
-	// Count total records
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM cars c WHERE %s`, whereClause)
-	var totalCount int
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count cars: %w", err)
+	if filters.InsuranceCompanyID != "" {
+		where = append(where, "c.insurance_company_id = "+addArg(filters.InsuranceCompanyID))
+	}
+	if filters.Brand != "" {
+		where = append(where, "LOWER(c.brand) = LOWER("+addArg(filters.Brand)+")")
+	}
+	if filters.Model != "" {
+		where = append(where, "LOWER(c.model) = LOWER("+addArg(filters.Model)+")")
+	}
+	if filters.RentalStartFrom != nil {
+		where = append(where, "c.rental_start_date >= "+addArg(*filters.RentalStartFrom))
+	}
+	if filters.RentalStartTo != nil {
+		where = append(where, "c.rental_start_date <= "+addArg(*filters.RentalStartTo))
+	}
+
+	// A car is assigned while an assignment has no end date
+	if filters.Assigned != nil {
+		where = append(where, existsClause(*filters.Assigned,
+			"SELECT 1 FROM car_operator_assignments a WHERE a.car_id = c.id AND a.end_date IS NULL"))
+	}
+	if filters.Department != "" {
+		where = append(where, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM car_operator_assignments a
+			JOIN car_operators o ON o.id = a.operator_id
+			WHERE a.car_id = c.id AND a.end_date IS NULL AND o.department = %s)`, addArg(filters.Department)))
+	}
+	if filters.HasOpenAccident != nil {
+		where = append(where, existsClause(*filters.HasOpenAccident,
+			"SELECT 1 FROM accidents ac WHERE ac.car_id = c.id AND ac.status <> 'closed'"))
+	}
+	if filters.HasRepairInProgress != nil {
+		where = append(where, existsClause(*filters.HasRepairInProgress,
+			"SELECT 1 FROM repairs rp WHERE rp.car_id = c.id AND rp.status = 'in_progress'"))
 	}
 
-	// Build ORDER BY clause
-	orderBy := "c.created_at DESC"
-	if filters.SortBy != "" {
-		direction := "ASC"
-		if filters.SortOrder == "desc" {
-			direction = "DESC"
+	// Count total records, in page number mode only
+	totalCount := 0
+	if filters.Cursor == nil {
+		countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM cars c WHERE %s`, strings.Join(where, " AND "))
+		if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+			return nil, 0, models.PageCursors{}, fmt.Errorf("failed to count cars: %w", err)
 		}
-		switch filters.SortBy {
-		case "brand":
-			orderBy = fmt.Sprintf("c.brand %s", direction)
-		case "model":
-			orderBy = fmt.Sprintf("c.model %s", direction)
-		case "licensePlate":
-			orderBy = fmt.Sprintf("c.license_plate %s", direction)
-		case "createdAt":
-			orderBy = fmt.Sprintf("c.created_at %s", direction)
+	}
+
+	order := carKeyset(filters.SortBy, filters.SortOrder)
+	backward := filters.Cursor != nil && filters.Cursor.Before
+	offset := 0
+	if filters.Cursor != nil {
+		condition, err := order.condition(filters.Cursor, addArg)
+		if err != nil {
+			return nil, 0, models.PageCursors{}, err
 		}
+		where = append(where, condition)
+	} else {
+		offset = (filters.Page - 1) * filters.Limit
 	}
 
-	// Query with pagination
+	// One more row than asked tells whether there is a next page
 	query := fmt.Sprintf(`
-		SELECT c.id, c.license_plate, c.brand, c.model, c.grey_card_number, 
-		       c.insurance_company_id, c.rental_start_date, c.status, 
-		       c.created_at, c.updated_at, c.created_by,
+		SELECT c.id, c.license_plate, c.plate_country, c.vin, c.brand, c.model, c.grey_card_number, 
+		       c.fuel_type, c.tank_capacity, c.insurance_company_id, c.rental_start_date, c.status, 
+		       c.immobilization_reason, c.created_at, c.updated_at, c.created_by,
 		       i.id, i.name, i.contact_person, i.phone, i.email, i.address, 
 		       i.policy_number, i.is_active, i.created_at, i.updated_at, i.created_by
 		FROM cars c
 		LEFT JOIN insurance_companies i ON c.insurance_company_id = i.id
 		WHERE %s
 		ORDER BY %s
-		LIMIT $%d OFFSET $%d
-	`, whereClause, orderBy, argCount+1, argCount+2)
-
-	offset := (filters.Page - 1) * filters.Limit
-	args = append(args, filters.Limit, offset)
+		LIMIT %s OFFSET %s
+	`, strings.Join(where, " AND "), order.orderBy(backward), addArg(filters.Limit+1), addArg(offset))
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to query cars: %w", err)
+		return nil, 0, models.PageCursors{}, fmt.Errorf("failed to query cars: %w", err)
 	}
 	defer rows.Close()
 
@@ -185,12 +306,17 @@ func (r *CarRepository) FindAll(ctx context.Context, filters *models.CarFilters)
 		err := rows.Scan(
 			&car.ID,
 			&car.LicensePlate,
+			&car.PlateCountry,
+			&car.VIN,
 			&car.Brand,
 			&car.Model,
 			&car.GreyCardNumber,
+			&car.FuelType,
+			&car.TankCapacity,
 			&car.InsuranceCompanyID,
 			&car.RentalStartDate,
 			&car.Status,
+			&car.ImmobilizationReason,
 			&car.CreatedAt,
 			&car.UpdatedAt,
 			&car.CreatedBy,
@@ -207,7 +333,7 @@ func (r *CarRepository) FindAll(ctx context.Context, filters *models.CarFilters)
 			&insurance.CreatedBy,
 		)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan car: %w", err)
+			return nil, 0, models.PageCursors{}, fmt.Errorf("failed to scan car: %w", err)
 		}
 
 		car.InsuranceCompany = &insurance
@@ -215,10 +341,64 @@ func (r *CarRepository) FindAll(ctx context.Context, filters *models.CarFilters)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating cars: %w", err)
+		return nil, 0, models.PageCursors{}, fmt.Errorf("error iterating cars: %w", err)
+	}
+
+	cars, cursors := order.page(cars, filters.Limit, filters.Cursor, offset)
+	return cars, totalCount, cursors, nil
+}
+
+// carKeyset returns the ordering of the car list for a sort field and
+// order. Rows are sorted by creation date, newest first, by default.
+func carKeyset(sortBy, sortOrder string) keyset[*models.Car] {
+	desc := sortOrder == "desc"
+	columns := map[string][]keysetColumn{
+		"brand":           {{expr: "c.brand"}},
+		"model":           {{expr: "c.model"}},
+		"licensePlate":    {{expr: "c.license_plate"}},
+		"createdAt":       {{expr: "c.created_at", isTime: true}},
+		"status":          {{expr: "c.status"}, {expr: "c.license_plate"}},
+		"rentalStartDate": {{expr: "c.rental_start_date", isTime: true}, {expr: "c.license_plate"}},
+	}
+	if _, ok := columns[sortBy]; !ok {
+		sortBy, desc = "createdAt", true
 	}
 
-	return cars, totalCount, nil
+	name := sortBy + ":asc"
+	if desc {
+		name = sortBy + ":desc"
+	}
+
+	return keyset[*models.Car]{
+		name:    name,
+		columns: append(columns[sortBy], keysetColumn{expr: "c.id"}),
+		desc:    desc,
+		key: func(car *models.Car) []interface{} {
+			switch sortBy {
+			case "brand":
+				return []interface{}{car.Brand, car.ID}
+			case "model":
+				return []interface{}{car.Model, car.ID}
+			case "licensePlate":
+				return []interface{}{car.LicensePlate, car.ID}
+			case "status":
+				return []interface{}{car.Status, car.LicensePlate, car.ID}
+			case "rentalStartDate":
+				return []interface{}{car.RentalStartDate, car.LicensePlate, car.ID}
+			default:
+				return []interface{}{car.CreatedAt, car.ID}
+			}
+		},
+	}
+}
+
+// existsClause returns an EXISTS condition on the subquery, negated when
+// want is false
+func existsClause(want bool, subquery string) string {
+	if want {
+		return fmt.Sprintf("EXISTS (%s)", subquery)
+	}
+	return fmt.Sprintf("NOT EXISTS (%s)", subquery)
 }
 
 // Update updates a car's information
@@ -263,27 +443,159 @@ func (r *CarRepository) Update(ctx context.Context, id string, updates map[strin
 	return nil
 }
 
-// Delete soft deletes a car by setting status to retired
-func (r *CarRepository) Delete(ctx context.Context, id string) error {
-	query := `
-		UPDATE cars
-		SET status = $1, updated_at = $2
-		WHERE id = $3
-	`
-
-	result, err := r.db.ExecContext(ctx, query, models.CarStatusRetired, time.Now(), id)
+// ApplyLifecycleEvent records a lifecycle event of a car and sets the car
+// to the resulting status, in one transaction. The immobilization reason is
+// kept while the car stays in maintenance.
+func (r *CarRepository) ApplyLifecycleEvent(ctx context.Context, event *models.CarLifecycleEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to delete car: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
+	result, err := tx.ExecContext(ctx, `
+		UPDATE cars
+		SET status = $2,
+		    immobilization_reason = CASE WHEN $2 = 'maintenance' THEN COALESCE($3, immobilization_reason) END,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, event.CarID, event.Status, event.Reason)
+	if err != nil {
+		return fmt.Errorf("failed to update car status: %w", err)
+	}
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
-
 	if rowsAffected == 0 {
 		return fmt.Errorf("car not found")
 	}
 
+	if err := insertLifecycleEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateLifecycleEvent updates the date and details of a lifecycle event that
+// does not change the status of the car
+func (r *CarRepository) UpdateLifecycleEvent(ctx context.Context, event *models.CarLifecycleEvent) error {
+	var price interface{}
+	var currency *string
+	if event.Price != nil {
+		price = event.Price.Amount
+		currency = &event.Price.Currency
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE car_lifecycle_events
+		SET event_date = $2, price = $3, currency = $4, counterparty = $5, notes = $6
+		WHERE id = $1
+	`, event.ID, event.EventDate, price, currency, event.Counterparty, event.Notes)
+	if err != nil {
+		return fmt.Errorf("failed to update lifecycle event: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("lifecycle event not found")
+	}
+
+	return nil
+}
+
+// FindLifecycleEvents retrieves the lifecycle events of a car, oldest first
+func (r *CarRepository) FindLifecycleEvents(ctx context.Context, carID string) ([]*models.CarLifecycleEvent, error) {
+	query := `
+		SELECT id, car_id, event_type, event_date, previous_status, status, reason,
+		       accident_id, price, currency, counterparty, notes, created_at, created_by
+		FROM car_lifecycle_events
+		WHERE car_id = $1
+		ORDER BY event_date, created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, carID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lifecycle events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*models.CarLifecycleEvent{}
+	for rows.Next() {
+		var event models.CarLifecycleEvent
+		var price decimal.NullDecimal
+		var currency sql.NullString
+
+		err := rows.Scan(
+			&event.ID,
+			&event.CarID,
+			&event.Type,
+			&event.EventDate,
+			&event.PreviousStatus,
+			&event.Status,
+			&event.Reason,
+			&event.AccidentID,
+			&price,
+			&currency,
+			&event.Counterparty,
+			&event.Notes,
+			&event.CreatedAt,
+			&event.CreatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan lifecycle event: %w", err)
+		}
+		if price.Valid {
+			amount := money.New(price.Decimal, currency.String)
+			event.Price = &amount
+		}
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating lifecycle events: %w", err)
+	}
+
+	return events, nil
+}
+
+// insertLifecycleEvent records a lifecycle event within a transaction that
+// also changes the status of the car
+func insertLifecycleEvent(ctx context.Context, tx *sql.Tx, event *models.CarLifecycleEvent) error {
+	var price interface{}
+	var currency *string
+	if event.Price != nil {
+		price = event.Price.Amount
+		currency = &event.Price.Currency
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO car_lifecycle_events (id, car_id, event_type, event_date, previous_status, status,
+		                                  reason, accident_id, price, currency, counterparty, notes,
+		                                  created_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`,
+		event.ID,
+		event.CarID,
+		event.Type,
+		event.EventDate,
+		event.PreviousStatus,
+		event.Status,
+		event.Reason,
+		event.AccidentID,
+		price,
+		currency,
+		event.Counterparty,
+		event.Notes,
+		event.CreatedAt,
+		event.CreatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create lifecycle event: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// gzipMagic is the header of gzip streams
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// gzipCompress compresses file data before it is stored in a BYTEA column
+func gzipCompress(data []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	if _, err := gzipWriter.Write(data); err != nil {
+		return nil, fmt.Errorf("échec de la compression du fichier: %w", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("échec de la fermeture du compresseur: %w", err)
+	}
+
+	return compressed.Bytes(), nil
+}
+
+// gunzip decompresses gzip data
+func gunzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+)
+
+// documentMetadataColumns are the columns selected when file data is not needed
+const documentMetadataColumns = `id, document_group_id, version, entity_type, entity_id, document_type,
+		       filename, file_size, mime_type, compression_type, description, expiry_date,
+		       uploaded_at, uploaded_by`
+
+// DocumentRepository handles database operations for documents
+type DocumentRepository struct {
+	db *sql.DB
+}
+
+// NewDocumentRepository creates a new document repository
+func NewDocumentRepository(db *sql.DB) *DocumentRepository {
+	return &DocumentRepository{db: db}
+}
+
+// Create stores a document with gzip compression. The version is assigned
+// atomically as the next version of the document group.
+func (r *DocumentRepository) Create(ctx context.Context, doc *models.Document) error {
+	compressed, err := gzipCompress(doc.FileData)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO documents (id, document_group_id, version, entity_type, entity_id, document_type,
+		                       filename, file_data, file_size, mime_type, compression_type,
+		                       description, expiry_date, uploaded_at, uploaded_by)
+		VALUES ($1, $2,
+		        (SELECT COALESCE(MAX(version), 0) + 1 FROM documents WHERE document_group_id = $2),
+		        $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING version
+	`
+
+	err = r.db.QueryRowContext(
+		ctx,
+		query,
+		doc.ID,
+		doc.DocumentGroupID,
+		doc.EntityType,
+		doc.EntityID,
+		doc.DocumentType,
+		doc.Filename,
+		compressed,
+		doc.FileSize,
+		doc.MimeType,
+		models.CompressionTypeGzip,
+		doc.Description,
+		doc.ExpiryDate,
+		doc.UploadedAt,
+		doc.UploadedBy,
+	).Scan(&doc.Version)
+
+	if err != nil {
+		return fmt.Errorf("échec de la création du document: %w", err)
+	}
+
+	doc.CompressionType = models.CompressionTypeGzip
+	return nil
+}
+
+// FindByID retrieves a document's metadata by ID
+func (r *DocumentRepository) FindByID(ctx context.Context, id string) (*models.Document, error) {
+	query := `SELECT ` + documentMetadataColumns + ` FROM documents WHERE id = $1`
+
+	doc, err := scanDocument(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("document non trouvé")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("échec de la recherche du document: %w", err)
+	}
+
+	return doc, nil
+}
+
+// FindContent retrieves a document with its decompressed file data
+func (r *DocumentRepository) FindContent(ctx context.Context, id string) (*models.Document, error) {
+	doc, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if err := r.db.QueryRowContext(ctx, `SELECT file_data FROM documents WHERE id = $1`, id).Scan(&data); err != nil {
+		return nil, fmt.Errorf("échec de la lecture du document: %w", err)
+	}
+
+	if doc.CompressionType == models.CompressionTypeGzip {
+		if data, err = gunzip(data); err != nil {
+			return nil, fmt.Errorf("échec de la décompression du document: %w", err)
+		}
+	}
+	doc.FileData = data
+
+	return doc, nil
+}
+
+// FindLatest retrieves the latest version of each document matching the filters
+func (r *DocumentRepository) FindLatest(ctx context.Context, filters *models.DocumentFilters) ([]*models.Document, error) {
+	var conditions []string
+	var args []interface{}
+	argPos := 1
+
+	if filters.EntityType != "" {
+		conditions = append(conditions, fmt.Sprintf("entity_type = $%d", argPos))
+		args = append(args, filters.EntityType)
+		argPos++
+	}
+
+	if filters.EntityID != "" {
+		conditions = append(conditions, fmt.Sprintf("entity_id = $%d", argPos))
+		args = append(args, filters.EntityID)
+		argPos++
+	}
+
+	if filters.DocumentType != "" {
+		conditions = append(conditions, fmt.Sprintf("document_type = $%d", argPos))
+		args = append(args, filters.DocumentType)
+		argPos++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// The expiry filter applies to the latest version only, so it is evaluated
+	// after picking the latest version of each group
+	outerWhere := ""
+	if filters.ExpiringBefore != nil {
+		outerWhere = fmt.Sprintf("WHERE expiry_date IS NOT NULL AND expiry_date <= $%d", argPos)
+		args = append(args, *filters.ExpiringBefore)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM (
+			SELECT DISTINCT ON (document_group_id) *
+			FROM documents
+			%s
+			ORDER BY document_group_id, version DESC
+		) latest
+		%s
+		ORDER BY uploaded_at DESC
+	`, documentMetadataColumns, whereClause, outerWhere)
+
+	return r.queryDocuments(ctx, query, args...)
+}
+
+// FindVersions retrieves every version of a document group, newest first
+func (r *DocumentRepository) FindVersions(ctx context.Context, groupID string) ([]*models.Document, error) {
+	query := `
+		SELECT ` + documentMetadataColumns + `
+		FROM documents
+		WHERE document_group_id = $1
+		ORDER BY version DESC
+	`
+
+	return r.queryDocuments(ctx, query, groupID)
+}
+
+// DeleteGroup deletes a document and all its versions
+func (r *DocumentRepository) DeleteGroup(ctx context.Context, groupID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM documents WHERE document_group_id = $1`, groupID)
+	if err != nil {
+		return fmt.Errorf("échec de la suppression du document: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("échec de la vérification des lignes affectées: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("document non trouvé")
+	}
+
+	return nil
+}
+
+func (r *DocumentRepository) queryDocuments(ctx context.Context, query string, args ...interface{}) ([]*models.Document, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("échec de la recherche des documents: %w", err)
+	}
+	defer rows.Close()
+
+	documents := []*models.Document{}
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("échec du scan du document: %w", err)
+		}
+		documents = append(documents, doc)
+	}
+
+	return documents, rows.Err()
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDocument(row rowScanner) (*models.Document, error) {
+	var doc models.Document
+	err := row.Scan(
+		&doc.ID,
+		&doc.DocumentGroupID,
+		&doc.Version,
+		&doc.EntityType,
+		&doc.EntityID,
+		&doc.DocumentType,
+		&doc.Filename,
+		&doc.FileSize,
+		&doc.MimeType,
+		&doc.CompressionType,
+		&doc.Description,
+		&doc.ExpiryDate,
+		&doc.UploadedAt,
+		&doc.UploadedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
+}
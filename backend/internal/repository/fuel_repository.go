@@ -0,0 +1,408 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/shopspring/decimal"
+)
+
+// fuelCardColumns are the columns read by scanFuelCard
+const fuelCardColumns = `
+	f.id, f.card_number, f.provider, f.car_id, f.operator_id, f.status,
+	f.expiry_date, f.notes, f.created_at, f.updated_at, f.created_by,
+	c.license_plate, o.first_name || ' ' || o.last_name
+`
+
+// fuelCardJoins joins the holder of a card
+const fuelCardJoins = `
+	FROM fuel_cards f
+	LEFT JOIN cars c ON f.car_id = c.id
+	LEFT JOIN car_operators o ON f.operator_id = o.id
+`
+
+// fuelTransactionColumns are the columns read by scanFuelTransaction
+const fuelTransactionColumns = `
+	t.id, t.card_number, t.reference, t.card_id, t.car_id, t.operator_id,
+	t.license_plate, t.transacted_at, t.station, t.product, t.fuel_type,
+	t.volume, t.amount, t.currency, t.mileage, t.consumption, t.anomalies,
+	t.imported_at, t.imported_by
+`
+
+// FuelRepository handles database operations for fuel cards and fuel
+// transactions
+type FuelRepository struct {
+	db *sql.DB
+}
+
+// NewFuelRepository creates a new fuel repository
+func NewFuelRepository(db *sql.DB) *FuelRepository {
+	return &FuelRepository{db: db}
+}
+
+// CreateCard creates a new fuel card
+func (r *FuelRepository) CreateCard(ctx context.Context, card *models.FuelCard) error {
+	query := `
+		INSERT INTO fuel_cards (id, card_number, provider, car_id, operator_id, status,
+		                        expiry_date, notes, created_at, updated_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		card.ID,
+		card.CardNumber,
+		card.Provider,
+		card.CarID,
+		card.OperatorID,
+		card.Status,
+		card.ExpiryDate,
+		card.Notes,
+		card.CreatedAt,
+		card.UpdatedAt,
+		card.CreatedBy,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "fuel_cards_card_number_key") {
+			return fmt.Errorf("a fuel card with this number already exists")
+		}
+		return fmt.Errorf("failed to create fuel card: %w", err)
+	}
+
+	return nil
+}
+
+// FindCardByID retrieves a fuel card by ID
+func (r *FuelRepository) FindCardByID(ctx context.Context, id string) (*models.FuelCard, error) {
+	query := `SELECT ` + fuelCardColumns + fuelCardJoins + ` WHERE f.id = $1`
+
+	card, err := scanFuelCard(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("fuel card not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find fuel card: %w", err)
+	}
+
+	return card, nil
+}
+
+// FindCardByNumber retrieves a fuel card by its normalized number, nil if
+// there is none
+func (r *FuelRepository) FindCardByNumber(ctx context.Context, number string) (*models.FuelCard, error) {
+	query := `SELECT ` + fuelCardColumns + fuelCardJoins + ` WHERE f.card_number = $1`
+
+	card, err := scanFuelCard(r.db.QueryRowContext(ctx, query, number))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find fuel card: %w", err)
+	}
+
+	return card, nil
+}
+
+// FindAllCards retrieves the fuel cards matching the filters, by number
+func (r *FuelRepository) FindAllCards(ctx context.Context, filters *models.FuelCardFilters) ([]*models.FuelCard, error) {
+	var where []string
+	var args []interface{}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	where = append(where, "1=1")
+	if filters.CarID != "" {
+		where = append(where, "f.car_id = "+addArg(filters.CarID))
+	}
+	if filters.OperatorID != "" {
+		where = append(where, "f.operator_id = "+addArg(filters.OperatorID))
+	}
+	if filters.Status != "" {
+		where = append(where, "f.status = "+addArg(filters.Status))
+	}
+	if filters.Provider != "" {
+		where = append(where, "f.provider ILIKE "+addArg("%"+filters.Provider+"%"))
+	}
+
+	query := fmt.Sprintf(`SELECT %s %s WHERE %s ORDER BY f.card_number`,
+		fuelCardColumns, fuelCardJoins, strings.Join(where, " AND "))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fuel cards: %w", err)
+	}
+	defer rows.Close()
+
+	cards := []*models.FuelCard{}
+	for rows.Next() {
+		card, err := scanFuelCard(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan fuel card: %w", err)
+		}
+		cards = append(cards, card)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fuel cards: %w", err)
+	}
+
+	return cards, nil
+}
+
+// UpdateCard updates a fuel card
+func (r *FuelRepository) UpdateCard(ctx context.Context, card *models.FuelCard) error {
+	query := `
+		UPDATE fuel_cards
+		SET provider = $2, car_id = $3, operator_id = $4, status = $5,
+		    expiry_date = $6, notes = $7
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		card.ID,
+		card.Provider,
+		card.CarID,
+		card.OperatorID,
+		card.Status,
+		card.ExpiryDate,
+		card.Notes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update fuel card: %w", err)
+	}
+
+	return checkFuelCardAffected(result)
+}
+
+// DeleteCard deletes a fuel card. Its transactions are kept, with the card
+// number they were imported with.
+func (r *FuelRepository) DeleteCard(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM fuel_cards WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete fuel card: %w", err)
+	}
+
+	return checkFuelCardAffected(result)
+}
+
+// FindPreviousMileage retrieves the odometer reading of the last fill-up of
+// a car before the given time, nil if there is none
+func (r *FuelRepository) FindPreviousMileage(ctx context.Context, carID string, before time.Time) (*int, error) {
+	var mileage int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT mileage FROM fuel_transactions
+		WHERE car_id = $1 AND transacted_at < $2 AND mileage IS NOT NULL
+		  AND fuel_type IN ('petrol', 'diesel', 'lpg')
+		ORDER BY transacted_at DESC
+		LIMIT 1
+	`, carID, before).Scan(&mileage)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find previous mileage: %w", err)
+	}
+
+	return &mileage, nil
+}
+
+// CreateTransaction stores an imported fuel transaction. It returns false,
+// without error, when the transaction was already imported.
+func (r *FuelRepository) CreateTransaction(ctx context.Context, tx *models.FuelTransaction) (bool, error) {
+	query := `
+		INSERT INTO fuel_transactions (id, card_number, reference, card_id, car_id, operator_id,
+		                               license_plate, transacted_at, station, product, fuel_type,
+		                               volume, amount, currency, mileage, consumption, anomalies,
+		                               imported_at, imported_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		ON CONFLICT ON CONSTRAINT unique_fuel_transaction_reference DO NOTHING
+	`
+
+	anomalies := make([]string, len(tx.Anomalies))
+	for i, anomaly := range tx.Anomalies {
+		anomalies[i] = string(anomaly)
+	}
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		tx.ID,
+		tx.CardNumber,
+		tx.Reference,
+		tx.CardID,
+		tx.CarID,
+		tx.OperatorID,
+		tx.LicensePlate,
+		tx.TransactedAt,
+		tx.Station,
+		tx.Product,
+		tx.FuelType,
+		tx.Volume,
+		tx.Amount.Amount,
+		tx.Amount.Currency,
+		tx.Mileage,
+		tx.Consumption,
+		strings.Join(anomalies, " "),
+		tx.ImportedAt,
+		tx.ImportedBy,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create fuel transaction: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// FindTransactions retrieves the fuel transactions matching the filters,
+// latest first
+func (r *FuelRepository) FindTransactions(ctx context.Context, filters *models.FuelTransactionFilters) ([]*models.FuelTransaction, error) {
+	var where []string
+	var args []interface{}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	where = append(where, "1=1")
+	if filters.CarID != "" {
+		where = append(where, "t.car_id = "+addArg(filters.CarID))
+	}
+	if filters.CardID != "" {
+		where = append(where, "t.card_id = "+addArg(filters.CardID))
+	}
+	if filters.Anomaly != "" {
+		where = append(where, addArg(string(filters.Anomaly))+" = ANY(string_to_array(t.anomalies, ' '))")
+	}
+	if filters.Flagged {
+		where = append(where, "t.anomalies <> ''")
+	}
+	if filters.From != nil {
+		where = append(where, "t.transacted_at >= "+addArg(*filters.From))
+	}
+	if filters.To != nil {
+		// To is inclusive of the whole day
+		where = append(where, "t.transacted_at < "+addArg(filters.To.AddDate(0, 0, 1)))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM fuel_transactions t
+		WHERE %s
+		ORDER BY t.transacted_at DESC, t.id
+	`, fuelTransactionColumns, strings.Join(where, " AND "))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fuel transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := []*models.FuelTransaction{}
+	for rows.Next() {
+		tx, err := scanFuelTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan fuel transaction: %w", err)
+		}
+		transactions = append(transactions, tx)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fuel transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+func scanFuelCard(row rowScanner) (*models.FuelCard, error) {
+	var card models.FuelCard
+
+	err := row.Scan(
+		&card.ID,
+		&card.CardNumber,
+		&card.Provider,
+		&card.CarID,
+		&card.OperatorID,
+		&card.Status,
+		&card.ExpiryDate,
+		&card.Notes,
+		&card.CreatedAt,
+		&card.UpdatedAt,
+		&card.CreatedBy,
+		&card.LicensePlate,
+		&card.OperatorName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &card, nil
+}
+
+func scanFuelTransaction(row rowScanner) (*models.FuelTransaction, error) {
+	var tx models.FuelTransaction
+	var amount decimal.Decimal
+	var currency, anomalies string
+	var consumption decimal.NullDecimal
+
+	err := row.Scan(
+		&tx.ID,
+		&tx.CardNumber,
+		&tx.Reference,
+		&tx.CardID,
+		&tx.CarID,
+		&tx.OperatorID,
+		&tx.LicensePlate,
+		&tx.TransactedAt,
+		&tx.Station,
+		&tx.Product,
+		&tx.FuelType,
+		&tx.Volume,
+		&amount,
+		&currency,
+		&tx.Mileage,
+		&consumption,
+		&anomalies,
+		&tx.ImportedAt,
+		&tx.ImportedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Amount = money.New(amount, currency)
+	if consumption.Valid {
+		tx.Consumption = &consumption.Decimal
+	}
+	tx.Anomalies = []models.FuelAnomaly{}
+	for _, anomaly := range strings.Fields(anomalies) {
+		tx.Anomalies = append(tx.Anomalies, models.FuelAnomaly(anomaly))
+	}
+
+	return &tx, nil
+}
+
+func checkFuelCardAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("fuel card not found")
+	}
+	return nil
+}
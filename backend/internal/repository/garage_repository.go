@@ -87,45 +87,108 @@ func (r *GarageRepository) FindByID(ctx context.Context, id string) (*models.Gar
 
 // FindAll retrieves all garages with optional filters
 func (r *GarageRepository) FindAll(ctx context.Context, filters map[string]interface{}) ([]*models.Garage, error) {
+	var args []interface{}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
 	query := `
 		SELECT id, name, contact_person, phone, email, address, 
 		       specialization, is_active, created_at, updated_at, created_by
 		FROM garages
-		WHERE 1=1
-	`
+		WHERE ` + strings.Join(garageConditions(filters, addArg), " AND ")
+
+	// Add ordering
+	query += " ORDER BY name ASC"
 
+	// Add pagination
+	if limit, ok := filters["limit"].(int); ok && limit > 0 {
+		query += " LIMIT " + addArg(limit)
+	}
+
+	if offset, ok := filters["offset"].(int); ok && offset > 0 {
+		query += " OFFSET " + addArg(offset)
+	}
+
+	return r.queryGarages(ctx, query, args...)
+}
+
+// FindPage retrieves a page of garages with optional filters, by name. With
+// a cursor it reads the page next to it; otherwise it reads the page
+// numbered page and also returns the total count.
+func (r *GarageRepository) FindPage(ctx context.Context, filters map[string]interface{}, cursor *models.Cursor, page, limit int) ([]*models.Garage, int, models.PageCursors, error) {
 	var args []interface{}
-	argCount := 1
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
 
-	// Apply filters
-	if isActive, ok := filters["is_active"].(bool); ok {
-		query += fmt.Sprintf(" AND is_active = $%d", argCount)
-		args = append(args, isActive)
-		argCount++
+	where := garageConditions(filters, addArg)
+	offset := 0
+	totalCount := 0
+	if cursor != nil {
+		condition, err := garageKeyset.condition(cursor, addArg)
+		if err != nil {
+			return nil, 0, models.PageCursors{}, err
+		}
+		where = append(where, condition)
+	} else {
+		offset = (page - 1) * limit
+		count, err := r.Count(ctx, filters)
+		if err != nil {
+			return nil, 0, models.PageCursors{}, err
+		}
+		totalCount = count
 	}
 
-	if search, ok := filters["search"].(string); ok && search != "" {
-		searchPattern := "%" + strings.ToLower(search) + "%"
-		query += fmt.Sprintf(" AND (LOWER(name) LIKE $%d OR LOWER(specialization) LIKE $%d)", argCount, argCount)
-		args = append(args, searchPattern)
-		argCount++
+	// One more row than asked tells whether there is a next page
+	query := fmt.Sprintf(`
+		SELECT id, name, contact_person, phone, email, address, 
+		       specialization, is_active, created_at, updated_at, created_by
+		FROM garages
+		WHERE %s
+		ORDER BY %s
+		LIMIT %s OFFSET %s
+	`, strings.Join(where, " AND "), garageKeyset.orderBy(cursor != nil && cursor.Before), addArg(limit+1), addArg(offset))
+
+	garages, err := r.queryGarages(ctx, query, args...)
+	if err != nil {
+		return nil, 0, models.PageCursors{}, err
 	}
 
-	// Add ordering
-	query += " ORDER BY name ASC"
+	garages, cursors := garageKeyset.page(garages, limit, cursor, offset)
+	return garages, totalCount, cursors, nil
+}
 
-	// Add pagination
-	if limit, ok := filters["limit"].(int); ok && limit > 0 {
-		query += fmt.Sprintf(" LIMIT $%d", argCount)
-		args = append(args, limit)
-		argCount++
+// garageKeyset orders garage lists by name
+var garageKeyset = keyset[*models.Garage]{
+	name:    "name:asc",
+	columns: []keysetColumn{{expr: "name"}, {expr: "id"}},
+	key: func(garage *models.Garage) []interface{} {
+		return []interface{}{garage.Name, garage.ID}
+	},
+}
+
+// garageConditions returns the WHERE conditions for the is_active and search
+// filters, adding their values as query arguments
+func garageConditions(filters map[string]interface{}, addArg func(interface{}) string) []string {
+	where := []string{"1=1"}
+
+	if isActive, ok := filters["is_active"].(bool); ok {
+		where = append(where, "is_active = "+addArg(isActive))
 	}
 
-	if offset, ok := filters["offset"].(int); ok && offset > 0 {
-		query += fmt.Sprintf(" OFFSET $%d", argCount)
-		args = append(args, offset)
+	if search, ok := filters["search"].(string); ok && search != "" {
+		searchPattern := addArg("%" + strings.ToLower(search) + "%")
+		where = append(where, fmt.Sprintf("(LOWER(name) LIKE %s OR LOWER(specialization) LIKE %s)", searchPattern, searchPattern))
 	}
 
+	return where
+}
+
+// queryGarages runs a query selecting garage columns and scans the rows
+func (r *GarageRepository) queryGarages(ctx context.Context, query string, args ...interface{}) ([]*models.Garage, error) {
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("échec de la recherche des garages: %w", err)
@@ -223,21 +286,13 @@ func (r *GarageRepository) Delete(ctx context.Context, id string) error {
 
 // Count counts garages with optional filters
 func (r *GarageRepository) Count(ctx context.Context, filters map[string]interface{}) (int, error) {
-	query := "SELECT COUNT(*) FROM garages WHERE 1=1"
 	var args []interface{}
-	argCount := 1
-
-	if isActive, ok := filters["is_active"].(bool); ok {
-		query += fmt.Sprintf(" AND is_active = $%d", argCount)
-		args = append(args, isActive)
-		argCount++
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
 	}
 
-	if search, ok := filters["search"].(string); ok && search != "" {
-		searchPattern := "%" + strings.ToLower(search) + "%"
-		query += fmt.Sprintf(" AND (LOWER(name) LIKE $%d OR LOWER(specialization) LIKE $%d)", argCount, argCount)
-		args = append(args, searchPattern)
-	}
+	query := "SELECT COUNT(*) FROM garages WHERE " + strings.Join(garageConditions(filters, addArg), " AND ")
 
 	var count int
 	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
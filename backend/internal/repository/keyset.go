@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/google/uuid"
+)
+
+// keysetColumn is a column of a keyset ordering. Time columns travel in
+// cursors as RFC 3339 strings and are parsed back before being compared.
+type keysetColumn struct {
+	expr   string
+	isTime bool
+}
+
+// keyset is a stable ordering of rows of type T usable for keyset (cursor)
+// pagination: the sort columns followed by the row ID as a tiebreaker, all
+// in the same direction. key returns the values of the columns for a row.
+type keyset[T any] struct {
+	name    string
+	columns []keysetColumn
+	desc    bool
+	key     func(T) []interface{}
+}
+
+// orderBy returns the ORDER BY clause, reversed when reading backwards
+func (k keyset[T]) orderBy(backward bool) string {
+	direction := "ASC"
+	if k.desc != backward {
+		direction = "DESC"
+	}
+
+	terms := make([]string, len(k.columns))
+	for i, column := range k.columns {
+		terms[i] = column.expr + " " + direction
+	}
+	return strings.Join(terms, ", ")
+}
+
+// condition returns the WHERE condition selecting the rows on the requested
+// side of the cursor, adding its values as query arguments
+func (k keyset[T]) condition(cursor *models.Cursor, addArg func(interface{}) string) (string, error) {
+	if cursor.Sort != k.name || len(cursor.Values) != len(k.columns) {
+		return "", models.ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(cursor.Values[len(cursor.Values)-1]); err != nil {
+		return "", models.ErrInvalidCursor
+	}
+
+	exprs := make([]string, len(k.columns))
+	placeholders := make([]string, len(k.columns))
+	for i, column := range k.columns {
+		var value interface{} = cursor.Values[i]
+		if column.isTime {
+			t, err := time.Parse(time.RFC3339Nano, cursor.Values[i])
+			if err != nil {
+				return "", models.ErrInvalidCursor
+			}
+			value = t
+		}
+		exprs[i] = column.expr
+		placeholders[i] = addArg(value)
+	}
+
+	operator := ">"
+	if k.desc != cursor.Before {
+		operator = "<"
+	}
+	return fmt.Sprintf("(%s) %s (%s)", strings.Join(exprs, ", "), operator, strings.Join(placeholders, ", ")), nil
+}
+
+// cursor returns the encoded cursor of a row
+func (k keyset[T]) cursor(row T, before bool) *string {
+	values := k.key(row)
+	cursor := &models.Cursor{Sort: k.name, Values: make([]string, len(values)), Before: before}
+	for i, value := range values {
+		switch v := value.(type) {
+		case time.Time:
+			cursor.Values[i] = v.Format(time.RFC3339Nano)
+		case *string:
+			if v != nil {
+				cursor.Values[i] = *v
+			}
+		default:
+			cursor.Values[i] = fmt.Sprint(v)
+		}
+	}
+	encoded := cursor.Encode()
+	return &encoded
+}
+
+// page trims rows, fetched with a limit of limit+1 in the order given by
+// orderBy, to the requested page and returns the cursors around it. cursor
+// is the one the page was read from, nil in page number mode.
+func (k keyset[T]) page(rows []T, limit int, cursor *models.Cursor, offset int) ([]T, models.PageCursors) {
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+
+	var cursors models.PageCursors
+	if cursor != nil && cursor.Before {
+		// Rows were read backwards from the cursor
+		slices.Reverse(rows)
+		if len(rows) > 0 {
+			cursors.Next = k.cursor(rows[len(rows)-1], false)
+			if hasMore {
+				cursors.Prev = k.cursor(rows[0], true)
+			}
+		}
+		return rows, cursors
+	}
+
+	if len(rows) > 0 {
+		if hasMore {
+			cursors.Next = k.cursor(rows[len(rows)-1], false)
+		}
+		if cursor != nil || offset > 0 {
+			cursors.Prev = k.cursor(rows[0], true)
+		}
+	}
+	return rows, cursors
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type keysetRow struct {
+	date time.Time
+	id   string
+}
+
+var testKeyset = keyset[keysetRow]{
+	name:    "date:desc",
+	columns: []keysetColumn{{expr: "t.date", isTime: true}, {expr: "t.id"}},
+	desc:    true,
+	key: func(row keysetRow) []interface{} {
+		return []interface{}{row.date, row.id}
+	},
+}
+
+func keysetRows(n int) []keysetRow {
+	rows := make([]keysetRow, n)
+	for i := range rows {
+		rows[i] = keysetRow{
+			date: time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC).AddDate(0, 0, -i),
+			id:   fmt.Sprintf("00000000-0000-0000-0000-%012d", i),
+		}
+	}
+	return rows
+}
+
+func decodeTestCursor(t *testing.T, encoded *string) *models.Cursor {
+	t.Helper()
+	require.NotNil(t, encoded)
+	cursor, err := models.DecodeCursor(*encoded)
+	require.NoError(t, err)
+	return cursor
+}
+
+func TestKeyset_OrderBy(t *testing.T) {
+	assert.Equal(t, "t.date DESC, t.id DESC", testKeyset.orderBy(false))
+	assert.Equal(t, "t.date ASC, t.id ASC", testKeyset.orderBy(true))
+}
+
+func TestKeyset_Condition(t *testing.T) {
+	var args []interface{}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	row := keysetRows(1)[0]
+	after := decodeTestCursor(t, testKeyset.cursor(row, false))
+	condition, err := testKeyset.condition(after, addArg)
+	require.NoError(t, err)
+	assert.Equal(t, "(t.date, t.id) < ($1, $2)", condition)
+	assert.Equal(t, []interface{}{row.date, row.id}, args)
+
+	before := decodeTestCursor(t, testKeyset.cursor(row, true))
+	condition, err = testKeyset.condition(before, addArg)
+	require.NoError(t, err)
+	assert.Equal(t, "(t.date, t.id) > ($3, $4)", condition)
+}
+
+func TestKeyset_Condition_RejectsForeignCursors(t *testing.T) {
+	addArg := func(value interface{}) string { return "$1" }
+
+	tests := []struct {
+		name   string
+		cursor *models.Cursor
+	}{
+		{"other sort", &models.Cursor{Sort: "date:asc", Values: []string{"2025-03-01T12:00:00Z", "00000000-0000-0000-0000-000000000000"}}},
+		{"missing value", &models.Cursor{Sort: "date:desc", Values: []string{"00000000-0000-0000-0000-000000000000"}}},
+		{"bad time", &models.Cursor{Sort: "date:desc", Values: []string{"yesterday", "00000000-0000-0000-0000-000000000000"}}},
+		{"bad id", &models.Cursor{Sort: "date:desc", Values: []string{"2025-03-01T12:00:00Z", "1 OR 1=1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testKeyset.condition(tt.cursor, addArg)
+			assert.ErrorIs(t, err, models.ErrInvalidCursor)
+		})
+	}
+}
+
+func TestKeyset_Page(t *testing.T) {
+	rows := keysetRows(5)
+
+	t.Run("first page", func(t *testing.T) {
+		page, cursors := testKeyset.page(rows[:3], 2, nil, 0)
+		assert.Equal(t, rows[:2], page)
+		assert.Nil(t, cursors.Prev)
+		next := decodeTestCursor(t, cursors.Next)
+		assert.False(t, next.Before)
+		assert.Equal(t, rows[1].id, next.Values[1])
+	})
+
+	t.Run("last page after a cursor", func(t *testing.T) {
+		after := decodeTestCursor(t, testKeyset.cursor(rows[2], false))
+		page, cursors := testKeyset.page(rows[3:], 2, after, 0)
+		assert.Equal(t, rows[3:], page)
+		assert.Nil(t, cursors.Next)
+		prev := decodeTestCursor(t, cursors.Prev)
+		assert.True(t, prev.Before)
+		assert.Equal(t, rows[3].id, prev.Values[1])
+	})
+
+	t.Run("page before a cursor", func(t *testing.T) {
+		// Rows come back in reverse order when reading backwards
+		before := decodeTestCursor(t, testKeyset.cursor(rows[3], true))
+		page, cursors := testKeyset.page([]keysetRow{rows[2], rows[1], rows[0]}, 2, before, 0)
+		assert.Equal(t, rows[1:3], page)
+		assert.Equal(t, rows[2].id, decodeTestCursor(t, cursors.Next).Values[1])
+		assert.Equal(t, rows[1].id, decodeTestCursor(t, cursors.Prev).Values[1])
+	})
+
+	t.Run("page number mode", func(t *testing.T) {
+		page, cursors := testKeyset.page(rows[2:4], 2, nil, 2)
+		assert.Equal(t, rows[2:4], page)
+		assert.Nil(t, cursors.Next)
+		assert.NotNil(t, cursors.Prev)
+	})
+}
+
+func TestDecodeCursor(t *testing.T) {
+	cursor, err := models.DecodeCursor("")
+	assert.NoError(t, err)
+	assert.Nil(t, cursor)
+
+	_, err = models.DecodeCursor("not a cursor!")
+	assert.ErrorIs(t, err, models.ErrInvalidCursor)
+
+	_, err = models.DecodeCursor("e30") // {}
+	assert.ErrorIs(t, err, models.ErrInvalidCursor)
+}
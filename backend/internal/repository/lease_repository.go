@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/shopspring/decimal"
+)
+
+// leaseColumns are the columns read by scanLease
+const leaseColumns = `
+	l.id, l.car_id, l.lessor, l.contract_number, l.start_date, l.end_date,
+	l.monthly_rent, l.currency, l.contracted_mileage, l.excess_km_price,
+	l.start_mileage, l.current_mileage, l.mileage_recorded_at, l.status,
+	l.return_date, l.return_mileage, l.notes, l.created_at, l.updated_at,
+	l.created_by, c.license_plate
+`
+
+// LeaseRepository handles database operations for lease contracts
+type LeaseRepository struct {
+	db *sql.DB
+}
+
+// NewLeaseRepository creates a new lease repository
+func NewLeaseRepository(db *sql.DB) *LeaseRepository {
+	return &LeaseRepository{db: db}
+}
+
+// Create creates a new lease contract
+func (r *LeaseRepository) Create(ctx context.Context, lease *models.LeaseContract) error {
+	query := `
+		INSERT INTO lease_contracts (id, car_id, lessor, contract_number, start_date, end_date,
+		                             monthly_rent, currency, contracted_mileage, excess_km_price,
+		                             start_mileage, status, notes, created_at, updated_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		lease.ID,
+		lease.CarID,
+		lease.Lessor,
+		lease.ContractNumber,
+		lease.StartDate,
+		lease.EndDate,
+		lease.MonthlyRent.Amount,
+		lease.MonthlyRent.Currency,
+		lease.ContractedMileage,
+		lease.ExcessKmPrice,
+		lease.StartMileage,
+		lease.Status,
+		lease.Notes,
+		lease.CreatedAt,
+		lease.UpdatedAt,
+		lease.CreatedBy,
+	)
+	if err != nil {
+		return leaseWriteError("failed to create lease contract", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a lease contract by ID
+func (r *LeaseRepository) FindByID(ctx context.Context, id string) (*models.LeaseContract, error) {
+	query := `
+		SELECT ` + leaseColumns + `
+		FROM lease_contracts l
+		JOIN cars c ON l.car_id = c.id
+		WHERE l.id = $1
+	`
+
+	lease, err := scanLease(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("lease contract not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find lease contract: %w", err)
+	}
+
+	return lease, nil
+}
+
+// FindAll retrieves lease contracts matching the filters, latest first
+func (r *LeaseRepository) FindAll(ctx context.Context, filters *models.LeaseFilters) ([]*models.LeaseContract, error) {
+	var where []string
+	var args []interface{}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	where = append(where, "1=1")
+	if filters.CarID != "" {
+		where = append(where, "l.car_id = "+addArg(filters.CarID))
+	}
+	if filters.Status != "" {
+		where = append(where, "l.status = "+addArg(filters.Status))
+	}
+	if filters.Lessor != "" {
+		where = append(where, "l.lessor ILIKE "+addArg("%"+filters.Lessor+"%"))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM lease_contracts l
+		JOIN cars c ON l.car_id = c.id
+		WHERE %s
+		ORDER BY l.start_date DESC, l.id
+	`, leaseColumns, strings.Join(where, " AND "))
+
+	return r.queryLeases(ctx, query, args...)
+}
+
+// FindExpiring retrieves the active lease contracts ending on or before the
+// given date, soonest first
+func (r *LeaseRepository) FindExpiring(ctx context.Context, before time.Time) ([]*models.LeaseContract, error) {
+	query := `
+		SELECT ` + leaseColumns + `
+		FROM lease_contracts l
+		JOIN cars c ON l.car_id = c.id
+		WHERE l.status = 'active' AND l.end_date <= $1
+		ORDER BY l.end_date, l.id
+	`
+
+	return r.queryLeases(ctx, query, before)
+}
+
+// FindActiveByCar retrieves the active lease contract of a car, nil if none
+func (r *LeaseRepository) FindActiveByCar(ctx context.Context, carID string) (*models.LeaseContract, error) {
+	query := `
+		SELECT ` + leaseColumns + `
+		FROM lease_contracts l
+		JOIN cars c ON l.car_id = c.id
+		WHERE l.car_id = $1 AND l.status = 'active'
+	`
+
+	lease, err := scanLease(r.db.QueryRowContext(ctx, query, carID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find active lease contract: %w", err)
+	}
+
+	return lease, nil
+}
+
+func (r *LeaseRepository) queryLeases(ctx context.Context, query string, args ...interface{}) ([]*models.LeaseContract, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lease contracts: %w", err)
+	}
+	defer rows.Close()
+
+	leases := []*models.LeaseContract{}
+	for rows.Next() {
+		lease, err := scanLease(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan lease contract: %w", err)
+		}
+		leases = append(leases, lease)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating lease contracts: %w", err)
+	}
+
+	return leases, nil
+}
+
+// Update updates the terms of a lease contract
+func (r *LeaseRepository) Update(ctx context.Context, lease *models.LeaseContract) error {
+	query := `
+		UPDATE lease_contracts
+		SET lessor = $2, contract_number = $3, start_date = $4, end_date = $5,
+		    monthly_rent = $6, currency = $7, contracted_mileage = $8,
+		    excess_km_price = $9, start_mileage = $10, notes = $11
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		lease.ID,
+		lease.Lessor,
+		lease.ContractNumber,
+		lease.StartDate,
+		lease.EndDate,
+		lease.MonthlyRent.Amount,
+		lease.MonthlyRent.Currency,
+		lease.ContractedMileage,
+		lease.ExcessKmPrice,
+		lease.StartMileage,
+		lease.Notes,
+	)
+	if err != nil {
+		return leaseWriteError("failed to update lease contract", err)
+	}
+
+	return checkLeaseAffected(result)
+}
+
+// RecordMileage stores the latest odometer reading of a leased car
+func (r *LeaseRepository) RecordMileage(ctx context.Context, id string, mileage int, date time.Time) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE lease_contracts
+		SET current_mileage = $2, mileage_recorded_at = $3
+		WHERE id = $1
+	`, id, mileage, date)
+	if err != nil {
+		return fmt.Errorf("failed to record mileage: %w", err)
+	}
+
+	return checkLeaseAffected(result)
+}
+
+// Return closes an active lease contract and retires its car, in one
+// transaction. The return event is recorded in the lifecycle of the car,
+// unless it is nil because the car was already retired.
+func (r *LeaseRepository) Return(ctx context.Context, id string, returnDate time.Time, returnMileage int, notes *string, event *models.CarLifecycleEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var carID string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE lease_contracts
+		SET status = 'returned', return_date = $2, return_mileage = $3,
+		    current_mileage = $3, mileage_recorded_at = $2,
+		    notes = COALESCE($4, notes)
+		WHERE id = $1 AND status = 'active'
+		RETURNING car_id
+	`, id, returnDate, returnMileage, notes).Scan(&carID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("lease contract not found or already returned")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to return lease contract: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE cars SET status = $2, immobilization_reason = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = $1
+	`, carID, models.CarStatusRetired)
+	if err != nil {
+		return fmt.Errorf("failed to retire car: %w", err)
+	}
+
+	if event != nil {
+		if err := insertLifecycleEvent(ctx, tx, event); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes a lease contract
+func (r *LeaseRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM lease_contracts WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete lease contract: %w", err)
+	}
+
+	return checkLeaseAffected(result)
+}
+
+func scanLease(row rowScanner) (*models.LeaseContract, error) {
+	var lease models.LeaseContract
+	var rent decimal.Decimal
+	var currency string
+
+	err := row.Scan(
+		&lease.ID,
+		&lease.CarID,
+		&lease.Lessor,
+		&lease.ContractNumber,
+		&lease.StartDate,
+		&lease.EndDate,
+		&rent,
+		&currency,
+		&lease.ContractedMileage,
+		&lease.ExcessKmPrice,
+		&lease.StartMileage,
+		&lease.CurrentMileage,
+		&lease.MileageRecordedAt,
+		&lease.Status,
+		&lease.ReturnDate,
+		&lease.ReturnMileage,
+		&lease.Notes,
+		&lease.CreatedAt,
+		&lease.UpdatedAt,
+		&lease.CreatedBy,
+		&lease.LicensePlate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	lease.MonthlyRent = money.New(rent, currency)
+	return &lease, nil
+}
+
+func checkLeaseAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("lease contract not found")
+	}
+	return nil
+}
+
+// leaseWriteError reports the unique constraints of lease contracts as
+// conflicts instead of database failures
+func leaseWriteError(message string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "unique_lease_contract_number"):
+		return fmt.Errorf("a lease contract with this number already exists for this lessor")
+	case strings.Contains(err.Error(), "idx_lease_contracts_active_car"):
+		return fmt.Errorf("car already has an active lease contract")
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+)
+
+// LoginAttemptRepository handles database operations for login attempts
+type LoginAttemptRepository struct {
+	db *sql.DB
+}
+
+// NewLoginAttemptRepository creates a new login attempt repository
+func NewLoginAttemptRepository(db *sql.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+// Create records a login attempt
+func (r *LoginAttemptRepository) Create(ctx context.Context, attempt *models.LoginAttempt) error {
+	query := `
+		INSERT INTO login_attempts (id, email, ip_address, user_agent, succeeded, attempted_at)
+		VALUES ($1, LOWER($2), $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		attempt.ID,
+		attempt.Email,
+		attempt.IPAddress,
+		attempt.UserAgent,
+		attempt.Succeeded,
+		attempt.AttemptedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record login attempt: %w", err)
+	}
+
+	return nil
+}
+
+// CountFailuresByIP counts failed login attempts from an IP address since a given time
+func (r *LoginAttemptRepository) CountFailuresByIP(ctx context.Context, ipAddress string, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM login_attempts
+		WHERE ip_address = $1 AND NOT succeeded AND attempted_at > $2
+	`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, ipAddress, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count login attempts: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountFailuresByEmail counts failed login attempts for an email since a given
+// time and returns the time of the latest one, if any
+func (r *LoginAttemptRepository) CountFailuresByEmail(ctx context.Context, email string, since time.Time) (int, *time.Time, error) {
+	query := `
+		SELECT COUNT(*), MAX(attempted_at)
+		FROM login_attempts
+		WHERE LOWER(email) = LOWER($1) AND NOT succeeded AND attempted_at > $2
+	`
+
+	var count int
+	var lastFailure sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query, email, since).Scan(&count, &lastFailure); err != nil {
+		return 0, nil, fmt.Errorf("failed to count login attempts: %w", err)
+	}
+
+	if lastFailure.Valid {
+		return count, &lastFailure.Time, nil
+	}
+	return count, nil, nil
+}
+
+// DeleteBefore deletes login attempts older than the given time
+func (r *LoginAttemptRepository) DeleteBefore(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM login_attempts WHERE attempted_at < $1`, before)
+	if err != nil {
+		return fmt.Errorf("failed to delete login attempts: %w", err)
+	}
+
+	return nil
+}
@@ -125,8 +125,9 @@ func (r *OperatorRepository) FindByEmployeeNumber(ctx context.Context, employeeN
 	return &operator, nil
 }
 
-// FindAll retrieves operators with pagination and filters
-func (r *OperatorRepository) FindAll(ctx context.Context, filters *models.OperatorFilters) ([]models.OperatorWithCurrentCar, int, error) {
+// FindAll retrieves operators with pagination and filters. Like
+// CarRepository.FindAll, it only counts them when paginating by page number.
+func (r *OperatorRepository) FindAll(ctx context.Context, filters *models.OperatorFilters) ([]models.OperatorWithCurrentCar, int, models.PageCursors, error) {
 	// Build WHERE clause
 	where := []string{"1=1"}
 	args := []interface{}{}
@@ -151,38 +152,37 @@ func (r *OperatorRepository) FindAll(ctx context.Context, filters *models.Operat
 		args = append(args, searchPattern)
 	}
 
-	whereClause := strings.Join(where, " AND ")
-
-	// Count total records
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM car_operators o WHERE %s`, whereClause)
-	var totalCount int
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count operators: %w", err)
+	// addArg appends a query argument and returns its placeholder
+	addArg := func(value interface{}) string {
+		argCount++
+		args = append(args, value)
+		return fmt.Sprintf("$%d", argCount)
 	}
 
-	// Build ORDER BY clause
-	orderBy := "o.created_at DESC"
-	if filters.SortBy != "" {
-		direction := "ASC"
-		if filters.SortOrder == "desc" {
-			direction = "DESC"
+	// Count total records, in page number mode only
+	totalCount := 0
+	if filters.Cursor == nil {
+		countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM car_operators o WHERE %s`, strings.Join(where, " AND "))
+		if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+			return nil, 0, models.PageCursors{}, fmt.Errorf("failed to count operators: %w", err)
 		}
-		switch filters.SortBy {
-		case "firstName":
-			orderBy = fmt.Sprintf("o.first_name %s", direction)
-		case "lastName":
-			orderBy = fmt.Sprintf("o.last_name %s", direction)
-		case "employeeNumber":
-			orderBy = fmt.Sprintf("o.employee_number %s", direction)
-		case "department":
-			orderBy = fmt.Sprintf("o.department %s", direction)
-		case "createdAt":
-			orderBy = fmt.Sprintf("o.created_at %s", direction)
+	}
+
+	order := operatorKeyset(filters.SortBy, filters.SortOrder)
+	backward := filters.Cursor != nil && filters.Cursor.Before
+	offset := 0
+	if filters.Cursor != nil {
+		condition, err := order.condition(filters.Cursor, addArg)
+		if err != nil {
+			return nil, 0, models.PageCursors{}, err
 		}
+		where = append(where, condition)
+	} else {
+		offset = (filters.Page - 1) * filters.Limit
 	}
 
-	// Query with pagination and current assignment
+	// Query with pagination and current assignment. One more row than asked
+	// tells whether there is a next page.
 	query := fmt.Sprintf(`
 		SELECT o.id, o.employee_number, o.first_name, o.last_name, 
 		       o.email, o.phone, o.department, o.is_active, 
@@ -193,15 +193,12 @@ func (r *OperatorRepository) FindAll(ctx context.Context, filters *models.Operat
 		LEFT JOIN cars c ON a.car_id = c.id
 		WHERE %s
 		ORDER BY %s
-		LIMIT $%d OFFSET $%d
-	`, whereClause, orderBy, argCount+1, argCount+2)
-
-	offset := (filters.Page - 1) * filters.Limit
-	args = append(args, filters.Limit, offset)
+		LIMIT %s OFFSET %s
+	`, strings.Join(where, " AND "), order.orderBy(backward), addArg(filters.Limit+1), addArg(offset))
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to query operators: %w", err)
+		return nil, 0, models.PageCursors{}, fmt.Errorf("failed to query operators: %w", err)
 	}
 	defer rows.Close()
 
@@ -230,7 +227,7 @@ func (r *OperatorRepository) FindAll(ctx context.Context, filters *models.Operat
 			&since,
 		)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan operator: %w", err)
+			return nil, 0, models.PageCursors{}, fmt.Errorf("failed to scan operator: %w", err)
 		}
 
 		if carID.Valid {
@@ -247,10 +244,52 @@ func (r *OperatorRepository) FindAll(ctx context.Context, filters *models.Operat
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating operators: %w", err)
+		return nil, 0, models.PageCursors{}, fmt.Errorf("error iterating operators: %w", err)
 	}
 
-	return operators, totalCount, nil
+	operators, cursors := order.page(operators, filters.Limit, filters.Cursor, offset)
+	return operators, totalCount, cursors, nil
+}
+
+// operatorKeyset returns the ordering of the operator list for a sort field
+// and order. Operators without a department sort as an empty one.
+func operatorKeyset(sortBy, sortOrder string) keyset[models.OperatorWithCurrentCar] {
+	desc := sortOrder == "desc"
+	columns := map[string][]keysetColumn{
+		"firstName":      {{expr: "o.first_name"}},
+		"lastName":       {{expr: "o.last_name"}},
+		"employeeNumber": {{expr: "o.employee_number"}},
+		"department":     {{expr: "COALESCE(o.department, '')"}},
+		"createdAt":      {{expr: "o.created_at", isTime: true}},
+	}
+	if _, ok := columns[sortBy]; !ok {
+		sortBy, desc = "createdAt", true
+	}
+
+	name := sortBy + ":asc"
+	if desc {
+		name = sortBy + ":desc"
+	}
+
+	return keyset[models.OperatorWithCurrentCar]{
+		name:    name,
+		columns: append(columns[sortBy], keysetColumn{expr: "o.id"}),
+		desc:    desc,
+		key: func(operator models.OperatorWithCurrentCar) []interface{} {
+			switch sortBy {
+			case "firstName":
+				return []interface{}{operator.FirstName, operator.ID}
+			case "lastName":
+				return []interface{}{operator.LastName, operator.ID}
+			case "employeeNumber":
+				return []interface{}{operator.EmployeeNumber, operator.ID}
+			case "department":
+				return []interface{}{operator.Department, operator.ID}
+			default:
+				return []interface{}{operator.CreatedAt, operator.ID}
+			}
+		},
+	}
 }
 
 // Update updates an operator's information
@@ -450,20 +489,91 @@ func (r *OperatorRepository) FindActiveAssignmentByOperator(ctx context.Context,
 
 // FindAssignmentHistory retrieves assignment history based on filters
 func (r *OperatorRepository) FindAssignmentHistory(ctx context.Context, filters *models.AssignmentFilters) ([]models.CarOperatorAssignment, error) {
+	var args []interface{}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, car_id, operator_id, start_date, end_date, 
+		       notes, created_at, created_by
+		FROM car_operator_assignments
+		WHERE %s
+		ORDER BY start_date DESC
+	`, strings.Join(assignmentConditions(filters, addArg), " AND "))
+
+	return r.queryAssignments(ctx, query, args...)
+}
+
+// FindAssignmentHistoryPage retrieves a page of assignment history, most
+// recent first. With filters.Cursor it reads the page next to it; otherwise
+// it reads the page numbered filters.Page and also returns the total count.
+func (r *OperatorRepository) FindAssignmentHistoryPage(ctx context.Context, filters *models.AssignmentFilters) ([]models.CarOperatorAssignment, int, models.PageCursors, error) {
+	var args []interface{}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	where := assignmentConditions(filters, addArg)
+
+	totalCount := 0
+	offset := 0
+	if filters.Cursor != nil {
+		condition, err := assignmentKeyset.condition(filters.Cursor, addArg)
+		if err != nil {
+			return nil, 0, models.PageCursors{}, err
+		}
+		where = append(where, condition)
+	} else {
+		countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM car_operator_assignments WHERE %s`, strings.Join(where, " AND "))
+		if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+			return nil, 0, models.PageCursors{}, fmt.Errorf("failed to count assignments: %w", err)
+		}
+		offset = (filters.Page - 1) * filters.Limit
+	}
+
+	// One more row than asked tells whether there is a next page
+	query := fmt.Sprintf(`
+		SELECT id, car_id, operator_id, start_date, end_date, 
+		       notes, created_at, created_by
+		FROM car_operator_assignments
+		WHERE %s
+		ORDER BY %s
+		LIMIT %s OFFSET %s
+	`, strings.Join(where, " AND "), assignmentKeyset.orderBy(filters.Cursor != nil && filters.Cursor.Before), addArg(filters.Limit+1), addArg(offset))
+
+	assignments, err := r.queryAssignments(ctx, query, args...)
+	if err != nil {
+		return nil, 0, models.PageCursors{}, err
+	}
+
+	assignments, cursors := assignmentKeyset.page(assignments, filters.Limit, filters.Cursor, offset)
+	return assignments, totalCount, cursors, nil
+}
+
+// assignmentKeyset orders assignment history, most recent first
+var assignmentKeyset = keyset[models.CarOperatorAssignment]{
+	name:    "startDate:desc",
+	columns: []keysetColumn{{expr: "start_date", isTime: true}, {expr: "id"}},
+	desc:    true,
+	key: func(assignment models.CarOperatorAssignment) []interface{} {
+		return []interface{}{assignment.StartDate, assignment.ID}
+	},
+}
+
+// assignmentConditions returns the WHERE conditions for the assignment
+// filters, adding their values as query arguments
+func assignmentConditions(filters *models.AssignmentFilters, addArg func(interface{}) string) []string {
 	where := []string{"1=1"}
-	args := []interface{}{}
-	argCount := 0
 
 	if filters.CarID != nil {
-		argCount++
-		where = append(where, fmt.Sprintf("car_id = $%d", argCount))
-		args = append(args, *filters.CarID)
+		where = append(where, "car_id = "+addArg(*filters.CarID))
 	}
 
 	if filters.OperatorID != nil {
-		argCount++
-		where = append(where, fmt.Sprintf("operator_id = $%d", argCount))
-		args = append(args, *filters.OperatorID)
+		where = append(where, "operator_id = "+addArg(*filters.OperatorID))
 	}
 
 	if filters.Active != nil && *filters.Active {
@@ -473,27 +583,18 @@ func (r *OperatorRepository) FindAssignmentHistory(ctx context.Context, filters
 	}
 
 	if filters.StartDate != nil {
-		argCount++
-		where = append(where, fmt.Sprintf("start_date >= $%d", argCount))
-		args = append(args, *filters.StartDate)
+		where = append(where, "start_date >= "+addArg(*filters.StartDate))
 	}
 
 	if filters.EndDate != nil {
-		argCount++
-		where = append(where, fmt.Sprintf("(end_date IS NULL OR end_date <= $%d)", argCount))
-		args = append(args, *filters.EndDate)
+		where = append(where, "(end_date IS NULL OR end_date <= "+addArg(*filters.EndDate)+")")
 	}
 
-	whereClause := strings.Join(where, " AND ")
-
-	query := fmt.Sprintf(`
-		SELECT id, car_id, operator_id, start_date, end_date, 
-		       notes, created_at, created_by
-		FROM car_operator_assignments
-		WHERE %s
-		ORDER BY start_date DESC
-	`, whereClause)
+	return where
+}
 
+// queryAssignments runs a query selecting assignment columns and scans the rows
+func (r *OperatorRepository) queryAssignments(ctx context.Context, query string, args ...interface{}) ([]models.CarOperatorAssignment, error) {
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query assignment history: %w", err)
@@ -586,3 +687,55 @@ func (r *OperatorRepository) CarHasActiveAssignment(ctx context.Context, carID s
 
 	return exists, nil
 }
+
+// FindCarOfOperatorAt retrieves the car assigned to an operator on the
+// given date, nil if they had none
+func (r *OperatorRepository) FindCarOfOperatorAt(ctx context.Context, operatorID string, at time.Time) (*string, error) {
+	var carID string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT car_id FROM car_operator_assignments
+		WHERE operator_id = $1 AND start_date <= $2::date AND (end_date IS NULL OR end_date >= $2::date)
+		ORDER BY start_date DESC
+		LIMIT 1
+	`, operatorID, at).Scan(&carID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find assignment: %w", err)
+	}
+
+	return &carID, nil
+}
+
+// FindOperatorsAt retrieves the operators assigned to a car on the given
+// date, latest assignment first. Assignments are kept by day, so the day a
+// car changes hands has two operators.
+func (r *OperatorRepository) FindOperatorsAt(ctx context.Context, carID string, at time.Time) ([]string, error) {
+	query := `
+		SELECT operator_id FROM car_operator_assignments
+		WHERE car_id = $1 AND start_date <= $2::date AND (end_date IS NULL OR end_date >= $2::date)
+		ORDER BY start_date DESC, created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, carID, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var operatorIDs []string
+	for rows.Next() {
+		var operatorID string
+		if err := rows.Scan(&operatorID); err != nil {
+			return nil, fmt.Errorf("failed to scan assignment: %w", err)
+		}
+		operatorIDs = append(operatorIDs, operatorID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating assignments: %w", err)
+	}
+
+	return operatorIDs, nil
+}
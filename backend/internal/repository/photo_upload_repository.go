@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+)
+
+// PhotoUploadRepository handles database operations for resumable photo uploads
+type PhotoUploadRepository struct {
+	db *sql.DB
+}
+
+// NewPhotoUploadRepository creates a new photo upload repository
+func NewPhotoUploadRepository(db *sql.DB) *PhotoUploadRepository {
+	return &PhotoUploadRepository{db: db}
+}
+
+// Create creates a new upload session with no data received yet
+func (r *PhotoUploadRepository) Create(ctx context.Context, upload *models.PhotoUpload) error {
+	query := `
+		INSERT INTO photo_uploads (id, accident_id, filename, description, upload_length,
+		                           upload_offset, created_at, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, 0, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		upload.ID,
+		upload.AccidentID,
+		upload.Filename,
+		upload.Description,
+		upload.UploadLength,
+		upload.CreatedAt,
+		upload.ExpiresAt,
+		upload.CreatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("échec de la création de l'upload: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves an upload session that has not expired
+func (r *PhotoUploadRepository) FindByID(ctx context.Context, id string) (*models.PhotoUpload, error) {
+	query := `
+		SELECT id, accident_id, filename, description, upload_length, upload_offset,
+		       created_at, expires_at, created_by
+		FROM photo_uploads
+		WHERE id = $1 AND expires_at > CURRENT_TIMESTAMP
+	`
+
+	upload := &models.PhotoUpload{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&upload.ID,
+		&upload.AccidentID,
+		&upload.Filename,
+		&upload.Description,
+		&upload.UploadLength,
+		&upload.UploadOffset,
+		&upload.CreatedAt,
+		&upload.ExpiresAt,
+		&upload.CreatedBy,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("upload non trouvé")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("échec de la récupération de l'upload: %w", err)
+	}
+
+	return upload, nil
+}
+
+// AppendChunk appends a chunk at the given offset and returns the new offset.
+// The offset condition makes concurrent or replayed chunks fail instead of
+// corrupting the data.
+func (r *PhotoUploadRepository) AppendChunk(ctx context.Context, id string, offset int64, chunk []byte) (int64, error) {
+	query := `
+		UPDATE photo_uploads
+		SET data = data || $3, upload_offset = upload_offset + $4
+		WHERE id = $1 AND upload_offset = $2 AND upload_offset + $4 <= upload_length
+		  AND expires_at > CURRENT_TIMESTAMP
+		RETURNING upload_offset
+	`
+
+	var newOffset int64
+	err := r.db.QueryRowContext(ctx, query, id, offset, chunk, int64(len(chunk))).Scan(&newOffset)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("décalage d'upload invalide")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("échec de l'ajout du fragment: %w", err)
+	}
+
+	return newOffset, nil
+}
+
+// GetData returns the bytes received so far for an upload
+func (r *PhotoUploadRepository) GetData(ctx context.Context, id string) ([]byte, error) {
+	var data []byte
+	err := r.db.QueryRowContext(ctx, `SELECT data FROM photo_uploads WHERE id = $1`, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("upload non trouvé")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("échec de la récupération des données de l'upload: %w", err)
+	}
+
+	return data, nil
+}
+
+// Delete removes an upload session
+func (r *PhotoUploadRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM photo_uploads WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("échec de la suppression de l'upload: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("échec de la vérification de la suppression: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("upload non trouvé")
+	}
+
+	return nil
+}
+
+// DeleteExpired removes abandoned upload sessions
+func (r *PhotoUploadRepository) DeleteExpired(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM photo_uploads WHERE expires_at <= CURRENT_TIMESTAMP`)
+	if err != nil {
+		return fmt.Errorf("échec de la suppression des uploads expirés: %w", err)
+	}
+
+	return nil
+}
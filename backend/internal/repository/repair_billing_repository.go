@@ -0,0 +1,370 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/shopspring/decimal"
+)
+
+// invoiceLineTotalSQL computes a line total including VAT with the same
+// per-line rounding as models.InvoiceLine.ComputeTotals
+const invoiceLineTotalSQL = `ROUND(quantity * unit_price, 2) + ROUND(ROUND(quantity * unit_price, 2) * vat_rate / 100, 2)`
+
+// RepairBillingRepository handles database operations for repair quotes and invoice lines
+type RepairBillingRepository struct {
+	db *sql.DB
+}
+
+// NewRepairBillingRepository creates a new repair billing repository
+func NewRepairBillingRepository(db *sql.DB) *RepairBillingRepository {
+	return &RepairBillingRepository{db: db}
+}
+
+// CreateQuote creates a new quote
+func (r *RepairBillingRepository) CreateQuote(ctx context.Context, quote *models.RepairQuote) error {
+	query := `
+		INSERT INTO repair_quotes (id, repair_id, garage_id, quote_number, quote_date, amount,
+		                           currency, status, notes, created_at, updated_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		quote.ID,
+		quote.RepairID,
+		quote.GarageID,
+		quote.QuoteNumber,
+		quote.QuoteDate,
+		quote.Amount.Amount,
+		quote.Amount.Currency,
+		quote.Status,
+		quote.Notes,
+		quote.CreatedAt,
+		quote.UpdatedAt,
+		quote.CreatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("échec de la création du devis: %w", err)
+	}
+
+	return nil
+}
+
+// FindQuoteByID retrieves a quote by ID
+func (r *RepairBillingRepository) FindQuoteByID(ctx context.Context, id string) (*models.RepairQuote, error) {
+	query := `
+		SELECT q.id, q.repair_id, q.garage_id, q.quote_number, q.quote_date, q.amount,
+		       q.currency, q.status, q.notes, q.created_at, q.updated_at, q.created_by,
+		       g.id, g.name
+		FROM repair_quotes q
+		LEFT JOIN garages g ON q.garage_id = g.id
+		WHERE q.id = $1
+	`
+
+	quote, err := scanQuote(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("devis non trouvé")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("échec de la recherche du devis: %w", err)
+	}
+
+	return quote, nil
+}
+
+// FindQuotesByRepairID retrieves all quotes of a repair
+func (r *RepairBillingRepository) FindQuotesByRepairID(ctx context.Context, repairID string) ([]*models.RepairQuote, error) {
+	query := `
+		SELECT q.id, q.repair_id, q.garage_id, q.quote_number, q.quote_date, q.amount,
+		       q.currency, q.status, q.notes, q.created_at, q.updated_at, q.created_by,
+		       g.id, g.name
+		FROM repair_quotes q
+		LEFT JOIN garages g ON q.garage_id = g.id
+		WHERE q.repair_id = $1
+		ORDER BY q.quote_date DESC, q.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, repairID)
+	if err != nil {
+		return nil, fmt.Errorf("échec de la recherche des devis: %w", err)
+	}
+	defer rows.Close()
+
+	quotes := []*models.RepairQuote{}
+	for rows.Next() {
+		quote, err := scanQuote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("échec du scan du devis: %w", err)
+		}
+		quotes = append(quotes, quote)
+	}
+
+	return quotes, rows.Err()
+}
+
+// FindAcceptedQuote retrieves the accepted quote of a repair, or nil if there is none
+func (r *RepairBillingRepository) FindAcceptedQuote(ctx context.Context, repairID string) (*models.RepairQuote, error) {
+	query := `
+		SELECT q.id, q.repair_id, q.garage_id, q.quote_number, q.quote_date, q.amount,
+		       q.currency, q.status, q.notes, q.created_at, q.updated_at, q.created_by,
+		       g.id, g.name
+		FROM repair_quotes q
+		LEFT JOIN garages g ON q.garage_id = g.id
+		WHERE q.repair_id = $1 AND q.status = 'accepted'
+	`
+
+	quote, err := scanQuote(r.db.QueryRowContext(ctx, query, repairID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("échec de la recherche du devis accepté: %w", err)
+	}
+
+	return quote, nil
+}
+
+// UpdateQuoteStatus updates the status of a quote. Accepting a quote rejects
+// the previously accepted quote of the same repair.
+func (r *RepairBillingRepository) UpdateQuoteStatus(ctx context.Context, id string, status models.QuoteStatus) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("échec du démarrage de la transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if status == models.QuoteStatusAccepted {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE repair_quotes
+			SET status = 'rejected', updated_at = CURRENT_TIMESTAMP
+			WHERE status = 'accepted' AND id <> $1
+			  AND repair_id = (SELECT repair_id FROM repair_quotes WHERE id = $1)
+		`, id)
+		if err != nil {
+			return fmt.Errorf("échec du rejet du devis précédent: %w", err)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE repair_quotes
+		SET status = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id, status)
+	if err != nil {
+		return fmt.Errorf("échec de la mise à jour du statut du devis: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("échec de la vérification des lignes affectées: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("devis non trouvé")
+	}
+
+	return tx.Commit()
+}
+
+// DeleteQuote deletes a quote
+func (r *RepairBillingRepository) DeleteQuote(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM repair_quotes WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("échec de la suppression du devis: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("échec de la vérification des lignes affectées: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("devis non trouvé")
+	}
+
+	return nil
+}
+
+// CreateInvoiceLine creates a new invoice line
+func (r *RepairBillingRepository) CreateInvoiceLine(ctx context.Context, line *models.InvoiceLine) error {
+	query := `
+		INSERT INTO repair_invoice_lines (id, repair_id, description, quantity, unit_price,
+		                                  vat_rate, category, currency, created_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		line.ID,
+		line.RepairID,
+		line.Description,
+		line.Quantity,
+		line.UnitPrice,
+		line.VATRate,
+		line.Category,
+		line.Currency,
+		line.CreatedAt,
+		line.CreatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("échec de la création de la ligne de facture: %w", err)
+	}
+
+	return nil
+}
+
+// FindInvoiceLines retrieves the invoice lines of a repair in insertion order
+func (r *RepairBillingRepository) FindInvoiceLines(ctx context.Context, repairID string) ([]*models.InvoiceLine, error) {
+	query := `
+		SELECT id, repair_id, description, quantity, unit_price, vat_rate, category,
+		       currency, created_at, created_by
+		FROM repair_invoice_lines
+		WHERE repair_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, repairID)
+	if err != nil {
+		return nil, fmt.Errorf("échec de la recherche des lignes de facture: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []*models.InvoiceLine{}
+	for rows.Next() {
+		var line models.InvoiceLine
+		err := rows.Scan(
+			&line.ID,
+			&line.RepairID,
+			&line.Description,
+			&line.Quantity,
+			&line.UnitPrice,
+			&line.VATRate,
+			&line.Category,
+			&line.Currency,
+			&line.CreatedAt,
+			&line.CreatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("échec du scan de la ligne de facture: %w", err)
+		}
+		lines = append(lines, &line)
+	}
+
+	return lines, rows.Err()
+}
+
+// DeleteInvoiceLine deletes an invoice line of a repair
+func (r *RepairBillingRepository) DeleteInvoiceLine(ctx context.Context, repairID, lineID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM repair_invoice_lines WHERE id = $1 AND repair_id = $2`, lineID, repairID)
+	if err != nil {
+		return fmt.Errorf("échec de la suppression de la ligne de facture: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("échec de la vérification des lignes affectées: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("ligne de facture non trouvée")
+	}
+
+	return nil
+}
+
+// FindOverQuote retrieves repairs whose invoice total exceeds the accepted
+// quote by more than thresholdPercent. Totals are computed in NUMERIC and
+// only compared within the same currency.
+func (r *RepairBillingRepository) FindOverQuote(ctx context.Context, thresholdPercent decimal.Decimal) ([]*models.OverQuoteRepair, error) {
+	query := `
+		WITH invoice_totals AS (
+			SELECT repair_id, currency, SUM(` + invoiceLineTotalSQL + `) AS total_incl_vat
+			FROM repair_invoice_lines
+			GROUP BY repair_id, currency
+		), comparison AS (
+			SELECT r.id, r.car_id, r.garage_id, r.description, q.id AS quote_id,
+			       q.amount AS quote_amount, q.currency, t.total_incl_vat,
+			       CASE WHEN q.amount = 0 THEN NULL
+			            ELSE ROUND((t.total_incl_vat - q.amount) * 100 / q.amount, 2)
+			       END AS difference_rate
+			FROM repairs r
+			JOIN repair_quotes q ON q.repair_id = r.id AND q.status = 'accepted'
+			JOIN invoice_totals t ON t.repair_id = r.id AND t.currency = q.currency
+		)
+		SELECT id, car_id, garage_id, description, quote_id, quote_amount, currency, total_incl_vat,
+		       difference_rate
+		FROM comparison
+		WHERE (difference_rate IS NULL AND total_incl_vat > 0) OR difference_rate > $1
+		ORDER BY difference_rate DESC NULLS FIRST
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, thresholdPercent)
+	if err != nil {
+		return nil, fmt.Errorf("échec de la recherche des dépassements de devis: %w", err)
+	}
+	defer rows.Close()
+
+	repairs := []*models.OverQuoteRepair{}
+	for rows.Next() {
+		var repair models.OverQuoteRepair
+		var quoteAmount, invoiceAmount decimal.Decimal
+		var currency string
+		var differenceRate decimal.NullDecimal
+		err := rows.Scan(
+			&repair.RepairID,
+			&repair.CarID,
+			&repair.GarageID,
+			&repair.Description,
+			&repair.QuoteID,
+			&quoteAmount,
+			&currency,
+			&invoiceAmount,
+			&differenceRate,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("échec du scan du dépassement de devis: %w", err)
+		}
+		repair.QuoteAmount = money.New(quoteAmount, currency)
+		repair.InvoiceAmount = money.New(invoiceAmount, currency)
+		repair.DifferenceRate = differenceRate.Decimal
+		repairs = append(repairs, &repair)
+	}
+
+	return repairs, rows.Err()
+}
+
+func scanQuote(row rowScanner) (*models.RepairQuote, error) {
+	var quote models.RepairQuote
+	var amount decimal.Decimal
+	var currency string
+	var garageID, garageName sql.NullString
+	err := row.Scan(
+		&quote.ID,
+		&quote.RepairID,
+		&quote.GarageID,
+		&quote.QuoteNumber,
+		&quote.QuoteDate,
+		&amount,
+		&currency,
+		&quote.Status,
+		&quote.Notes,
+		&quote.CreatedAt,
+		&quote.UpdatedAt,
+		&quote.CreatedBy,
+		&garageID,
+		&garageName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	quote.Amount = money.New(amount, currency)
+	if garageID.Valid {
+		quote.Garage = &models.Garage{ID: garageID.String, Name: garageName.String}
+	}
+
+	return &quote, nil
+}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/shopspring/decimal"
 )
 
 // RepairRepository handles database operations for repairs
@@ -24,11 +26,13 @@ func NewRepairRepository(db *sql.DB) *RepairRepository {
 func (r *RepairRepository) Create(ctx context.Context, repair *models.Repair) error {
 	query := `
 		INSERT INTO repairs (id, car_id, accident_id, garage_id, repair_type, description, 
-		                     start_date, end_date, cost, status, invoice_number, notes, 
-		                     created_at, updated_at, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		                     start_date, end_date, cost, cost_vat_rate, cost_currency, status, 
+		                     invoice_number, notes, created_at, updated_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 	`
 
+	cost, costVATRate, costCurrency := costColumns(repair.Cost)
+
 	_, err := r.db.ExecContext(
 		ctx,
 		query,
@@ -40,7 +44,9 @@ func (r *RepairRepository) Create(ctx context.Context, repair *models.Repair) er
 		repair.Description,
 		repair.StartDate,
 		repair.EndDate,
-		repair.Cost,
+		cost,
+		costVATRate,
+		costCurrency,
 		repair.Status,
 		repair.InvoiceNumber,
 		repair.Notes,
@@ -60,31 +66,13 @@ func (r *RepairRepository) Create(ctx context.Context, repair *models.Repair) er
 func (r *RepairRepository) FindByID(ctx context.Context, id string) (*models.Repair, error) {
 	query := `
 		SELECT id, car_id, accident_id, garage_id, repair_type, description, 
-		       start_date, end_date, cost, status, invoice_number, notes, 
-		       created_at, updated_at, created_by
+		       start_date, end_date, cost, cost_vat_rate, cost_currency, status, invoice_number, 
+		       notes, created_at, updated_at, created_by
 		FROM repairs
 		WHERE id = $1
 	`
 
-	var repair models.Repair
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&repair.ID,
-		&repair.CarID,
-		&repair.AccidentID,
-		&repair.GarageID,
-		&repair.RepairType,
-		&repair.Description,
-		&repair.StartDate,
-		&repair.EndDate,
-		&repair.Cost,
-		&repair.Status,
-		&repair.InvoiceNumber,
-		&repair.Notes,
-		&repair.CreatedAt,
-		&repair.UpdatedAt,
-		&repair.CreatedBy,
-	)
-
+	repair, err := scanRepair(r.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("réparation non trouvée")
 	}
@@ -92,75 +80,117 @@ func (r *RepairRepository) FindByID(ctx context.Context, id string) (*models.Rep
 		return nil, fmt.Errorf("échec de la recherche de la réparation: %w", err)
 	}
 
-	return &repair, nil
+	return repair, nil
 }
 
 // FindAll retrieves all repairs with optional filters
 func (r *RepairRepository) FindAll(ctx context.Context, filters map[string]interface{}) ([]*models.Repair, error) {
+	var args []interface{}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
 	query := `
 		SELECT id, car_id, accident_id, garage_id, repair_type, description, 
-		       start_date, end_date, cost, status, invoice_number, notes, 
-		       created_at, updated_at, created_by
+		       start_date, end_date, cost, cost_vat_rate, cost_currency, status, invoice_number, 
+		       notes, created_at, updated_at, created_by
 		FROM repairs
-		WHERE 1=1
-	`
+		WHERE ` + strings.Join(repairConditions(filters, addArg), " AND ")
 
-	var args []interface{}
-	argCount := 1
+	// Add ordering
+	query += " ORDER BY start_date DESC"
 
-	// Apply filters
-	if carID, ok := filters["car_id"].(string); ok && carID != "" {
-		query += fmt.Sprintf(" AND car_id = $%d", argCount)
-		args = append(args, carID)
-		argCount++
+	// Add pagination
+	if limit, ok := filters["limit"].(int); ok && limit > 0 {
+		query += " LIMIT " + addArg(limit)
 	}
 
-	if accidentID, ok := filters["accident_id"].(string); ok && accidentID != "" {
-		query += fmt.Sprintf(" AND accident_id = $%d", argCount)
-		args = append(args, accidentID)
-		argCount++
+	if offset, ok := filters["offset"].(int); ok && offset > 0 {
+		query += " OFFSET " + addArg(offset)
 	}
 
-	if garageID, ok := filters["garage_id"].(string); ok && garageID != "" {
-		query += fmt.Sprintf(" AND garage_id = $%d", argCount)
-		args = append(args, garageID)
-		argCount++
-	}
+	return r.queryRepairs(ctx, query, args...)
+}
 
-	if repairType, ok := filters["repair_type"].(string); ok && repairType != "" {
-		query += fmt.Sprintf(" AND repair_type = $%d", argCount)
-		args = append(args, repairType)
-		argCount++
+// FindPage retrieves a page of repairs with optional filters, most recent
+// first. With a cursor it reads the page next to it; otherwise it reads the
+// page numbered page and also returns the total count.
+func (r *RepairRepository) FindPage(ctx context.Context, filters map[string]interface{}, cursor *models.Cursor, page, limit int) ([]*models.Repair, int, models.PageCursors, error) {
+	var args []interface{}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
 	}
 
-	if status, ok := filters["status"].(string); ok && status != "" {
-		query += fmt.Sprintf(" AND status = $%d", argCount)
-		args = append(args, status)
-		argCount++
+	where := repairConditions(filters, addArg)
+	offset := 0
+	totalCount := 0
+	if cursor != nil {
+		condition, err := repairKeyset.condition(cursor, addArg)
+		if err != nil {
+			return nil, 0, models.PageCursors{}, err
+		}
+		where = append(where, condition)
+	} else {
+		offset = (page - 1) * limit
+		count, err := r.Count(ctx, filters)
+		if err != nil {
+			return nil, 0, models.PageCursors{}, err
+		}
+		totalCount = count
 	}
 
-	if search, ok := filters["search"].(string); ok && search != "" {
-		searchPattern := "%" + strings.ToLower(search) + "%"
-		query += fmt.Sprintf(" AND LOWER(description) LIKE $%d", argCount)
-		args = append(args, searchPattern)
-		argCount++
+	// One more row than asked tells whether there is a next page
+	query := fmt.Sprintf(`
+		SELECT id, car_id, accident_id, garage_id, repair_type, description, 
+		       start_date, end_date, cost, cost_vat_rate, cost_currency, status, invoice_number, 
+		       notes, created_at, updated_at, created_by
+		FROM repairs
+		WHERE %s
+		ORDER BY %s
+		LIMIT %s OFFSET %s
+	`, strings.Join(where, " AND "), repairKeyset.orderBy(cursor != nil && cursor.Before), addArg(limit+1), addArg(offset))
+
+	repairs, err := r.queryRepairs(ctx, query, args...)
+	if err != nil {
+		return nil, 0, models.PageCursors{}, err
 	}
 
-	// Add ordering
-	query += " ORDER BY start_date DESC"
+	repairs, cursors := repairKeyset.page(repairs, limit, cursor, offset)
+	return repairs, totalCount, cursors, nil
+}
 
-	// Add pagination
-	if limit, ok := filters["limit"].(int); ok && limit > 0 {
-		query += fmt.Sprintf(" LIMIT $%d", argCount)
-		args = append(args, limit)
-		argCount++
+// repairKeyset orders repair lists, most recent start first
+var repairKeyset = keyset[*models.Repair]{
+	name:    "startDate:desc",
+	columns: []keysetColumn{{expr: "start_date", isTime: true}, {expr: "id"}},
+	desc:    true,
+	key: func(repair *models.Repair) []interface{} {
+		return []interface{}{repair.StartDate, repair.ID}
+	},
+}
+
+// repairConditions returns the WHERE conditions for the repair list filters,
+// adding their values as query arguments
+func repairConditions(filters map[string]interface{}, addArg func(interface{}) string) []string {
+	where := []string{"1=1"}
+
+	for _, column := range []string{"car_id", "accident_id", "garage_id", "repair_type", "status"} {
+		if value, ok := filters[column].(string); ok && value != "" {
+			where = append(where, column+" = "+addArg(value))
+		}
 	}
 
-	if offset, ok := filters["offset"].(int); ok && offset > 0 {
-		query += fmt.Sprintf(" OFFSET $%d", argCount)
-		args = append(args, offset)
+	if search, ok := filters["search"].(string); ok && search != "" {
+		where = append(where, "LOWER(description) LIKE "+addArg("%"+strings.ToLower(search)+"%"))
 	}
 
+	return where
+}
+
+// queryRepairs runs a query selecting repair columns and scans the rows
+func (r *RepairRepository) queryRepairs(ctx context.Context, query string, args ...interface{}) ([]*models.Repair, error) {
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("échec de la recherche des réparations: %w", err)
@@ -169,28 +199,11 @@ func (r *RepairRepository) FindAll(ctx context.Context, filters map[string]inter
 
 	var repairs []*models.Repair
 	for rows.Next() {
-		var repair models.Repair
-		err := rows.Scan(
-			&repair.ID,
-			&repair.CarID,
-			&repair.AccidentID,
-			&repair.GarageID,
-			&repair.RepairType,
-			&repair.Description,
-			&repair.StartDate,
-			&repair.EndDate,
-			&repair.Cost,
-			&repair.Status,
-			&repair.InvoiceNumber,
-			&repair.Notes,
-			&repair.CreatedAt,
-			&repair.UpdatedAt,
-			&repair.CreatedBy,
-		)
+		repair, err := scanRepair(rows)
 		if err != nil {
 			return nil, fmt.Errorf("échec du scan de la réparation: %w", err)
 		}
-		repairs = append(repairs, &repair)
+		repairs = append(repairs, repair)
 	}
 
 	return repairs, nil
@@ -200,8 +213,8 @@ func (r *RepairRepository) FindAll(ctx context.Context, filters map[string]inter
 func (r *RepairRepository) FindByCarID(ctx context.Context, carID string) ([]*models.Repair, error) {
 	query := `
 		SELECT id, car_id, accident_id, garage_id, repair_type, description, 
-		       start_date, end_date, cost, status, invoice_number, notes, 
-		       created_at, updated_at, created_by
+		       start_date, end_date, cost, cost_vat_rate, cost_currency, status, invoice_number, 
+		       notes, created_at, updated_at, created_by
 		FROM repairs
 		WHERE car_id = $1
 		ORDER BY start_date DESC
@@ -215,28 +228,11 @@ func (r *RepairRepository) FindByCarID(ctx context.Context, carID string) ([]*mo
 
 	var repairs []*models.Repair
 	for rows.Next() {
-		var repair models.Repair
-		err := rows.Scan(
-			&repair.ID,
-			&repair.CarID,
-			&repair.AccidentID,
-			&repair.GarageID,
-			&repair.RepairType,
-			&repair.Description,
-			&repair.StartDate,
-			&repair.EndDate,
-			&repair.Cost,
-			&repair.Status,
-			&repair.InvoiceNumber,
-			&repair.Notes,
-			&repair.CreatedAt,
-			&repair.UpdatedAt,
-			&repair.CreatedBy,
-		)
+		repair, err := scanRepair(rows)
 		if err != nil {
 			return nil, fmt.Errorf("échec du scan de la réparation: %w", err)
 		}
-		repairs = append(repairs, &repair)
+		repairs = append(repairs, repair)
 	}
 
 	return repairs, nil
@@ -246,8 +242,8 @@ func (r *RepairRepository) FindByCarID(ctx context.Context, carID string) ([]*mo
 func (r *RepairRepository) FindByAccidentID(ctx context.Context, accidentID string) ([]*models.Repair, error) {
 	query := `
 		SELECT id, car_id, accident_id, garage_id, repair_type, description, 
-		       start_date, end_date, cost, status, invoice_number, notes, 
-		       created_at, updated_at, created_by
+		       start_date, end_date, cost, cost_vat_rate, cost_currency, status, invoice_number, 
+		       notes, created_at, updated_at, created_by
 		FROM repairs
 		WHERE accident_id = $1
 		ORDER BY start_date DESC
@@ -261,28 +257,11 @@ func (r *RepairRepository) FindByAccidentID(ctx context.Context, accidentID stri
 
 	var repairs []*models.Repair
 	for rows.Next() {
-		var repair models.Repair
-		err := rows.Scan(
-			&repair.ID,
-			&repair.CarID,
-			&repair.AccidentID,
-			&repair.GarageID,
-			&repair.RepairType,
-			&repair.Description,
-			&repair.StartDate,
-			&repair.EndDate,
-			&repair.Cost,
-			&repair.Status,
-			&repair.InvoiceNumber,
-			&repair.Notes,
-			&repair.CreatedAt,
-			&repair.UpdatedAt,
-			&repair.CreatedBy,
-		)
+		repair, err := scanRepair(rows)
 		if err != nil {
 			return nil, fmt.Errorf("échec du scan de la réparation: %w", err)
 		}
-		repairs = append(repairs, &repair)
+		repairs = append(repairs, repair)
 	}
 
 	return repairs, nil
@@ -292,8 +271,8 @@ func (r *RepairRepository) FindByAccidentID(ctx context.Context, accidentID stri
 func (r *RepairRepository) FindByGarageID(ctx context.Context, garageID string) ([]*models.Repair, error) {
 	query := `
 		SELECT id, car_id, accident_id, garage_id, repair_type, description, 
-		       start_date, end_date, cost, status, invoice_number, notes, 
-		       created_at, updated_at, created_by
+		       start_date, end_date, cost, cost_vat_rate, cost_currency, status, invoice_number, 
+		       notes, created_at, updated_at, created_by
 		FROM repairs
 		WHERE garage_id = $1
 		ORDER BY start_date DESC
@@ -307,28 +286,11 @@ func (r *RepairRepository) FindByGarageID(ctx context.Context, garageID string)
 
 	var repairs []*models.Repair
 	for rows.Next() {
-		var repair models.Repair
-		err := rows.Scan(
-			&repair.ID,
-			&repair.CarID,
-			&repair.AccidentID,
-			&repair.GarageID,
-			&repair.RepairType,
-			&repair.Description,
-			&repair.StartDate,
-			&repair.EndDate,
-			&repair.Cost,
-			&repair.Status,
-			&repair.InvoiceNumber,
-			&repair.Notes,
-			&repair.CreatedAt,
-			&repair.UpdatedAt,
-			&repair.CreatedBy,
-		)
+		repair, err := scanRepair(rows)
 		if err != nil {
 			return nil, fmt.Errorf("échec du scan de la réparation: %w", err)
 		}
-		repairs = append(repairs, &repair)
+		repairs = append(repairs, repair)
 	}
 
 	return repairs, nil
@@ -421,44 +383,170 @@ func (r *RepairRepository) Delete(ctx context.Context, id string) error {
 
 // Count counts repairs with optional filters
 func (r *RepairRepository) Count(ctx context.Context, filters map[string]interface{}) (int, error) {
-	query := "SELECT COUNT(*) FROM repairs WHERE 1=1"
+	var args []interface{}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	query := "SELECT COUNT(*) FROM repairs WHERE " + strings.Join(repairConditions(filters, addArg), " AND ")
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("échec du comptage des réparations: %w", err)
+	}
+
+	return count, nil
+}
+
+// SumCosts aggregates repair costs in SQL NUMERIC, grouped by currency and
+// by the requested dimension. Repairs without a cost are counted but add nothing.
+func (r *RepairRepository) SumCosts(ctx context.Context, filters *models.RepairCostFilters) ([]*models.RepairCostSummary, error) {
+	var groupExpr string
+	switch filters.GroupBy {
+	case models.RepairCostGroupByCar:
+		groupExpr = "car_id::text"
+	case models.RepairCostGroupByGarage:
+		groupExpr = "garage_id::text"
+	case models.RepairCostGroupByMonth:
+		groupExpr = "to_char(start_date, 'YYYY-MM')"
+	case models.RepairCostGroupByRepairType:
+		groupExpr = "repair_type"
+	default:
+		return nil, fmt.Errorf("regroupement invalide")
+	}
+
+	// HT is derived from the stored TTC amount per repair, rounded to the
+	// cent, so that HT + TVA = TTC holds for every row and every sum.
+	query := `
+		WITH costs AS (
+			SELECT ` + groupExpr + ` AS group_key, cost_currency, cost,
+			       ROUND(cost * 100 / (100 + cost_vat_rate), 2) AS cost_excl_vat
+			FROM repairs
+			WHERE 1=1
+	`
+
 	var args []interface{}
 	argCount := 1
 
-	if carID, ok := filters["car_id"].(string); ok && carID != "" {
+	if filters.CarID != "" {
 		query += fmt.Sprintf(" AND car_id = $%d", argCount)
-		args = append(args, carID)
+		args = append(args, filters.CarID)
 		argCount++
 	}
 
-	if accidentID, ok := filters["accident_id"].(string); ok && accidentID != "" {
-		query += fmt.Sprintf(" AND accident_id = $%d", argCount)
-		args = append(args, accidentID)
+	if filters.GarageID != "" {
+		query += fmt.Sprintf(" AND garage_id = $%d", argCount)
+		args = append(args, filters.GarageID)
 		argCount++
 	}
 
-	if garageID, ok := filters["garage_id"].(string); ok && garageID != "" {
-		query += fmt.Sprintf(" AND garage_id = $%d", argCount)
-		args = append(args, garageID)
+	if filters.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argCount)
+		args = append(args, filters.Status)
 		argCount++
 	}
 
-	if repairType, ok := filters["repair_type"].(string); ok && repairType != "" {
-		query += fmt.Sprintf(" AND repair_type = $%d", argCount)
-		args = append(args, repairType)
+	if filters.From != nil {
+		query += fmt.Sprintf(" AND start_date >= $%d", argCount)
+		args = append(args, *filters.From)
 		argCount++
 	}
 
-	if status, ok := filters["status"].(string); ok && status != "" {
-		query += fmt.Sprintf(" AND status = $%d", argCount)
-		args = append(args, status)
+	if filters.To != nil {
+		query += fmt.Sprintf(" AND start_date <= $%d", argCount)
+		args = append(args, *filters.To)
 	}
 
-	var count int
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	query += `
+		)
+		SELECT group_key, cost_currency, COUNT(*),
+		       COALESCE(SUM(cost_excl_vat), 0),
+		       COALESCE(SUM(cost - cost_excl_vat), 0),
+		       COALESCE(SUM(cost), 0)
+		FROM costs
+		GROUP BY group_key, cost_currency
+		ORDER BY group_key, cost_currency
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return 0, fmt.Errorf("échec du comptage des réparations: %w", err)
+		return nil, fmt.Errorf("échec du calcul des coûts des réparations: %w", err)
 	}
+	defer rows.Close()
 
-	return count, nil
+	summaries := []*models.RepairCostSummary{}
+	for rows.Next() {
+		var summary models.RepairCostSummary
+		var currency string
+		var totalExclVAT, vatAmount, totalInclVAT decimal.Decimal
+		err := rows.Scan(
+			&summary.Group,
+			&currency,
+			&summary.RepairCount,
+			&totalExclVAT,
+			&vatAmount,
+			&totalInclVAT,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("échec du scan du coût des réparations: %w", err)
+		}
+		summary.TotalExclVAT = money.New(totalExclVAT, currency)
+		summary.VATAmount = money.New(vatAmount, currency)
+		summary.TotalInclVAT = money.New(totalInclVAT, currency)
+		summaries = append(summaries, &summary)
+	}
+
+	return summaries, rows.Err()
+}
+
+// costColumns splits an optional repair cost into its stored columns: the
+// amount including VAT, the VAT rate and the currency
+func costColumns(cost *money.Price) (decimal.NullDecimal, decimal.Decimal, string) {
+	if cost == nil {
+		return decimal.NullDecimal{}, money.DefaultVATRate, money.DefaultCurrency
+	}
+	return decimal.NullDecimal{Decimal: cost.InclVAT, Valid: true}, cost.VATRate, cost.Currency
+}
+
+// SetRepairCostUpdates adds the cost columns of a repair to an Update map
+func SetRepairCostUpdates(updates map[string]interface{}, cost *money.Price) {
+	updates["cost"], updates["cost_vat_rate"], updates["cost_currency"] = costColumns(cost)
+}
+
+func scanRepair(row rowScanner) (*models.Repair, error) {
+	var repair models.Repair
+	var cost decimal.NullDecimal
+	var costVATRate decimal.Decimal
+	var costCurrency string
+	err := row.Scan(
+		&repair.ID,
+		&repair.CarID,
+		&repair.AccidentID,
+		&repair.GarageID,
+		&repair.RepairType,
+		&repair.Description,
+		&repair.StartDate,
+		&repair.EndDate,
+		&cost,
+		&costVATRate,
+		&costCurrency,
+		&repair.Status,
+		&repair.InvoiceNumber,
+		&repair.Notes,
+		&repair.CreatedAt,
+		&repair.UpdatedAt,
+		&repair.CreatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if cost.Valid {
+		price := money.NewPriceInclVAT(cost.Decimal, costVATRate, costCurrency)
+		repair.Cost = &price
+	}
+
+	return &repair, nil
 }
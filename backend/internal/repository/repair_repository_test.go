@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -54,7 +56,7 @@ func TestRepairRepository_Create(t *testing.T) {
 	createTestGarageForRepair(t, ctx, garageID)
 	createTestAccidentForRepair(t, ctx, accidentID, carID)
 
-	cost := 1500.50
+	cost := money.NewPriceInclVAT(decimal.RequireFromString("1500.50"), money.DefaultVATRate, money.DefaultCurrency)
 	invoiceNum := "INV-2025-001"
 	notes := "Front bumper replacement"
 
@@ -284,7 +286,7 @@ func TestRepairRepository_Update(t *testing.T) {
 	createTestCarForRepair(t, ctx, carID)
 	createTestGarageForRepair(t, ctx, garageID)
 
-	cost := 1000.00
+	cost := money.NewPriceInclVAT(decimal.NewFromInt(1000), money.DefaultVATRate, money.DefaultCurrency)
 	repair := &models.Repair{
 		ID:          repairID,
 		CarID:       carID,
@@ -301,12 +303,12 @@ func TestRepairRepository_Update(t *testing.T) {
 	require.NoError(t, err)
 
 	endDate := time.Now().Add(24 * time.Hour)
-	newCost := 2000.00
+	newCost := money.NewPriceExclVAT(decimal.RequireFromString("1666.67"), decimal.RequireFromString("5.5"), "EUR")
 
 	updates := map[string]interface{}{
 		"end_date": endDate,
-		"cost":     newCost,
 	}
+	SetRepairCostUpdates(updates, &newCost)
 
 	err = repo.Update(ctx, repairID, updates)
 	assert.NoError(t, err)
@@ -316,7 +318,7 @@ func TestRepairRepository_Update(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotNil(t, updated.EndDate)
 	assert.NotNil(t, updated.Cost)
-	assert.Equal(t, newCost, *updated.Cost)
+	assert.True(t, newCost.Equal(*updated.Cost), "cost round-trips exactly: %v", updated.Cost)
 }
 
 func TestRepairRepository_Delete(t *testing.T) {
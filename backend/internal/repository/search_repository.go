@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+)
+
+// searchQueries select the records of each type matching the tsquery q. They
+// all return the same columns: type, id, title, subtitle, car id, the text to
+// highlight and the rank.
+var searchQueries = map[models.SearchResultType]string{
+	models.SearchResultCar: `
+		SELECT 'car', c.id::text, c.license_plate, c.brand || ' ' || c.model, NULL::text,
+		       concat_ws(' ', c.license_plate, c.brand, c.model, c.grey_card_number),
+		       ts_rank(c.search_vector, q.query)
+		FROM cars c, q
+		WHERE c.search_vector @@ q.query`,
+	models.SearchResultOperator: `
+		SELECT 'operator', o.id::text, o.first_name || ' ' || o.last_name,
+		       concat_ws(' · ', o.employee_number, o.department), NULL::text,
+		       concat_ws(' ', o.first_name, o.last_name, o.employee_number, o.email, o.department),
+		       ts_rank(o.search_vector, q.query)
+		FROM car_operators o, q
+		WHERE o.search_vector @@ q.query`,
+	models.SearchResultGarage: `
+		SELECT 'garage', g.id::text, g.name, g.address, NULL::text,
+		       concat_ws(' ', g.name, g.contact_person, g.specialization, g.address),
+		       ts_rank(g.search_vector, q.query)
+		FROM garages g, q
+		WHERE g.search_vector @@ q.query`,
+	models.SearchResultAccident: `
+		SELECT 'accident', a.id::text, a.location,
+		       c.license_plate || ' · ' || to_char(a.accident_date, 'DD/MM/YYYY'), a.car_id::text,
+		       concat_ws(' ', a.police_report_number, a.insurance_claim_number, a.location, a.description, a.damages_description),
+		       ts_rank(a.search_vector, q.query)
+		FROM accidents a
+		JOIN cars c ON c.id = a.car_id, q
+		WHERE a.search_vector @@ q.query`,
+	models.SearchResultRepair: `
+		SELECT 'repair', r.id::text, r.description,
+		       c.license_plate || ' · ' || g.name, r.car_id::text,
+		       concat_ws(' ', r.invoice_number, r.description, r.notes),
+		       ts_rank(r.search_vector, q.query)
+		FROM repairs r
+		JOIN cars c ON c.id = r.car_id
+		JOIN garages g ON g.id = r.garage_id, q
+		WHERE r.search_vector @@ q.query`,
+}
+
+// SearchRepository handles the full-text search across the fleet
+type SearchRepository struct {
+	db *sql.DB
+}
+
+// NewSearchRepository creates a new search repository
+func NewSearchRepository(db *sql.DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// Search returns the best ranked records of the given types matching the
+// tsquery, as understood by to_tsquery with the autoparc_french
+// configuration. Highlights are only computed for the returned records, as
+// HTML: the stored text is escaped before the matches are marked, so that
+// free text such as notes cannot inject markup.
+func (r *SearchRepository) Search(ctx context.Context, tsquery string, types []models.SearchResultType, limit int) ([]models.SearchResult, error) {
+	branches := make([]string, 0, len(types))
+	for _, t := range types {
+		branch, ok := searchQueries[t]
+		if !ok {
+			return nil, fmt.Errorf("unknown search result type: %s", t)
+		}
+		branches = append(branches, branch)
+	}
+	if len(branches) == 0 {
+		return []models.SearchResult{}, nil
+	}
+
+	query := `
+		WITH q AS (SELECT to_tsquery('autoparc_french', $1) AS query),
+		matches (type, id, title, subtitle, car_id, document, rank) AS (` +
+		strings.Join(branches, "\n\t\tUNION ALL") + `
+		)
+		SELECT m.type, m.id, m.title, m.subtitle, m.car_id,
+		       ts_headline('autoparc_french',
+		                   replace(replace(replace(replace(m.document, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'),
+		                   q.query,
+		                   'StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=5, MaxFragments=2, FragmentDelimiter=" … "'),
+		       m.rank
+		FROM (
+			SELECT * FROM matches ORDER BY rank DESC, title LIMIT $2
+		) m, q
+		ORDER BY m.rank DESC, m.title
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tsquery, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.SearchResult{}
+	for rows.Next() {
+		var result models.SearchResult
+		if err := rows.Scan(
+			&result.Type,
+			&result.ID,
+			&result.Title,
+			&result.Subtitle,
+			&result.CarID,
+			&result.Highlight,
+			&result.Rank,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	return results, nil
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
 )
 
 // SessionRepository handles database operations for sessions
@@ -19,11 +20,15 @@ func NewSessionRepository(db *sql.DB) *SessionRepository {
 	return &SessionRepository{db: db}
 }
 
-// Create creates a new session in the database
+// Create creates a new session in the database. Only the hash of the
+// session token is stored.
 func (r *SessionRepository) Create(ctx context.Context, session *models.Session) error {
+	session.TokenHash = utils.HashSessionToken(session.SessionToken)
+
 	query := `
-		INSERT INTO sessions (id, user_id, session_token, expires_at, ip_address, user_agent, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO sessions (id, user_id, token_hash, expires_at, absolute_expires_at, last_seen_at,
+		                      ip_address, user_agent, created_at, mfa_pending, password_change_required)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
 	_, err := r.db.ExecContext(
@@ -31,11 +36,15 @@ func (r *SessionRepository) Create(ctx context.Context, session *models.Session)
 		query,
 		session.ID,
 		session.UserID,
-		session.SessionToken,
+		session.TokenHash,
 		session.ExpiresAt,
+		session.AbsoluteExpiresAt,
+		session.LastSeenAt,
 		session.IPAddress,
 		session.UserAgent,
 		session.CreatedAt,
+		session.MFAPending,
+		session.PasswordChangeRequired,
 	)
 
 	if err != nil {
@@ -45,25 +54,18 @@ func (r *SessionRepository) Create(ctx context.Context, session *models.Session)
 	return nil
 }
 
-// FindByToken finds a session by its token
+// FindByToken finds a session by its token. The lookup is done on the token
+// hash, so timing differences in the index scan reveal nothing about the
+// token itself, and the match is confirmed in constant time.
 func (r *SessionRepository) FindByToken(ctx context.Context, token string) (*models.Session, error) {
 	query := `
-		SELECT id, user_id, session_token, expires_at, ip_address, user_agent, created_at
+		SELECT id, user_id, token_hash, expires_at, absolute_expires_at, last_seen_at,
+		       ip_address, user_agent, created_at, mfa_pending, password_change_required
 		FROM sessions
-		WHERE session_token = $1 AND expires_at > $2
+		WHERE token_hash = $1 AND expires_at > $2
 	`
 
-	var session models.Session
-	err := r.db.QueryRowContext(ctx, query, token, time.Now()).Scan(
-		&session.ID,
-		&session.UserID,
-		&session.SessionToken,
-		&session.ExpiresAt,
-		&session.IPAddress,
-		&session.UserAgent,
-		&session.CreatedAt,
-	)
-
+	session, err := scanSession(r.db.QueryRowContext(ctx, query, utils.HashSessionToken(token), time.Now()))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("session not found or expired")
 	}
@@ -71,14 +73,70 @@ func (r *SessionRepository) FindByToken(ctx context.Context, token string) (*mod
 		return nil, fmt.Errorf("failed to find session: %w", err)
 	}
 
-	return &session, nil
+	if !utils.CheckSessionTokenHash(token, session.TokenHash) {
+		return nil, fmt.Errorf("session not found or expired")
+	}
+
+	return session, nil
+}
+
+// FindByUserID finds the active sessions of a user, most recently used first
+func (r *SessionRepository) FindByUserID(ctx context.Context, userID string) ([]*models.Session, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires_at, absolute_expires_at, last_seen_at,
+		       ip_address, user_agent, created_at, mfa_pending, password_change_required
+		FROM sessions
+		WHERE user_id = $1 AND expires_at > $2
+		ORDER BY last_seen_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []*models.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// ClearPasswordChangeRequired lifts the restriction of a session opened with
+// an expired password once a new one has been chosen
+func (r *SessionRepository) ClearPasswordChangeRequired(ctx context.Context, id string) error {
+	query := `UPDATE sessions SET password_change_required = false WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+
+	return nil
+}
+
+// Touch records activity on a session and slides its expiry
+func (r *SessionRepository) Touch(ctx context.Context, id string, lastSeenAt, expiresAt time.Time) error {
+	query := `UPDATE sessions SET last_seen_at = $2, expires_at = $3 WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, id, lastSeenAt, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+
+	return nil
 }
 
 // Delete deletes a session by token
 func (r *SessionRepository) Delete(ctx context.Context, token string) error {
-	query := `DELETE FROM sessions WHERE session_token = $1`
+	query := `DELETE FROM sessions WHERE token_hash = $1`
 
-	_, err := r.db.ExecContext(ctx, query, token)
+	_, err := r.db.ExecContext(ctx, query, utils.HashSessionToken(token))
 	if err != nil {
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
@@ -86,14 +144,82 @@ func (r *SessionRepository) Delete(ctx context.Context, token string) error {
 	return nil
 }
 
-// DeleteExpired deletes all expired sessions
-func (r *SessionRepository) DeleteExpired(ctx context.Context) error {
-	query := `DELETE FROM sessions WHERE expires_at < $1`
+// DeleteByID deletes a session of a user by its ID
+func (r *SessionRepository) DeleteByID(ctx context.Context, userID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
 
-	_, err := r.db.ExecContext(ctx, query, time.Now())
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to delete expired sessions: %w", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("session not found")
 	}
 
 	return nil
 }
+
+// DeleteByUserID deletes all sessions of a user except keepID, if given,
+// and returns the number of deleted sessions
+func (r *SessionRepository) DeleteByUserID(ctx context.Context, userID, keepID string) (int64, error) {
+	query := `DELETE FROM sessions WHERE user_id = $1 AND ($2 = '' OR id::text <> $2)`
+
+	result, err := r.db.ExecContext(ctx, query, userID, keepID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// DeleteExpired deletes all expired sessions and returns how many were deleted
+func (r *SessionRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	query := `DELETE FROM sessions WHERE expires_at < $1`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+func scanSession(row rowScanner) (*models.Session, error) {
+	var session models.Session
+	var ipAddress, userAgent sql.NullString
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.TokenHash,
+		&session.ExpiresAt,
+		&session.AbsoluteExpiresAt,
+		&session.LastSeenAt,
+		&ipAddress,
+		&userAgent,
+		&session.CreatedAt,
+		&session.MFAPending,
+		&session.PasswordChangeRequired,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	session.IPAddress = ipAddress.String
+	session.UserAgent = userAgent.String
+
+	return &session, nil
+}
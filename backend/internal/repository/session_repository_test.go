@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestSession(t *testing.T, repo *SessionRepository) *models.Session {
+	t.Helper()
+
+	userRepo := NewUserRepository(testDB)
+	employee := &models.AdministrativeEmployee{
+		Email:        "session@example.com",
+		PasswordHash: "hashedpassword",
+		FirstName:    "John",
+		LastName:     "Doe",
+		Role:         "admin",
+		IsActive:     true,
+	}
+	require.NoError(t, userRepo.Create(testContext(), employee))
+
+	token, err := utils.GenerateSessionToken()
+	require.NoError(t, err)
+
+	now := time.Now()
+	session := &models.Session{
+		ID:                "550e8400-e29b-41d4-a716-446655440100",
+		UserID:            employee.ID,
+		SessionToken:      token,
+		ExpiresAt:         now.Add(time.Hour),
+		AbsoluteExpiresAt: now.Add(24 * time.Hour),
+		LastSeenAt:        now,
+		IPAddress:         "127.0.0.1",
+		UserAgent:         "test-agent",
+		CreatedAt:         now,
+	}
+	require.NoError(t, repo.Create(testContext(), session))
+
+	return session
+}
+
+func TestSessionRepository_Create_StoresHashOnly(t *testing.T) {
+	cleanupDB(t)
+
+	repo := NewSessionRepository(testDB)
+	session := createTestSession(t, repo)
+
+	var storedHash, row string
+	err := testDB.QueryRow(`SELECT token_hash, row_to_json(s)::text FROM sessions s WHERE id = $1`, session.ID).Scan(&storedHash, &row)
+	require.NoError(t, err)
+
+	assert.Equal(t, utils.HashSessionToken(session.SessionToken), storedHash)
+	assert.NotContains(t, row, session.SessionToken)
+}
+
+func TestSessionRepository_FindByToken(t *testing.T) {
+	cleanupDB(t)
+
+	repo := NewSessionRepository(testDB)
+	ctx := testContext()
+	session := createTestSession(t, repo)
+
+	found, err := repo.FindByToken(ctx, session.SessionToken)
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, found.ID)
+	assert.Empty(t, found.SessionToken)
+
+	// The stored hash is not a valid token
+	_, err = repo.FindByToken(ctx, found.TokenHash)
+	assert.Error(t, err)
+}
+
+func TestSessionRepository_Delete(t *testing.T) {
+	cleanupDB(t)
+
+	repo := NewSessionRepository(testDB)
+	ctx := testContext()
+	session := createTestSession(t, repo)
+
+	require.NoError(t, repo.Delete(ctx, session.SessionToken))
+
+	_, err := repo.FindByToken(ctx, session.SessionToken)
+	assert.Error(t, err)
+}
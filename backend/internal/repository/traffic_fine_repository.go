@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/shopspring/decimal"
+)
+
+// trafficFineColumns are the columns read by scanTrafficFine
+const trafficFineColumns = `
+	f.id, f.notice_number, f.car_id, f.license_plate, f.offense_at, f.location,
+	f.offense, f.amount, f.currency, f.notice_date, f.designation_deadline,
+	f.operator_id, f.status, f.designated_at, f.reminded_at, f.notes,
+	f.created_at, f.updated_at, f.created_by,
+	o.employee_number, o.first_name, o.last_name, o.email
+`
+
+// trafficFineJoins joins the driver of a fine
+const trafficFineJoins = `
+	FROM traffic_fines f
+	LEFT JOIN car_operators o ON f.operator_id = o.id
+`
+
+// TrafficFineRepository handles database operations for traffic fines
+type TrafficFineRepository struct {
+	db *sql.DB
+}
+
+// NewTrafficFineRepository creates a new traffic fine repository
+func NewTrafficFineRepository(db *sql.DB) *TrafficFineRepository {
+	return &TrafficFineRepository{db: db}
+}
+
+// Create creates a new traffic fine
+func (r *TrafficFineRepository) Create(ctx context.Context, fine *models.TrafficFine) error {
+	query := `
+		INSERT INTO traffic_fines (id, notice_number, car_id, license_plate, offense_at, location,
+		                           offense, amount, currency, notice_date, designation_deadline,
+		                           operator_id, status, notes, created_at, updated_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		fine.ID,
+		fine.NoticeNumber,
+		fine.CarID,
+		fine.LicensePlate,
+		fine.OffenseAt,
+		fine.Location,
+		fine.Offense,
+		fine.Amount.Amount,
+		fine.Amount.Currency,
+		fine.NoticeDate,
+		fine.DesignationDeadline,
+		fine.OperatorID,
+		fine.Status,
+		fine.Notes,
+		fine.CreatedAt,
+		fine.UpdatedAt,
+		fine.CreatedBy,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "traffic_fines_notice_number_key") {
+			return fmt.Errorf("a fine with this notice number already exists")
+		}
+		return fmt.Errorf("failed to create traffic fine: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a traffic fine by ID
+func (r *TrafficFineRepository) FindByID(ctx context.Context, id string) (*models.TrafficFine, error) {
+	query := `SELECT ` + trafficFineColumns + trafficFineJoins + ` WHERE f.id = $1`
+
+	fine, err := scanTrafficFine(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("traffic fine not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find traffic fine: %w", err)
+	}
+
+	return fine, nil
+}
+
+// FindAll retrieves the traffic fines matching the filters, soonest
+// deadline first
+func (r *TrafficFineRepository) FindAll(ctx context.Context, filters *models.TrafficFineFilters) ([]*models.TrafficFine, error) {
+	var where []string
+	var args []interface{}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	where = append(where, "1=1")
+	if filters.CarID != "" {
+		where = append(where, "f.car_id = "+addArg(filters.CarID))
+	}
+	if filters.OperatorID != "" {
+		where = append(where, "f.operator_id = "+addArg(filters.OperatorID))
+	}
+	if filters.Status != "" {
+		where = append(where, "f.status = "+addArg(filters.Status))
+	}
+	if filters.DeadlineBefore != nil {
+		where = append(where, "f.designation_deadline <= "+addArg(*filters.DeadlineBefore))
+	}
+	if filters.NotReminded {
+		where = append(where, "f.reminded_at IS NULL")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s %s
+		WHERE %s
+		ORDER BY f.designation_deadline, f.offense_at, f.id
+	`, trafficFineColumns, trafficFineJoins, strings.Join(where, " AND "))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query traffic fines: %w", err)
+	}
+	defer rows.Close()
+
+	fines := []*models.TrafficFine{}
+	for rows.Next() {
+		fine, err := scanTrafficFine(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan traffic fine: %w", err)
+		}
+		fines = append(fines, fine)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating traffic fines: %w", err)
+	}
+
+	return fines, nil
+}
+
+// Update updates a traffic fine
+func (r *TrafficFineRepository) Update(ctx context.Context, fine *models.TrafficFine) error {
+	query := `
+		UPDATE traffic_fines
+		SET location = $2, offense = $3, amount = $4, currency = $5, notice_date = $6,
+		    designation_deadline = $7, operator_id = $8, status = $9, designated_at = $10,
+		    notes = $11
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		fine.ID,
+		fine.Location,
+		fine.Offense,
+		fine.Amount.Amount,
+		fine.Amount.Currency,
+		fine.NoticeDate,
+		fine.DesignationDeadline,
+		fine.OperatorID,
+		fine.Status,
+		fine.DesignatedAt,
+		fine.Notes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update traffic fine: %w", err)
+	}
+
+	return checkTrafficFineAffected(result)
+}
+
+// MarkReminded records that the deadline reminder of the fines was sent
+func (r *TrafficFineRepository) MarkReminded(ctx context.Context, ids []string, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := []interface{}{at}
+	for i, id := range ids {
+		args = append(args, id)
+		placeholders[i] = fmt.Sprintf("$%d", i+2)
+	}
+
+	query := fmt.Sprintf(`UPDATE traffic_fines SET reminded_at = $1 WHERE id IN (%s)`, strings.Join(placeholders, ", "))
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to mark fines as reminded: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a traffic fine
+func (r *TrafficFineRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM traffic_fines WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete traffic fine: %w", err)
+	}
+
+	return checkTrafficFineAffected(result)
+}
+
+func scanTrafficFine(row rowScanner) (*models.TrafficFine, error) {
+	var fine models.TrafficFine
+	var amount decimal.Decimal
+	var currency string
+	var employeeNumber, firstName, lastName sql.NullString
+	var email *string
+
+	err := row.Scan(
+		&fine.ID,
+		&fine.NoticeNumber,
+		&fine.CarID,
+		&fine.LicensePlate,
+		&fine.OffenseAt,
+		&fine.Location,
+		&fine.Offense,
+		&amount,
+		&currency,
+		&fine.NoticeDate,
+		&fine.DesignationDeadline,
+		&fine.OperatorID,
+		&fine.Status,
+		&fine.DesignatedAt,
+		&fine.RemindedAt,
+		&fine.Notes,
+		&fine.CreatedAt,
+		&fine.UpdatedAt,
+		&fine.CreatedBy,
+		&employeeNumber,
+		&firstName,
+		&lastName,
+		&email,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	fine.Amount = money.New(amount, currency)
+	if fine.OperatorID != nil {
+		fine.Driver = &models.FineDriver{
+			ID:             *fine.OperatorID,
+			EmployeeNumber: employeeNumber.String,
+			FirstName:      firstName.String,
+			LastName:       lastName.String,
+			Email:          email,
+		}
+	}
+
+	return &fine, nil
+}
+
+func checkTrafficFineAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("traffic fine not found")
+	}
+	return nil
+}
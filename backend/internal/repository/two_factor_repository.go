@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TwoFactorRepository handles database operations for TOTP secrets and
+// recovery codes
+type TwoFactorRepository struct {
+	db *sql.DB
+}
+
+// NewTwoFactorRepository creates a new two-factor repository
+func NewTwoFactorRepository(db *sql.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// GetSecret returns the TOTP secret of a user, empty if not enrolling, and
+// whether 2FA is enabled
+func (r *TwoFactorRepository) GetSecret(ctx context.Context, userID string) (string, bool, error) {
+	query := `SELECT totp_secret, totp_enabled FROM administrative_employees WHERE id = $1`
+
+	var secret sql.NullString
+	var enabled bool
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&secret, &enabled)
+	if err == sql.ErrNoRows {
+		return "", false, fmt.Errorf("employee not found")
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get two-factor secret: %w", err)
+	}
+
+	return secret.String, enabled, nil
+}
+
+// SetSecret stores the secret of a pending enrollment. It fails once 2FA is
+// enabled, so an active secret can never be silently replaced.
+func (r *TwoFactorRepository) SetSecret(ctx context.Context, userID, secret string) error {
+	query := `
+		UPDATE administrative_employees
+		SET totp_secret = $2, totp_last_used_step = NULL
+		WHERE id = $1 AND NOT totp_enabled
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, secret)
+	if err != nil {
+		return fmt.Errorf("failed to set two-factor secret: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("two-factor authentication already enabled")
+	}
+
+	return nil
+}
+
+// Enable activates 2FA with the pending secret and replaces the recovery codes
+func (r *TwoFactorRepository) Enable(ctx context.Context, userID string, recoveryCodeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE administrative_employees
+		SET totp_enabled = true, totp_enabled_at = $2
+		WHERE id = $1 AND totp_secret IS NOT NULL AND NOT totp_enabled
+	`, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("two-factor enrollment not started")
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Disable removes the secret and the recovery codes of a user
+func (r *TwoFactorRepository) Disable(ctx context.Context, userID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE administrative_employees
+		SET totp_secret = NULL, totp_enabled = false, totp_enabled_at = NULL, totp_last_used_step = NULL
+		WHERE id = $1
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("employee not found")
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseStep records a TOTP time step as used. It returns false when the step,
+// or a later one, was already used, which rejects replayed codes.
+func (r *TwoFactorRepository) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query := `
+		UPDATE administrative_employees
+		SET totp_last_used_step = $2
+		WHERE id = $1 AND (totp_last_used_step IS NULL OR totp_last_used_step < $2)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to record two-factor code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// ConsumeRecoveryCode marks an unused recovery code as used. It returns false
+// if the code does not exist or was already used.
+func (r *TwoFactorRepository) ConsumeRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	query := `
+		UPDATE two_factor_recovery_codes
+		SET used_at = $3
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, codeHash, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// CountRecoveryCodes returns the number of unused recovery codes of a user
+func (r *TwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	query := `SELECT COUNT(*) FROM two_factor_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return count, nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID string, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM two_factor_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	for _, codeHash := range codeHashes {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO two_factor_recovery_codes (id, user_id, code_hash, created_at)
+			VALUES ($1, $2, $3, $4)
+		`, uuid.New().String(), userID, codeHash, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to create recovery code: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -9,7 +9,6 @@ import (
 
 	"github.com/goldenkiwi/autoparc/internal/models"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // EmployeeFilters contains filtering options for employee queries
@@ -46,13 +45,15 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models.AdministrativeEmployee, error) {
 	query := `
 		SELECT id, email, password_hash, first_name, last_name, role, is_active, 
-		       created_at, updated_at, last_login_at
+		       created_at, updated_at, last_login_at,
+		       failed_login_count, last_failed_login_at, locked_until, totp_enabled,
+		       password_changed_at, language, default_page_size
 		FROM administrative_employees
 		WHERE email = $1 AND is_active = true
 	`
 
 	var user models.AdministrativeEmployee
-	var lastLoginAt sql.NullTime
+	var lastLoginAt, lastFailedLoginAt, lockedUntil sql.NullTime
 
 	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
@@ -65,6 +66,13 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&lastLoginAt,
+		&user.FailedLoginCount,
+		&lastFailedLoginAt,
+		&lockedUntil,
+		&user.TwoFactorEnabled,
+		&user.PasswordChangedAt,
+		&user.Preferences.Language,
+		&user.Preferences.DefaultPageSize,
 	)
 
 	if err == sql.ErrNoRows {
@@ -77,6 +85,12 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models
 	if lastLoginAt.Valid {
 		user.LastLoginAt = &lastLoginAt.Time
 	}
+	if lastFailedLoginAt.Valid {
+		user.LastFailedLoginAt = &lastFailedLoginAt.Time
+	}
+	if lockedUntil.Valid {
+		user.LockedUntil = &lockedUntil.Time
+	}
 
 	return &user, nil
 }
@@ -85,7 +99,8 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models
 func (r *UserRepository) FindByID(ctx context.Context, id string) (*models.AdministrativeEmployee, error) {
 	query := `
 		SELECT id, email, password_hash, first_name, last_name, role, is_active, 
-		       created_at, updated_at, last_login_at
+		       created_at, updated_at, last_login_at, totp_enabled, password_changed_at,
+		       language, default_page_size
 		FROM administrative_employees
 		WHERE id = $1 AND is_active = true
 	`
@@ -104,6 +119,10 @@ func (r *UserRepository) FindByID(ctx context.Context, id string) (*models.Admin
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&lastLoginAt,
+		&user.TwoFactorEnabled,
+		&user.PasswordChangedAt,
+		&user.Preferences.Language,
+		&user.Preferences.DefaultPageSize,
 	)
 
 	if err == sql.ErrNoRows {
@@ -136,6 +155,105 @@ func (r *UserRepository) UpdateLastLogin(ctx context.Context, id string) error {
 	return nil
 }
 
+// RecordFailedLogin increments the failed login counter of a user and locks
+// the account until lockUntil once maxAttempts is reached. It returns the new
+// counter and lock expiry.
+func (r *UserRepository) RecordFailedLogin(ctx context.Context, id string, maxAttempts int, lockUntil time.Time) (int, *time.Time, error) {
+	query := `
+		UPDATE administrative_employees
+		SET failed_login_count = failed_login_count + 1,
+		    last_failed_login_at = $2,
+		    locked_until = CASE WHEN $3 > 0 AND failed_login_count + 1 >= $3 THEN $4 ELSE locked_until END
+		WHERE id = $1
+		RETURNING failed_login_count, locked_until
+	`
+
+	var count int
+	var lockedUntil sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, id, time.Now(), maxAttempts, lockUntil).Scan(&count, &lockedUntil)
+	if err == sql.ErrNoRows {
+		return 0, nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to record failed login: %w", err)
+	}
+
+	if lockedUntil.Valid {
+		return count, &lockedUntil.Time, nil
+	}
+	return count, nil, nil
+}
+
+// ResetFailedLogins clears the failed login counter and any lock of a user
+func (r *UserRepository) ResetFailedLogins(ctx context.Context, id string) error {
+	query := `
+		UPDATE administrative_employees
+		SET failed_login_count = 0, last_failed_login_at = NULL, locked_until = NULL
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to reset failed logins: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("employee not found")
+	}
+
+	return nil
+}
+
+// FindByOIDCSubject finds an active user by the SSO identity linked to it
+func (r *UserRepository) FindByOIDCSubject(ctx context.Context, issuer, subject string) (*models.AdministrativeEmployee, error) {
+	query := `SELECT id FROM administrative_employees WHERE oidc_issuer = $1 AND oidc_subject = $2`
+
+	var id string
+	err := r.db.QueryRowContext(ctx, query, issuer, subject).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	return r.FindByID(ctx, id)
+}
+
+// LinkOIDCSubject links an SSO identity to a user. A user already linked to
+// another identity is left untouched.
+func (r *UserRepository) LinkOIDCSubject(ctx context.Context, id, issuer, subject string) error {
+	query := `
+		UPDATE administrative_employees
+		SET oidc_issuer = $2, oidc_subject = $3
+		WHERE id = $1 AND (oidc_subject IS NULL OR (oidc_issuer = $2 AND oidc_subject = $3))
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, issuer, subject)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint") {
+			return fmt.Errorf("identity already linked to another employee")
+		}
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("employee already linked to another identity")
+	}
+
+	return nil
+}
+
 // Create creates a new employee in the database
 func (r *UserRepository) Create(ctx context.Context, employee *models.AdministrativeEmployee) error {
 	// Generate UUID if not provided
@@ -152,13 +270,23 @@ func (r *UserRepository) Create(ctx context.Context, employee *models.Administra
 	if employee.Role == "" {
 		employee.Role = "admin"
 	}
+	if employee.Preferences == (models.EmployeePreferences{}) {
+		employee.Preferences = models.DefaultEmployeePreferences()
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
 	query := `
-		INSERT INTO administrative_employees (id, email, password_hash, first_name, last_name, role, is_active, created_at, updated_at)
-		VALUES ($1, LOWER($2), $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO administrative_employees (id, email, password_hash, first_name, last_name, role, is_active,
+		                                      created_at, updated_at, password_changed_at, language, default_page_size)
+		VALUES ($1, LOWER($2), $3, $4, $5, $6, $7, $8, $9, $8, $10, $11)
 	`
 
-	_, err := r.db.ExecContext(
+	_, err = tx.ExecContext(
 		ctx,
 		query,
 		employee.ID,
@@ -170,6 +298,8 @@ func (r *UserRepository) Create(ctx context.Context, employee *models.Administra
 		employee.IsActive,
 		employee.CreatedAt,
 		employee.UpdatedAt,
+		employee.Preferences.Language,
+		employee.Preferences.DefaultPageSize,
 	)
 
 	if err != nil {
@@ -179,6 +309,16 @@ func (r *UserRepository) Create(ctx context.Context, employee *models.Administra
 		return fmt.Errorf("failed to create employee: %w", err)
 	}
 
+	if err := insertPasswordHistory(ctx, tx, employee.ID, employee.PasswordHash, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	employee.PasswordChangedAt = now
+
 	return nil
 }
 
@@ -186,13 +326,14 @@ func (r *UserRepository) Create(ctx context.Context, employee *models.Administra
 func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.AdministrativeEmployee, error) {
 	query := `
 		SELECT id, email, first_name, last_name, role, is_active, 
-		       created_at, updated_at, last_login_at
+		       created_at, updated_at, last_login_at, locked_until, totp_enabled,
+		       language, default_page_size
 		FROM administrative_employees
 		WHERE id = $1
 	`
 
 	var employee models.AdministrativeEmployee
-	var lastLoginAt sql.NullTime
+	var lastLoginAt, lockedUntil sql.NullTime
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&employee.ID,
@@ -204,6 +345,10 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.Admini
 		&employee.CreatedAt,
 		&employee.UpdatedAt,
 		&lastLoginAt,
+		&lockedUntil,
+		&employee.TwoFactorEnabled,
+		&employee.Preferences.Language,
+		&employee.Preferences.DefaultPageSize,
 	)
 
 	if err == sql.ErrNoRows {
@@ -216,6 +361,9 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.Admini
 	if lastLoginAt.Valid {
 		employee.LastLoginAt = &lastLoginAt.Time
 	}
+	if lockedUntil.Valid {
+		employee.LockedUntil = &lockedUntil.Time
+	}
 
 	return &employee, nil
 }
@@ -328,7 +476,7 @@ func (r *UserRepository) GetAll(ctx context.Context, filters EmployeeFilters) (*
 	// Query employees
 	query := fmt.Sprintf(`
 		SELECT id, email, first_name, last_name, role, is_active, 
-		       created_at, updated_at, last_login_at
+		       created_at, updated_at, last_login_at, totp_enabled
 		FROM administrative_employees
 		%s
 		ORDER BY %s %s
@@ -358,6 +506,7 @@ func (r *UserRepository) GetAll(ctx context.Context, filters EmployeeFilters) (*
 			&employee.CreatedAt,
 			&employee.UpdatedAt,
 			&lastLoginAt,
+			&employee.TwoFactorEnabled,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan employee: %w", err)
@@ -426,15 +575,59 @@ func (r *UserRepository) Update(ctx context.Context, id string, employee *models
 	return nil
 }
 
-// UpdatePassword updates an employee's password hash
+// UpdateProfile updates the details an employee may change themselves
+func (r *UserRepository) UpdateProfile(ctx context.Context, id string, employee *models.AdministrativeEmployee) error {
+	employee.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE administrative_employees
+		SET first_name = $1, last_name = $2, language = $3, default_page_size = $4, updated_at = $5
+		WHERE id = $6
+	`
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		employee.FirstName,
+		employee.LastName,
+		employee.Preferences.Language,
+		employee.Preferences.DefaultPageSize,
+		employee.UpdatedAt,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("employee not found")
+	}
+
+	return nil
+}
+
+// UpdatePassword updates an employee's password hash, records the change
+// date and keeps the hash in the password history
 func (r *UserRepository) UpdatePassword(ctx context.Context, id string, passwordHash string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE administrative_employees
-		SET password_hash = $1, updated_at = $2
+		SET password_hash = $1, password_changed_at = $2, updated_at = $2
 		WHERE id = $3
 	`
 
-	result, err := r.db.ExecContext(ctx, query, passwordHash, time.Now(), id)
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, query, passwordHash, now, id)
 	if err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
@@ -448,6 +641,76 @@ func (r *UserRepository) UpdatePassword(ctx context.Context, id string, password
 		return fmt.Errorf("employee not found")
 	}
 
+	if err := insertPasswordHistory(ctx, tx, id, passwordHash, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RecentPasswordHashes returns the last n password hashes of an employee,
+// most recent first. The current password is included.
+func (r *UserRepository) RecentPasswordHashes(ctx context.Context, id string, n int) ([]string, error) {
+	query := `
+		SELECT password_hash
+		FROM password_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id, n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find password history: %w", err)
+	}
+	defer rows.Close()
+
+	hashes := []string{}
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("failed to scan password history: %w", err)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, rows.Err()
+}
+
+// PrunePasswordHistory deletes all but the last keep password hashes of an
+// employee
+func (r *UserRepository) PrunePasswordHistory(ctx context.Context, id string, keep int) error {
+	query := `
+		DELETE FROM password_history
+		WHERE user_id = $1 AND id NOT IN (
+			SELECT id FROM password_history
+			WHERE user_id = $1
+			ORDER BY created_at DESC
+			LIMIT $2
+		)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, keep); err != nil {
+		return fmt.Errorf("failed to prune password history: %w", err)
+	}
+
+	return nil
+}
+
+func insertPasswordHistory(ctx context.Context, tx *sql.Tx, userID, passwordHash string, createdAt time.Time) error {
+	query := `
+		INSERT INTO password_history (id, user_id, password_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	if _, err := tx.ExecContext(ctx, query, uuid.New().String(), userID, passwordHash, createdAt); err != nil {
+		return fmt.Errorf("failed to record password history: %w", err)
+	}
+
 	return nil
 }
 
@@ -475,17 +738,3 @@ func (r *UserRepository) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
-
-// HashPassword hashes a password using bcrypt
-func HashPassword(password string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", fmt.Errorf("failed to hash password: %w", err)
-	}
-	return string(hash), nil
-}
-
-// CheckPassword validates a password against a hash
-func CheckPassword(password, hash string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-}
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/goldenkiwi/autoparc/internal/config"
 	"github.com/goldenkiwi/autoparc/internal/models"
 	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/pkg/imaging"
 	"github.com/goldenkiwi/autoparc/pkg/utils"
 	"github.com/google/uuid"
 )
@@ -18,6 +20,7 @@ type AccidentService struct {
 	accidentPhotoRepo *repository.AccidentPhotoRepository
 	carRepo           *repository.CarRepository
 	actionLogRepo     *repository.ActionLogRepository
+	uploadConfig      *config.UploadConfig
 }
 
 // NewAccidentService creates a new accident service
@@ -26,12 +29,14 @@ func NewAccidentService(
 	accidentPhotoRepo *repository.AccidentPhotoRepository,
 	carRepo *repository.CarRepository,
 	actionLogRepo *repository.ActionLogRepository,
+	uploadConfig *config.UploadConfig,
 ) *AccidentService {
 	return &AccidentService{
 		accidentRepo:      accidentRepo,
 		accidentPhotoRepo: accidentPhotoRepo,
 		carRepo:           carRepo,
 		actionLogRepo:     actionLogRepo,
+		uploadConfig:      uploadConfig,
 	}
 }
 
@@ -288,10 +293,37 @@ func (s *AccidentService) UpdateAccidentStatus(ctx context.Context, id string, s
 	return s.accidentRepo.FindByID(ctx, id)
 }
 
+// NewAccidentPhoto builds the photo record of a processed image, with its
+// variants and the metadata read from the original
+func NewAccidentPhoto(accidentID, filename string, processed *imaging.Result, description *string, userID string) *models.AccidentPhoto {
+	variantMimeType := imaging.VariantMimeType
+
+	return &models.AccidentPhoto{
+		ID:              uuid.New().String(),
+		AccidentID:      accidentID,
+		Filename:        filename,
+		FileData:        processed.Data,
+		FileSize:        len(processed.Data),
+		MimeType:        processed.MimeType,
+		CompressionType: models.CompressionTypeGzip,
+		Description:     description,
+		UploadedAt:      time.Now(),
+		UploadedBy:      &userID,
+		Width:           &processed.Width,
+		Height:          &processed.Height,
+		TakenAt:         processed.Metadata.TakenAt,
+		Latitude:        processed.Metadata.Latitude,
+		Longitude:       processed.Metadata.Longitude,
+		ThumbnailData:   processed.Thumbnail,
+		MediumData:      processed.Medium,
+		VariantMimeType: &variantMimeType,
+	}
+}
+
 // UploadAccidentPhoto uploads a photo for an accident
 func (s *AccidentService) UploadAccidentPhoto(ctx context.Context, req *models.UploadPhotoRequest, userID string) (*models.AccidentPhoto, error) {
 	// Validate request
-	if err := req.Validate(); err != nil {
+	if err := req.Validate(s.uploadConfig.MaxFileSize); err != nil {
 		return nil, err
 	}
 
@@ -300,21 +332,15 @@ func (s *AccidentService) UploadAccidentPhoto(ctx context.Context, req *models.U
 		return nil, fmt.Errorf("accident non trouvé")
 	}
 
-	// Create photo
-	uploadedBy := userID
-	photo := &models.AccidentPhoto{
-		ID:              uuid.New().String(),
-		AccidentID:      req.AccidentID,
-		Filename:        req.FileName,
-		FileSize:        req.FileSize,
-		MimeType:        req.MimeType,
-		FileData:        req.FileData,
-		CompressionType: "gzip",
-		Description:     req.Description,
-		UploadedAt:      time.Now(),
-		UploadedBy:      &uploadedBy,
+	// Sniff the real content type, strip EXIF and generate variants
+	processed, err := imaging.Process(req.FileData)
+	if err != nil {
+		return nil, fmt.Errorf("image invalide: %w", err)
 	}
 
+	// Create photo
+	photo := NewAccidentPhoto(req.AccidentID, req.FileName, processed, req.Description, userID)
+
 	if err := s.accidentPhotoRepo.Create(ctx, photo); err != nil {
 		return nil, fmt.Errorf("échec de l'upload de la photo: %w", err)
 	}
@@ -322,8 +348,8 @@ func (s *AccidentService) UploadAccidentPhoto(ctx context.Context, req *models.U
 	// Log action
 	changes := map[string]interface{}{
 		"fileName": req.FileName,
-		"fileSize": req.FileSize,
-		"mimeType": req.MimeType,
+		"fileSize": photo.FileSize,
+		"mimeType": photo.MimeType,
 	}
 	changesJSON, _ := json.Marshal(changes)
 	log := &models.ActionLog{
@@ -5,7 +5,9 @@ import (
 	"time"
 
 	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/imaging"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Service tests for accident validation logic
@@ -287,3 +289,96 @@ func TestAccidentStatus_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestUploadPhotoRequest_Validate(t *testing.T) {
+	const maxSize = 10 << 20 // 10MB
+
+	validRequest := func() *models.UploadPhotoRequest {
+		return &models.UploadPhotoRequest{
+			AccidentID: "123e4567-e89b-12d3-a456-426614174000",
+			FileName:   "photo.jpg",
+			FileData:   []byte{0xFF, 0xD8},
+			FileSize:   2,
+			MimeType:   "image/jpeg",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(req *models.UploadPhotoRequest)
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "valid request",
+			modify:  func(req *models.UploadPhotoRequest) {},
+			wantErr: false,
+		},
+		{
+			name:    "file at the configured limit",
+			modify:  func(req *models.UploadPhotoRequest) { req.FileSize = maxSize },
+			wantErr: false,
+		},
+		{
+			name:    "file over the configured limit",
+			modify:  func(req *models.UploadPhotoRequest) { req.FileSize = maxSize + 1 },
+			wantErr: true,
+			errMsg:  "ne peut pas dépasser 10MB",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validRequest()
+			tt.modify(req)
+			err := req.Validate(maxSize)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errMsg != "" {
+					assert.Contains(t, err.Error(), tt.errMsg)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewAccidentPhoto(t *testing.T) {
+	latitude, longitude := 48.85667, 2.35222
+	takenAt := time.Date(2025, time.March, 3, 9, 30, 0, 0, time.UTC)
+	processed := &imaging.Result{
+		MimeType:  imaging.MimeTypePNG,
+		Data:      []byte("image"),
+		Width:     640,
+		Height:    480,
+		Thumbnail: []byte("thumb"),
+		Medium:    []byte("medium"),
+		Metadata:  imaging.Metadata{TakenAt: &takenAt, Latitude: &latitude, Longitude: &longitude},
+	}
+	description := "Front bumper"
+
+	photo := NewAccidentPhoto("accident-1", "bumper.png", processed, &description, "user-1")
+	assert.NotEmpty(t, photo.ID)
+	assert.Equal(t, "accident-1", photo.AccidentID)
+	assert.Equal(t, "bumper.png", photo.Filename)
+	assert.Equal(t, 5, photo.FileSize)
+	assert.Equal(t, imaging.MimeTypePNG, photo.MimeType)
+	assert.Equal(t, models.CompressionTypeGzip, photo.CompressionType)
+	require.NotNil(t, photo.UploadedBy)
+	assert.Equal(t, "user-1", *photo.UploadedBy)
+	assert.Equal(t, 640, *photo.Width)
+	assert.Equal(t, &takenAt, photo.TakenAt)
+	assert.Equal(t, &latitude, photo.Latitude)
+	assert.Equal(t, []byte("thumb"), photo.ThumbnailData)
+	assert.Equal(t, []byte("medium"), photo.MediumData)
+	require.NotNil(t, photo.VariantMimeType)
+	assert.Equal(t, imaging.VariantMimeType, *photo.VariantMimeType)
+}
+
+func TestFormatFileSize(t *testing.T) {
+	assert.Equal(t, "10MB", models.FormatFileSize(10<<20))
+	assert.Equal(t, "1.5MB", models.FormatFileSize(3<<19))
+	assert.Equal(t, "512KB", models.FormatFileSize(512<<10))
+	assert.Equal(t, "100 octets", models.FormatFileSize(100))
+}
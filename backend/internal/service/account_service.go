@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/pkg/mailer"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// AccountService handles employee invitations and password resets, where
+// employees set their own password through a link sent by email
+type AccountService struct {
+	userRepo       *repository.UserRepository
+	sessionRepo    *repository.SessionRepository
+	tokenRepo      *repository.AccountTokenRepository
+	actionLogRepo  *repository.ActionLogRepository
+	passwordPolicy *PasswordPolicy
+	mailer         mailer.Mailer
+	accountConfig  *config.AccountConfig
+}
+
+// NewAccountService creates a new account service
+func NewAccountService(
+	userRepo *repository.UserRepository,
+	sessionRepo *repository.SessionRepository,
+	tokenRepo *repository.AccountTokenRepository,
+	actionLogRepo *repository.ActionLogRepository,
+	passwordPolicy *PasswordPolicy,
+	mailer mailer.Mailer,
+	accountConfig *config.AccountConfig,
+) *AccountService {
+	return &AccountService{
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		tokenRepo:      tokenRepo,
+		actionLogRepo:  actionLogRepo,
+		passwordPolicy: passwordPolicy,
+		mailer:         mailer,
+		accountConfig:  accountConfig,
+	}
+}
+
+// InviteEmployee creates an employee without a usable password and emails
+// them a link to choose one
+func (s *AccountService) InviteEmployee(ctx context.Context, req models.InviteEmployeeRequest, performedBy string) (*EmployeeResponse, error) {
+	if err := ValidateEmail(req.Email); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.FirstName) == "" {
+		return nil, fmt.Errorf("first name is required")
+	}
+	if strings.TrimSpace(req.LastName) == "" {
+		return nil, fmt.Errorf("last name is required")
+	}
+
+	role := req.Role
+	if role == "" {
+		role = "admin"
+	}
+
+	// Nobody knows this password: the employee sets theirs from the invitation
+	placeholder, err := utils.GenerateSessionToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	passwordHash, err := utils.HashPassword(placeholder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	employee := &models.AdministrativeEmployee{
+		ID:           uuid.New().String(),
+		Email:        strings.ToLower(strings.TrimSpace(req.Email)),
+		PasswordHash: passwordHash,
+		FirstName:    strings.TrimSpace(req.FirstName),
+		LastName:     strings.TrimSpace(req.LastName),
+		Role:         role,
+		IsActive:     true,
+	}
+
+	if err := s.userRepo.Create(ctx, employee); err != nil {
+		return nil, err
+	}
+
+	s.logAction(ctx, employee.ID, models.ActionTypeCreate, performedBy, map[string]interface{}{
+		"employeeId": employee.ID,
+		"email":      employee.Email,
+		"firstName":  employee.FirstName,
+		"lastName":   employee.LastName,
+		"role":       employee.Role,
+		"invited":    true,
+	})
+
+	if err := s.sendInvitation(ctx, employee, performedBy); err != nil {
+		return nil, err
+	}
+
+	employee.PasswordHash = ""
+	return &EmployeeResponse{AdministrativeEmployee: employee}, nil
+}
+
+// ResendInvitation sends a new invitation link, invalidating the previous ones
+func (s *AccountService) ResendInvitation(ctx context.Context, employeeID, performedBy string) error {
+	if _, err := uuid.Parse(employeeID); err != nil {
+		return fmt.Errorf("invalid employee ID format")
+	}
+
+	employee, err := s.userRepo.FindByID(ctx, employeeID)
+	if err != nil {
+		return err
+	}
+
+	return s.sendInvitation(ctx, employee, performedBy)
+}
+
+// AcceptInvitation sets the password of an invited employee
+func (s *AccountService) AcceptInvitation(ctx context.Context, req models.SetPasswordRequest) error {
+	employeeID, err := s.setPassword(ctx, req, models.AccountTokenPurposeInvitation)
+	if err != nil {
+		return err
+	}
+
+	s.logAction(ctx, employeeID, models.ActionTypeInvitationAccepted, employeeID, map[string]interface{}{
+		"employeeId": employeeID,
+	})
+
+	return nil
+}
+
+// RequestPasswordReset emails a password reset link. Unknown or inactive
+// addresses are silently ignored so that the response does not reveal
+// which employees exist.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	if err := ValidateEmail(email); err != nil {
+		return err
+	}
+
+	employee, err := s.userRepo.FindByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return nil
+	}
+
+	token, err := s.createToken(ctx, employee.ID, models.AccountTokenPurposePasswordReset, s.accountConfig.PasswordResetTTL, nil)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(`Bonjour %s,
+
+Une réinitialisation de votre mot de passe AutoParc a été demandée.
+Pour choisir un nouveau mot de passe, ouvrez le lien suivant :
+
+%s
+
+Ce lien expire dans %s. Si vous n'êtes pas à l'origine de cette demande, ignorez ce message.
+`, employee.FirstName, s.link("/reset-password", token), formatTTL(s.accountConfig.PasswordResetTTL))
+
+	return s.send(ctx, employee.Email, "Réinitialisation de votre mot de passe AutoParc", body)
+}
+
+// ResetPassword sets a new password with a reset token. Existing sessions
+// are revoked and any lockout is lifted.
+func (s *AccountService) ResetPassword(ctx context.Context, req models.SetPasswordRequest) error {
+	employeeID, err := s.setPassword(ctx, req, models.AccountTokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := s.sessionRepo.DeleteByUserID(ctx, employeeID, "")
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.ResetFailedLogins(ctx, employeeID); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, employeeID, models.ActionTypePasswordReset, employeeID, map[string]interface{}{
+		"employeeId":      employeeID,
+		"revokedSessions": revoked,
+	})
+
+	return nil
+}
+
+// setPassword checks the new password against the policy, consumes the token
+// and stores the password. It returns the ID of the employee the token belongs to.
+func (s *AccountService) setPassword(ctx context.Context, req models.SetPasswordRequest, purpose models.AccountTokenPurpose) (string, error) {
+	if req.Token == "" {
+		return "", fmt.Errorf("invalid or expired token")
+	}
+
+	// Checked first so that a rejected password does not burn the token
+	if err := s.passwordPolicy.Validate(req.Password); err != nil {
+		return "", err
+	}
+
+	token, err := s.tokenRepo.FindValid(ctx, req.Token, purpose)
+	if err != nil {
+		return "", err
+	}
+
+	// Deactivated employees cannot use links sent before their deactivation
+	if _, err := s.userRepo.FindByID(ctx, token.UserID); err != nil {
+		return "", fmt.Errorf("invalid or expired token")
+	}
+
+	if err := s.passwordPolicy.CheckReuse(ctx, token.UserID, req.Password); err != nil {
+		return "", err
+	}
+
+	token, err = s.tokenRepo.Consume(ctx, req.Token, purpose)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.passwordPolicy.store(ctx, token.UserID, req.Password); err != nil {
+		return "", err
+	}
+
+	return token.UserID, nil
+}
+
+func (s *AccountService) sendInvitation(ctx context.Context, employee *models.AdministrativeEmployee, performedBy string) error {
+	token, err := s.createToken(ctx, employee.ID, models.AccountTokenPurposeInvitation, s.accountConfig.InvitationTTL, &performedBy)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(`Bonjour %s,
+
+Un compte AutoParc a été créé pour vous.
+Pour l'activer et choisir votre mot de passe, ouvrez le lien suivant :
+
+%s
+
+Ce lien expire dans %s.
+`, employee.FirstName, s.link("/accept-invitation", token), formatTTL(s.accountConfig.InvitationTTL))
+
+	if err := s.send(ctx, employee.Email, "Invitation à rejoindre AutoParc", body); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, employee.ID, models.ActionTypeInvite, performedBy, map[string]interface{}{
+		"employeeId": employee.ID,
+		"email":      employee.Email,
+	})
+
+	return nil
+}
+
+// createToken invalidates the pending tokens of the user for the purpose and
+// returns a new raw token
+func (s *AccountService) createToken(ctx context.Context, userID string, purpose models.AccountTokenPurpose, ttl time.Duration, createdBy *string) (string, error) {
+	if err := s.tokenRepo.InvalidateByUserID(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+
+	raw, err := utils.GenerateSessionToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	now := time.Now()
+	token := &models.AccountToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Purpose:   purpose,
+		Token:     raw,
+		ExpiresAt: now.Add(ttl),
+		CreatedBy: createdBy,
+		CreatedAt: now,
+	}
+
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return "", err
+	}
+
+	return raw, nil
+}
+
+func (s *AccountService) send(ctx context.Context, to, subject, body string) error {
+	if err := s.mailer.Send(ctx, mailer.Message{To: to, Subject: subject, Body: body}); err != nil {
+		log.Printf("Failed to send email to %s: %v", to, err)
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// link builds a frontend URL carrying the token
+func (s *AccountService) link(path, token string) string {
+	return strings.TrimSuffix(s.accountConfig.AppBaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+func (s *AccountService) logAction(ctx context.Context, employeeID string, actionType models.ActionType, performedBy string, changes map[string]interface{}) {
+	changesJSON, _ := json.Marshal(changes)
+	s.actionLogRepo.Create(ctx, &models.ActionLog{
+		ID:          uuid.New().String(),
+		EntityType:  models.EntityTypeAdministrativeEmployee,
+		EntityID:    employeeID,
+		ActionType:  actionType,
+		PerformedBy: performedBy,
+		Changes:     changesJSON,
+		Timestamp:   time.Now(),
+	})
+}
+
+// formatTTL formats a token lifetime in French, e.g. "72 heures"
+func formatTTL(ttl time.Duration) string {
+	if ttl >= time.Hour && ttl%time.Hour == 0 {
+		if hours := int(ttl / time.Hour); hours > 1 {
+			return fmt.Sprintf("%d heures", hours)
+		}
+		return "1 heure"
+	}
+	return fmt.Sprintf("%d minutes", int(ttl.Round(time.Minute)/time.Minute))
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// Service tests for invitation and password reset helpers
+
+func TestFormatTTL(t *testing.T) {
+	assert.Equal(t, "72 heures", formatTTL(72*time.Hour))
+	assert.Equal(t, "1 heure", formatTTL(time.Hour))
+	assert.Equal(t, "30 minutes", formatTTL(30*time.Minute))
+	assert.Equal(t, "90 minutes", formatTTL(90*time.Minute))
+}
+
+func TestAccountService_Link(t *testing.T) {
+	s := &AccountService{accountConfig: &config.AccountConfig{AppBaseURL: "https://autoparc.example.com/"}}
+
+	assert.Equal(t,
+		"https://autoparc.example.com/reset-password?token=abc-_%3D",
+		s.link("/reset-password", "abc-_="),
+	)
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// apiTokenPrefixLength is the number of leading characters of a token kept in
+// clear to help employees recognise it
+const apiTokenPrefixLength = 12
+
+// APITokenService handles the personal access tokens used by machine clients
+type APITokenService struct {
+	userRepo      *repository.UserRepository
+	tokenRepo     *repository.APITokenRepository
+	actionLogRepo *repository.ActionLogRepository
+}
+
+// NewAPITokenService creates a new API token service
+func NewAPITokenService(
+	userRepo *repository.UserRepository,
+	tokenRepo *repository.APITokenRepository,
+	actionLogRepo *repository.ActionLogRepository,
+) *APITokenService {
+	return &APITokenService{
+		userRepo:      userRepo,
+		tokenRepo:     tokenRepo,
+		actionLogRepo: actionLogRepo,
+	}
+}
+
+// Create creates an API token for the user. The raw token is only returned
+// here and cannot be retrieved afterwards.
+func (s *APITokenService) Create(ctx context.Context, userID string, req models.CreateAPITokenRequest) (*models.APIToken, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if len(name) > 100 {
+		return nil, fmt.Errorf("name must not exceed 100 characters")
+	}
+
+	scopes, err := normalizeAPIScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("expiry must be in the future")
+	}
+
+	raw, err := utils.GenerateSessionToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api token: %w", err)
+	}
+	raw = models.APITokenPrefix + raw
+
+	token := &models.APIToken{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		Name:        name,
+		Token:       raw,
+		TokenPrefix: raw[:apiTokenPrefixLength],
+		Scopes:      scopes,
+		ExpiresAt:   req.ExpiresAt,
+		CreatedAt:   now,
+	}
+
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return nil, err
+	}
+
+	s.logAction(ctx, userID, models.ActionTypeAPITokenCreate, userID, map[string]interface{}{
+		"apiTokenId": token.ID,
+		"name":       token.Name,
+		"scopes":     token.Scopes,
+		"expiresAt":  token.ExpiresAt,
+	})
+
+	return token, nil
+}
+
+// List returns the API tokens of a user
+func (s *APITokenService) List(ctx context.Context, userID string) ([]*models.APIToken, error) {
+	return s.tokenRepo.FindByUserID(ctx, userID)
+}
+
+// Revoke revokes an API token of a user
+func (s *APITokenService) Revoke(ctx context.Context, userID, id string) error {
+	if err := s.tokenRepo.Revoke(ctx, userID, id); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, userID, models.ActionTypeAPITokenRevoke, userID, map[string]interface{}{
+		"apiTokenId": id,
+	})
+
+	return nil
+}
+
+// Authenticate validates a raw API token and returns the token and its owner.
+// Use is recorded at most once per touch interval to spare the database.
+func (s *APITokenService) Authenticate(ctx context.Context, rawToken, ipAddress string) (*models.AdministrativeEmployee, *models.APIToken, error) {
+	if !strings.HasPrefix(rawToken, models.APITokenPrefix) {
+		return nil, nil, fmt.Errorf("invalid or expired api token")
+	}
+
+	token, err := s.tokenRepo.FindByToken(ctx, rawToken)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid or expired api token")
+	}
+
+	// Tokens of deactivated employees stop working with their sessions
+	user, err := s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("user not found")
+	}
+
+	now := time.Now()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= sessionTouchInterval ||
+		token.LastUsedIP == nil || *token.LastUsedIP != ipAddress {
+		if err := s.tokenRepo.Touch(ctx, token.ID, now, ipAddress); err != nil {
+			return nil, nil, err
+		}
+		token.LastUsedAt = &now
+		token.LastUsedIP = &ipAddress
+	}
+
+	return user, token, nil
+}
+
+// normalizeAPIScopes validates scopes and removes duplicates
+func normalizeAPIScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !models.IsValidAPIScope(scope) {
+			return nil, fmt.Errorf("invalid scope: %s", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+
+	return normalized, nil
+}
+
+func (s *APITokenService) logAction(ctx context.Context, employeeID string, actionType models.ActionType, performedBy string, changes map[string]interface{}) {
+	changesJSON, _ := json.Marshal(changes)
+	s.actionLogRepo.Create(ctx, &models.ActionLog{
+		ID:          uuid.New().String(),
+		EntityType:  models.EntityTypeAdministrativeEmployee,
+		EntityID:    employeeID,
+		ActionType:  actionType,
+		PerformedBy: performedBy,
+		Changes:     changesJSON,
+		Timestamp:   time.Now(),
+	})
+}
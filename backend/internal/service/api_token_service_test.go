@@ -0,0 +1,64 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Service tests for API token scopes
+
+func TestNormalizeAPIScopes(t *testing.T) {
+	scopes, err := normalizeAPIScopes([]string{" Cars:Write", "read", "cars:write", "repairs:read"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cars:write", "read", "repairs:read"}, scopes)
+
+	_, err = normalizeAPIScopes(nil)
+	assert.EqualError(t, err, "at least one scope is required")
+
+	for _, scope := range []string{"write", "cars", "cars:delete", "auth:read", "sessions:write", "employees:read", "employees:write", ""} {
+		_, err = normalizeAPIScopes([]string{scope})
+		assert.Error(t, err, scope)
+	}
+}
+
+func TestAPIToken_Allows(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   []string
+		resource string
+		write    bool
+		want     bool
+	}{
+		{name: "global read", scopes: []string{"read"}, resource: "cars", want: true},
+		{name: "global read cannot write", scopes: []string{"read"}, resource: "cars", write: true, want: false},
+		{name: "resource read", scopes: []string{"cars:read"}, resource: "cars", want: true},
+		{name: "resource read cannot write", scopes: []string{"cars:read"}, resource: "cars", write: true, want: false},
+		{name: "write implies read", scopes: []string{"cars:write"}, resource: "cars", want: true},
+		{name: "resource write", scopes: []string{"cars:write"}, resource: "cars", write: true, want: true},
+		{name: "other resource", scopes: []string{"cars:write"}, resource: "repairs", want: false},
+		{name: "unknown resource", scopes: []string{"read"}, resource: "auth", want: false},
+		{name: "employees are never scoped", scopes: []string{"read"}, resource: "employees", want: false},
+		{name: "no scopes", scopes: nil, resource: "cars", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := &models.APIToken{Scopes: tt.scopes}
+			assert.Equal(t, tt.want, token.Allows(tt.resource, tt.write))
+		})
+	}
+}
+
+func TestAPIToken_IsExpired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	assert.False(t, (&models.APIToken{}).IsExpired(now))
+	assert.True(t, (&models.APIToken{ExpiresAt: &past}).IsExpired(now))
+	assert.False(t, (&models.APIToken{ExpiresAt: &future}).IsExpired(now))
+}
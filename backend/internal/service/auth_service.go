@@ -2,26 +2,68 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"log"
 	"time"
 
+	"github.com/goldenkiwi/autoparc/internal/config"
 	"github.com/goldenkiwi/autoparc/internal/models"
 	"github.com/goldenkiwi/autoparc/internal/repository"
 	"github.com/goldenkiwi/autoparc/pkg/utils"
 	"github.com/google/uuid"
 )
 
+// sessionTouchInterval limits how often session activity is written back to
+// the database, so that every request does not cause an UPDATE
+const sessionTouchInterval = time.Minute
+
+// dummyPasswordHash is checked against when the email has no account, so that
+// the bcrypt comparison takes as long as for an existing account
+const dummyPasswordHash = "$2a$12$E.KopBLP6SyW8bAPb0bTQONnVnxcFUifCFlT92wtpQL/0JExd1TtS"
+
+// LoginThrottledError is returned when a login attempt is rejected because
+// of too many recent failures, before the password is even checked
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return "too many failed login attempts, please retry later"
+}
+
 // AuthService handles authentication business logic
 type AuthService struct {
-	userRepo    *repository.UserRepository
-	sessionRepo *repository.SessionRepository
+	userRepo         *repository.UserRepository
+	sessionRepo      *repository.SessionRepository
+	loginAttemptRepo *repository.LoginAttemptRepository
+	actionLogRepo    *repository.ActionLogRepository
+	loginConfig      *config.LoginConfig
+	sessionConfig    *config.SessionConfig
+	twoFactorConfig  *config.TwoFactorConfig
+	passwordPolicy   *PasswordPolicy
 }
 
 // NewAuthService creates a new auth service
-func NewAuthService(userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository) *AuthService {
+func NewAuthService(
+	userRepo *repository.UserRepository,
+	sessionRepo *repository.SessionRepository,
+	loginAttemptRepo *repository.LoginAttemptRepository,
+	actionLogRepo *repository.ActionLogRepository,
+	loginConfig *config.LoginConfig,
+	sessionConfig *config.SessionConfig,
+	twoFactorConfig *config.TwoFactorConfig,
+	passwordPolicy *PasswordPolicy,
+) *AuthService {
 	return &AuthService{
-		userRepo:    userRepo,
-		sessionRepo: sessionRepo,
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		actionLogRepo:    actionLogRepo,
+		loginConfig:      loginConfig,
+		sessionConfig:    sessionConfig,
+		twoFactorConfig:  twoFactorConfig,
+		passwordPolicy:   passwordPolicy,
 	}
 }
 
@@ -36,36 +78,88 @@ func (s *AuthService) Login(ctx context.Context, email, password, ipAddress, use
 		return nil, nil, fmt.Errorf("password is required")
 	}
 
+	now := time.Now()
+
+	// Reject addresses with too many recent failures without hashing anything
+	if s.loginConfig.IPMaxFailedAttempts > 0 {
+		failures, err := s.loginAttemptRepo.CountFailuresByIP(ctx, ipAddress, now.Add(-s.loginConfig.IPWindow))
+		if err != nil {
+			return nil, nil, err
+		}
+		if failures >= s.loginConfig.IPMaxFailedAttempts {
+			return nil, nil, &LoginThrottledError{RetryAfter: s.loginConfig.IPWindow}
+		}
+	}
+
 	// Find user by email
 	user, err := s.userRepo.FindByEmail(ctx, email)
 	if err != nil {
+		// Unknown emails are throttled and take as long to check as
+		// accounts, so that the response does not reveal which emails have one
+		if err := s.throttleUnknownEmail(ctx, email, now); err != nil {
+			return nil, nil, err
+		}
+		utils.CheckPassword(dummyPasswordHash, password)
+		s.recordAttempt(ctx, email, ipAddress, userAgent, false)
 		return nil, nil, fmt.Errorf("invalid credentials")
 	}
 
+	if user.IsLocked(now) {
+		return nil, nil, &LoginThrottledError{RetryAfter: user.LockedUntil.Sub(now)}
+	}
+	if user.LockedUntil != nil {
+		// The lock has expired: start counting failures again
+		if err := s.userRepo.ResetFailedLogins(ctx, user.ID); err != nil {
+			return nil, nil, err
+		}
+		user.FailedLoginCount = 0
+		user.LastFailedLoginAt = nil
+	}
+
+	if user.LastFailedLoginAt != nil {
+		delay := LoginDelay(user.FailedLoginCount, s.loginConfig)
+		if retryAt := user.LastFailedLoginAt.Add(delay); retryAt.After(now) {
+			return nil, nil, &LoginThrottledError{RetryAfter: retryAt.Sub(now)}
+		}
+	}
+
 	// Check password
 	if !utils.CheckPassword(user.PasswordHash, password) {
+		s.recordFailedLogin(ctx, user, ipAddress, userAgent)
 		return nil, nil, fmt.Errorf("invalid credentials")
 	}
 
-	// Generate session token
-	token, err := utils.GenerateSessionToken()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to generate session token: %w", err)
+	if user.FailedLoginCount > 0 {
+		if err := s.userRepo.ResetFailedLogins(ctx, user.ID); err != nil {
+			return nil, nil, err
+		}
 	}
+	s.recordAttempt(ctx, email, ipAddress, userAgent, true)
 
-	// Create session
-	session := &models.Session{
-		ID:           uuid.New().String(),
-		UserID:       user.ID,
-		SessionToken: token,
-		ExpiresAt:    time.Now().Add(24 * time.Hour),
-		IPAddress:    ipAddress,
-		UserAgent:    userAgent,
-		CreatedAt:    time.Now(),
+	// An expired password only lets the employee choose a new one
+	session, err := s.startSession(ctx, user, ipAddress, userAgent, s.passwordPolicy.Expired(user, now))
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if err := s.sessionRepo.Create(ctx, session); err != nil {
-		return nil, nil, fmt.Errorf("failed to create session: %w", err)
+	return user, session, nil
+}
+
+// StartSession opens a session for a user whose identity has been verified,
+// by password or single sign-on. Employees with 2FA, or whose role requires
+// it, get a session that only allows verifying the code or enrolling.
+func (s *AuthService) StartSession(ctx context.Context, user *models.AdministrativeEmployee, ipAddress, userAgent string) (*models.Session, error) {
+	// Single sign-on employees do not use their AutoParc password, which
+	// therefore never expires for them
+	return s.startSession(ctx, user, ipAddress, userAgent, false)
+}
+
+func (s *AuthService) startSession(ctx context.Context, user *models.AdministrativeEmployee, ipAddress, userAgent string, passwordChangeRequired bool) (*models.Session, error) {
+	mfaPending := user.TwoFactorEnabled || s.TwoFactorRequired(user.Role)
+
+	session, err := s.createSession(ctx, user.ID, ipAddress, userAgent, mfaPending, passwordChangeRequired)
+	if err != nil {
+		return nil, err
 	}
 
 	// Update last login
@@ -74,7 +168,200 @@ func (s *AuthService) Login(ctx context.Context, email, password, ipAddress, use
 		// In production, you would use a proper logger here
 	}
 
-	return user, session, nil
+	return session, nil
+}
+
+// TwoFactorRequired reports whether employees with the given role must use 2FA
+func (s *AuthService) TwoFactorRequired(role string) bool {
+	for _, required := range s.twoFactorConfig.RequiredRoles {
+		if role == required {
+			return true
+		}
+	}
+	return false
+}
+
+// PromoteSession replaces a session awaiting the second factor with a fully
+// authenticated one. The token changes so that a token captured before the
+// second factor is worthless.
+func (s *AuthService) PromoteSession(ctx context.Context, pending *models.Session) (*models.Session, error) {
+	session, err := s.createSession(ctx, pending.UserID, pending.IPAddress, pending.UserAgent, false, pending.PasswordChangeRequired)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionRepo.DeleteByID(ctx, pending.UserID, pending.ID); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// RecordTwoFactorFailure counts a wrong second factor like a wrong password,
+// so that codes cannot be brute-forced past the account lockout
+func (s *AuthService) RecordTwoFactorFailure(ctx context.Context, user *models.AdministrativeEmployee, ipAddress, userAgent string) {
+	s.recordFailedLogin(ctx, user, ipAddress, userAgent)
+}
+
+// createSession creates a session for the user. Sessions awaiting the second
+// factor are short-lived and cannot be extended.
+func (s *AuthService) createSession(ctx context.Context, userID, ipAddress, userAgent string, mfaPending, passwordChangeRequired bool) (*models.Session, error) {
+	token, err := utils.GenerateSessionToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate session token: %w", err)
+	}
+
+	now := time.Now()
+	lifetime := s.sessionConfig.AbsoluteTimeout
+	if mfaPending {
+		lifetime = s.twoFactorConfig.PendingTimeout
+	}
+
+	session := &models.Session{
+		ID:                uuid.New().String(),
+		UserID:            userID,
+		SessionToken:      token,
+		AbsoluteExpiresAt: now.Add(lifetime),
+		LastSeenAt:        now,
+		IPAddress:         ipAddress,
+		UserAgent:         userAgent,
+		CreatedAt:         now,
+		MFAPending:        mfaPending,
+
+		PasswordChangeRequired: passwordChangeRequired,
+	}
+	session.ExpiresAt = session.SlidingExpiry(now, s.sessionConfig.IdleTimeout)
+
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return session, nil
+}
+
+// UnlockAccount clears the lock and failed login counter of an employee
+func (s *AuthService) UnlockAccount(ctx context.Context, employeeID, performedBy string) error {
+	employee, err := s.userRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.ResetFailedLogins(ctx, employeeID); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, employeeID, models.ActionTypeUnlock, performedBy, map[string]interface{}{
+		"lockedUntil": employee.LockedUntil,
+	})
+
+	return nil
+}
+
+// LoginDelay returns the delay imposed before the next login attempt after
+// the given number of consecutive failures. The first ThrottleAfter failures
+// are free, then the delay doubles with each failure up to MaxDelay.
+func LoginDelay(failures int, cfg *config.LoginConfig) time.Duration {
+	excess := failures - cfg.ThrottleAfter
+	if excess <= 0 || cfg.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := cfg.BaseDelay
+	for i := 1; i < excess && delay < cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > cfg.MaxDelay {
+		delay = cfg.MaxDelay
+	}
+	return delay
+}
+
+// LoginAttemptRetention returns how long login attempts are kept: the
+// configured retention, but never less than what throttling looks back on
+func LoginAttemptRetention(cfg *config.LoginConfig) time.Duration {
+	retention := cfg.AttemptRetention
+	if cfg.LockoutDuration > retention {
+		retention = cfg.LockoutDuration
+	}
+	if cfg.IPWindow > retention {
+		retention = cfg.IPWindow
+	}
+	return retention
+}
+
+// recordFailedLogin increments the failure counter of the user, locking the
+// account when the limit is reached, and writes the audit entries
+func (s *AuthService) recordFailedLogin(ctx context.Context, user *models.AdministrativeEmployee, ipAddress, userAgent string) {
+	s.recordAttempt(ctx, user.Email, ipAddress, userAgent, false)
+
+	lockUntil := time.Now().Add(s.loginConfig.LockoutDuration)
+	failures, lockedUntil, err := s.userRepo.RecordFailedLogin(ctx, user.ID, s.loginConfig.MaxFailedAttempts, lockUntil)
+	if err != nil {
+		return
+	}
+
+	// Failed logins are attributed to the targeted account itself
+	s.logAction(ctx, user.ID, models.ActionTypeLoginFailed, user.ID, map[string]interface{}{
+		"ipAddress":      ipAddress,
+		"userAgent":      userAgent,
+		"failedAttempts": failures,
+	})
+
+	if lockedUntil != nil && failures == s.loginConfig.MaxFailedAttempts {
+		s.logAction(ctx, user.ID, models.ActionTypeLock, user.ID, map[string]interface{}{
+			"ipAddress":      ipAddress,
+			"failedAttempts": failures,
+			"lockedUntil":    lockedUntil,
+		})
+	}
+}
+
+// throttleUnknownEmail applies the account lockout and delays to an email
+// without an account. Its failures are counted from the recorded attempts
+// within the lockout duration, since there is no account to hold a counter.
+func (s *AuthService) throttleUnknownEmail(ctx context.Context, email string, now time.Time) error {
+	failures, lastFailure, err := s.loginAttemptRepo.CountFailuresByEmail(ctx, email, now.Add(-s.loginConfig.LockoutDuration))
+	if err != nil {
+		return err
+	}
+	if lastFailure == nil {
+		return nil
+	}
+
+	if s.loginConfig.MaxFailedAttempts > 0 && failures >= s.loginConfig.MaxFailedAttempts {
+		return &LoginThrottledError{RetryAfter: lastFailure.Add(s.loginConfig.LockoutDuration).Sub(now)}
+	}
+
+	delay := LoginDelay(failures, s.loginConfig)
+	if retryAt := lastFailure.Add(delay); retryAt.After(now) {
+		return &LoginThrottledError{RetryAfter: retryAt.Sub(now)}
+	}
+	return nil
+}
+
+func (s *AuthService) recordAttempt(ctx context.Context, email, ipAddress, userAgent string, succeeded bool) {
+	s.loginAttemptRepo.Create(ctx, &models.LoginAttempt{
+		ID:          uuid.New().String(),
+		Email:       email,
+		IPAddress:   ipAddress,
+		UserAgent:   userAgent,
+		Succeeded:   succeeded,
+		AttemptedAt: time.Now(),
+	})
+}
+
+func (s *AuthService) logAction(ctx context.Context, employeeID string, actionType models.ActionType, performedBy string, changes map[string]interface{}) {
+	changesJSON, _ := json.Marshal(changes)
+	log := &models.ActionLog{
+		ID:          uuid.New().String(),
+		EntityType:  models.EntityTypeAdministrativeEmployee,
+		EntityID:    employeeID,
+		ActionType:  actionType,
+		PerformedBy: performedBy,
+		Changes:     changesJSON,
+		Timestamp:   time.Now(),
+	}
+	s.actionLogRepo.Create(ctx, log)
 }
 
 // Logout invalidates a user session
@@ -92,21 +379,115 @@ func (s *AuthService) Logout(ctx context.Context, token string) error {
 
 // ValidateSession validates a session token and returns the user
 func (s *AuthService) ValidateSession(ctx context.Context, token string) (*models.AdministrativeEmployee, error) {
+	user, _, err := s.AuthenticateSession(ctx, token)
+	return user, err
+}
+
+// AuthenticateSession validates a session token and returns the user and the
+// session. Activity slides the session expiry by the idle timeout, without
+// ever going past its absolute expiry.
+func (s *AuthService) AuthenticateSession(ctx context.Context, token string) (*models.AdministrativeEmployee, *models.Session, error) {
 	if token == "" {
-		return nil, fmt.Errorf("session token is required")
+		return nil, nil, fmt.Errorf("session token is required")
 	}
 
 	// Find session
 	session, err := s.sessionRepo.FindByToken(ctx, token)
 	if err != nil {
-		return nil, fmt.Errorf("invalid or expired session")
+		return nil, nil, fmt.Errorf("invalid or expired session")
 	}
 
 	// Get user
 	user, err := s.userRepo.FindByID(ctx, session.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("user not found")
+		return nil, nil, fmt.Errorf("user not found")
+	}
+
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval {
+		expiresAt := session.SlidingExpiry(now, s.sessionConfig.IdleTimeout)
+		if err := s.sessionRepo.Touch(ctx, session.ID, now, expiresAt); err != nil {
+			return nil, nil, err
+		}
+		session.LastSeenAt = now
+		session.ExpiresAt = expiresAt
+	}
+
+	return user, session, nil
+}
+
+// ListSessions returns the active sessions of a user, flagging currentID
+func (s *AuthService) ListSessions(ctx context.Context, userID, currentID string) ([]*models.Session, error) {
+	sessions, err := s.sessionRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == currentID
 	}
 
-	return user, nil
+	return sessions, nil
+}
+
+// RevokeSession deletes one of the sessions of a user
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	return s.sessionRepo.DeleteByID(ctx, userID, sessionID)
+}
+
+// RevokeOtherSessions deletes every session of a user except the current one
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, userID, currentID string) (int64, error) {
+	return s.sessionRepo.DeleteByUserID(ctx, userID, currentID)
+}
+
+// RevokeAllSessions deletes every session of an employee on behalf of an
+// administrator
+func (s *AuthService) RevokeAllSessions(ctx context.Context, employeeID, performedBy string) (int64, error) {
+	if _, err := s.userRepo.GetByID(ctx, employeeID); err != nil {
+		return 0, err
+	}
+
+	revoked, err := s.sessionRepo.DeleteByUserID(ctx, employeeID, "")
+	if err != nil {
+		return 0, err
+	}
+
+	s.logAction(ctx, employeeID, models.ActionTypeRevokeSessions, performedBy, map[string]interface{}{
+		"revokedSessions": revoked,
+	})
+
+	return revoked, nil
+}
+
+// StartSessionJanitor purges expired sessions and old login attempts every
+// CleanupInterval until ctx is cancelled
+func (s *AuthService) StartSessionJanitor(ctx context.Context) {
+	if s.sessionConfig.CleanupInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.sessionConfig.CleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				deleted, err := s.sessionRepo.DeleteExpired(ctx)
+				if err != nil {
+					log.Printf("Failed to purge expired sessions: %v", err)
+					continue
+				}
+				if deleted > 0 {
+					log.Printf("Purged %d expired sessions", deleted)
+				}
+
+				if err := s.loginAttemptRepo.DeleteBefore(ctx, time.Now().Add(-LoginAttemptRetention(s.loginConfig))); err != nil {
+					log.Printf("Failed to purge login attempts: %v", err)
+				}
+			}
+		}
+	}()
 }
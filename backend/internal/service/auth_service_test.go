@@ -0,0 +1,95 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Service tests for login throttling and session expiry logic
+
+func TestLoginDelay(t *testing.T) {
+	cfg := &config.LoginConfig{
+		ThrottleAfter: 3,
+		BaseDelay:     time.Second,
+		MaxDelay:      30 * time.Second,
+	}
+
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{name: "no failure", failures: 0, want: 0},
+		{name: "free failures", failures: 3, want: 0},
+		{name: "first throttled failure", failures: 4, want: time.Second},
+		{name: "doubles", failures: 5, want: 2 * time.Second},
+		{name: "keeps doubling", failures: 8, want: 16 * time.Second},
+		{name: "capped", failures: 9, want: 30 * time.Second},
+		{name: "stays capped", failures: 100, want: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, LoginDelay(tt.failures, cfg))
+		})
+	}
+}
+
+func TestLoginDelay_Disabled(t *testing.T) {
+	cfg := &config.LoginConfig{ThrottleAfter: 0, MaxDelay: time.Minute}
+	assert.Equal(t, time.Duration(0), LoginDelay(10, cfg))
+}
+
+func TestAdministrativeEmployee_IsLocked(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	assert.False(t, (&models.AdministrativeEmployee{}).IsLocked(now))
+	assert.False(t, (&models.AdministrativeEmployee{LockedUntil: &past}).IsLocked(now))
+	assert.True(t, (&models.AdministrativeEmployee{LockedUntil: &future}).IsLocked(now))
+}
+
+func TestLoginAttemptRetention(t *testing.T) {
+	cfg := &config.LoginConfig{LockoutDuration: 15 * time.Minute, IPWindow: time.Hour, AttemptRetention: 24 * time.Hour}
+	assert.Equal(t, 24*time.Hour, LoginAttemptRetention(cfg))
+
+	cfg.AttemptRetention = 0
+	assert.Equal(t, time.Hour, LoginAttemptRetention(cfg))
+
+	cfg.LockoutDuration = 2 * time.Hour
+	assert.Equal(t, 2*time.Hour, LoginAttemptRetention(cfg))
+}
+
+func TestLoginThrottledError(t *testing.T) {
+	err := &LoginThrottledError{RetryAfter: 5 * time.Second}
+	assert.Contains(t, err.Error(), "too many failed login attempts")
+}
+
+func TestDummyPasswordHash_MatchesPasswordCost(t *testing.T) {
+	hash, err := utils.HashPassword("Password123")
+	require.NoError(t, err)
+
+	want, err := bcrypt.Cost([]byte(hash))
+	require.NoError(t, err)
+	got, err := bcrypt.Cost([]byte(dummyPasswordHash))
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.False(t, utils.CheckPassword(dummyPasswordHash, "Password123"))
+}
+
+func TestSession_SlidingExpiry(t *testing.T) {
+	now := time.Now()
+	session := &models.Session{AbsoluteExpiresAt: now.Add(3 * time.Hour)}
+
+	assert.Equal(t, now.Add(2*time.Hour), session.SlidingExpiry(now, 2*time.Hour))
+	assert.Equal(t, session.AbsoluteExpiresAt, session.SlidingExpiry(now.Add(2*time.Hour), 2*time.Hour))
+	assert.Equal(t, session.AbsoluteExpiresAt, session.SlidingExpiry(now, 0))
+}
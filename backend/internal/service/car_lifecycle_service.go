@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/google/uuid"
+)
+
+// CarLifecycleService handles the lifecycle events and the timeline of cars
+type CarLifecycleService struct {
+	carRepo       *repository.CarRepository
+	operatorRepo  *repository.OperatorRepository
+	accidentRepo  *repository.AccidentRepository
+	repairRepo    *repository.RepairRepository
+	actionLogRepo *repository.ActionLogRepository
+}
+
+// NewCarLifecycleService creates a new car lifecycle service
+func NewCarLifecycleService(
+	carRepo *repository.CarRepository,
+	operatorRepo *repository.OperatorRepository,
+	accidentRepo *repository.AccidentRepository,
+	repairRepo *repository.RepairRepository,
+	actionLogRepo *repository.ActionLogRepository,
+) *CarLifecycleService {
+	return &CarLifecycleService{
+		carRepo:       carRepo,
+		operatorRepo:  operatorRepo,
+		accidentRepo:  accidentRepo,
+		repairRepo:    repairRepo,
+		actionLogRepo: actionLogRepo,
+	}
+}
+
+// RecordEvent records a step in the life of a car and sets the car to the
+// resulting status. Events other than the acquisition cannot predate the
+// last status change, so the history stays in order. The acquisition is
+// recorded when the car is entered: recording it again fills in its details.
+func (s *CarLifecycleService) RecordEvent(ctx context.Context, carID string, req *models.CreateCarLifecycleEventRequest, userID string) (*models.CarLifecycleEvent, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	car, err := s.carRepo.FindByID(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+
+	eventDate := time.Now()
+	if req.EventDate != nil {
+		eventDate = *req.EventDate
+	}
+	if eventDate.After(time.Now()) {
+		return nil, fmt.Errorf("event date cannot be in the future")
+	}
+
+	event, err := newLifecycleEvent(car, req.Type, eventDate, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.carRepo.FindLifecycleEvents(ctx, car.ID)
+	if err != nil {
+		return nil, err
+	}
+	var acquisition *models.CarLifecycleEvent
+	if req.Type == models.CarEventAcquisition {
+		acquisition = findAcquisition(events)
+	} else if last := lastStatusChange(events); last != nil && eventDate.Before(last.EventDate) {
+		return nil, fmt.Errorf("event date cannot be before the last status change on %s", last.EventDate.Format("2006-01-02"))
+	}
+
+	if req.AccidentID != nil {
+		accident, err := s.accidentRepo.FindByID(ctx, *req.AccidentID)
+		if err != nil {
+			return nil, err
+		}
+		if accident.CarID != car.ID {
+			return nil, fmt.Errorf("accident does not involve this car")
+		}
+	}
+
+	event.Reason = req.Reason
+	event.AccidentID = req.AccidentID
+	event.Notes = req.Notes
+	if req.Price != nil {
+		price := *req.Price
+		if price.Currency == "" {
+			price.Currency = money.DefaultCurrency
+		}
+		event.Price = &price
+	}
+	if req.Counterparty != nil {
+		counterparty := strings.TrimSpace(*req.Counterparty)
+		event.Counterparty = &counterparty
+	}
+
+	if acquisition != nil {
+		event.ID = acquisition.ID
+		event.PreviousStatus = acquisition.PreviousStatus
+		event.Status = acquisition.Status
+		event.CreatedAt = acquisition.CreatedAt
+		event.CreatedBy = acquisition.CreatedBy
+		if err := s.carRepo.UpdateLifecycleEvent(ctx, event); err != nil {
+			return nil, err
+		}
+	} else if err := s.carRepo.ApplyLifecycleEvent(ctx, event); err != nil {
+		return nil, err
+	}
+
+	s.logAction(ctx, car.ID, userID, event)
+	return event, nil
+}
+
+// GetEvents retrieves the lifecycle events of a car, oldest first
+func (s *CarLifecycleService) GetEvents(ctx context.Context, carID string) ([]*models.CarLifecycleEvent, error) {
+	if _, err := s.carRepo.FindByID(ctx, carID); err != nil {
+		return nil, err
+	}
+	return s.carRepo.FindLifecycleEvents(ctx, carID)
+}
+
+// GetTimeline merges the lifecycle events, operator assignments, accidents
+// and repairs of a car into its history, oldest first
+func (s *CarLifecycleService) GetTimeline(ctx context.Context, carID string) ([]models.CarTimelineEntry, error) {
+	if _, err := s.carRepo.FindByID(ctx, carID); err != nil {
+		return nil, err
+	}
+
+	events, err := s.carRepo.FindLifecycleEvents(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := s.operatorRepo.FindAssignmentHistory(ctx, &models.AssignmentFilters{CarID: &carID})
+	if err != nil {
+		return nil, err
+	}
+	accidents, err := s.accidentRepo.FindAll(ctx, map[string]interface{}{"car_id": carID})
+	if err != nil {
+		return nil, err
+	}
+	repairs, err := s.repairRepo.FindAll(ctx, map[string]interface{}{"car_id": carID})
+	if err != nil {
+		return nil, err
+	}
+
+	operatorNames := make(map[string]string)
+	for _, assignment := range assignments {
+		if _, ok := operatorNames[assignment.OperatorID]; ok {
+			continue
+		}
+		operator, err := s.operatorRepo.FindByID(ctx, assignment.OperatorID)
+		if err != nil {
+			return nil, err
+		}
+		operatorNames[assignment.OperatorID] = operator.FirstName + " " + operator.LastName
+	}
+
+	return buildCarTimeline(events, assignments, accidents, repairs, operatorNames, time.Now()), nil
+}
+
+// buildCarTimeline merges the records of a car into timeline entries sorted
+// by date. Entries of the same date keep the order of the lifecycle events
+// first, then assignments, accidents and repairs. Repairs only end once
+// completed, and cancelled repairs are left out.
+func buildCarTimeline(
+	events []*models.CarLifecycleEvent,
+	assignments []models.CarOperatorAssignment,
+	accidents []*models.Accident,
+	repairs []*models.Repair,
+	operatorNames map[string]string,
+	now time.Time,
+) []models.CarTimelineEntry {
+	entries := []models.CarTimelineEntry{}
+
+	for _, event := range events {
+		entries = append(entries, models.CarTimelineEntry{
+			Date:     event.EventDate,
+			Type:     models.CarTimelineLifecycle,
+			Summary:  lifecycleSummary(event),
+			EntityID: event.ID,
+			Details:  event,
+		})
+	}
+
+	for i := range assignments {
+		assignment := &assignments[i]
+		name := operatorNames[assignment.OperatorID]
+		if name == "" {
+			name = assignment.OperatorID
+		}
+		entries = append(entries, models.CarTimelineEntry{
+			Date:     assignment.StartDate,
+			Type:     models.CarTimelineAssignmentStart,
+			Summary:  "Assigned to " + name,
+			EntityID: assignment.ID,
+			Details:  assignment,
+		})
+		if assignment.EndDate != nil && !assignment.EndDate.After(now) {
+			entries = append(entries, models.CarTimelineEntry{
+				Date:     *assignment.EndDate,
+				Type:     models.CarTimelineAssignmentEnd,
+				Summary:  "Unassigned from " + name,
+				EntityID: assignment.ID,
+				Details:  assignment,
+			})
+		}
+	}
+
+	for _, accident := range accidents {
+		entries = append(entries, models.CarTimelineEntry{
+			Date:     accident.AccidentDate,
+			Type:     models.CarTimelineAccident,
+			Summary:  fmt.Sprintf("Accident at %s: %s", accident.Location, accident.Description),
+			EntityID: accident.ID,
+			Details:  accident,
+		})
+	}
+
+	for _, repair := range repairs {
+		if repair.Status == models.RepairStatusCancelled {
+			continue
+		}
+		entries = append(entries, models.CarTimelineEntry{
+			Date:     repair.StartDate,
+			Type:     models.CarTimelineRepairStart,
+			Summary:  fmt.Sprintf("%s repair started: %s", capitalize(string(repair.RepairType)), repair.Description),
+			EntityID: repair.ID,
+			Details:  repair,
+		})
+		if repair.Status == models.RepairStatusCompleted && repair.EndDate != nil {
+			entries = append(entries, models.CarTimelineEntry{
+				Date:     *repair.EndDate,
+				Type:     models.CarTimelineRepairEnd,
+				Summary:  fmt.Sprintf("%s repair completed: %s", capitalize(string(repair.RepairType)), repair.Description),
+				EntityID: repair.ID,
+				Details:  repair,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+	return entries
+}
+
+// lifecycleSummary describes a lifecycle event in a timeline entry
+func lifecycleSummary(event *models.CarLifecycleEvent) string {
+	summary := capitalize(string(event.Type))
+	if event.Reason != nil {
+		summary += " (" + strings.ReplaceAll(string(*event.Reason), "_", " ") + ")"
+	}
+	if event.Counterparty != nil {
+		switch event.Type {
+		case models.CarEventAcquisition:
+			summary += " from " + *event.Counterparty
+		default:
+			summary += " to " + *event.Counterparty
+		}
+	}
+	if event.Price != nil {
+		summary += " for " + event.Price.String()
+	}
+	if event.StatusChanged() {
+		summary += fmt.Sprintf(", status %s to %s", event.PreviousStatus, event.Status)
+	}
+	return summary
+}
+
+// capitalize upper-cases the first letter of an identifier
+func capitalize(value string) string {
+	if value == "" {
+		return value
+	}
+	return strings.ToUpper(value[:1]) + value[1:]
+}
+
+// lastStatusChange returns the latest event that changed the status of the
+// car, nil if there is none
+func lastStatusChange(events []*models.CarLifecycleEvent) *models.CarLifecycleEvent {
+	var last *models.CarLifecycleEvent
+	for _, event := range events {
+		if event.StatusChanged() && (last == nil || !event.EventDate.Before(last.EventDate)) {
+			last = event
+		}
+	}
+	return last
+}
+
+// findAcquisition returns the acquisition of the car, nil if there is none
+func findAcquisition(events []*models.CarLifecycleEvent) *models.CarLifecycleEvent {
+	for _, event := range events {
+		if event.Type == models.CarEventAcquisition {
+			return event
+		}
+	}
+	return nil
+}
+
+// newLifecycleEvent builds an event of the given type for the car, checking
+// that it can happen to the car in its current status
+func newLifecycleEvent(car *models.Car, eventType models.CarEventType, eventDate time.Time, userID string) (*models.CarLifecycleEvent, error) {
+	if err := eventType.CheckTransition(car.Status); err != nil {
+		return nil, err
+	}
+
+	return &models.CarLifecycleEvent{
+		ID:             uuid.New().String(),
+		CarID:          car.ID,
+		Type:           eventType,
+		EventDate:      eventDate,
+		PreviousStatus: car.Status,
+		Status:         eventType.ResultingStatus(car.Status),
+		CreatedAt:      time.Now(),
+		CreatedBy:      &userID,
+	}, nil
+}
+
+func (s *CarLifecycleService) logAction(ctx context.Context, carID string, userID string, changes interface{}) {
+	changesJSON, _ := json.Marshal(changes)
+	log := &models.ActionLog{
+		ID:          uuid.New().String(),
+		EntityType:  models.EntityTypeCar,
+		EntityID:    carID,
+		ActionType:  models.ActionTypeLifecycleEvent,
+		PerformedBy: userID,
+		Changes:     changesJSON,
+		Timestamp:   time.Now(),
+	}
+	s.actionLogRepo.Create(ctx, log)
+}
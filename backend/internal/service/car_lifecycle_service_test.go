@@ -0,0 +1,121 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Service tests for car lifecycle logic
+
+func TestCreateCarLifecycleEventRequest_Validate(t *testing.T) {
+	breakdown := models.ImmobilizationReasonBreakdown
+	accident := models.ImmobilizationReasonAccident
+	unknown := models.ImmobilizationReason("flood")
+	price := eur("8500")
+	negative := eur("-1")
+	accidentID := "accident"
+
+	tests := []struct {
+		name    string
+		req     models.CreateCarLifecycleEventRequest
+		wantErr string
+	}{
+		{name: "acquisition with price and seller", req: models.CreateCarLifecycleEventRequest{Type: models.CarEventAcquisition, Price: &price, Counterparty: stringPtr("Garage Martin")}},
+		{name: "commissioning", req: models.CreateCarLifecycleEventRequest{Type: models.CarEventCommissioning}},
+		{name: "immobilization", req: models.CreateCarLifecycleEventRequest{Type: models.CarEventImmobilization, Reason: &breakdown}},
+		{name: "immobilization after an accident", req: models.CreateCarLifecycleEventRequest{Type: models.CarEventImmobilization, Reason: &accident, AccidentID: &accidentID}},
+		{name: "sale", req: models.CreateCarLifecycleEventRequest{Type: models.CarEventSale, Price: &price}},
+		{name: "return without price", req: models.CreateCarLifecycleEventRequest{Type: models.CarEventReturn, Counterparty: stringPtr("Leasys")}},
+		{name: "unknown type", req: models.CreateCarLifecycleEventRequest{Type: "theft"}, wantErr: "invalid event type"},
+		{name: "immobilization without reason", req: models.CreateCarLifecycleEventRequest{Type: models.CarEventImmobilization}, wantErr: "reason is required"},
+		{name: "unknown reason", req: models.CreateCarLifecycleEventRequest{Type: models.CarEventImmobilization, Reason: &unknown}, wantErr: "invalid immobilization reason"},
+		{name: "reason on a release", req: models.CreateCarLifecycleEventRequest{Type: models.CarEventRelease, Reason: &breakdown}, wantErr: "only given for an immobilization"},
+		{name: "accident on a breakdown", req: models.CreateCarLifecycleEventRequest{Type: models.CarEventImmobilization, Reason: &breakdown, AccidentID: &accidentID}, wantErr: "after an accident"},
+		{name: "sale without price", req: models.CreateCarLifecycleEventRequest{Type: models.CarEventSale}, wantErr: "sale price is required"},
+		{name: "negative price", req: models.CreateCarLifecycleEventRequest{Type: models.CarEventSale, Price: &negative}, wantErr: "cannot be negative"},
+		{name: "price on a retirement", req: models.CreateCarLifecycleEventRequest{Type: models.CarEventRetirement, Price: &price}, wantErr: "only given for an acquisition"},
+		{name: "empty counterparty", req: models.CreateCarLifecycleEventRequest{Type: models.CarEventReturn, Counterparty: stringPtr(" ")}, wantErr: "counterparty cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCarEventType_Transitions(t *testing.T) {
+	assert.Equal(t, models.CarStatusMaintenance, models.CarEventImmobilization.ResultingStatus(models.CarStatusActive))
+	assert.Equal(t, models.CarStatusActive, models.CarEventRelease.ResultingStatus(models.CarStatusMaintenance))
+	assert.Equal(t, models.CarStatusRetired, models.CarEventSale.ResultingStatus(models.CarStatusActive))
+	assert.Equal(t, models.CarStatusMaintenance, models.CarEventAcquisition.ResultingStatus(models.CarStatusMaintenance))
+
+	assert.NoError(t, models.CarEventImmobilization.CheckTransition(models.CarStatusMaintenance))
+	assert.NoError(t, models.CarEventCommissioning.CheckTransition(models.CarStatusRetired))
+	assert.ErrorContains(t, models.CarEventImmobilization.CheckTransition(models.CarStatusRetired), "cannot be immobilized")
+	assert.ErrorContains(t, models.CarEventRelease.CheckTransition(models.CarStatusActive), "only an immobilized car")
+	assert.ErrorContains(t, models.CarEventReturn.CheckTransition(models.CarStatusRetired), "already retired")
+}
+
+func TestStatusChangeEvent(t *testing.T) {
+	assert.Equal(t, models.CarEventImmobilization, models.StatusChangeEvent(models.CarStatusActive, models.CarStatusMaintenance))
+	assert.Equal(t, models.CarEventRelease, models.StatusChangeEvent(models.CarStatusMaintenance, models.CarStatusActive))
+	assert.Equal(t, models.CarEventCommissioning, models.StatusChangeEvent(models.CarStatusRetired, models.CarStatusActive))
+	assert.Equal(t, models.CarEventRetirement, models.StatusChangeEvent(models.CarStatusMaintenance, models.CarStatusRetired))
+}
+
+func TestBuildCarTimeline(t *testing.T) {
+	breakdown := models.ImmobilizationReasonBreakdown
+	price := eur("8500")
+	events := []*models.CarLifecycleEvent{
+		{ID: "commissioning", Type: models.CarEventCommissioning, EventDate: date(2025, time.January, 6), PreviousStatus: models.CarStatusActive, Status: models.CarStatusActive},
+		{ID: "immobilization", Type: models.CarEventImmobilization, EventDate: date(2025, time.March, 3), PreviousStatus: models.CarStatusActive, Status: models.CarStatusMaintenance, Reason: &breakdown},
+		{ID: "sale", Type: models.CarEventSale, EventDate: date(2025, time.June, 30), PreviousStatus: models.CarStatusMaintenance, Status: models.CarStatusRetired, Price: &price, Counterparty: stringPtr("Garage Martin")},
+	}
+	assignments := []models.CarOperatorAssignment{
+		{ID: "current", OperatorID: "op", StartDate: date(2025, time.June, 1), EndDate: timePtr(date(2025, time.December, 31))},
+		{ID: "past", OperatorID: "op", StartDate: date(2025, time.January, 6), EndDate: timePtr(date(2025, time.March, 3))},
+	}
+	accidents := []*models.Accident{
+		{ID: "accident", AccidentDate: date(2025, time.February, 14).Add(9 * time.Hour), Location: "Lyon", Description: "Rear-ended"},
+	}
+	repairs := []*models.Repair{
+		{ID: "repair", RepairType: models.RepairTypeAccident, Description: "Bumper", StartDate: date(2025, time.February, 20), EndDate: timePtr(date(2025, time.February, 25)), Status: models.RepairStatusCompleted},
+		{ID: "cancelled", RepairType: models.RepairTypeMaintenance, Description: "Service", StartDate: date(2025, time.April, 1), Status: models.RepairStatusCancelled},
+	}
+
+	timeline := buildCarTimeline(events, assignments, accidents, repairs, map[string]string{"op": "Paul Durand"}, date(2025, time.July, 1))
+
+	var got []string
+	for _, entry := range timeline {
+		got = append(got, string(entry.Type)+" "+entry.EntityID)
+	}
+	assert.Equal(t, []string{
+		"lifecycle commissioning",
+		"assignment_start past",
+		"accident accident",
+		"repair_start repair",
+		"repair_end repair",
+		"lifecycle immobilization",
+		"assignment_end past",
+		"assignment_start current",
+		"lifecycle sale",
+	}, got)
+
+	require.Len(t, timeline, 9)
+	assert.Equal(t, "Commissioning", timeline[0].Summary)
+	assert.Equal(t, "Assigned to Paul Durand", timeline[1].Summary)
+	assert.Equal(t, "Accident at Lyon: Rear-ended", timeline[2].Summary)
+	assert.Equal(t, "Accident repair completed: Bumper", timeline[4].Summary)
+	assert.Equal(t, "Immobilization (breakdown), status active to maintenance", timeline[5].Summary)
+	assert.Equal(t, "Sale to Garage Martin for 8500.00 EUR, status maintenance to retired", timeline[8].Summary)
+}
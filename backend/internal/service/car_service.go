@@ -3,12 +3,16 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/goldenkiwi/autoparc/internal/models"
 	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/pkg/plates"
 	"github.com/goldenkiwi/autoparc/pkg/utils"
+	"github.com/goldenkiwi/autoparc/pkg/vin"
 	"github.com/google/uuid"
 )
 
@@ -40,13 +44,37 @@ func NewCarService(
 
 // CreateCar creates a new car and logs the action
 func (s *CarService) CreateCar(ctx context.Context, req *models.CreateCarRequest, userID string) (*models.Car, error) {
-	// Validate license plate
-	if !utils.ValidateLicensePlate(req.LicensePlate) {
-		return nil, fmt.Errorf("invalid license plate format. Expected format: AA-123-BB")
+	// Validate and normalize license plate against the formats of its country
+	plateCountry := strings.ToUpper(strings.TrimSpace(req.PlateCountry))
+	if plateCountry == "" {
+		plateCountry = plates.DefaultCountry
+	}
+	licensePlate, _, err := plates.Default.Normalize(req.LicensePlate, plateCountry)
+	if errors.Is(err, plates.ErrUnsupportedCountry) {
+		return nil, fmt.Errorf("invalid plate country: %s", plateCountry)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid license plate format for country %s. Expected format: %s", plateCountry, plateExamples(plateCountry))
+	}
+
+	// Validate VIN, and check the brand against it or fill it in
+	brand := req.Brand
+	var vinNumber *string
+	if utils.ValidateRequired(req.VIN) {
+		info, err := s.decodeVIN(ctx, req.VIN, "")
+		if err != nil {
+			return nil, err
+		}
+		if !utils.ValidateRequired(brand) && info.Manufacturer != "" {
+			brand = info.Manufacturer
+		} else if !info.MatchesBrand(brand) {
+			return nil, fmt.Errorf("VIN manufacturer %s does not match brand %s", info.Manufacturer, brand)
+		}
+		vinNumber = &info.VIN
 	}
 
 	// Validate required fields
-	if !utils.ValidateRequired(req.Brand) {
+	if !utils.ValidateRequired(brand) {
 		return nil, fmt.Errorf("brand is required")
 	}
 	if !utils.ValidateRequired(req.Model) {
@@ -59,8 +87,12 @@ func (s *CarService) CreateCar(ctx context.Context, req *models.CreateCarRequest
 		return nil, fmt.Errorf("insurance company is required")
 	}
 
+	if err := validateFuelSpecs(req.FuelType, req.TankCapacity); err != nil {
+		return nil, err
+	}
+
 	// Validate insurance company exists
-	_, err := s.insuranceRepo.FindByID(ctx, req.InsuranceCompanyID)
+	_, err = s.insuranceRepo.FindByID(ctx, req.InsuranceCompanyID)
 	if err != nil {
 		return nil, fmt.Errorf("insurance company not found")
 	}
@@ -72,16 +104,17 @@ func (s *CarService) CreateCar(ctx context.Context, req *models.CreateCarRequest
 		return nil, fmt.Errorf("invalid status. Must be: active, maintenance, or retired")
 	}
 
-	// Normalize license plate
-	licensePlate := utils.NormalizeLicensePlate(req.LicensePlate)
-
 	// Create car
 	car := &models.Car{
 		ID:                 uuid.New().String(),
 		LicensePlate:       licensePlate,
-		Brand:              req.Brand,
+		PlateCountry:       plateCountry,
+		VIN:                vinNumber,
+		Brand:              brand,
 		Model:              req.Model,
 		GreyCardNumber:     req.GreyCardNumber,
+		FuelType:           req.FuelType,
+		TankCapacity:       req.TankCapacity,
 		InsuranceCompanyID: req.InsuranceCompanyID,
 		RentalStartDate:    req.RentalStartDate,
 		Status:             req.Status,
@@ -90,7 +123,19 @@ func (s *CarService) CreateCar(ctx context.Context, req *models.CreateCarRequest
 		CreatedBy:          userID,
 	}
 
-	if err := s.carRepo.Create(ctx, car); err != nil {
+	if car.Status == models.CarStatusMaintenance {
+		reason := models.ImmobilizationReasonOther
+		car.ImmobilizationReason = &reason
+	}
+
+	// Entering the car starts its lifecycle; the details of the acquisition
+	// can be filled in later
+	acquisition, err := newLifecycleEvent(car, models.CarEventAcquisition, car.CreatedAt, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.carRepo.CreateWithLifecycleEvent(ctx, car, acquisition); err != nil {
 		return nil, fmt.Errorf("failed to create car: %w", err)
 	}
 
@@ -155,20 +200,40 @@ func (s *CarService) GetCars(ctx context.Context, filters *models.CarFilters) (*
 		filters.Limit = 20
 	}
 
-	cars, totalCount, err := s.carRepo.FindAll(ctx, filters)
+	if filters.VIN != "" {
+		filters.VIN = vin.Normalize(filters.VIN)
+		if err := vin.Validate(filters.VIN); err != nil {
+			return nil, fmt.Errorf("invalid VIN: %w", err)
+		}
+	}
+
+	for _, status := range filters.Statuses {
+		if !status.IsValid() {
+			return nil, fmt.Errorf("invalid status. Must be: active, maintenance, or retired")
+		}
+	}
+	if filters.RentalStartFrom != nil && filters.RentalStartTo != nil && filters.RentalStartTo.Before(*filters.RentalStartFrom) {
+		return nil, fmt.Errorf("invalid rental start date range")
+	}
+
+	cars, totalCount, cursors, err := s.carRepo.FindAll(ctx, filters)
 	if err != nil {
 		return nil, err
 	}
 
-	totalPages := (totalCount + filters.Limit - 1) / filters.Limit
-
-	return &models.CarListResponse{
+	response := &models.CarListResponse{
 		Cars:       cars,
-		TotalCount: totalCount,
-		Page:       filters.Page,
 		Limit:      filters.Limit,
-		TotalPages: totalPages,
-	}, nil
+		NextCursor: cursors.Next,
+		PrevCursor: cursors.Prev,
+	}
+	if filters.Cursor == nil {
+		response.TotalCount = totalCount
+		response.Page = filters.Page
+		response.TotalPages = (totalCount + filters.Limit - 1) / filters.Limit
+	}
+
+	return response, nil
 }
 
 // UpdateCar updates a car and logs the action
@@ -203,6 +268,37 @@ func (s *CarService) UpdateCar(ctx context.Context, id string, req *models.Updat
 		changes["model"] = map[string]string{"old": existingCar.Model, "new": *req.Model}
 	}
 
+	// A changed VIN must be valid and not used by another car; whenever the
+	// VIN or the brand changes they must still agree
+	existingVIN := ""
+	if existingCar.VIN != nil {
+		existingVIN = *existingCar.VIN
+	}
+	carVIN := existingVIN
+	if req.VIN != nil && vin.Normalize(*req.VIN) != existingVIN {
+		carVIN = vin.Normalize(*req.VIN)
+		if carVIN == "" {
+			updates["vin"] = nil
+		} else {
+			if _, err := s.decodeVIN(ctx, carVIN, id); err != nil {
+				return nil, err
+			}
+			updates["vin"] = carVIN
+		}
+		changes["vin"] = map[string]string{"old": existingVIN, "new": carVIN}
+	}
+	_, brandChanged := updates["brand"]
+	_, vinChanged := updates["vin"]
+	if carVIN != "" && (brandChanged || vinChanged) {
+		brand := existingCar.Brand
+		if req.Brand != nil {
+			brand = *req.Brand
+		}
+		if info, err := vin.Decode(carVIN); err == nil && !info.MatchesBrand(brand) {
+			return nil, fmt.Errorf("VIN manufacturer %s does not match brand %s", info.Manufacturer, brand)
+		}
+	}
+
 	if req.GreyCardNumber != nil && *req.GreyCardNumber != existingCar.GreyCardNumber {
 		if !utils.ValidateRequired(*req.GreyCardNumber) {
 			return nil, fmt.Errorf("grey card number cannot be empty")
@@ -211,6 +307,18 @@ func (s *CarService) UpdateCar(ctx context.Context, id string, req *models.Updat
 		changes["greyCardNumber"] = map[string]string{"old": existingCar.GreyCardNumber, "new": *req.GreyCardNumber}
 	}
 
+	if err := validateFuelSpecs(req.FuelType, req.TankCapacity); err != nil {
+		return nil, err
+	}
+	if req.FuelType != nil && (existingCar.FuelType == nil || *existingCar.FuelType != *req.FuelType) {
+		updates["fuel_type"] = *req.FuelType
+		changes["fuelType"] = map[string]interface{}{"old": existingCar.FuelType, "new": *req.FuelType}
+	}
+	if req.TankCapacity != nil && (existingCar.TankCapacity == nil || *existingCar.TankCapacity != *req.TankCapacity) {
+		updates["tank_capacity"] = *req.TankCapacity
+		changes["tankCapacity"] = map[string]interface{}{"old": existingCar.TankCapacity, "new": *req.TankCapacity}
+	}
+
 	if req.InsuranceCompanyID != nil && *req.InsuranceCompanyID != existingCar.InsuranceCompanyID {
 		if !utils.ValidateRequired(*req.InsuranceCompanyID) {
 			return nil, fmt.Errorf("insurance company ID cannot be empty")
@@ -229,23 +337,39 @@ func (s *CarService) UpdateCar(ctx context.Context, id string, req *models.Updat
 		changes["rentalStartDate"] = map[string]string{"old": existingCar.RentalStartDate.Format(time.RFC3339), "new": req.RentalStartDate.Format(time.RFC3339)}
 	}
 
+	// A status change is recorded in the lifecycle of the car
+	var statusEvent *models.CarLifecycleEvent
 	if req.Status != nil && *req.Status != existingCar.Status {
 		if *req.Status != models.CarStatusActive &&
 			*req.Status != models.CarStatusMaintenance &&
 			*req.Status != models.CarStatusRetired {
 			return nil, fmt.Errorf("invalid status. Must be: active, maintenance, or retired")
 		}
-		updates["status"] = *req.Status
+		statusEvent, err = newLifecycleEvent(existingCar, models.StatusChangeEvent(existingCar.Status, *req.Status), time.Now(), userID)
+		if err != nil {
+			return nil, err
+		}
+		if statusEvent.Type == models.CarEventImmobilization {
+			reason := models.ImmobilizationReasonOther
+			statusEvent.Reason = &reason
+		}
 		changes["status"] = map[string]string{"old": string(existingCar.Status), "new": string(*req.Status)}
 	}
 
-	if len(updates) == 0 {
+	if len(updates) == 0 && statusEvent == nil {
 		return existingCar, nil
 	}
 
 	// Update car
-	if err := s.carRepo.Update(ctx, id, updates); err != nil {
-		return nil, fmt.Errorf("failed to update car: %w", err)
+	if len(updates) > 0 {
+		if err := s.carRepo.Update(ctx, id, updates); err != nil {
+			return nil, fmt.Errorf("failed to update car: %w", err)
+		}
+	}
+	if statusEvent != nil {
+		if err := s.carRepo.ApplyLifecycleEvent(ctx, statusEvent); err != nil {
+			return nil, err
+		}
 	}
 
 	// Log action
@@ -265,7 +389,19 @@ func (s *CarService) UpdateCar(ctx context.Context, id string, req *models.Updat
 	return s.carRepo.FindByID(ctx, id)
 }
 
-// DeleteCar soft deletes a car and logs the action
+// validateFuelSpecs checks the optional fuel type and tank capacity of a car
+func validateFuelSpecs(fuelType *models.FuelType, tankCapacity *int) error {
+	if fuelType != nil && !fuelType.IsValidForCar() {
+		return fmt.Errorf("invalid fuel type. Must be: petrol, diesel, hybrid, electric, or lpg")
+	}
+	if tankCapacity != nil && *tankCapacity <= 0 {
+		return fmt.Errorf("tank capacity must be positive")
+	}
+	return nil
+}
+
+// DeleteCar soft deletes a car by retiring it, records the retirement in
+// its lifecycle and logs the action. Deleting a retired car does nothing.
 func (s *CarService) DeleteCar(ctx context.Context, id string, userID string) error {
 	if !utils.ValidateRequired(id) {
 		return fmt.Errorf("car ID is required")
@@ -277,9 +413,17 @@ func (s *CarService) DeleteCar(ctx context.Context, id string, userID string) er
 		return err
 	}
 
+	if existingCar.Status == models.CarStatusRetired {
+		return nil
+	}
+
 	// Soft delete
-	if err := s.carRepo.Delete(ctx, id); err != nil {
-		return fmt.Errorf("failed to delete car: %w", err)
+	event, err := newLifecycleEvent(existingCar, models.CarEventRetirement, time.Now(), userID)
+	if err != nil {
+		return err
+	}
+	if err := s.carRepo.ApplyLifecycleEvent(ctx, event); err != nil {
+		return err
 	}
 
 	// Log action
@@ -300,3 +444,40 @@ func (s *CarService) DeleteCar(ctx context.Context, id string, userID string) er
 
 	return nil
 }
+
+// DecodeVIN validates a VIN and decodes its manufacturer and region
+func (s *CarService) DecodeVIN(number string) (*vin.Info, error) {
+	info, err := vin.Decode(number)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VIN: %w", err)
+	}
+	return info, nil
+}
+
+// decodeVIN decodes a VIN for a car, checking that no car other than
+// excludeID has it
+func (s *CarService) decodeVIN(ctx context.Context, number string, excludeID string) (*vin.Info, error) {
+	info, err := s.DecodeVIN(number)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.carRepo.ExistsByVIN(ctx, info.VIN, excludeID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("a car with VIN %s already exists", info.VIN)
+	}
+
+	return info, nil
+}
+
+// plateExamples lists an example plate of each format of a country
+func plateExamples(country string) string {
+	var examples []string
+	for _, format := range plates.Default.Formats(country) {
+		examples = append(examples, format.Example)
+	}
+	return strings.Join(examples, ", ")
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/pkg/imaging"
+	"github.com/google/uuid"
+)
+
+// DocumentService handles document business logic
+type DocumentService struct {
+	documentRepo  *repository.DocumentRepository
+	carRepo       *repository.CarRepository
+	repairRepo    *repository.RepairRepository
+	operatorRepo  *repository.OperatorRepository
+	accidentRepo  *repository.AccidentRepository
+	actionLogRepo *repository.ActionLogRepository
+	uploadConfig  *config.UploadConfig
+}
+
+// NewDocumentService creates a new document service
+func NewDocumentService(
+	documentRepo *repository.DocumentRepository,
+	carRepo *repository.CarRepository,
+	repairRepo *repository.RepairRepository,
+	operatorRepo *repository.OperatorRepository,
+	accidentRepo *repository.AccidentRepository,
+	actionLogRepo *repository.ActionLogRepository,
+	uploadConfig *config.UploadConfig,
+) *DocumentService {
+	return &DocumentService{
+		documentRepo:  documentRepo,
+		carRepo:       carRepo,
+		repairRepo:    repairRepo,
+		operatorRepo:  operatorRepo,
+		accidentRepo:  accidentRepo,
+		actionLogRepo: actionLogRepo,
+		uploadConfig:  uploadConfig,
+	}
+}
+
+// UploadDocument attaches a new document to an entity
+func (s *DocumentService) UploadDocument(ctx context.Context, req *models.UploadDocumentRequest, userID string) (*models.Document, error) {
+	if err := req.Validate(s.uploadConfig.MaxFileSize); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureEntityExists(ctx, req.EntityType, req.EntityID); err != nil {
+		return nil, err
+	}
+
+	id := uuid.New().String()
+	return s.store(ctx, id, id, req, userID)
+}
+
+// UploadDocumentVersion stores a new version of an existing document. The
+// entity and document type are inherited from the existing document.
+func (s *DocumentService) UploadDocumentVersion(ctx context.Context, documentID string, req *models.UploadDocumentRequest, userID string) (*models.Document, error) {
+	existing, err := s.documentRepo.FindByID(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	req.EntityType = existing.EntityType
+	req.EntityID = existing.EntityID
+	if req.DocumentType == "" {
+		req.DocumentType = existing.DocumentType
+	}
+
+	if err := req.Validate(s.uploadConfig.MaxFileSize); err != nil {
+		return nil, err
+	}
+
+	return s.store(ctx, uuid.New().String(), existing.DocumentGroupID, req, userID)
+}
+
+// GetDocument retrieves document metadata by ID
+func (s *DocumentService) GetDocument(ctx context.Context, id string) (*models.Document, error) {
+	return s.documentRepo.FindByID(ctx, id)
+}
+
+// GetDocumentContent retrieves a document with its file data
+func (s *DocumentService) GetDocumentContent(ctx context.Context, id string) (*models.Document, error) {
+	return s.documentRepo.FindContent(ctx, id)
+}
+
+// GetDocuments retrieves the latest version of documents matching the filters
+func (s *DocumentService) GetDocuments(ctx context.Context, filters *models.DocumentFilters) ([]*models.Document, error) {
+	if filters.EntityType != "" && !models.IsValidDocumentEntityType(filters.EntityType) {
+		return nil, fmt.Errorf("type d'entité invalide")
+	}
+	if filters.DocumentType != "" && !filters.DocumentType.IsValid() {
+		return nil, fmt.Errorf("type de document invalide")
+	}
+
+	return s.documentRepo.FindLatest(ctx, filters)
+}
+
+// GetDocumentVersions retrieves the version history of a document
+func (s *DocumentService) GetDocumentVersions(ctx context.Context, id string) ([]*models.Document, error) {
+	doc, err := s.documentRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.documentRepo.FindVersions(ctx, doc.DocumentGroupID)
+}
+
+// DeleteDocument deletes a document with all its versions and logs the action
+func (s *DocumentService) DeleteDocument(ctx context.Context, id string, userID string) error {
+	doc, err := s.documentRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.documentRepo.DeleteGroup(ctx, doc.DocumentGroupID); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, doc, models.ActionTypeDelete, userID)
+
+	return nil
+}
+
+// store sanitizes the file content and saves it as a document version
+func (s *DocumentService) store(ctx context.Context, id, groupID string, req *models.UploadDocumentRequest, userID string) (*models.Document, error) {
+	data, mimeType, err := sanitizeDocument(req.FileData)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &models.Document{
+		ID:              id,
+		DocumentGroupID: groupID,
+		EntityType:      req.EntityType,
+		EntityID:        req.EntityID,
+		DocumentType:    req.DocumentType,
+		Filename:        req.FileName,
+		FileData:        data,
+		FileSize:        len(data),
+		MimeType:        mimeType,
+		Description:     req.Description,
+		ExpiryDate:      req.ExpiryDate,
+		UploadedAt:      time.Now(),
+		UploadedBy:      &userID,
+	}
+
+	if err := s.documentRepo.Create(ctx, doc); err != nil {
+		return nil, fmt.Errorf("échec de l'enregistrement du document: %w", err)
+	}
+
+	s.logAction(ctx, doc, models.ActionTypeCreate, userID)
+
+	return doc, nil
+}
+
+// sanitizeDocument sniffs the real content type. Images go through the same
+// processing as accident photos so their EXIF metadata is stripped; PDFs are
+// stored as-is.
+func sanitizeDocument(data []byte) ([]byte, string, error) {
+	mimeType := imaging.DetectMimeType(data)
+
+	if imaging.IsSupportedMimeType(mimeType) {
+		processed, err := imaging.Process(data)
+		if err != nil {
+			return nil, "", fmt.Errorf("image invalide: %w", err)
+		}
+		return processed.Data, processed.MimeType, nil
+	}
+
+	if mimeType != models.MimeTypePDF {
+		return nil, "", fmt.Errorf("type de fichier non supporté. Types acceptés: PDF, JPEG, PNG, WebP, GIF")
+	}
+
+	return data, mimeType, nil
+}
+
+// ensureEntityExists checks the entity a document is attached to. Lookup
+// failures other than a missing entity are passed on.
+func (s *DocumentService) ensureEntityExists(ctx context.Context, entityType models.EntityType, entityID string) error {
+	var err error
+	switch entityType {
+	case models.EntityTypeCar:
+		_, err = s.carRepo.FindByID(ctx, entityID)
+	case models.EntityTypeRepair:
+		_, err = s.repairRepo.FindByID(ctx, entityID)
+	case models.EntityTypeOperator:
+		_, err = s.operatorRepo.FindByID(ctx, entityID)
+	case models.EntityTypeAccident:
+		_, err = s.accidentRepo.FindByID(ctx, entityID)
+	default:
+		return fmt.Errorf("type d'entité invalide")
+	}
+
+	if err != nil {
+		// Repositories report a missing entity in English or in French
+		if msg := err.Error(); strings.Contains(msg, "not found") || strings.Contains(msg, "non trouvé") {
+			return fmt.Errorf("entité non trouvée")
+		}
+		return fmt.Errorf("échec de la vérification de l'entité: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DocumentService) logAction(ctx context.Context, doc *models.Document, actionType models.ActionType, userID string) {
+	changes, _ := json.Marshal(map[string]interface{}{
+		"documentGroupId": doc.DocumentGroupID,
+		"version":         doc.Version,
+		"entityType":      doc.EntityType,
+		"entityId":        doc.EntityID,
+		"documentType":    doc.DocumentType,
+		"filename":        doc.Filename,
+	})
+	log := &models.ActionLog{
+		ID:          uuid.New().String(),
+		EntityType:  models.EntityTypeDocument,
+		EntityID:    doc.ID,
+		ActionType:  actionType,
+		PerformedBy: userID,
+		Changes:     changes,
+		Timestamp:   time.Now(),
+	}
+	s.actionLogRepo.Create(ctx, log)
+}
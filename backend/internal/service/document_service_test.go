@@ -0,0 +1,137 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Service tests for document validation logic
+
+func TestUploadDocumentRequest_Validate(t *testing.T) {
+	const maxSize = 10 << 20 // 10MB
+
+	validRequest := func() *models.UploadDocumentRequest {
+		return &models.UploadDocumentRequest{
+			EntityType:   models.EntityTypeCar,
+			EntityID:     "123e4567-e89b-12d3-a456-426614174000",
+			DocumentType: models.DocumentTypeRegistrationCertificate,
+			FileName:     "carte-grise.pdf",
+			FileData:     []byte("%PDF-1.7"),
+			FileSize:     8,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(req *models.UploadDocumentRequest)
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "valid request",
+			modify:  func(req *models.UploadDocumentRequest) {},
+			wantErr: false,
+		},
+		{
+			name: "valid image on an operator",
+			modify: func(req *models.UploadDocumentRequest) {
+				req.EntityType = models.EntityTypeOperator
+				req.DocumentType = models.DocumentTypeDrivingLicence
+				req.FileName = "permis.JPG"
+			},
+			wantErr: false,
+		},
+		{
+			name:    "unsupported entity type",
+			modify:  func(req *models.UploadDocumentRequest) { req.EntityType = models.EntityTypeGarage },
+			wantErr: true,
+			errMsg:  "type d'entité invalide",
+		},
+		{
+			name:    "missing entity ID",
+			modify:  func(req *models.UploadDocumentRequest) { req.EntityID = "" },
+			wantErr: true,
+			errMsg:  "l'identifiant de l'entité est requis",
+		},
+		{
+			name:    "invalid document type",
+			modify:  func(req *models.UploadDocumentRequest) { req.DocumentType = "passport" },
+			wantErr: true,
+			errMsg:  "type de document invalide",
+		},
+		{
+			name:    "file too large",
+			modify:  func(req *models.UploadDocumentRequest) { req.FileSize = maxSize + 1 },
+			wantErr: true,
+			errMsg:  "ne peut pas dépasser 10MB",
+		},
+		{
+			name:    "unsupported extension",
+			modify:  func(req *models.UploadDocumentRequest) { req.FileName = "facture.docx" },
+			wantErr: true,
+			errMsg:  "extension de fichier non supportée",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validRequest()
+			tt.modify(req)
+			err := req.Validate(maxSize)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errMsg != "" {
+					assert.Contains(t, err.Error(), tt.errMsg)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSanitizeDocument(t *testing.T) {
+	pdf := []byte("%PDF-1.7\n1 0 obj\n<<>>\nendobj\n")
+	data, mimeType, err := sanitizeDocument(pdf)
+	require.NoError(t, err)
+	assert.Equal(t, models.MimeTypePDF, mimeType)
+	assert.Equal(t, pdf, data)
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+	_, mimeType, err = sanitizeDocument(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", mimeType)
+
+	// A Word document renamed to .pdf is rejected on its content
+	_, _, err = sanitizeDocument([]byte("PK\x03\x04word/document.xml"))
+	assert.Error(t, err)
+}
+
+func TestEnsureEntityExists_DatabaseFailure(t *testing.T) {
+	// A closed database fails every query without a server
+	db, err := sql.Open("pgx", "host=127.0.0.1")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	s := NewDocumentService(nil, repository.NewCarRepository(db), repository.NewRepairRepository(db),
+		repository.NewOperatorRepository(db), repository.NewAccidentRepository(db), nil, nil)
+
+	for _, entityType := range []models.EntityType{models.EntityTypeCar, models.EntityTypeRepair, models.EntityTypeOperator, models.EntityTypeAccident} {
+		err := s.ensureEntityExists(context.Background(), entityType, "123e4567-e89b-12d3-a456-426614174000")
+		require.Error(t, err, entityType)
+		assert.NotContains(t, err.Error(), "non trouvée", entityType)
+		assert.True(t, strings.HasPrefix(err.Error(), "échec"), "%s: %v", entityType, err)
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/goldenkiwi/autoparc/internal/models"
 	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
 	"github.com/google/uuid"
 )
 
@@ -52,18 +54,24 @@ type PaginatedEmployeesResponse struct {
 
 // EmployeeService handles employee business logic
 type EmployeeService struct {
-	userRepo      *repository.UserRepository
-	actionLogRepo *repository.ActionLogRepository
+	userRepo       *repository.UserRepository
+	sessionRepo    *repository.SessionRepository
+	actionLogRepo  *repository.ActionLogRepository
+	passwordPolicy *PasswordPolicy
 }
 
 // NewEmployeeService creates a new employee service
 func NewEmployeeService(
 	userRepo *repository.UserRepository,
+	sessionRepo *repository.SessionRepository,
 	actionLogRepo *repository.ActionLogRepository,
+	passwordPolicy *PasswordPolicy,
 ) *EmployeeService {
 	return &EmployeeService{
-		userRepo:      userRepo,
-		actionLogRepo: actionLogRepo,
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		actionLogRepo:  actionLogRepo,
+		passwordPolicy: passwordPolicy,
 	}
 }
 
@@ -81,23 +89,6 @@ func ValidateEmail(email string) error {
 	return nil
 }
 
-// ValidatePasswordStrength validates password strength
-func ValidatePasswordStrength(password string) error {
-	if len(password) < 8 {
-		return fmt.Errorf("password must be at least 8 characters long")
-	}
-
-	hasUpper := regexp.MustCompile(`[A-Z]`).MatchString(password)
-	hasLower := regexp.MustCompile(`[a-z]`).MatchString(password)
-	hasNumber := regexp.MustCompile(`[0-9]`).MatchString(password)
-
-	if !hasUpper || !hasLower || !hasNumber {
-		return fmt.Errorf("password must contain at least one uppercase letter, one lowercase letter, and one number")
-	}
-
-	return nil
-}
-
 // CreateEmployee creates a new employee
 func (s *EmployeeService) CreateEmployee(ctx context.Context, req CreateEmployeeRequest, performedBy string) (*EmployeeResponse, error) {
 	// Validate email
@@ -111,8 +102,8 @@ func (s *EmployeeService) CreateEmployee(ctx context.Context, req CreateEmployee
 		return nil, fmt.Errorf("email already exists")
 	}
 
-	// Validate password strength
-	if err := ValidatePasswordStrength(req.Password); err != nil {
+	// Validate password against the policy
+	if err := s.passwordPolicy.Validate(req.Password); err != nil {
 		return nil, err
 	}
 
@@ -131,7 +122,7 @@ func (s *EmployeeService) CreateEmployee(ctx context.Context, req CreateEmployee
 	}
 
 	// Hash password
-	passwordHash, err := repository.HashPassword(req.Password)
+	passwordHash, err := utils.HashPassword(req.Password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
@@ -306,6 +297,15 @@ func (s *EmployeeService) UpdateEmployee(ctx context.Context, id string, req Upd
 		return nil, err
 	}
 
+	// A deactivated employee must not keep using existing sessions
+	if !existing.IsActive && changes["isActive"] != nil {
+		revoked, err := s.sessionRepo.DeleteByUserID(ctx, id, "")
+		if err != nil {
+			return nil, err
+		}
+		changes["revokedSessions"] = revoked
+	}
+
 	// Log action if there were changes
 	if len(changes) > 0 {
 		jsonData, _ := json.Marshal(changes)
@@ -323,51 +323,57 @@ func (s *EmployeeService) UpdateEmployee(ctx context.Context, id string, req Upd
 	return &EmployeeResponse{AdministrativeEmployee: existing}, nil
 }
 
-// ChangePassword changes an employee's password
+// ChangePassword changes an employee's password. Employees changing their
+// own password must confirm the current one. All sessions are revoked.
 func (s *EmployeeService) ChangePassword(ctx context.Context, id string, req ChangePasswordRequest, performedBy string) error {
+	return s.changePassword(ctx, id, req, performedBy, "")
+}
+
+// ChangeOwnPassword changes the password of the employee making the request,
+// who must confirm the current one. Other sessions are revoked while the
+// current one is kept, and no longer restricted if the password had expired.
+func (s *EmployeeService) ChangeOwnPassword(ctx context.Context, id, sessionID string, req ChangePasswordRequest) error {
+	if err := s.changePassword(ctx, id, req, id, sessionID); err != nil {
+		return err
+	}
+
+	return s.sessionRepo.ClearPasswordChangeRequired(ctx, sessionID)
+}
+
+func (s *EmployeeService) changePassword(ctx context.Context, id string, req ChangePasswordRequest, performedBy, keepSessionID string) error {
 	// Validate UUID format
 	if _, err := uuid.Parse(id); err != nil {
 		return fmt.Errorf("invalid employee ID format")
 	}
 
 	// Get employee with password hash
-	employee, err := s.userRepo.GetByEmail(ctx, "")
+	employee, err := s.userRepo.FindByID(ctx, id)
 	if err != nil {
-		// Use FindByID from auth flow
-		employee, err = s.userRepo.FindByID(ctx, id)
-		if err != nil {
-			return fmt.Errorf("employee not found")
-		}
+		return fmt.Errorf("employee not found")
 	}
 
 	// For self-service, validate current password
-	if id == performedBy && req.CurrentPassword != "" {
-		err = repository.CheckPassword(req.CurrentPassword, employee.PasswordHash)
-		if err != nil {
+	if id == performedBy {
+		if req.CurrentPassword == "" || !utils.CheckPassword(employee.PasswordHash, req.CurrentPassword) {
 			return fmt.Errorf("current password is incorrect")
 		}
 	}
 
-	// Validate new password strength
-	if err := ValidatePasswordStrength(req.NewPassword); err != nil {
+	// Validate, hash and store the new password
+	if err := s.passwordPolicy.SetPassword(ctx, id, req.NewPassword); err != nil {
 		return err
 	}
 
-	// Hash new password
-	newPasswordHash, err := repository.HashPassword(req.NewPassword)
-	if err != nil {
-		return fmt.Errorf("failed to hash password: %w", err)
-	}
-
-	// Update password
-	err = s.userRepo.UpdatePassword(ctx, id, newPasswordHash)
+	// Sessions opened with the old password are revoked
+	revoked, err := s.sessionRepo.DeleteByUserID(ctx, id, keepSessionID)
 	if err != nil {
 		return err
 	}
 
 	// Log action
 	actionData := map[string]interface{}{
-		"employeeId": id,
+		"employeeId":      id,
+		"revokedSessions": revoked,
 	}
 	jsonData, _ := json.Marshal(actionData)
 
@@ -384,6 +390,67 @@ func (s *EmployeeService) ChangePassword(ctx context.Context, id string, req Cha
 	return nil
 }
 
+// UpdateProfile updates the name and preferences of the employee making the
+// request. Email, role and status remain managed by admins.
+func (s *EmployeeService) UpdateProfile(ctx context.Context, id string, req models.UpdateProfileRequest) (*EmployeeResponse, error) {
+	existing, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Track changes for logging
+	changes := make(map[string]interface{})
+
+	if firstName := strings.TrimSpace(req.FirstName); firstName != "" && firstName != existing.FirstName {
+		changes["firstName"] = map[string]string{"old": existing.FirstName, "new": firstName}
+		existing.FirstName = firstName
+	}
+
+	if lastName := strings.TrimSpace(req.LastName); lastName != "" && lastName != existing.LastName {
+		changes["lastName"] = map[string]string{"old": existing.LastName, "new": lastName}
+		existing.LastName = lastName
+	}
+
+	if prefs := req.Preferences; prefs != nil {
+		if prefs.Language != "" && prefs.Language != existing.Preferences.Language {
+			if !slices.Contains(models.Languages, prefs.Language) {
+				return nil, fmt.Errorf("invalid language: %s", prefs.Language)
+			}
+			changes["language"] = map[string]string{"old": existing.Preferences.Language, "new": prefs.Language}
+			existing.Preferences.Language = prefs.Language
+		}
+
+		if prefs.DefaultPageSize != 0 && prefs.DefaultPageSize != existing.Preferences.DefaultPageSize {
+			if !slices.Contains(models.PageSizes, prefs.DefaultPageSize) {
+				return nil, fmt.Errorf("invalid page size: %d", prefs.DefaultPageSize)
+			}
+			changes["defaultPageSize"] = map[string]int{"old": existing.Preferences.DefaultPageSize, "new": prefs.DefaultPageSize}
+			existing.Preferences.DefaultPageSize = prefs.DefaultPageSize
+		}
+	}
+
+	if len(changes) == 0 {
+		return &EmployeeResponse{AdministrativeEmployee: existing}, nil
+	}
+
+	if err := s.userRepo.UpdateProfile(ctx, id, existing); err != nil {
+		return nil, err
+	}
+
+	jsonData, _ := json.Marshal(changes)
+	actionLog := &models.ActionLog{
+		ID:          uuid.New().String(),
+		ActionType:  models.ActionTypeUpdate,
+		EntityType:  models.EntityTypeAdministrativeEmployee,
+		EntityID:    id,
+		PerformedBy: id,
+		Changes:     jsonData,
+	}
+	_ = s.actionLogRepo.Create(ctx, actionLog)
+
+	return &EmployeeResponse{AdministrativeEmployee: existing}, nil
+}
+
 // DeleteEmployee performs a soft delete on an employee
 func (s *EmployeeService) DeleteEmployee(ctx context.Context, id string, performedBy string) error {
 	// Validate UUID format
@@ -403,9 +470,15 @@ func (s *EmployeeService) DeleteEmployee(ctx context.Context, id string, perform
 		return err
 	}
 
+	revoked, err := s.sessionRepo.DeleteByUserID(ctx, id, "")
+	if err != nil {
+		return err
+	}
+
 	// Log action
 	actionData := map[string]interface{}{
-		"employeeId": id,
+		"employeeId":      id,
+		"revokedSessions": revoked,
 	}
 	jsonData, _ := json.Marshal(actionData)
 
@@ -33,31 +33,5 @@ func TestValidateEmail(t *testing.T) {
 	}
 }
 
-func TestValidatePasswordStrength(t *testing.T) {
-	tests := []struct {
-		name     string
-		password string
-		wantErr  bool
-	}{
-		{"valid strong password", "SecurePass123", false},
-		{"valid with special chars", "Secure@Pass123!", false},
-		{"too short", "Pass1", true},
-		{"no uppercase", "password123", true},
-		{"no lowercase", "PASSWORD123", true},
-		{"no numbers", "PasswordSecure", true},
-		{"empty password", "", true},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := ValidatePasswordStrength(tt.password)
-			if tt.wantErr {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-			}
-		})
-	}
-}
-
 // Service tests require integration tests with database
 // Skipping for now as services expect concrete repository types, not interfaces
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/pkg/plates"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// FuelService handles fuel card and fuel transaction business logic
+type FuelService struct {
+	fuelRepo      *repository.FuelRepository
+	carRepo       *repository.CarRepository
+	operatorRepo  *repository.OperatorRepository
+	actionLogRepo *repository.ActionLogRepository
+	fuelConfig    *config.FuelConfig
+}
+
+// NewFuelService creates a new fuel service
+func NewFuelService(
+	fuelRepo *repository.FuelRepository,
+	carRepo *repository.CarRepository,
+	operatorRepo *repository.OperatorRepository,
+	actionLogRepo *repository.ActionLogRepository,
+	fuelConfig *config.FuelConfig,
+) *FuelService {
+	return &FuelService{
+		fuelRepo:      fuelRepo,
+		carRepo:       carRepo,
+		operatorRepo:  operatorRepo,
+		actionLogRepo: actionLogRepo,
+		fuelConfig:    fuelConfig,
+	}
+}
+
+// CreateFuelCard registers a fuel card, given to a car, to an operator or
+// to nobody yet
+func (s *FuelService) CreateFuelCard(ctx context.Context, req *models.CreateFuelCardRequest, userID string) (*models.FuelCard, error) {
+	cardNumber := models.NormalizeFuelCardNumber(req.CardNumber)
+	if err := models.ValidateFuelCardNumber(cardNumber); err != nil {
+		return nil, err
+	}
+	provider := strings.TrimSpace(req.Provider)
+	if provider == "" {
+		return nil, fmt.Errorf("provider is required")
+	}
+
+	now := time.Now()
+	card := &models.FuelCard{
+		ID:         uuid.New().String(),
+		CardNumber: cardNumber,
+		Provider:   provider,
+		CarID:      nonEmpty(req.CarID),
+		OperatorID: nonEmpty(req.OperatorID),
+		Status:     models.FuelCardStatusActive,
+		ExpiryDate: req.ExpiryDate,
+		Notes:      req.Notes,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		CreatedBy:  &userID,
+	}
+	if err := s.validateCardHolder(ctx, card); err != nil {
+		return nil, err
+	}
+
+	if err := s.fuelRepo.CreateCard(ctx, card); err != nil {
+		return nil, err
+	}
+
+	s.logAction(ctx, card.ID, models.ActionTypeCreate, userID, card)
+	return s.fuelRepo.FindCardByID(ctx, card.ID)
+}
+
+// GetFuelCard retrieves a fuel card by ID
+func (s *FuelService) GetFuelCard(ctx context.Context, id string) (*models.FuelCard, error) {
+	return s.fuelRepo.FindCardByID(ctx, id)
+}
+
+// GetFuelCards retrieves the fuel cards matching the filters
+func (s *FuelService) GetFuelCards(ctx context.Context, filters *models.FuelCardFilters) ([]*models.FuelCard, error) {
+	if filters.Status != "" && !filters.Status.IsValid() {
+		return nil, fmt.Errorf("invalid status. Must be: active, blocked, or cancelled")
+	}
+	return s.fuelRepo.FindAllCards(ctx, filters)
+}
+
+// UpdateFuelCard updates a fuel card. Giving the card to a car takes it
+// back from its operator and the other way round.
+func (s *FuelService) UpdateFuelCard(ctx context.Context, id string, req *models.UpdateFuelCardRequest, userID string) (*models.FuelCard, error) {
+	card, err := s.fuelRepo.FindCardByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *card
+
+	if req.Provider != nil {
+		card.Provider = strings.TrimSpace(*req.Provider)
+		if card.Provider == "" {
+			return nil, fmt.Errorf("provider cannot be empty")
+		}
+	}
+	if req.CarID != nil {
+		card.CarID = nonEmpty(req.CarID)
+		if card.CarID != nil && req.OperatorID == nil {
+			card.OperatorID = nil
+		}
+	}
+	if req.OperatorID != nil {
+		card.OperatorID = nonEmpty(req.OperatorID)
+		if card.OperatorID != nil && req.CarID == nil {
+			card.CarID = nil
+		}
+	}
+	if req.Status != nil {
+		if !req.Status.IsValid() {
+			return nil, fmt.Errorf("invalid status. Must be: active, blocked, or cancelled")
+		}
+		card.Status = *req.Status
+	}
+	if req.ExpiryDate != nil {
+		card.ExpiryDate = req.ExpiryDate
+	}
+	if req.Notes != nil {
+		card.Notes = req.Notes
+	}
+	if err := s.validateCardHolder(ctx, card); err != nil {
+		return nil, err
+	}
+
+	if err := s.fuelRepo.UpdateCard(ctx, card); err != nil {
+		return nil, err
+	}
+
+	s.logAction(ctx, id, models.ActionTypeUpdate, userID, map[string]interface{}{"old": before, "new": card})
+	return s.fuelRepo.FindCardByID(ctx, id)
+}
+
+// DeleteFuelCard deletes a fuel card. Its transactions are kept.
+func (s *FuelService) DeleteFuelCard(ctx context.Context, id string, userID string) error {
+	card, err := s.fuelRepo.FindCardByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.fuelRepo.DeleteCard(ctx, id); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, id, models.ActionTypeDelete, userID, card)
+	return nil
+}
+
+// validateCardHolder checks that a card is given to at most one existing
+// car or operator
+func (s *FuelService) validateCardHolder(ctx context.Context, card *models.FuelCard) error {
+	if card.CarID != nil && card.OperatorID != nil {
+		return fmt.Errorf("a fuel card is given either to a car or to an operator, not both")
+	}
+	if card.CarID != nil {
+		if _, err := s.carRepo.FindByID(ctx, *card.CarID); err != nil {
+			return fmt.Errorf("car not found")
+		}
+	}
+	if card.OperatorID != nil {
+		if _, err := s.operatorRepo.FindByID(ctx, *card.OperatorID); err != nil {
+			return fmt.Errorf("operator not found")
+		}
+	}
+	return nil
+}
+
+// GetFuelTransactions retrieves the fuel transactions matching the filters
+func (s *FuelService) GetFuelTransactions(ctx context.Context, filters *models.FuelTransactionFilters) ([]*models.FuelTransaction, error) {
+	if filters.Anomaly != "" && !filters.Anomaly.IsValid() {
+		return nil, fmt.Errorf("invalid anomaly. Must be: fuel_type_mismatch, tank_volume_exceeded, unassigned_car, or unmatched_car")
+	}
+	if filters.From != nil && filters.To != nil && filters.To.Before(*filters.From) {
+		return nil, fmt.Errorf("invalid dates: to must not be before from")
+	}
+	return s.fuelRepo.FindTransactions(ctx, filters)
+}
+
+// ImportTransactions imports a CSV statement from a fuel card provider.
+// Each transaction is matched to a car through its card (the card's car, or
+// the car assigned to the card's operator on that day) or else its plate,
+// then its consumption is computed from the previous fill-up and anomalies
+// are flagged. Lines are imported one by one in date order: transactions
+// already imported are skipped, so a statement can be imported again after
+// fixing the lines reported in error.
+func (s *FuelService) ImportTransactions(ctx context.Context, data []byte, userID string) (*models.FuelImportResult, error) {
+	lines, lineErrors, err := parseFuelStatement(data, s.location())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.FuelImportResult{Errors: []models.FuelImportLineError{}}
+	result.Errors = append(result.Errors, lineErrors...)
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].transactedAt.Before(lines[j].transactedAt)
+	})
+
+	matcher := &fuelMatcher{
+		service: s,
+		cards:   make(map[string]*models.FuelCard),
+		cars:    make(map[string]*models.Car),
+	}
+	importedAt := time.Now()
+	for _, line := range lines {
+		tx, err := matcher.match(ctx, line)
+		if err != nil {
+			result.Errors = append(result.Errors, models.FuelImportLineError{Line: line.line, Error: err.Error()})
+			continue
+		}
+		tx.ImportedAt = importedAt
+		tx.ImportedBy = &userID
+
+		created, err := s.fuelRepo.CreateTransaction(ctx, tx)
+		if err != nil {
+			result.Errors = append(result.Errors, models.FuelImportLineError{Line: line.line, Error: err.Error()})
+			continue
+		}
+		if !created {
+			result.Duplicates++
+			continue
+		}
+		result.Imported++
+		if len(tx.Anomalies) > 0 {
+			result.Flagged++
+		}
+	}
+
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Line < result.Errors[j].Line
+	})
+	return result, nil
+}
+
+// fuelMatcher matches the lines of a statement to cards and cars, caching
+// them for the duration of an import
+type fuelMatcher struct {
+	service *FuelService
+	cards   map[string]*models.FuelCard
+	cars    map[string]*models.Car
+}
+
+func (m *fuelMatcher) match(ctx context.Context, line *fuelStatementLine) (*models.FuelTransaction, error) {
+	repo := m.service.fuelRepo
+	tx := &models.FuelTransaction{
+		ID:           uuid.New().String(),
+		CardNumber:   line.cardNumber,
+		Reference:    line.reference,
+		LicensePlate: optionalString(line.licensePlate),
+		TransactedAt: line.transactedAt,
+		Station:      optionalString(line.station),
+		Product:      line.product,
+		FuelType:     models.ParseFuelProduct(line.product),
+		Volume:       line.volume,
+		Amount:       line.amount,
+		Mileage:      line.mileage,
+	}
+
+	card, ok := m.cards[line.cardNumber]
+	if !ok {
+		var err error
+		if card, err = repo.FindCardByNumber(ctx, line.cardNumber); err != nil {
+			return nil, err
+		}
+		m.cards[line.cardNumber] = card
+	}
+
+	if card != nil {
+		tx.CardID = &card.ID
+		switch {
+		case card.CarID != nil:
+			tx.CarID = card.CarID
+		case card.OperatorID != nil:
+			carID, err := m.service.operatorRepo.FindCarOfOperatorAt(ctx, *card.OperatorID, line.transactedAt)
+			if err != nil {
+				return nil, err
+			}
+			if carID != nil {
+				tx.CarID, tx.OperatorID = carID, card.OperatorID
+			}
+		}
+	}
+	if tx.CarID == nil && line.licensePlate != "" {
+		carID, err := m.service.carRepo.FindIDByPlate(ctx, plates.Compact(line.licensePlate))
+		if err != nil {
+			return nil, err
+		}
+		tx.CarID = carID
+	}
+
+	var car *models.Car
+	if tx.CarID != nil {
+		if car, ok = m.cars[*tx.CarID]; !ok {
+			var err error
+			if car, err = m.service.carRepo.FindByID(ctx, *tx.CarID); err != nil {
+				return nil, err
+			}
+			m.cars[*tx.CarID] = car
+		}
+
+		if tx.OperatorID == nil {
+			operatorIDs, err := m.service.operatorRepo.FindOperatorsAt(ctx, car.ID, line.transactedAt)
+			if err != nil {
+				return nil, err
+			}
+			if len(operatorIDs) > 0 {
+				tx.OperatorID = &operatorIDs[0]
+			}
+		}
+
+		if tx.FuelType.IsFuel() && tx.Mileage != nil {
+			previous, err := repo.FindPreviousMileage(ctx, car.ID, line.transactedAt)
+			if err != nil {
+				return nil, err
+			}
+			if previous != nil {
+				tx.Consumption = tx.ComputeConsumption(*previous)
+			}
+		}
+	}
+
+	tx.Anomalies = fuelAnomalies(tx, car)
+	return tx, nil
+}
+
+// fuelAnomalies returns the anomalies of a transaction. A transaction that
+// matched no car is only flagged as unmatched.
+func fuelAnomalies(tx *models.FuelTransaction, car *models.Car) []models.FuelAnomaly {
+	anomalies := []models.FuelAnomaly{}
+	if car == nil {
+		return append(anomalies, models.FuelAnomalyUnmatchedCar)
+	}
+	if car.FuelType != nil && !car.FuelType.Accepts(tx.FuelType) {
+		anomalies = append(anomalies, models.FuelAnomalyFuelTypeMismatch)
+	}
+	if car.TankCapacity != nil && tx.FuelType.IsFuel() && tx.Volume.GreaterThan(decimal.NewFromInt(int64(*car.TankCapacity))) {
+		anomalies = append(anomalies, models.FuelAnomalyTankVolumeExceeded)
+	}
+	if tx.OperatorID == nil {
+		anomalies = append(anomalies, models.FuelAnomalyUnassignedCar)
+	}
+	return anomalies
+}
+
+// nonEmpty returns nil for a nil or blank ID
+func nonEmpty(id *string) *string {
+	if id == nil || strings.TrimSpace(*id) == "" {
+		return nil
+	}
+	return id
+}
+
+// optionalString returns nil for an empty string
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func (s *FuelService) logAction(ctx context.Context, cardID string, actionType models.ActionType, userID string, changes interface{}) {
+	changesJSON, _ := json.Marshal(changes)
+	log := &models.ActionLog{
+		ID:          uuid.New().String(),
+		EntityType:  models.EntityTypeFuelCard,
+		EntityID:    cardID,
+		ActionType:  actionType,
+		PerformedBy: userID,
+		Changes:     changesJSON,
+		Timestamp:   time.Now(),
+	}
+	s.actionLogRepo.Create(ctx, log)
+}
+
+// location returns the time zone of the provider statements
+func (s *FuelService) location() *time.Location {
+	if s.fuelConfig.Location == nil {
+		return time.UTC
+	}
+	return s.fuelConfig.Location
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Service tests for fuel statement import logic
+
+func TestParseFuelStatement(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	t.Run("semicolon statement with decimal commas", func(t *testing.T) {
+		data := "\xef\xbb\xbfDate;Heure;Numéro carte;Immatriculation;Station;Produit;Quantité;Montant TTC;Kilométrage;Référence\n" +
+			"03/02/2025;08:15;7077 1234 5678 9012;AB-123-CD;Total Lyon;Gazole;45,20;81,36;15 200;T-1\n" +
+			"\n" +
+			"05/02/2025;17:40;7077-1234-5678-9012;;Total Lyon;AdBlue;5,00;4,50;;T-2\n"
+
+		lines, lineErrors, err := parseFuelStatement([]byte(data), paris)
+		require.NoError(t, err)
+		assert.Empty(t, lineErrors)
+		require.Len(t, lines, 2)
+
+		first := lines[0]
+		assert.Equal(t, 2, first.line)
+		assert.Equal(t, "7077123456789012", first.cardNumber)
+		assert.Equal(t, "AB-123-CD", first.licensePlate)
+		assert.Equal(t, "Total Lyon", first.station)
+		assert.Equal(t, "Gazole", first.product)
+		assert.Equal(t, "T-1", first.reference)
+		assert.Equal(t, time.Date(2025, time.February, 3, 8, 15, 0, 0, paris), first.transactedAt)
+		assert.Equal(t, time.Date(2025, time.February, 3, 7, 15, 0, 0, time.UTC), first.transactedAt.UTC(), "read in the statement time zone, not the host's")
+		assert.Equal(t, "45.2", first.volume.String())
+		assert.Equal(t, "81.36 EUR", first.amount.String())
+		require.NotNil(t, first.mileage)
+		assert.Equal(t, 15200, *first.mileage)
+
+		assert.Equal(t, 4, lines[1].line)
+		assert.Equal(t, "7077123456789012", lines[1].cardNumber)
+		assert.Nil(t, lines[1].mileage)
+	})
+
+	t.Run("comma statement without reference", func(t *testing.T) {
+		data := "date,card_number,fuel_type,volume,amount,currency\n" +
+			"2025-02-03 08:15,7077123456789012,SP95-E10,\"1,234.5\",\"2,100.00\",chf\n"
+
+		lines, lineErrors, err := parseFuelStatement([]byte(data), paris)
+		require.NoError(t, err)
+		assert.Empty(t, lineErrors)
+		require.Len(t, lines, 1)
+		assert.Equal(t, "1234.5", lines[0].volume.String())
+		assert.Equal(t, "2100.00 CHF", lines[0].amount.String())
+		assert.Equal(t, "20250203T081500/1234.5/2100", lines[0].reference)
+	})
+
+	t.Run("invalid lines are reported and skipped", func(t *testing.T) {
+		data := "date;card_number;product;volume;amount;mileage\n" +
+			"2025-02-03;7077123456789012;Gazole;45,2;81,36;15200\n" +
+			"2025-13-03;7077123456789012;Gazole;45,2;81,36;15200\n" +
+			"2025-02-04;12;Gazole;45,2;81,36;15200\n" +
+			"2025-02-05;7077123456789012;Gazole;-3;81,36;15200\n" +
+			"2025-02-06;7077123456789012;Gazole;45,2;81,36;lots\n"
+
+		lines, lineErrors, err := parseFuelStatement([]byte(data), paris)
+		require.NoError(t, err)
+		assert.Len(t, lines, 1)
+		require.Len(t, lineErrors, 4)
+		assert.Equal(t, 3, lineErrors[0].Line)
+		assert.Contains(t, lineErrors[0].Error, "invalid date")
+		assert.Contains(t, lineErrors[1].Error, "invalid card number")
+		assert.Contains(t, lineErrors[2].Error, "invalid volume")
+		assert.Contains(t, lineErrors[3].Error, "invalid mileage")
+	})
+
+	t.Run("unusable files", func(t *testing.T) {
+		_, _, err := parseFuelStatement(nil, paris)
+		assert.EqualError(t, err, "statement is empty")
+
+		_, _, err = parseFuelStatement([]byte("date;card_number;product\n"), paris)
+		assert.EqualError(t, err, "statement is missing columns: volume, amount")
+	})
+}
+
+func TestParseFuelProduct(t *testing.T) {
+	tests := map[string]models.FuelType{
+		"Gazole":        models.FuelTypeDiesel,
+		"GAZOLE B7":     models.FuelTypeDiesel,
+		"Diesel":        models.FuelTypeDiesel,
+		"SP95-E10":      models.FuelTypePetrol,
+		"SP 98":         models.FuelTypePetrol,
+		"Super E85":     models.FuelTypePetrol,
+		"Sans plomb 95": models.FuelTypePetrol,
+		"GPL":           models.FuelTypeLPG,
+		"AdBlue":        models.FuelTypeAdBlue,
+		"Recharge AC":   models.FuelTypeElectric,
+		"Lavage":        models.FuelTypeOther,
+	}
+
+	for label, want := range tests {
+		assert.Equal(t, want, models.ParseFuelProduct(label), label)
+	}
+}
+
+func TestFuelType_Accepts(t *testing.T) {
+	assert.True(t, models.FuelTypeDiesel.Accepts(models.FuelTypeDiesel))
+	assert.False(t, models.FuelTypeDiesel.Accepts(models.FuelTypePetrol))
+	assert.False(t, models.FuelTypePetrol.Accepts(models.FuelTypeDiesel))
+	assert.True(t, models.FuelTypeHybrid.Accepts(models.FuelTypePetrol))
+	assert.False(t, models.FuelTypeHybrid.Accepts(models.FuelTypeDiesel))
+	assert.True(t, models.FuelTypeLPG.Accepts(models.FuelTypePetrol))
+	assert.False(t, models.FuelTypeElectric.Accepts(models.FuelTypePetrol))
+	// Non fuel products never mismatch
+	assert.True(t, models.FuelTypePetrol.Accepts(models.FuelTypeAdBlue))
+	assert.True(t, models.FuelTypeElectric.Accepts(models.FuelTypeOther))
+}
+
+func TestFuelTransaction_ComputeConsumption(t *testing.T) {
+	tx := &models.FuelTransaction{Volume: dec("45.20")}
+	assert.Nil(t, tx.ComputeConsumption(14500), "no mileage")
+
+	tx.Mileage = intPtr(15200)
+	// 45.20 l over 700 km
+	assert.Equal(t, "6.46", tx.ComputeConsumption(14500).String())
+	assert.Nil(t, tx.ComputeConsumption(15200), "mileage did not increase")
+	assert.Nil(t, tx.ComputeConsumption(16000), "mileage went back")
+}
+
+func TestFuelAnomalies(t *testing.T) {
+	diesel := models.FuelTypeDiesel
+	car := &models.Car{ID: "car", FuelType: &diesel, TankCapacity: intPtr(50)}
+	operatorID := "operator"
+
+	tests := []struct {
+		name string
+		tx   models.FuelTransaction
+		car  *models.Car
+		want []models.FuelAnomaly
+	}{
+		{
+			name: "regular fill-up",
+			tx:   models.FuelTransaction{FuelType: models.FuelTypeDiesel, Volume: dec("50"), OperatorID: &operatorID},
+			car:  car,
+			want: []models.FuelAnomaly{},
+		},
+		{
+			name: "wrong fuel and more than the tank",
+			tx:   models.FuelTransaction{FuelType: models.FuelTypePetrol, Volume: dec("50.01"), OperatorID: &operatorID},
+			car:  car,
+			want: []models.FuelAnomaly{models.FuelAnomalyFuelTypeMismatch, models.FuelAnomalyTankVolumeExceeded},
+		},
+		{
+			name: "AdBlue is not checked against the tank",
+			tx:   models.FuelTransaction{FuelType: models.FuelTypeAdBlue, Volume: dec("60"), OperatorID: &operatorID},
+			car:  car,
+			want: []models.FuelAnomaly{},
+		},
+		{
+			name: "car without operator",
+			tx:   models.FuelTransaction{FuelType: models.FuelTypeDiesel, Volume: dec("30")},
+			car:  car,
+			want: []models.FuelAnomaly{models.FuelAnomalyUnassignedCar},
+		},
+		{
+			name: "car without fuel specs",
+			tx:   models.FuelTransaction{FuelType: models.FuelTypePetrol, Volume: dec("120"), OperatorID: &operatorID},
+			car:  &models.Car{ID: "other"},
+			want: []models.FuelAnomaly{},
+		},
+		{
+			name: "no car",
+			tx:   models.FuelTransaction{FuelType: models.FuelTypePetrol, Volume: dec("30")},
+			want: []models.FuelAnomaly{models.FuelAnomalyUnmatchedCar},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, fuelAnomalies(&tt.tx, tt.car))
+		})
+	}
+}
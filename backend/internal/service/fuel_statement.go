@@ -0,0 +1,265 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/shopspring/decimal"
+)
+
+// fuelStatementColumns lists the header names accepted for each column of a
+// provider statement, compared in lower case without accents and with
+// spaces as underscores
+var fuelStatementColumns = map[string][]string{
+	"date":          {"date", "transaction_date", "date_transaction", "date_operation"},
+	"time":          {"time", "heure", "transaction_time"},
+	"card_number":   {"card_number", "card", "card_no", "numero_carte", "carte"},
+	"license_plate": {"license_plate", "plate", "registration", "immatriculation"},
+	"station":       {"station", "site", "location", "lieu"},
+	"product":       {"fuel_type", "product", "produit", "carburant"},
+	"volume":        {"volume", "quantity", "quantite", "liters", "litres"},
+	"amount":        {"amount", "amount_ttc", "montant", "montant_ttc"},
+	"currency":      {"currency", "devise"},
+	"mileage":       {"mileage", "odometer", "kilometrage", "km"},
+	"reference":     {"reference", "transaction_id", "ref", "numero_transaction"},
+}
+
+// fuelStatementRequired are the columns a statement must have
+var fuelStatementRequired = []string{"date", "card_number", "product", "volume", "amount"}
+
+// fuelStatementDateLayouts are the date formats accepted, ISO or French
+var fuelStatementDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"02/01/2006",
+}
+
+// fuelStatementLine is a parsed line of a provider statement
+type fuelStatementLine struct {
+	line         int
+	cardNumber   string
+	licensePlate string
+	station      string
+	product      string
+	reference    string
+	transactedAt time.Time
+	volume       decimal.Decimal
+	amount       money.Money
+	mileage      *int
+}
+
+// parseFuelStatement reads a CSV statement exported by a fuel card
+// provider. The delimiter (comma, semicolon or tab) is detected from the
+// header line, and decimals may use a comma. Times are read in the given
+// location. Lines that cannot be read are reported and skipped; an error is
+// returned only when the file itself is unusable.
+func parseFuelStatement(data []byte, loc *time.Location) ([]*fuelStatementLine, []models.FuelImportLineError, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("statement is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid statement: %w", err)
+	}
+
+	columns := mapStatementColumns(header)
+	var missing []string
+	for _, name := range fuelStatementRequired {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, nil, fmt.Errorf("statement is missing columns: %s", strings.Join(missing, ", "))
+	}
+
+	var lines []*fuelStatementLine
+	var lineErrors []models.FuelImportLineError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				lineErrors = append(lineErrors, models.FuelImportLineError{Line: parseErr.Line, Error: parseErr.Err.Error()})
+				continue
+			}
+			return nil, nil, fmt.Errorf("invalid statement: %w", err)
+		}
+		lineNumber, _ := reader.FieldPos(0)
+		if isBlankRecord(record) {
+			continue
+		}
+
+		line, err := parseStatementRecord(record, columns, loc)
+		if err != nil {
+			lineErrors = append(lineErrors, models.FuelImportLineError{Line: lineNumber, Error: err.Error()})
+			continue
+		}
+		line.line = lineNumber
+		lines = append(lines, line)
+	}
+
+	return lines, lineErrors, nil
+}
+
+// detectDelimiter picks the most frequent delimiter of the header line
+func detectDelimiter(data []byte) rune {
+	header := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		header = data[:i]
+	}
+
+	delimiter, best := ',', bytes.Count(header, []byte(","))
+	for _, candidate := range []rune{';', '\t'} {
+		if count := bytes.Count(header, []byte(string(candidate))); count > best {
+			delimiter, best = candidate, count
+		}
+	}
+	return delimiter
+}
+
+// headerAccents folds the accents of French statement headers
+var headerAccents = strings.NewReplacer("é", "e", "è", "e", "ê", "e", "à", "a", "â", "a", "ç", "c", "î", "i", "ô", "o", "û", "u", "ù", "u")
+
+// mapStatementColumns returns the index of each known column of the header
+func mapStatementColumns(header []string) map[string]int {
+	aliases := make(map[string]string)
+	for name, names := range fuelStatementColumns {
+		for _, alias := range names {
+			aliases[alias] = name
+		}
+	}
+
+	columns := make(map[string]int)
+	for i, title := range header {
+		key := strings.Join(strings.Fields(headerAccents.Replace(strings.ToLower(title))), "_")
+		if name, ok := aliases[key]; ok {
+			if _, seen := columns[name]; !seen {
+				columns[name] = i
+			}
+		}
+	}
+	return columns
+}
+
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func parseStatementRecord(record []string, columns map[string]int, loc *time.Location) (*fuelStatementLine, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	line := &fuelStatementLine{
+		cardNumber:   models.NormalizeFuelCardNumber(field("card_number")),
+		licensePlate: field("license_plate"),
+		station:      field("station"),
+		product:      field("product"),
+		reference:    field("reference"),
+	}
+	if err := models.ValidateFuelCardNumber(line.cardNumber); err != nil {
+		return nil, err
+	}
+	if line.product == "" {
+		return nil, fmt.Errorf("product is required")
+	}
+
+	date := field("date")
+	if clock := field("time"); clock != "" {
+		date += " " + clock
+	}
+	transactedAt, err := parseStatementDate(date, loc)
+	if err != nil {
+		return nil, err
+	}
+	line.transactedAt = transactedAt
+
+	volume, err := parseStatementDecimal(field("volume"))
+	if err != nil || volume.IsNegative() {
+		return nil, fmt.Errorf("invalid volume %q", field("volume"))
+	}
+	line.volume = volume.Round(2)
+	amount, err := parseStatementDecimal(field("amount"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount %q", field("amount"))
+	}
+	currency := strings.ToUpper(field("currency"))
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	if err := money.ValidateCurrency(currency); err != nil {
+		return nil, fmt.Errorf("invalid currency %q", currency)
+	}
+	line.amount = money.New(amount, currency).Round()
+
+	if value := strings.Join(strings.Fields(field("mileage")), ""); value != "" {
+		mileage, err := strconv.Atoi(value)
+		if err != nil || mileage < 0 {
+			return nil, fmt.Errorf("invalid mileage %q", field("mileage"))
+		}
+		line.mileage = &mileage
+	}
+
+	// Statements without a provider reference are deduplicated on the time,
+	// volume and amount of the transaction
+	if line.reference == "" {
+		line.reference = fmt.Sprintf("%s/%s/%s", line.transactedAt.Format("20060102T150405"), line.volume, line.amount.Amount)
+	}
+
+	return line, nil
+}
+
+func parseStatementDate(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range fuelStatementDateLayouts {
+		if date, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// parseStatementDecimal reads "1234.56", "1234,56", "1 234,56" or
+// "1,234.56": the last separator is the decimal one
+func parseStatementDecimal(value string) (decimal.Decimal, error) {
+	value = strings.Join(strings.Fields(value), "")
+	comma, dot := strings.LastIndex(value, ","), strings.LastIndex(value, ".")
+	if comma > dot {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.Replace(value, ",", ".", 1)
+	} else {
+		value = strings.ReplaceAll(value, ",", "")
+	}
+	return decimal.NewFromString(value)
+}
@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/google/uuid"
+)
+
+// maxLeaseAlertDays bounds the window of the ending soon report
+const maxLeaseAlertDays = 365
+
+// LeaseService handles lease contract business logic
+type LeaseService struct {
+	leaseRepo       *repository.LeaseRepository
+	carRepo         *repository.CarRepository
+	operatorRepo    *repository.OperatorRepository
+	actionLogRepo   *repository.ActionLogRepository
+	expiryAlertDays int
+}
+
+// NewLeaseService creates a new lease service
+func NewLeaseService(
+	leaseRepo *repository.LeaseRepository,
+	carRepo *repository.CarRepository,
+	operatorRepo *repository.OperatorRepository,
+	actionLogRepo *repository.ActionLogRepository,
+	leaseConfig *config.LeaseConfig,
+) *LeaseService {
+	return &LeaseService{
+		leaseRepo:       leaseRepo,
+		carRepo:         carRepo,
+		operatorRepo:    operatorRepo,
+		actionLogRepo:   actionLogRepo,
+		expiryAlertDays: leaseConfig.ExpiryAlertDays,
+	}
+}
+
+// CreateLease creates the lease contract of a car. A car has at most one
+// active contract, and retired cars cannot be leased.
+func (s *LeaseService) CreateLease(ctx context.Context, req *models.CreateLeaseContractRequest, userID string) (*models.LeaseContract, error) {
+	car, err := s.carRepo.FindByID(ctx, req.CarID)
+	if err != nil {
+		return nil, fmt.Errorf("car not found")
+	}
+	if car.Status == models.CarStatusRetired {
+		return nil, fmt.Errorf("cannot lease a retired car")
+	}
+
+	now := time.Now()
+	lease := &models.LeaseContract{
+		ID:                uuid.New().String(),
+		CarID:             req.CarID,
+		Lessor:            strings.TrimSpace(req.Lessor),
+		ContractNumber:    strings.TrimSpace(req.ContractNumber),
+		StartDate:         req.StartDate,
+		EndDate:           req.EndDate,
+		MonthlyRent:       req.MonthlyRent,
+		ContractedMileage: req.ContractedMileage,
+		ExcessKmPrice:     req.ExcessKmPrice,
+		StartMileage:      req.StartMileage,
+		Status:            models.LeaseStatusActive,
+		Notes:             req.Notes,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		CreatedBy:         &userID,
+	}
+	if lease.MonthlyRent.Currency == "" {
+		lease.MonthlyRent.Currency = money.DefaultCurrency
+	}
+	if err := lease.Validate(); err != nil {
+		return nil, err
+	}
+
+	active, err := s.leaseRepo.FindActiveByCar(ctx, req.CarID)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		return nil, fmt.Errorf("car already has an active lease contract")
+	}
+
+	if err := s.leaseRepo.Create(ctx, lease); err != nil {
+		return nil, err
+	}
+
+	s.logAction(ctx, lease.ID, models.ActionTypeCreate, userID, lease)
+
+	return s.GetLease(ctx, lease.ID)
+}
+
+// GetLease retrieves a lease contract with its mileage projection
+func (s *LeaseService) GetLease(ctx context.Context, id string) (*models.LeaseContract, error) {
+	lease, err := s.leaseRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lease.MileageProjection = lease.ProjectMileage()
+	return lease, nil
+}
+
+// GetLeases retrieves lease contracts matching the filters
+func (s *LeaseService) GetLeases(ctx context.Context, filters *models.LeaseFilters) ([]*models.LeaseContract, error) {
+	if filters.Status != "" && filters.Status != models.LeaseStatusActive && filters.Status != models.LeaseStatusReturned {
+		return nil, fmt.Errorf("invalid status. Must be: active or returned")
+	}
+
+	leases, err := s.leaseRepo.FindAll(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lease := range leases {
+		lease.MileageProjection = lease.ProjectMileage()
+	}
+	return leases, nil
+}
+
+// GetExpiringLeases retrieves the active contracts ending within the given
+// number of days, the configured window when zero. Contracts past their end
+// date and not yet returned are included with a negative number of days.
+func (s *LeaseService) GetExpiringLeases(ctx context.Context, days int) ([]*models.ExpiringLease, error) {
+	if days == 0 {
+		days = s.expiryAlertDays
+	}
+	if days < 0 || days > maxLeaseAlertDays {
+		return nil, fmt.Errorf("invalid days: must be between 1 and %d", maxLeaseAlertDays)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	leases, err := s.leaseRepo.FindExpiring(ctx, today.AddDate(0, 0, days))
+	if err != nil {
+		return nil, err
+	}
+
+	expiring := make([]*models.ExpiringLease, len(leases))
+	for i, lease := range leases {
+		lease.MileageProjection = lease.ProjectMileage()
+		expiring[i] = &models.ExpiringLease{
+			LeaseContract: lease,
+			DaysRemaining: lease.DaysRemaining(today),
+		}
+	}
+	return expiring, nil
+}
+
+// UpdateLease updates the terms of an active lease contract
+func (s *LeaseService) UpdateLease(ctx context.Context, id string, req *models.UpdateLeaseContractRequest, userID string) (*models.LeaseContract, error) {
+	lease, err := s.leaseRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if lease.Status != models.LeaseStatusActive {
+		return nil, fmt.Errorf("cannot update a returned lease contract")
+	}
+
+	old := *lease
+	if req.Lessor != nil {
+		lease.Lessor = strings.TrimSpace(*req.Lessor)
+	}
+	if req.ContractNumber != nil {
+		lease.ContractNumber = strings.TrimSpace(*req.ContractNumber)
+	}
+	if req.StartDate != nil {
+		lease.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		lease.EndDate = *req.EndDate
+	}
+	if req.MonthlyRent != nil {
+		lease.MonthlyRent = *req.MonthlyRent
+	}
+	if req.ContractedMileage != nil {
+		lease.ContractedMileage = *req.ContractedMileage
+	}
+	if req.ExcessKmPrice != nil {
+		lease.ExcessKmPrice = *req.ExcessKmPrice
+	}
+	if req.StartMileage != nil {
+		lease.StartMileage = *req.StartMileage
+	}
+	if req.Notes != nil {
+		lease.Notes = req.Notes
+	}
+
+	if err := lease.Validate(); err != nil {
+		return nil, err
+	}
+	if lease.CurrentMileage != nil && *lease.CurrentMileage < lease.StartMileage {
+		return nil, fmt.Errorf("start mileage cannot exceed the recorded mileage")
+	}
+
+	if err := s.leaseRepo.Update(ctx, lease); err != nil {
+		return nil, err
+	}
+
+	s.logAction(ctx, id, models.ActionTypeUpdate, userID, map[string]interface{}{
+		"old": &old,
+		"new": lease,
+	})
+
+	return s.GetLease(ctx, id)
+}
+
+// RecordMileage stores an odometer reading of the car of an active lease
+// contract. Readings cannot go backwards, in mileage or in date, and cannot
+// be dated in the future.
+func (s *LeaseService) RecordMileage(ctx context.Context, id string, req *models.RecordMileageRequest, userID string) (*models.LeaseContract, error) {
+	lease, err := s.leaseRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if lease.Status != models.LeaseStatusActive {
+		return nil, fmt.Errorf("cannot record the mileage of a returned lease contract")
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	date := today
+	if req.Date != nil {
+		date = *req.Date
+	}
+	if date.After(today) {
+		return nil, fmt.Errorf("invalid date: mileage cannot be recorded in the future")
+	}
+	if date.Before(lease.StartDate) {
+		return nil, fmt.Errorf("invalid date: mileage cannot be recorded before the start of the contract")
+	}
+	if lease.MileageRecordedAt != nil && date.Before(*lease.MileageRecordedAt) {
+		return nil, fmt.Errorf("invalid date: before the last mileage reading on %s", lease.MileageRecordedAt.Format("2006-01-02"))
+	}
+	if req.Mileage < lease.StartMileage {
+		return nil, fmt.Errorf("invalid mileage: below the start mileage of %d km", lease.StartMileage)
+	}
+	if lease.CurrentMileage != nil && req.Mileage < *lease.CurrentMileage {
+		return nil, fmt.Errorf("invalid mileage: below the last recorded mileage of %d km", *lease.CurrentMileage)
+	}
+
+	if err := s.leaseRepo.RecordMileage(ctx, id, req.Mileage, date); err != nil {
+		return nil, err
+	}
+
+	s.logAction(ctx, id, models.ActionTypeUpdate, userID, map[string]interface{}{
+		"mileage": map[string]interface{}{"old": lease.CurrentMileage, "new": req.Mileage},
+	})
+
+	return s.GetLease(ctx, id)
+}
+
+// ReturnLease records the return of a leased car to its lessor: the contract
+// is closed with the final mileage and the car is retired. The car must no
+// longer be assigned to an operator.
+func (s *LeaseService) ReturnLease(ctx context.Context, id string, req *models.ReturnLeaseRequest, userID string) (*models.LeaseContract, error) {
+	lease, err := s.leaseRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if lease.Status != models.LeaseStatusActive {
+		return nil, fmt.Errorf("lease contract is already returned")
+	}
+
+	if req.ReturnDate.IsZero() {
+		return nil, fmt.Errorf("return date is required")
+	}
+	if req.ReturnDate.Before(lease.StartDate) {
+		return nil, fmt.Errorf("invalid return date: before the start of the contract")
+	}
+	if req.ReturnMileage < lease.StartMileage {
+		return nil, fmt.Errorf("invalid return mileage: below the start mileage of %d km", lease.StartMileage)
+	}
+	if lease.CurrentMileage != nil && req.ReturnMileage < *lease.CurrentMileage {
+		return nil, fmt.Errorf("invalid return mileage: below the last recorded mileage of %d km", *lease.CurrentMileage)
+	}
+
+	assigned, err := s.operatorRepo.CarHasActiveAssignment(ctx, lease.CarID)
+	if err != nil {
+		return nil, err
+	}
+	if assigned {
+		return nil, fmt.Errorf("car must be unassigned from its operator before it is returned")
+	}
+
+	car, err := s.carRepo.FindByID(ctx, lease.CarID)
+	if err != nil {
+		return nil, err
+	}
+
+	var event *models.CarLifecycleEvent
+	if car.Status != models.CarStatusRetired {
+		event = &models.CarLifecycleEvent{
+			ID:             uuid.New().String(),
+			CarID:          car.ID,
+			Type:           models.CarEventReturn,
+			EventDate:      req.ReturnDate,
+			PreviousStatus: car.Status,
+			Status:         models.CarStatusRetired,
+			Counterparty:   &lease.Lessor,
+			Notes:          req.Notes,
+			CreatedAt:      time.Now(),
+			CreatedBy:      &userID,
+		}
+	}
+
+	if err := s.leaseRepo.Return(ctx, id, req.ReturnDate, req.ReturnMileage, req.Notes, event); err != nil {
+		return nil, err
+	}
+
+	returned, err := s.GetLease(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logAction(ctx, id, models.ActionTypeLeaseReturn, userID, map[string]interface{}{
+		"returnDate":        req.ReturnDate.Format("2006-01-02"),
+		"returnMileage":     req.ReturnMileage,
+		"mileageProjection": returned.MileageProjection,
+	})
+
+	if car.Status != models.CarStatusRetired {
+		changes, _ := json.Marshal(map[string]interface{}{
+			"status":          map[string]string{"old": string(car.Status), "new": string(models.CarStatusRetired)},
+			"leaseContractId": id,
+		})
+		s.actionLogRepo.Create(ctx, &models.ActionLog{
+			ID:          uuid.New().String(),
+			EntityType:  models.EntityTypeCar,
+			EntityID:    car.ID,
+			ActionType:  models.ActionTypeStatusChange,
+			PerformedBy: userID,
+			Changes:     changes,
+			Timestamp:   time.Now(),
+		})
+	}
+
+	return returned, nil
+}
+
+// DeleteLease deletes a lease contract entered by mistake. Returned
+// contracts are kept as the record of the return.
+func (s *LeaseService) DeleteLease(ctx context.Context, id string, userID string) error {
+	lease, err := s.leaseRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if lease.Status != models.LeaseStatusActive {
+		return fmt.Errorf("cannot delete a returned lease contract")
+	}
+
+	if err := s.leaseRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, id, models.ActionTypeDelete, userID, lease)
+	return nil
+}
+
+func (s *LeaseService) logAction(ctx context.Context, leaseID string, actionType models.ActionType, userID string, changes interface{}) {
+	changesJSON, _ := json.Marshal(changes)
+	log := &models.ActionLog{
+		ID:          uuid.New().String(),
+		EntityType:  models.EntityTypeLeaseContract,
+		EntityID:    leaseID,
+		ActionType:  actionType,
+		PerformedBy: userID,
+		Changes:     changesJSON,
+		Timestamp:   time.Now(),
+	}
+	s.actionLogRepo.Create(ctx, log)
+}
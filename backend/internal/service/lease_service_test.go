@@ -0,0 +1,142 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// Service tests for lease contract logic
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func newLease() *models.LeaseContract {
+	return &models.LeaseContract{
+		Lessor:            "Arval",
+		ContractNumber:    "LC-2024-001",
+		StartDate:         date(2024, time.January, 1),
+		EndDate:           date(2026, time.January, 1),
+		MonthlyRent:       eur("389.90"),
+		ContractedMileage: 60000,
+		ExcessKmPrice:     dec("0.085"),
+		StartMileage:      12,
+		Status:            models.LeaseStatusActive,
+	}
+}
+
+func TestLeaseContract_Validate(t *testing.T) {
+	assert.NoError(t, newLease().Validate())
+
+	tests := []struct {
+		name   string
+		modify func(*models.LeaseContract)
+		want   string
+	}{
+		{"missing lessor", func(l *models.LeaseContract) { l.Lessor = " " }, "lessor is required"},
+		{"missing contract number", func(l *models.LeaseContract) { l.ContractNumber = "" }, "contract number is required"},
+		{"end before start", func(l *models.LeaseContract) { l.EndDate = l.StartDate }, "end date must be after start date"},
+		{"negative rent", func(l *models.LeaseContract) { l.MonthlyRent = eur("-1") }, "monthly rent cannot be negative"},
+		{"rent below the cent", func(l *models.LeaseContract) { l.MonthlyRent = eur("389.905") }, "more than 2 decimal places"},
+		{"invalid currency", func(l *models.LeaseContract) { l.MonthlyRent.Currency = "euro" }, "invalid currency"},
+		{"no contracted mileage", func(l *models.LeaseContract) { l.ContractedMileage = 0 }, "contracted mileage must be positive"},
+		{"negative excess price", func(l *models.LeaseContract) { l.ExcessKmPrice = dec("-0.1") }, "cannot be negative"},
+		{"excess price too precise", func(l *models.LeaseContract) { l.ExcessKmPrice = dec("0.08512") }, "more than 4 decimal places"},
+		{"negative start mileage", func(l *models.LeaseContract) { l.StartMileage = -1 }, "start mileage cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lease := newLease()
+			tt.modify(lease)
+			err := lease.Validate()
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestLeaseContract_ProjectMileage(t *testing.T) {
+	t.Run("no mileage recorded", func(t *testing.T) {
+		assert.Nil(t, newLease().ProjectMileage())
+	})
+
+	t.Run("extrapolates to the end date", func(t *testing.T) {
+		lease := newLease()
+		// 366 of 731 days driven 36012 - 12 = 36000 km: 71902 km projected
+		lease.CurrentMileage = intPtr(36012)
+		lease.MileageRecordedAt = timePtr(date(2025, time.January, 1))
+
+		projection := lease.ProjectMileage()
+		assert.Equal(t, 36000, projection.DrivenKm)
+		assert.Equal(t, 71902, projection.ProjectedKm)
+		assert.Equal(t, 11902, projection.ExcessKm)
+		assert.Equal(t, "1011.67 EUR", projection.ExcessCost.String())
+		assert.False(t, projection.Final)
+	})
+
+	t.Run("within the contracted mileage", func(t *testing.T) {
+		lease := newLease()
+		lease.CurrentMileage = intPtr(10012)
+		lease.MileageRecordedAt = timePtr(date(2025, time.January, 1))
+
+		projection := lease.ProjectMileage()
+		assert.Equal(t, 0, projection.ExcessKm)
+		assert.True(t, projection.ExcessCost.IsZero())
+	})
+
+	t.Run("reading on the start date is not extrapolated", func(t *testing.T) {
+		lease := newLease()
+		lease.CurrentMileage = intPtr(112)
+		lease.MileageRecordedAt = timePtr(lease.StartDate)
+
+		assert.Equal(t, 100, lease.ProjectMileage().ProjectedKm)
+	})
+
+	t.Run("reading after the end date is used as is", func(t *testing.T) {
+		lease := newLease()
+		lease.CurrentMileage = intPtr(61012)
+		lease.MileageRecordedAt = timePtr(date(2026, time.February, 1))
+
+		projection := lease.ProjectMileage()
+		assert.Equal(t, 61000, projection.ProjectedKm)
+		assert.Equal(t, 1000, projection.ExcessKm)
+		assert.Equal(t, "85.00 EUR", projection.ExcessCost.String())
+	})
+
+	t.Run("returned contract uses the return mileage", func(t *testing.T) {
+		lease := newLease()
+		lease.Status = models.LeaseStatusReturned
+		lease.ReturnDate = timePtr(date(2025, time.June, 1))
+		lease.ReturnMileage = intPtr(60512)
+		lease.CurrentMileage = intPtr(30012)
+		lease.MileageRecordedAt = timePtr(date(2025, time.January, 1))
+
+		projection := lease.ProjectMileage()
+		assert.True(t, projection.Final)
+		assert.Equal(t, 60500, projection.ProjectedKm)
+		assert.Equal(t, 500, projection.ExcessKm)
+		assert.Equal(t, "42.50 EUR", projection.ExcessCost.String())
+	})
+}
+
+func TestLeaseContract_DaysRemaining(t *testing.T) {
+	lease := newLease()
+	assert.Equal(t, 31, lease.DaysRemaining(date(2025, time.December, 1)))
+	assert.Equal(t, 0, lease.DaysRemaining(date(2026, time.January, 1)))
+	assert.Equal(t, -14, lease.DaysRemaining(date(2026, time.January, 15)))
+	// The time of day does not matter
+	assert.Equal(t, 31, lease.DaysRemaining(time.Date(2025, time.December, 1, 23, 30, 0, 0, time.UTC)))
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/pkg/oidc"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// OIDCLoginState holds the values generated when a login starts, which the
+// browser keeps until the provider redirects back
+type OIDCLoginState struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+// OIDCService handles single sign-on through an OpenID Connect provider
+type OIDCService struct {
+	userRepo      *repository.UserRepository
+	actionLogRepo *repository.ActionLogRepository
+	authService   *AuthService
+	provider      *oidc.Provider
+	oidcConfig    *config.OIDCConfig
+}
+
+// NewOIDCService creates a new OIDC service
+func NewOIDCService(
+	userRepo *repository.UserRepository,
+	actionLogRepo *repository.ActionLogRepository,
+	authService *AuthService,
+	provider *oidc.Provider,
+	oidcConfig *config.OIDCConfig,
+) *OIDCService {
+	return &OIDCService{
+		userRepo:      userRepo,
+		actionLogRepo: actionLogRepo,
+		authService:   authService,
+		provider:      provider,
+		oidcConfig:    oidcConfig,
+	}
+}
+
+// BeginLogin returns the provider URL to send the browser to, and the state
+// to keep until the callback
+func (s *OIDCService) BeginLogin(ctx context.Context) (string, *OIDCLoginState, error) {
+	var values [3]string
+	for i := range values {
+		value, err := oidc.RandomString()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to generate login state: %w", err)
+		}
+		values[i] = value
+	}
+	state := &OIDCLoginState{State: values[0], Nonce: values[1], CodeVerifier: values[2]}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state.State, state.Nonce, oidc.CodeChallenge(state.CodeVerifier))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return authURL, state, nil
+}
+
+// CompleteLogin handles the provider callback: it checks the state, redeems
+// the code, maps the identity to an employee and opens a session
+func (s *OIDCService) CompleteLogin(ctx context.Context, state *OIDCLoginState, returnedState, code, ipAddress, userAgent string) (*models.AdministrativeEmployee, *models.Session, error) {
+	if state == nil || returnedState == "" || subtle.ConstantTimeCompare([]byte(state.State), []byte(returnedState)) != 1 {
+		return nil, nil, fmt.Errorf("invalid login state")
+	}
+	if code == "" {
+		return nil, nil, fmt.Errorf("authorization code is required")
+	}
+
+	token, err := s.provider.Exchange(ctx, code, state.CodeVerifier)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	claims, err := s.provider.VerifyIDToken(ctx, token.IDToken, state.Nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.resolveUser(ctx, claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session, err := s.authService.StartSession(ctx, user, ipAddress, userAgent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, session, nil
+}
+
+// resolveUser finds the employee linked to the identity. On the first login
+// the identity is linked to the employee with the same verified email, or a
+// new employee is provisioned when enabled.
+func (s *OIDCService) resolveUser(ctx context.Context, claims *oidc.Claims) (*models.AdministrativeEmployee, error) {
+	user, err := s.userRepo.FindByOIDCSubject(ctx, claims.Issuer, claims.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if !strings.Contains(err.Error(), "not found") {
+		return nil, err
+	}
+
+	// Without a verified email, anyone able to set an arbitrary address at
+	// the provider could take over an account
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" || !claims.EmailVerified {
+		return nil, fmt.Errorf("identity provider did not return a verified email")
+	}
+
+	user, err = s.userRepo.FindByEmail(ctx, email)
+	if err == nil {
+		if err := s.userRepo.LinkOIDCSubject(ctx, user.ID, claims.Issuer, claims.Subject); err != nil {
+			return nil, err
+		}
+		s.logAction(ctx, user.ID, models.ActionTypeSSOLink, user.ID, map[string]interface{}{
+			"issuer":  claims.Issuer,
+			"subject": claims.Subject,
+		})
+		return user, nil
+	}
+	if !strings.Contains(err.Error(), "not found") {
+		return nil, err
+	}
+
+	if !s.oidcConfig.AutoProvision {
+		return nil, fmt.Errorf("no employee matches this identity")
+	}
+
+	return s.provision(ctx, claims, email)
+}
+
+// provision creates an employee for an identity, with the role given by its
+// groups. The employee gets no usable password and signs in through SSO.
+func (s *OIDCService) provision(ctx context.Context, claims *oidc.Claims, email string) (*models.AdministrativeEmployee, error) {
+	role, ok := OIDCProvisionRole(s.oidcConfig, claims.Strings(s.oidcConfig.GroupsClaim))
+	if !ok {
+		return nil, fmt.Errorf("identity is not allowed to access AutoParc")
+	}
+
+	placeholder, err := utils.GenerateSessionToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	passwordHash, err := utils.HashPassword(placeholder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" && lastName == "" {
+		firstName, lastName, _ = strings.Cut(strings.TrimSpace(claims.Name), " ")
+	}
+	if firstName == "" {
+		firstName, _, _ = strings.Cut(email, "@")
+	}
+	if lastName == "" {
+		lastName = "-"
+	}
+
+	employee := &models.AdministrativeEmployee{
+		ID:           uuid.New().String(),
+		Email:        email,
+		PasswordHash: passwordHash,
+		FirstName:    firstName,
+		LastName:     lastName,
+		Role:         role,
+		IsActive:     true,
+	}
+	if err := s.userRepo.Create(ctx, employee); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.LinkOIDCSubject(ctx, employee.ID, claims.Issuer, claims.Subject); err != nil {
+		return nil, err
+	}
+
+	// Provisioned employees are recorded as having created themselves
+	s.logAction(ctx, employee.ID, models.ActionTypeCreate, employee.ID, map[string]interface{}{
+		"email":   employee.Email,
+		"role":    employee.Role,
+		"source":  "sso",
+		"issuer":  claims.Issuer,
+		"subject": claims.Subject,
+	})
+
+	return s.userRepo.FindByID(ctx, employee.ID)
+}
+
+// OIDCProvisionRole returns the role of a provisioned employee: the role of
+// the first configured group the user belongs to, or the default role. When
+// groups are configured, users in none of them are refused unless a default
+// role is set.
+func OIDCProvisionRole(cfg *config.OIDCConfig, groups []string) (string, bool) {
+	for _, groupRole := range cfg.GroupRoles {
+		for _, group := range groups {
+			if group == groupRole.Group {
+				return groupRole.Role, true
+			}
+		}
+	}
+
+	if cfg.DefaultRole != "" {
+		return cfg.DefaultRole, true
+	}
+	return "", false
+}
+
+func (s *OIDCService) logAction(ctx context.Context, employeeID string, actionType models.ActionType, performedBy string, changes map[string]interface{}) {
+	changesJSON, _ := json.Marshal(changes)
+	s.actionLogRepo.Create(ctx, &models.ActionLog{
+		ID:          uuid.New().String(),
+		EntityType:  models.EntityTypeAdministrativeEmployee,
+		EntityID:    employeeID,
+		ActionType:  actionType,
+		PerformedBy: performedBy,
+		Changes:     changesJSON,
+		Timestamp:   time.Now(),
+	})
+}
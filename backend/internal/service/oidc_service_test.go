@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// Service tests for SSO role provisioning
+
+func TestOIDCProvisionRole(t *testing.T) {
+	groupRoles := []config.OIDCGroupRole{
+		{Group: "fleet-admins", Role: "admin"},
+		{Group: "fleet", Role: "manager"},
+	}
+
+	tests := []struct {
+		name     string
+		cfg      *config.OIDCConfig
+		groups   []string
+		wantRole string
+		wantOK   bool
+	}{
+		{name: "first configured group wins", cfg: &config.OIDCConfig{GroupRoles: groupRoles}, groups: []string{"fleet", "fleet-admins"}, wantRole: "admin", wantOK: true},
+		{name: "single group", cfg: &config.OIDCConfig{GroupRoles: groupRoles}, groups: []string{"fleet"}, wantRole: "manager", wantOK: true},
+		{name: "no matching group", cfg: &config.OIDCConfig{GroupRoles: groupRoles}, groups: []string{"sales"}, wantOK: false},
+		{name: "no matching group with default", cfg: &config.OIDCConfig{GroupRoles: groupRoles, DefaultRole: "viewer"}, groups: []string{"sales"}, wantRole: "viewer", wantOK: true},
+		{name: "default only", cfg: &config.OIDCConfig{DefaultRole: "viewer"}, wantRole: "viewer", wantOK: true},
+		{name: "nothing configured", cfg: &config.OIDCConfig{}, groups: []string{"fleet"}, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role, ok := OIDCProvisionRole(tt.cfg, tt.groups)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantRole, role)
+		})
+	}
+}
@@ -133,20 +133,24 @@ func (s *OperatorService) GetOperators(ctx context.Context, filters *models.Oper
 		filters.Limit = 20
 	}
 
-	operators, totalCount, err := s.operatorRepo.FindAll(ctx, filters)
+	operators, totalCount, cursors, err := s.operatorRepo.FindAll(ctx, filters)
 	if err != nil {
 		return nil, err
 	}
 
-	totalPages := (totalCount + filters.Limit - 1) / filters.Limit
-
-	return &models.OperatorListResponse{
+	response := &models.OperatorListResponse{
 		Data:       operators,
-		Total:      totalCount,
-		Page:       filters.Page,
 		Limit:      filters.Limit,
-		TotalPages: totalPages,
-	}, nil
+		NextCursor: cursors.Next,
+		PrevCursor: cursors.Prev,
+	}
+	if filters.Cursor == nil {
+		response.Total = totalCount
+		response.Page = filters.Page
+		response.TotalPages = (totalCount + filters.Limit - 1) / filters.Limit
+	}
+
+	return response, nil
 }
 
 // UpdateOperator updates an operator and logs the action
@@ -504,3 +508,38 @@ func (s *OperatorService) GetOperatorAssignmentHistory(ctx context.Context, oper
 
 	return s.operatorRepo.FindAssignmentHistory(ctx, filters)
 }
+
+// GetAssignmentHistoryPage retrieves a page of the assignment history of the
+// car or operator selected by filters
+func (s *OperatorService) GetAssignmentHistoryPage(ctx context.Context, filters *models.AssignmentFilters) (*models.AssignmentHistoryResponse, error) {
+	if (filters.CarID == nil || !utils.ValidateRequired(*filters.CarID)) && (filters.OperatorID == nil || !utils.ValidateRequired(*filters.OperatorID)) {
+		return nil, fmt.Errorf("car ID or operator ID is required")
+	}
+
+	// Set defaults
+	if filters.Page < 1 {
+		filters.Page = 1
+	}
+	if filters.Limit < 1 || filters.Limit > 100 {
+		filters.Limit = 20
+	}
+
+	assignments, totalCount, cursors, err := s.operatorRepo.FindAssignmentHistoryPage(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.AssignmentHistoryResponse{
+		Data:       assignments,
+		Limit:      filters.Limit,
+		NextCursor: cursors.Next,
+		PrevCursor: cursors.Prev,
+	}
+	if filters.Cursor == nil {
+		response.Total = totalCount
+		response.Page = filters.Page
+		response.TotalPages = (totalCount + filters.Limit - 1) / filters.Limit
+	}
+
+	return response, nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/pkg/breached"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
+)
+
+// PasswordPolicy checks passwords wherever they are set: employee creation,
+// password changes, invitations and resets
+type PasswordPolicy struct {
+	userRepo       *repository.UserRepository
+	passwordConfig *config.PasswordConfig
+	breachedList   *breached.List
+}
+
+// NewPasswordPolicy creates a new password policy. The breached list may be
+// nil when the check is disabled.
+func NewPasswordPolicy(
+	userRepo *repository.UserRepository,
+	passwordConfig *config.PasswordConfig,
+	breachedList *breached.List,
+) *PasswordPolicy {
+	return &PasswordPolicy{
+		userRepo:       userRepo,
+		passwordConfig: passwordConfig,
+		breachedList:   breachedList,
+	}
+}
+
+// Validate checks the length, character classes and breached list rules
+func (p *PasswordPolicy) Validate(password string) error {
+	cfg := p.passwordConfig
+
+	if len([]rune(password)) < cfg.MinLength {
+		return fmt.Errorf("password must be at least %d characters long", cfg.MinLength)
+	}
+	if len(password) > utils.MaxPasswordLength {
+		return fmt.Errorf("password must be at most %d bytes long", utils.MaxPasswordLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	var missing []string
+	if cfg.RequireUppercase && !hasUpper {
+		missing = append(missing, "one uppercase letter")
+	}
+	if cfg.RequireLowercase && !hasLower {
+		missing = append(missing, "one lowercase letter")
+	}
+	if cfg.RequireDigit && !hasDigit {
+		missing = append(missing, "one number")
+	}
+	if cfg.RequireSymbol && !hasSymbol {
+		missing = append(missing, "one symbol")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("password must contain at least %s", strings.Join(missing, ", "))
+	}
+
+	if cfg.CheckBreached && p.breachedList != nil && p.breachedList.Contains(password) {
+		return fmt.Errorf("password must not be a commonly used password")
+	}
+
+	return nil
+}
+
+// CheckReuse rejects a password matching the current one or one of the
+// previous passwords kept in the history
+func (p *PasswordPolicy) CheckReuse(ctx context.Context, userID, password string) error {
+	if p.passwordConfig.HistorySize <= 0 {
+		return nil
+	}
+
+	hashes, err := p.userRepo.RecentPasswordHashes(ctx, userID, p.passwordConfig.HistorySize)
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		if utils.CheckPassword(hash, password) {
+			return fmt.Errorf("password must differ from the last %d passwords", p.passwordConfig.HistorySize)
+		}
+	}
+
+	return nil
+}
+
+// Expired reports whether the password of the employee has to be changed
+// at the next login
+func (p *PasswordPolicy) Expired(user *models.AdministrativeEmployee, now time.Time) bool {
+	return p.passwordConfig.MaxAge > 0 && now.Sub(user.PasswordChangedAt) > p.passwordConfig.MaxAge
+}
+
+// SetPassword validates a new password for an existing employee, checks it
+// against the history and stores it
+func (p *PasswordPolicy) SetPassword(ctx context.Context, userID, password string) error {
+	if err := p.Validate(password); err != nil {
+		return err
+	}
+	if err := p.CheckReuse(ctx, userID, password); err != nil {
+		return err
+	}
+
+	return p.store(ctx, userID, password)
+}
+
+// store hashes and saves a password already checked against the policy
+func (p *PasswordPolicy) store(ctx context.Context, userID, password string) error {
+	passwordHash, err := utils.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := p.userRepo.UpdatePassword(ctx, userID, passwordHash); err != nil {
+		return err
+	}
+
+	// The current password is part of the history
+	keep := p.passwordConfig.HistorySize
+	if keep < 1 {
+		keep = 1
+	}
+	return p.userRepo.PrunePasswordHistory(ctx, userID, keep)
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/breached"
+	"github.com/stretchr/testify/assert"
+)
+
+// Service tests for the password policy
+
+func defaultPasswordConfig() *config.PasswordConfig {
+	return &config.PasswordConfig{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		CheckBreached:    true,
+		HistorySize:      5,
+	}
+}
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	policy := NewPasswordPolicy(nil, defaultPasswordConfig(), breached.New())
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  string
+	}{
+		{"valid strong password", "SecurePass123", ""},
+		{"valid with special chars", "Secure@Pass123!", ""},
+		{"valid with accents", "Écureuil2025", ""},
+		{"too short", "Pass1", "at least 8 characters"},
+		{"no uppercase", "password123", "one uppercase letter"},
+		{"no lowercase", "PASSWORD123", "one lowercase letter"},
+		{"no numbers", "PasswordSecure", "one number"},
+		{"empty password", "", "at least 8 characters"},
+		{"too long for bcrypt", "Aa1" + strings.Repeat("x", 70), "at most 72 bytes"},
+		{"breached", "Password123", "commonly used"},
+		{"breached with other case", "AZERTY123a", ""},
+		{"breached ignoring case", "Azerty123", "commonly used"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.True(t, strings.HasPrefix(err.Error(), "password must"))
+			}
+		})
+	}
+}
+
+func TestPasswordPolicy_ValidateConfigured(t *testing.T) {
+	cfg := &config.PasswordConfig{MinLength: 12, RequireSymbol: true}
+	policy := NewPasswordPolicy(nil, cfg, nil)
+
+	assert.NoError(t, policy.Validate("tout en minuscules !"))
+	assert.EqualError(t, policy.Validate("toutenminuscules"), "password must contain at least one symbol")
+	assert.EqualError(t, policy.Validate("court !"), "password must be at least 12 characters long")
+
+	// Without a list, the breached check is skipped
+	cfg.CheckBreached = true
+	assert.NoError(t, policy.Validate("password123!"))
+}
+
+func TestPasswordPolicy_Expired(t *testing.T) {
+	now := time.Now()
+	user := &models.AdministrativeEmployee{PasswordChangedAt: now.Add(-100 * 24 * time.Hour)}
+
+	cfg := defaultPasswordConfig()
+	policy := NewPasswordPolicy(nil, cfg, nil)
+	assert.False(t, policy.Expired(user, now), "expiry is disabled by default")
+
+	cfg.MaxAge = 90 * 24 * time.Hour
+	assert.True(t, policy.Expired(user, now))
+
+	user.PasswordChangedAt = now.Add(-89 * 24 * time.Hour)
+	assert.False(t, policy.Expired(user, now))
+}
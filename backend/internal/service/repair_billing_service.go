@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// RepairBillingService handles repair quotes and invoices
+type RepairBillingService struct {
+	billingRepo   *repository.RepairBillingRepository
+	repairRepo    *repository.RepairRepository
+	garageRepo    *repository.GarageRepository
+	actionLogRepo *repository.ActionLogRepository
+	overrunRate   decimal.Decimal
+}
+
+// NewRepairBillingService creates a new repair billing service
+func NewRepairBillingService(
+	billingRepo *repository.RepairBillingRepository,
+	repairRepo *repository.RepairRepository,
+	garageRepo *repository.GarageRepository,
+	actionLogRepo *repository.ActionLogRepository,
+	repairConfig *config.RepairConfig,
+) *RepairBillingService {
+	return &RepairBillingService{
+		billingRepo:   billingRepo,
+		repairRepo:    repairRepo,
+		garageRepo:    garageRepo,
+		actionLogRepo: actionLogRepo,
+		overrunRate:   decimal.NewFromFloat(repairConfig.QuoteOverrunPercent),
+	}
+}
+
+// CreateQuote adds a garage quote to a repair
+func (s *RepairBillingService) CreateQuote(ctx context.Context, repairID string, req *models.CreateRepairQuoteRequest, userID string) (*models.RepairQuote, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repairRepo.FindByID(ctx, repairID); err != nil {
+		return nil, fmt.Errorf("réparation non trouvée")
+	}
+	if _, err := s.garageRepo.FindByID(ctx, req.GarageID); err != nil {
+		return nil, fmt.Errorf("garage non trouvé")
+	}
+
+	now := time.Now()
+	quote := &models.RepairQuote{
+		ID:          uuid.New().String(),
+		RepairID:    repairID,
+		GarageID:    req.GarageID,
+		QuoteNumber: req.QuoteNumber,
+		QuoteDate:   req.QuoteDate,
+		Amount:      req.Amount,
+		Status:      models.QuoteStatusPending,
+		Notes:       req.Notes,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		CreatedBy:   &userID,
+	}
+
+	if err := s.billingRepo.CreateQuote(ctx, quote); err != nil {
+		return nil, err
+	}
+
+	s.logAction(ctx, repairID, models.ActionTypeUpdate, userID, map[string]interface{}{
+		"quote": map[string]interface{}{
+			"id":       quote.ID,
+			"garageId": quote.GarageID,
+			"amount":   quote.Amount,
+		},
+	})
+
+	return s.billingRepo.FindQuoteByID(ctx, quote.ID)
+}
+
+// GetQuotes retrieves the quotes of a repair
+func (s *RepairBillingService) GetQuotes(ctx context.Context, repairID string) ([]*models.RepairQuote, error) {
+	if _, err := s.repairRepo.FindByID(ctx, repairID); err != nil {
+		return nil, fmt.Errorf("réparation non trouvée")
+	}
+
+	return s.billingRepo.FindQuotesByRepairID(ctx, repairID)
+}
+
+// UpdateQuoteStatus accepts or rejects a quote of a repair
+func (s *RepairBillingService) UpdateQuoteStatus(ctx context.Context, repairID, quoteID string, status models.QuoteStatus, userID string) (*models.RepairQuote, error) {
+	if err := models.ValidateQuoteStatus(status); err != nil {
+		return nil, err
+	}
+
+	quote, err := s.findRepairQuote(ctx, repairID, quoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.billingRepo.UpdateQuoteStatus(ctx, quoteID, status); err != nil {
+		return nil, err
+	}
+
+	s.logAction(ctx, repairID, models.ActionTypeStatusChange, userID, map[string]interface{}{
+		"quoteId": quoteID,
+		"status": map[string]string{
+			"old": string(quote.Status),
+			"new": string(status),
+		},
+	})
+
+	return s.billingRepo.FindQuoteByID(ctx, quoteID)
+}
+
+// DeleteQuote deletes a quote of a repair
+func (s *RepairBillingService) DeleteQuote(ctx context.Context, repairID, quoteID string, userID string) error {
+	if _, err := s.findRepairQuote(ctx, repairID, quoteID); err != nil {
+		return err
+	}
+
+	if err := s.billingRepo.DeleteQuote(ctx, quoteID); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, repairID, models.ActionTypeUpdate, userID, map[string]interface{}{
+		"deletedQuoteId": quoteID,
+	})
+
+	return nil
+}
+
+// AddInvoiceLine adds a line to the invoice of a repair
+func (s *RepairBillingService) AddInvoiceLine(ctx context.Context, repairID string, req *models.CreateInvoiceLineRequest, userID string) (*models.InvoiceLine, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repairRepo.FindByID(ctx, repairID); err != nil {
+		return nil, fmt.Errorf("réparation non trouvée")
+	}
+
+	currency := req.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	line := &models.InvoiceLine{
+		ID:          uuid.New().String(),
+		RepairID:    repairID,
+		Description: req.Description,
+		Quantity:    req.Quantity,
+		UnitPrice:   req.UnitPrice,
+		VATRate:     req.VATRate,
+		Category:    req.Category,
+		Currency:    currency,
+		CreatedAt:   time.Now(),
+		CreatedBy:   &userID,
+	}
+	line.ComputeTotals()
+
+	if err := s.billingRepo.CreateInvoiceLine(ctx, line); err != nil {
+		return nil, err
+	}
+
+	s.logAction(ctx, repairID, models.ActionTypeUpdate, userID, map[string]interface{}{
+		"invoiceLine": line,
+	})
+
+	return line, nil
+}
+
+// DeleteInvoiceLine removes a line from the invoice of a repair
+func (s *RepairBillingService) DeleteInvoiceLine(ctx context.Context, repairID, lineID string, userID string) error {
+	if err := s.billingRepo.DeleteInvoiceLine(ctx, repairID, lineID); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, repairID, models.ActionTypeUpdate, userID, map[string]interface{}{
+		"deletedInvoiceLineId": lineID,
+	})
+
+	return nil
+}
+
+// GetInvoice retrieves the invoice of a repair with its totals and compares
+// it with the accepted quote
+func (s *RepairBillingService) GetInvoice(ctx context.Context, repairID string) (*models.RepairInvoice, error) {
+	repair, err := s.repairRepo.FindByID(ctx, repairID)
+	if err != nil {
+		return nil, fmt.Errorf("réparation non trouvée")
+	}
+
+	lines, err := s.billingRepo.FindInvoiceLines(ctx, repairID)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := s.billingRepo.FindAcceptedQuote(ctx, repairID)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := models.ComputeInvoiceTotals(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice := &models.RepairInvoice{
+		RepairID:      repairID,
+		InvoiceNumber: repair.InvoiceNumber,
+		Lines:         lines,
+		Totals:        totals,
+		AcceptedQuote: quote,
+	}
+
+	if quote != nil && len(lines) > 0 {
+		comparison, err := models.CompareToQuote(quote.Amount, totals.TotalInclVAT, s.overrunRate)
+		if err != nil {
+			return nil, err
+		}
+		invoice.QuoteComparison = &comparison
+	}
+
+	return invoice, nil
+}
+
+// GetOverQuoteRepairs lists repairs whose invoice exceeds the accepted quote
+// by more than the configured percentage
+func (s *RepairBillingService) GetOverQuoteRepairs(ctx context.Context) ([]*models.OverQuoteRepair, error) {
+	return s.billingRepo.FindOverQuote(ctx, s.overrunRate)
+}
+
+// GetRepairCosts aggregates repair costs per currency and group
+func (s *RepairBillingService) GetRepairCosts(ctx context.Context, filters *models.RepairCostFilters) ([]*models.RepairCostSummary, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.repairRepo.SumCosts(ctx, filters)
+}
+
+// findRepairQuote retrieves a quote and checks it belongs to the repair
+func (s *RepairBillingService) findRepairQuote(ctx context.Context, repairID, quoteID string) (*models.RepairQuote, error) {
+	quote, err := s.billingRepo.FindQuoteByID(ctx, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	if quote.RepairID != repairID {
+		return nil, fmt.Errorf("devis non trouvé")
+	}
+	return quote, nil
+}
+
+func (s *RepairBillingService) logAction(ctx context.Context, repairID string, actionType models.ActionType, userID string, changes map[string]interface{}) {
+	changesJSON, _ := json.Marshal(changes)
+	log := &models.ActionLog{
+		ID:          uuid.New().String(),
+		EntityType:  models.EntityTypeRepair,
+		EntityID:    repairID,
+		ActionType:  actionType,
+		PerformedBy: userID,
+		Changes:     changesJSON,
+		Timestamp:   time.Now(),
+	}
+	s.actionLogRepo.Create(ctx, log)
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// Service tests for repair quote and invoice logic
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func eur(s string) money.Money {
+	return money.New(dec(s), "EUR")
+}
+
+func TestComputeInvoiceTotals(t *testing.T) {
+	lines := []*models.InvoiceLine{
+		{Description: "Pare-choc avant", Quantity: dec("1"), UnitPrice: dec("412.50"), VATRate: dec("20"), Category: models.InvoiceLineCategoryParts, Currency: "EUR"},
+		{Description: "Clips", Quantity: dec("3"), UnitPrice: dec("0.1"), VATRate: dec("20"), Category: models.InvoiceLineCategoryParts, Currency: "EUR"},
+		{Description: "Main d'oeuvre", Quantity: dec("2.5"), UnitPrice: dec("65.333"), VATRate: dec("20"), Category: models.InvoiceLineCategoryLabour, Currency: "EUR"},
+	}
+
+	totals, err := models.ComputeInvoiceTotals(lines)
+	assert.NoError(t, err)
+
+	// 0.1 * 3 is exactly 0.30, not 0.30000000000000004
+	assert.Equal(t, "0.3", lines[1].TotalExclVAT.String())
+	// 2.5 * 65.333 = 163.3325, rounded to the cent
+	assert.Equal(t, "163.33", lines[2].TotalExclVAT.String())
+	assert.Equal(t, "32.67", lines[2].VATAmount.String())
+
+	assert.Equal(t, "412.80 EUR", totals.PartsExclVAT.String())
+	assert.Equal(t, "163.33 EUR", totals.LabourExclVAT.String())
+	assert.Equal(t, "576.13 EUR", totals.TotalExclVAT.String())
+	assert.Equal(t, "115.23 EUR", totals.VATAmount.String())
+	assert.Equal(t, "691.36 EUR", totals.TotalInclVAT.String())
+	assert.True(t, totals.TotalInclVAT.Amount.Equal(totals.TotalExclVAT.Amount.Add(totals.VATAmount.Amount)))
+}
+
+func TestComputeInvoiceTotals_Empty(t *testing.T) {
+	totals, err := models.ComputeInvoiceTotals(nil)
+	assert.NoError(t, err)
+	assert.True(t, totals.TotalInclVAT.IsZero())
+	assert.Equal(t, "EUR", totals.TotalInclVAT.Currency)
+}
+
+func TestComputeInvoiceTotals_MixedCurrencies(t *testing.T) {
+	lines := []*models.InvoiceLine{
+		{Quantity: dec("1"), UnitPrice: dec("10"), VATRate: dec("20"), Category: models.InvoiceLineCategoryParts, Currency: "EUR"},
+		{Quantity: dec("1"), UnitPrice: dec("10"), VATRate: dec("7.7"), Category: models.InvoiceLineCategoryParts, Currency: "CHF"},
+	}
+
+	_, err := models.ComputeInvoiceTotals(lines)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "même devise")
+}
+
+func TestCompareToQuote(t *testing.T) {
+	tests := []struct {
+		name        string
+		quote       string
+		invoice     string
+		threshold   string
+		wantRate    string
+		wantExceeds bool
+	}{
+		{name: "under quote", quote: "1000", invoice: "950", threshold: "10", wantRate: "-5", wantExceeds: false},
+		{name: "exactly at threshold", quote: "1000", invoice: "1100", threshold: "10", wantRate: "10", wantExceeds: false},
+		{name: "rounds down to threshold", quote: "1000", invoice: "1100.01", threshold: "10", wantRate: "10", wantExceeds: false},
+		{name: "clearly over threshold", quote: "1000", invoice: "1150", threshold: "10", wantRate: "15", wantExceeds: true},
+		{name: "zero threshold", quote: "1000", invoice: "1000.50", threshold: "0", wantRate: "0.05", wantExceeds: true},
+		{name: "free quote", quote: "0", invoice: "10", threshold: "10", wantRate: "0", wantExceeds: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison, err := models.CompareToQuote(eur(tt.quote), eur(tt.invoice), dec(tt.threshold))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRate, comparison.DifferenceRate.String())
+			assert.Equal(t, tt.wantExceeds, comparison.ExceedsQuote)
+			assert.True(t, comparison.Difference.Amount.Equal(dec(tt.invoice).Sub(dec(tt.quote))))
+		})
+	}
+}
+
+func TestCompareToQuote_CurrencyMismatch(t *testing.T) {
+	_, err := models.CompareToQuote(eur("100"), money.New(dec("100"), "USD"), dec("10"))
+	assert.Error(t, err)
+}
+
+func TestCreateRepairQuoteRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *models.CreateRepairQuoteRequest
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "valid quote",
+			req:     &models.CreateRepairQuoteRequest{GarageID: "garage-id", QuoteDate: time.Now(), Amount: eur("1250.40")},
+			wantErr: false,
+		},
+		{
+			name:    "missing garage",
+			req:     &models.CreateRepairQuoteRequest{QuoteDate: time.Now(), Amount: eur("10")},
+			wantErr: true,
+			errMsg:  "l'identifiant du garage est requis",
+		},
+		{
+			name:    "missing date",
+			req:     &models.CreateRepairQuoteRequest{GarageID: "garage-id", Amount: eur("10")},
+			wantErr: true,
+			errMsg:  "la date du devis est requise",
+		},
+		{
+			name:    "negative amount",
+			req:     &models.CreateRepairQuoteRequest{GarageID: "garage-id", QuoteDate: time.Now(), Amount: eur("-1")},
+			wantErr: true,
+			errMsg:  "ne peut pas être négatif",
+		},
+		{
+			name:    "sub-cent amount",
+			req:     &models.CreateRepairQuoteRequest{GarageID: "garage-id", QuoteDate: time.Now(), Amount: eur("10.001")},
+			wantErr: true,
+			errMsg:  "plus de 2 décimales",
+		},
+		{
+			name:    "invalid currency",
+			req:     &models.CreateRepairQuoteRequest{GarageID: "garage-id", QuoteDate: time.Now(), Amount: money.New(dec("10"), "eur")},
+			wantErr: true,
+			errMsg:  "devise invalide",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errMsg != "" {
+					assert.Contains(t, err.Error(), tt.errMsg)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCreateInvoiceLineRequest_Validate(t *testing.T) {
+	valid := func() *models.CreateInvoiceLineRequest {
+		return &models.CreateInvoiceLineRequest{
+			Description: "Plaquettes de frein",
+			Quantity:    dec("2"),
+			UnitPrice:   dec("45.90"),
+			VATRate:     dec("20"),
+			Category:    models.InvoiceLineCategoryParts,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(req *models.CreateInvoiceLineRequest)
+		wantErr bool
+		errMsg  string
+	}{
+		{name: "valid line", modify: func(req *models.CreateInvoiceLineRequest) {}},
+		{name: "labour line", modify: func(req *models.CreateInvoiceLineRequest) { req.Category = models.InvoiceLineCategoryLabour }},
+		{name: "missing description", modify: func(req *models.CreateInvoiceLineRequest) { req.Description = " " }, wantErr: true, errMsg: "la description est requise"},
+		{name: "zero quantity", modify: func(req *models.CreateInvoiceLineRequest) { req.Quantity = dec("0") }, wantErr: true, errMsg: "la quantité doit être positive"},
+		{name: "too precise quantity", modify: func(req *models.CreateInvoiceLineRequest) { req.Quantity = dec("1.0001") }, wantErr: true, errMsg: "plus de 3 décimales"},
+		{name: "negative unit price", modify: func(req *models.CreateInvoiceLineRequest) { req.UnitPrice = dec("-1") }, wantErr: true, errMsg: "ne peut pas être négatif"},
+		{name: "VAT rate over 100", modify: func(req *models.CreateInvoiceLineRequest) { req.VATRate = dec("120") }, wantErr: true, errMsg: "taux de TVA"},
+		{name: "invalid category", modify: func(req *models.CreateInvoiceLineRequest) { req.Category = "misc" }, wantErr: true, errMsg: "catégorie invalide"},
+		{name: "invalid currency", modify: func(req *models.CreateInvoiceLineRequest) { req.Currency = "EURO" }, wantErr: true, errMsg: "devise invalide"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(req)
+			err := req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errMsg != "" {
+					assert.Contains(t, err.Error(), tt.errMsg)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -213,15 +213,11 @@ func (s *RepairService) UpdateRepair(ctx context.Context, id string, req *models
 	}
 
 	if req.Cost != nil {
-		oldValue := float64(0)
-		if existingRepair.Cost != nil {
-			oldValue = *existingRepair.Cost
-		}
-		if existingRepair.Cost == nil || *req.Cost != *existingRepair.Cost {
-			updates["cost"] = *req.Cost
+		if existingRepair.Cost == nil || !req.Cost.Equal(*existingRepair.Cost) {
+			repository.SetRepairCostUpdates(updates, req.Cost)
 			changes["cost"] = map[string]interface{}{
-				"old": oldValue,
-				"new": *req.Cost,
+				"old": existingRepair.Cost,
+				"new": req.Cost,
 			}
 		}
 	}
@@ -1,11 +1,14 @@
 package service
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/pkg/money"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Service tests for repair validation logic
@@ -13,8 +16,8 @@ import (
 func TestCreateRepairRequest_Validate(t *testing.T) {
 	now := time.Now()
 	future := now.Add(24 * time.Hour)
-	cost := 1500.50
-	negativeCost := -100.0
+	cost := money.NewPriceInclVAT(dec("1500.50"), money.DefaultVATRate, money.DefaultCurrency)
+	negativeCost := money.NewPriceInclVAT(dec("-100"), money.DefaultVATRate, money.DefaultCurrency)
 	accidentID := "accident-123"
 
 	tests := []struct {
@@ -128,6 +131,19 @@ func TestCreateRepairRequest_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "le coût ne peut pas être négatif",
 		},
+		{
+			name: "invalid cost currency",
+			req: &models.CreateRepairRequest{
+				CarID:       "car-123",
+				GarageID:    "garage-123",
+				RepairType:  models.RepairTypeMaintenance,
+				Description: "Oil change",
+				StartDate:   now,
+				Cost:        &money.Price{InclVAT: dec("10"), VATRate: dec("20"), Currency: "euro"},
+			},
+			wantErr: true,
+			errMsg:  "devise invalide",
+		},
 		{
 			name: "valid with all optional fields",
 			req: &models.CreateRepairRequest{
@@ -177,8 +193,8 @@ func TestUpdateRepairRequest_Validate(t *testing.T) {
 	now := time.Now()
 	future := now.Add(24 * time.Hour)
 	past := now.Add(-24 * time.Hour)
-	cost := 1500.50
-	negativeCost := -100.0
+	cost := money.NewPriceInclVAT(dec("1500.50"), money.DefaultVATRate, money.DefaultCurrency)
+	negativeCost := money.NewPriceInclVAT(dec("-100"), money.DefaultVATRate, money.DefaultCurrency)
 
 	tests := []struct {
 		name    string
@@ -412,3 +428,25 @@ func TestRepairStatus_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestRepair_JSONCost(t *testing.T) {
+	cost := money.NewPriceInclVAT(dec("1500.5"), money.DefaultVATRate, money.DefaultCurrency)
+	data, err := json.Marshal(&models.Repair{ID: "repair-123", Cost: &cost})
+	require.NoError(t, err)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &body))
+	assert.Equal(t, 1500.5, body["cost"])
+	details, ok := body["costDetails"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "1250.42", details["amountExclVat"])
+
+	data, err = json.Marshal(&models.Repair{ID: "repair-123"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "cost")
+
+	var req models.CreateRepairRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"cost": 120}`), &req))
+	require.NotNil(t, req.Cost)
+	assert.True(t, req.Cost.ExclVAT.Equal(dec("100")))
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+	// maxSearchTerms bounds the size of the tsquery built from user input
+	maxSearchTerms = 10
+)
+
+// SearchService handles the global search across the fleet
+type SearchService struct {
+	searchRepo *repository.SearchRepository
+}
+
+// NewSearchService creates a new search service
+func NewSearchService(searchRepo *repository.SearchRepository) *SearchService {
+	return &SearchService{
+		searchRepo: searchRepo,
+	}
+}
+
+// Search returns the records matching every word of the query, best match
+// first. The last characters of each word may be left out, so that "dup"
+// already finds "Dupont".
+func (s *SearchService) Search(ctx context.Context, filters *models.SearchFilters) (*models.SearchResponse, error) {
+	query := strings.TrimSpace(filters.Query)
+	tsquery := buildSearchQuery(query)
+	if tsquery == "" {
+		return nil, fmt.Errorf("search query must contain at least 2 letters or digits")
+	}
+
+	types := filters.Types
+	if len(types) == 0 {
+		types = models.SearchResultTypes
+	}
+	for _, t := range types {
+		if !t.IsValid() {
+			return nil, fmt.Errorf("invalid type: %s", t)
+		}
+	}
+
+	limit := filters.Limit
+	if limit < 1 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	results, err := s.searchRepo.Search(ctx, tsquery, types, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	return &models.SearchResponse{
+		Query:   query,
+		Results: results,
+	}, nil
+}
+
+// buildSearchQuery turns free text into a to_tsquery expression requiring
+// every word as a prefix, e.g. "AB-123 Dup" gives "AB:* & 123:* & Dup:*".
+// Words only keep letters and digits so the input cannot inject tsquery
+// operators. It returns "" when there is nothing to search for.
+func buildSearchQuery(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	length := 0
+	for _, word := range words {
+		if len(terms) == maxSearchTerms {
+			break
+		}
+		terms = append(terms, word+":*")
+		length += len([]rune(word))
+	}
+
+	if length < 2 {
+		return ""
+	}
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// Service tests for the global search
+
+func TestBuildSearchQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"single word", "Dupont", "Dupont:*"},
+		{"license plate", "AB-123-CD", "AB:* & 123:* & CD:*"},
+		{"accents are kept", "Sévigné", "Sévigné:*"},
+		{"tsquery operators are dropped", "dup & !(font | x):*", "dup:* & font:* & x:*"},
+		{"too short", " a ", ""},
+		{"only punctuation", "--- !!", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, buildSearchQuery(tt.query))
+		})
+	}
+}
+
+func TestBuildSearchQuery_LimitsTerms(t *testing.T) {
+	query := buildSearchQuery("a b c d e f g h i j k l")
+	assert.Equal(t, "a:* & b:* & c:* & d:* & e:* & f:* & g:* & h:* & i:* & j:*", query)
+}
+
+func TestSearchService_Search_Validation(t *testing.T) {
+	service := NewSearchService(nil)
+
+	_, err := service.Search(context.Background(), &models.SearchFilters{Query: "x"})
+	assert.ErrorContains(t, err, "at least 2 letters or digits")
+
+	_, err = service.Search(context.Background(), &models.SearchFilters{
+		Query: "Dupont",
+		Types: []models.SearchResultType{models.SearchResultCar, "invoice"},
+	})
+	assert.ErrorContains(t, err, "invalid type: invoice")
+}
@@ -0,0 +1,484 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/pkg/mailer"
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/goldenkiwi/autoparc/pkg/plates"
+	"github.com/google/uuid"
+)
+
+// designationExportHeader are the columns of the designation export, named
+// after the fields of the ANTAI designation form
+var designationExportHeader = []string{
+	"numero_avis", "immatriculation", "date_infraction", "heure_infraction", "lieu",
+	"matricule", "nom", "prenom", "email", "date_limite",
+}
+
+// TrafficFineService handles traffic fine business logic
+type TrafficFineService struct {
+	fineRepo      *repository.TrafficFineRepository
+	carRepo       *repository.CarRepository
+	operatorRepo  *repository.OperatorRepository
+	userRepo      *repository.UserRepository
+	actionLogRepo *repository.ActionLogRepository
+	mailer        mailer.Mailer
+	fineConfig    *config.FineConfig
+}
+
+// NewTrafficFineService creates a new traffic fine service
+func NewTrafficFineService(
+	fineRepo *repository.TrafficFineRepository,
+	carRepo *repository.CarRepository,
+	operatorRepo *repository.OperatorRepository,
+	userRepo *repository.UserRepository,
+	actionLogRepo *repository.ActionLogRepository,
+	mailer mailer.Mailer,
+	fineConfig *config.FineConfig,
+) *TrafficFineService {
+	return &TrafficFineService{
+		fineRepo:      fineRepo,
+		carRepo:       carRepo,
+		operatorRepo:  operatorRepo,
+		userRepo:      userRepo,
+		actionLogRepo: actionLogRepo,
+		mailer:        mailer,
+		fineConfig:    fineConfig,
+	}
+}
+
+// CreateFine records a traffic fine. The car is found by its plate and the
+// driver is the operator assigned to it at the time of the offense, unless
+// the car changed hands that day.
+func (s *TrafficFineService) CreateFine(ctx context.Context, req *models.CreateTrafficFineRequest, userID string) (*models.TrafficFine, error) {
+	carID, err := s.carRepo.FindIDByPlate(ctx, plates.Compact(req.LicensePlate))
+	if err != nil {
+		return nil, err
+	}
+	if carID == nil {
+		return nil, fmt.Errorf("no car with license plate %s", strings.TrimSpace(req.LicensePlate))
+	}
+	car, err := s.carRepo.FindByID(ctx, *carID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if req.OffenseAt.After(now) {
+		return nil, fmt.Errorf("offense date cannot be in the future")
+	}
+	noticeDate := now.UTC().Truncate(24 * time.Hour)
+	if req.NoticeDate != nil {
+		noticeDate = *req.NoticeDate
+	}
+
+	fine := &models.TrafficFine{
+		ID:                  uuid.New().String(),
+		NoticeNumber:        models.NormalizeNoticeNumber(req.NoticeNumber),
+		CarID:               car.ID,
+		LicensePlate:        car.LicensePlate,
+		OffenseAt:           req.OffenseAt,
+		Location:            strings.TrimSpace(req.Location),
+		Offense:             nonEmpty(req.Offense),
+		Amount:              req.Amount,
+		NoticeDate:          noticeDate,
+		DesignationDeadline: models.DesignationDeadlineFor(noticeDate),
+		Status:              models.FineStatusPending,
+		Notes:               req.Notes,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+		CreatedBy:           &userID,
+	}
+	if fine.Amount.Currency == "" {
+		fine.Amount.Currency = money.DefaultCurrency
+	}
+	if err := fine.Validate(); err != nil {
+		return nil, err
+	}
+
+	// On a handover day both operators held the car: leave the driver to
+	// be chosen by hand rather than guess. Assignments are kept by local
+	// day, whatever offset the offense time was sent with.
+	operatorIDs, err := s.operatorRepo.FindOperatorsAt(ctx, car.ID, req.OffenseAt.In(s.location()))
+	if err != nil {
+		return nil, err
+	}
+	if len(operatorIDs) == 1 {
+		fine.OperatorID = &operatorIDs[0]
+	}
+
+	if err := s.fineRepo.Create(ctx, fine); err != nil {
+		return nil, err
+	}
+
+	s.logAction(ctx, fine.ID, models.ActionTypeCreate, userID, fine)
+	return s.fineRepo.FindByID(ctx, fine.ID)
+}
+
+// GetFine retrieves a traffic fine by ID
+func (s *TrafficFineService) GetFine(ctx context.Context, id string) (*models.TrafficFine, error) {
+	return s.fineRepo.FindByID(ctx, id)
+}
+
+// GetFines retrieves the traffic fines matching the filters
+func (s *TrafficFineService) GetFines(ctx context.Context, filters *models.TrafficFineFilters) ([]*models.TrafficFine, error) {
+	if filters.Status != "" && !filters.Status.IsValid() {
+		return nil, fmt.Errorf("invalid status: %s", filters.Status)
+	}
+	return s.fineRepo.FindAll(ctx, filters)
+}
+
+// GetDueFines lists the pending fines whose designation deadline is within
+// the given number of days (the reminder window by default), overdue ones
+// included
+func (s *TrafficFineService) GetDueFines(ctx context.Context, days int) ([]*models.DueFine, error) {
+	if days == 0 {
+		days = s.fineConfig.ReminderDays
+	}
+	if days < 0 || days > models.DesignationPeriodDays {
+		return nil, fmt.Errorf("invalid days: must be between 1 and %d", models.DesignationPeriodDays)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	deadline := today.AddDate(0, 0, days)
+	fines, err := s.fineRepo.FindAll(ctx, &models.TrafficFineFilters{
+		Status:         models.FineStatusPending,
+		DeadlineBefore: &deadline,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	due := make([]*models.DueFine, len(fines))
+	for i, fine := range fines {
+		due[i] = &models.DueFine{TrafficFine: fine, DaysRemaining: fine.DaysRemaining(today)}
+	}
+	return due, nil
+}
+
+// UpdateFine updates the details of a fine. The deadline follows the notice
+// date, and the driver can be changed until it is designated.
+func (s *TrafficFineService) UpdateFine(ctx context.Context, id string, req *models.UpdateTrafficFineRequest, userID string) (*models.TrafficFine, error) {
+	fine, err := s.fineRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Location != nil {
+		fine.Location = strings.TrimSpace(*req.Location)
+	}
+	if req.Offense != nil {
+		fine.Offense = nonEmpty(req.Offense)
+	}
+	if req.Amount != nil {
+		fine.Amount = *req.Amount
+		if fine.Amount.Currency == "" {
+			fine.Amount.Currency = money.DefaultCurrency
+		}
+	}
+	if req.NoticeDate != nil {
+		fine.NoticeDate = *req.NoticeDate
+		fine.DesignationDeadline = models.DesignationDeadlineFor(fine.NoticeDate)
+	}
+	if req.OperatorID != nil {
+		if fine.Status == models.FineStatusDesignated {
+			return nil, fmt.Errorf("cannot change the driver of a fine already designated")
+		}
+		fine.OperatorID = nonEmpty(req.OperatorID)
+		if fine.OperatorID != nil {
+			if _, err := s.operatorRepo.FindByID(ctx, *fine.OperatorID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if req.Status != nil {
+		if !req.Status.IsValid() {
+			return nil, fmt.Errorf("invalid status: %s", *req.Status)
+		}
+		if *req.Status == models.FineStatusDesignated && fine.Status != models.FineStatusDesignated {
+			return nil, fmt.Errorf("use the designation to mark a fine as designated")
+		}
+		if *req.Status != models.FineStatusDesignated {
+			fine.DesignatedAt = nil
+		}
+		fine.Status = *req.Status
+	}
+	if req.Notes != nil {
+		fine.Notes = req.Notes
+	}
+
+	if err := fine.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.fineRepo.Update(ctx, fine); err != nil {
+		return nil, err
+	}
+
+	s.logAction(ctx, fine.ID, models.ActionTypeUpdate, userID, req)
+	return s.fineRepo.FindByID(ctx, fine.ID)
+}
+
+// DesignateDriver records that the driver of a pending fine was designated
+// to the authorities
+func (s *TrafficFineService) DesignateDriver(ctx context.Context, id string, req *models.DesignateDriverRequest, userID string) (*models.TrafficFine, error) {
+	fine, err := s.fineRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if fine.Status != models.FineStatusPending {
+		return nil, fmt.Errorf("fine is %s: only pending fines can be designated", fine.Status)
+	}
+
+	operatorID := nonEmpty(req.OperatorID)
+	if operatorID == nil {
+		operatorID = fine.OperatorID
+	}
+	if operatorID == nil {
+		return nil, fmt.Errorf("driver is required: no operator held the car at the time of the offense")
+	}
+	if _, err := s.operatorRepo.FindByID(ctx, *operatorID); err != nil {
+		return nil, err
+	}
+
+	designatedAt := time.Now()
+	if req.DesignatedAt != nil {
+		designatedAt = *req.DesignatedAt
+	}
+	if designatedAt.After(time.Now()) {
+		return nil, fmt.Errorf("designation date cannot be in the future")
+	}
+
+	fine.OperatorID = operatorID
+	fine.Status = models.FineStatusDesignated
+	fine.DesignatedAt = &designatedAt
+	if err := s.fineRepo.Update(ctx, fine); err != nil {
+		return nil, err
+	}
+
+	s.logAction(ctx, fine.ID, models.ActionTypeFineDesignation, userID, map[string]interface{}{
+		"operatorId":   *operatorID,
+		"designatedAt": designatedAt,
+	})
+	return s.fineRepo.FindByID(ctx, fine.ID)
+}
+
+// DeleteFine deletes a traffic fine
+func (s *TrafficFineService) DeleteFine(ctx context.Context, id string, userID string) error {
+	if err := s.fineRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, id, models.ActionTypeDelete, userID, nil)
+	return nil
+}
+
+// ExportDesignations builds the CSV of the pending fines whose driver is
+// known, ready to fill in the designation on the ANTAI website
+func (s *TrafficFineService) ExportDesignations(ctx context.Context, filters *models.TrafficFineFilters) ([]byte, error) {
+	filters.Status = models.FineStatusPending
+	fines, err := s.fineRepo.FindAll(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := designationCSV(fines, s.location())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build designation export: %w", err)
+	}
+	return data, nil
+}
+
+// designationCSV writes one line per fine with a driver, in the
+// semicolon separated format spreadsheets expect in France. Offense times
+// are written in loc, the time zone of the notices.
+func designationCSV(fines []*models.TrafficFine, loc *time.Location) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Comma = ';'
+
+	if err := writer.Write(designationExportHeader); err != nil {
+		return nil, err
+	}
+	for _, fine := range fines {
+		if fine.Driver == nil {
+			continue
+		}
+		email := ""
+		if fine.Driver.Email != nil {
+			email = *fine.Driver.Email
+		}
+		offenseAt := fine.OffenseAt.In(loc)
+		record := []string{
+			fine.NoticeNumber,
+			fine.LicensePlate,
+			offenseAt.Format("02/01/2006"),
+			offenseAt.Format("15:04"),
+			fine.Location,
+			fine.Driver.EmployeeNumber,
+			fine.Driver.LastName,
+			fine.Driver.FirstName,
+			email,
+			fine.DesignationDeadline.Format("02/01/2006"),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SendDesignationReminders emails each employee the pending fines they
+// recorded whose deadline is within the reminder window, once per fine. It
+// returns the number of fines reminded.
+func (s *TrafficFineService) SendDesignationReminders(ctx context.Context, today time.Time) (int, error) {
+	deadline := time.Date(today.Year(), today.Month(), today.Day()+s.fineConfig.ReminderDays, 0, 0, 0, 0, time.UTC)
+	fines, err := s.fineRepo.FindAll(ctx, &models.TrafficFineFilters{
+		Status:         models.FineStatusPending,
+		DeadlineBefore: &deadline,
+		NotReminded:    true,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	byEmployee := map[string][]*models.TrafficFine{}
+	for _, fine := range fines {
+		if fine.CreatedBy == nil {
+			continue
+		}
+		byEmployee[*fine.CreatedBy] = append(byEmployee[*fine.CreatedBy], fine)
+	}
+
+	employeeIDs := make([]string, 0, len(byEmployee))
+	for id := range byEmployee {
+		employeeIDs = append(employeeIDs, id)
+	}
+	sort.Strings(employeeIDs)
+
+	reminded := 0
+	for _, employeeID := range employeeIDs {
+		employee, err := s.userRepo.FindByID(ctx, employeeID)
+		if err != nil {
+			log.Printf("Failed to find employee %s for fine reminders: %v", employeeID, err)
+			continue
+		}
+
+		employeeFines := byEmployee[employeeID]
+		subject, body := designationReminder(employee.FirstName, employeeFines, today, s.location())
+		if err := s.mailer.Send(ctx, mailer.Message{To: employee.Email, Subject: subject, Body: body}); err != nil {
+			log.Printf("Failed to send fine reminder to %s: %v", employee.Email, err)
+			continue
+		}
+
+		ids := make([]string, len(employeeFines))
+		for i, fine := range employeeFines {
+			ids[i] = fine.ID
+		}
+		if err := s.fineRepo.MarkReminded(ctx, ids, time.Now()); err != nil {
+			return reminded, err
+		}
+		reminded += len(ids)
+	}
+
+	return reminded, nil
+}
+
+// designationReminder builds the reminder email listing the fines to
+// designate, with offense times in loc
+func designationReminder(firstName string, fines []*models.TrafficFine, today time.Time, loc *time.Location) (string, string) {
+	subject := fmt.Sprintf("%d amende(s) à désigner sur AutoParc", len(fines))
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Bonjour %s,\n\n", firstName)
+	body.WriteString("Le conducteur des amendes suivantes doit être désigné avant la date limite :\n\n")
+	for _, fine := range fines {
+		driver := "conducteur inconnu"
+		if fine.Driver != nil {
+			driver = fine.Driver.FirstName + " " + fine.Driver.LastName
+		}
+		remaining := fine.DaysRemaining(today)
+		when := fmt.Sprintf("dans %d jour(s)", remaining)
+		if remaining < 0 {
+			when = "dépassée"
+		}
+		fmt.Fprintf(&body, "- Avis %s, %s, le %s : %s (date limite le %s, %s)\n",
+			fine.NoticeNumber,
+			fine.LicensePlate,
+			fine.OffenseAt.In(loc).Format("02/01/2006 15:04"),
+			driver,
+			fine.DesignationDeadline.Format("02/01/2006"),
+			when,
+		)
+	}
+
+	return subject, body.String()
+}
+
+// location returns the time zone offense times are written in, UTC unless
+// configured
+func (s *TrafficFineService) location() *time.Location {
+	if s.fineConfig.Location == nil {
+		return time.UTC
+	}
+	return s.fineConfig.Location
+}
+
+// StartDesignationReminders sends the designation reminders every
+// ReminderInterval until ctx is cancelled
+func (s *TrafficFineService) StartDesignationReminders(ctx context.Context) {
+	if s.fineConfig.ReminderInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.fineConfig.ReminderInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reminded, err := s.SendDesignationReminders(ctx, time.Now().UTC())
+				if err != nil {
+					log.Printf("Failed to send fine reminders: %v", err)
+					continue
+				}
+				if reminded > 0 {
+					log.Printf("Sent reminders for %d traffic fines", reminded)
+				}
+			}
+		}
+	}()
+}
+
+func (s *TrafficFineService) logAction(ctx context.Context, fineID string, actionType models.ActionType, userID string, changes interface{}) {
+	changesJSON, _ := json.Marshal(changes)
+	log := &models.ActionLog{
+		ID:          uuid.New().String(),
+		EntityType:  models.EntityTypeTrafficFine,
+		EntityID:    fineID,
+		ActionType:  actionType,
+		PerformedBy: userID,
+		Changes:     changesJSON,
+		Timestamp:   time.Now(),
+	}
+	s.actionLogRepo.Create(ctx, log)
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Service tests for traffic fine logic
+
+func newFine() *models.TrafficFine {
+	return &models.TrafficFine{
+		NoticeNumber:        "1234567890",
+		LicensePlate:        "AB-123-CD",
+		OffenseAt:           time.Date(2025, time.March, 3, 7, 15, 0, 0, time.UTC),
+		Location:            "A7, Valence",
+		Amount:              eur("135"),
+		NoticeDate:          date(2025, time.March, 10),
+		DesignationDeadline: date(2025, time.April, 24),
+	}
+}
+
+func TestNormalizeNoticeNumber(t *testing.T) {
+	assert.Equal(t, "12345678901AB", models.NormalizeNoticeNumber(" 1234 5678 901 ab "))
+}
+
+func TestTrafficFine_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(f *models.TrafficFine)
+		wantErr string
+	}{
+		{name: "valid fine", modify: func(f *models.TrafficFine) {}},
+		{name: "notice number too short", modify: func(f *models.TrafficFine) { f.NoticeNumber = "12345" }, wantErr: "invalid notice number"},
+		{name: "notice number with dashes", modify: func(f *models.TrafficFine) { f.NoticeNumber = "1234-5678" }, wantErr: "invalid notice number"},
+		{name: "missing offense date", modify: func(f *models.TrafficFine) { f.OffenseAt = time.Time{} }, wantErr: "offense date is required"},
+		{name: "missing location", modify: func(f *models.TrafficFine) { f.Location = "  " }, wantErr: "location is required"},
+		{name: "negative amount", modify: func(f *models.TrafficFine) { f.Amount = eur("-1") }, wantErr: "cannot be negative"},
+		{name: "too many decimals", modify: func(f *models.TrafficFine) { f.Amount = eur("135.005") }, wantErr: "2 decimal places"},
+		{name: "notice before offense", modify: func(f *models.TrafficFine) { f.NoticeDate = date(2025, time.March, 2) }, wantErr: "notice date cannot be before"},
+		{name: "notice on the day of the offense", modify: func(f *models.TrafficFine) { f.NoticeDate = date(2025, time.March, 3) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fine := newFine()
+			tt.modify(fine)
+			err := fine.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDesignationDeadlineFor(t *testing.T) {
+	assert.Equal(t, date(2025, time.April, 24), models.DesignationDeadlineFor(date(2025, time.March, 10)))
+	// Across the end of the year
+	assert.Equal(t, date(2026, time.January, 29), models.DesignationDeadlineFor(date(2025, time.December, 15)))
+}
+
+func TestTrafficFine_DaysRemaining(t *testing.T) {
+	fine := newFine()
+	assert.Equal(t, 10, fine.DaysRemaining(date(2025, time.April, 14)))
+	assert.Equal(t, 0, fine.DaysRemaining(date(2025, time.April, 24)))
+	assert.Equal(t, -2, fine.DaysRemaining(date(2025, time.April, 26)))
+}
+
+func TestDesignationCSV(t *testing.T) {
+	email := "paul.durand@autoparc.fr"
+	withDriver := newFine()
+	withDriver.Driver = &models.FineDriver{
+		EmployeeNumber: "EMP001",
+		FirstName:      "Paul",
+		LastName:       "Durand",
+		Email:          &email,
+	}
+	withoutDriver := newFine()
+	withoutDriver.NoticeNumber = "9999999999"
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	data, err := designationCSV([]*models.TrafficFine{withDriver, withoutDriver}, paris)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "numero_avis;immatriculation;date_infraction;heure_infraction;lieu;matricule;nom;prenom;email;date_limite", lines[0])
+	assert.Equal(t, "1234567890;AB-123-CD;03/03/2025;08:15;A7, Valence;EMP001;Durand;Paul;paul.durand@autoparc.fr;24/04/2025", lines[1])
+}
+
+func TestDesignationReminder(t *testing.T) {
+	known := newFine()
+	known.Driver = &models.FineDriver{FirstName: "Paul", LastName: "Durand"}
+	unknown := newFine()
+	unknown.NoticeNumber = "9999999999"
+	unknown.DesignationDeadline = date(2025, time.April, 12)
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	subject, body := designationReminder("Marie", []*models.TrafficFine{known, unknown}, date(2025, time.April, 14), paris)
+	assert.Equal(t, "2 amende(s) à désigner sur AutoParc", subject)
+	assert.True(t, strings.HasPrefix(body, "Bonjour Marie,"))
+	assert.Contains(t, body, "- Avis 1234567890, AB-123-CD, le 03/03/2025 08:15 : Paul Durand (date limite le 24/04/2025, dans 10 jour(s))")
+	assert.Contains(t, body, "- Avis 9999999999, AB-123-CD, le 03/03/2025 08:15 : conducteur inconnu (date limite le 12/04/2025, dépassée)")
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/pkg/totp"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
+	"github.com/google/uuid"
+)
+
+const (
+	// recoveryCodeCount is the number of recovery codes issued on enrollment
+	recoveryCodeCount = 10
+	// totpSkew accepts codes from the previous and next periods to tolerate
+	// clock drift between the server and the authenticator
+	totpSkew = 1
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactorService handles TOTP enrollment, verification and recovery codes
+type TwoFactorService struct {
+	userRepo        *repository.UserRepository
+	sessionRepo     *repository.SessionRepository
+	twoFactorRepo   *repository.TwoFactorRepository
+	actionLogRepo   *repository.ActionLogRepository
+	authService     *AuthService
+	twoFactorConfig *config.TwoFactorConfig
+}
+
+// NewTwoFactorService creates a new two-factor service
+func NewTwoFactorService(
+	userRepo *repository.UserRepository,
+	sessionRepo *repository.SessionRepository,
+	twoFactorRepo *repository.TwoFactorRepository,
+	actionLogRepo *repository.ActionLogRepository,
+	authService *AuthService,
+	twoFactorConfig *config.TwoFactorConfig,
+) *TwoFactorService {
+	return &TwoFactorService{
+		userRepo:        userRepo,
+		sessionRepo:     sessionRepo,
+		twoFactorRepo:   twoFactorRepo,
+		actionLogRepo:   actionLogRepo,
+		authService:     authService,
+		twoFactorConfig: twoFactorConfig,
+	}
+}
+
+// Setup starts an enrollment by generating a new secret. Calling it again
+// before Enable replaces the pending secret.
+func (s *TwoFactorService) Setup(ctx context.Context, user *models.AdministrativeEmployee) (*models.TwoFactorSetup, error) {
+	if user.TwoFactorEnabled {
+		return nil, fmt.Errorf("two-factor authentication already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	if err := s.twoFactorRepo.SetSecret(ctx, user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.twoFactorConfig.Issuer, user.Email, secret),
+	}, nil
+}
+
+// Enable confirms the enrollment with a code from the authenticator app and
+// returns the recovery codes, which are only available at this point
+func (s *TwoFactorService) Enable(ctx context.Context, user *models.AdministrativeEmployee, code string) ([]string, error) {
+	secret, enabled, err := s.twoFactorRepo.GetSecret(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, fmt.Errorf("two-factor authentication already enabled")
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("two-factor enrollment not started")
+	}
+
+	if ok, err := s.checkTOTP(ctx, user.ID, secret, code); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("invalid two-factor code")
+	}
+
+	codes, hashes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.Enable(ctx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	s.logAction(ctx, user.ID, models.ActionTypeTwoFactorEnable, user.ID, nil)
+
+	return codes, nil
+}
+
+// Verify checks the second factor of a pending session, accepting either a
+// TOTP code or an unused recovery code, and returns the session that
+// replaces it
+func (s *TwoFactorService) Verify(ctx context.Context, user *models.AdministrativeEmployee, pending *models.Session, code string) (*models.Session, error) {
+	if !pending.MFAPending {
+		return nil, fmt.Errorf("session already verified")
+	}
+
+	secret, enabled, err := s.twoFactorRepo.GetSecret(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, fmt.Errorf("two-factor authentication not enabled")
+	}
+
+	ok, usedRecoveryCode, err := s.checkCode(ctx, user.ID, secret, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		s.authService.RecordTwoFactorFailure(ctx, user, pending.IPAddress, pending.UserAgent)
+		return nil, fmt.Errorf("invalid two-factor code")
+	}
+
+	if usedRecoveryCode {
+		remaining, _ := s.twoFactorRepo.CountRecoveryCodes(ctx, user.ID)
+		s.logAction(ctx, user.ID, models.ActionTypeRecoveryCodeUsed, user.ID, map[string]interface{}{
+			"remainingRecoveryCodes": remaining,
+		})
+	}
+
+	return s.authService.PromoteSession(ctx, pending)
+}
+
+// Disable turns 2FA off for the current employee after checking a code.
+// Employees whose role requires 2FA cannot disable it.
+func (s *TwoFactorService) Disable(ctx context.Context, user *models.AdministrativeEmployee, code string) error {
+	if s.authService.TwoFactorRequired(user.Role) {
+		return fmt.Errorf("two-factor authentication is required for this role")
+	}
+
+	secret, enabled, err := s.twoFactorRepo.GetSecret(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return fmt.Errorf("two-factor authentication not enabled")
+	}
+
+	if ok, _, err := s.checkCode(ctx, user.ID, secret, code); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("invalid two-factor code")
+	}
+
+	if err := s.twoFactorRepo.Disable(ctx, user.ID); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, user.ID, models.ActionTypeTwoFactorDisable, user.ID, nil)
+
+	return nil
+}
+
+// Reset removes the 2FA of an employee who lost their device, on behalf of
+// an administrator, and revokes their sessions
+func (s *TwoFactorService) Reset(ctx context.Context, employeeID, performedBy string) error {
+	if _, err := uuid.Parse(employeeID); err != nil {
+		return fmt.Errorf("invalid employee ID format")
+	}
+
+	if err := s.twoFactorRepo.Disable(ctx, employeeID); err != nil {
+		return err
+	}
+
+	revoked, err := s.sessionRepo.DeleteByUserID(ctx, employeeID, "")
+	if err != nil {
+		return err
+	}
+
+	s.logAction(ctx, employeeID, models.ActionTypeTwoFactorReset, performedBy, map[string]interface{}{
+		"revokedSessions": revoked,
+	})
+
+	return nil
+}
+
+// checkCode accepts a TOTP code or a recovery code and reports which one was used
+func (s *TwoFactorService) checkCode(ctx context.Context, userID, secret, code string) (bool, bool, error) {
+	if ok, err := s.checkTOTP(ctx, userID, secret, code); err != nil || ok {
+		return ok, false, err
+	}
+
+	normalized := NormalizeRecoveryCode(code)
+	if normalized == "" {
+		return false, false, nil
+	}
+
+	ok, err := s.twoFactorRepo.ConsumeRecoveryCode(ctx, userID, utils.HashSessionToken(normalized))
+	return ok, ok, err
+}
+
+// checkTOTP validates a TOTP code and records its time step so that the same
+// code cannot be used twice
+func (s *TwoFactorService) checkTOTP(ctx context.Context, userID, secret, code string) (bool, error) {
+	step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok {
+		return false, nil
+	}
+	return s.twoFactorRepo.UseStep(ctx, userID, step)
+}
+
+func (s *TwoFactorService) logAction(ctx context.Context, employeeID string, actionType models.ActionType, performedBy string, changes map[string]interface{}) {
+	changesJSON, _ := json.Marshal(changes)
+	s.actionLogRepo.Create(ctx, &models.ActionLog{
+		ID:          uuid.New().String(),
+		EntityType:  models.EntityTypeAdministrativeEmployee,
+		EntityID:    employeeID,
+		ActionType:  actionType,
+		PerformedBy: performedBy,
+		Changes:     changesJSON,
+		Timestamp:   time.Now(),
+	})
+}
+
+// generateRecoveryCodes returns n recovery codes formatted as XXXX-XXXX-XXXX-XXXX
+// along with their hashes. Each code carries 80 random bits, enough for an
+// unsalted SHA-256 hash to resist brute force.
+func generateRecoveryCodes(n int) ([]string, []string, error) {
+	codes := make([]string, n)
+	hashes := make([]string, n)
+
+	for i := range codes {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		raw := recoveryCodeEncoding.EncodeToString(b)
+		codes[i] = raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16]
+		hashes[i] = utils.HashSessionToken(raw)
+	}
+
+	return codes, hashes, nil
+}
+
+// NormalizeRecoveryCode uppercases a recovery code and strips separators, so
+// that codes typed with spaces, dashes or in lowercase are accepted
+func NormalizeRecoveryCode(code string) string {
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return strings.ToUpper(code)
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/goldenkiwi/autoparc/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Service tests for two-factor recovery codes
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := generateRecoveryCodes(recoveryCodeCount)
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	require.Len(t, hashes, recoveryCodeCount)
+
+	format := regexp.MustCompile(`^[A-Z2-7]{4}-[A-Z2-7]{4}-[A-Z2-7]{4}-[A-Z2-7]{4}$`)
+	seen := map[string]bool{}
+	for i, code := range codes {
+		assert.Regexp(t, format, code)
+		assert.False(t, seen[code], "duplicate recovery code")
+		seen[code] = true
+
+		// Codes are hashed in their normalized form
+		assert.Equal(t, utils.HashSessionToken(NormalizeRecoveryCode(code)), hashes[i])
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	assert.Equal(t, "ABCD2345EFGH6712", NormalizeRecoveryCode("abcd-2345-efgh-6712"))
+	assert.Equal(t, "ABCD2345EFGH6712", NormalizeRecoveryCode(" ABCD 2345 EFGH 6712 "))
+	assert.Equal(t, "", NormalizeRecoveryCode("- -"))
+}
@@ -0,0 +1,68 @@
+// Package breached checks passwords against a local list of commonly used
+// and breached passwords. A small list is bundled; larger ones can be loaded
+// from a file without sending passwords to any external service.
+package breached
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+//go:embed common_passwords.txt
+var bundled string
+
+// List is a set of passwords, compared case-insensitively
+type List struct {
+	entries map[string]struct{}
+}
+
+// New returns a list holding the bundled passwords
+func New() *List {
+	l := &List{entries: map[string]struct{}{}}
+	// The bundled list is known to be valid
+	_ = l.Load(strings.NewReader(bundled))
+	return l
+}
+
+// Load adds the passwords read from r, one per line. Blank lines and lines
+// starting with # are ignored.
+func (l *List) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		l.entries[strings.ToLower(line)] = struct{}{}
+	}
+	return scanner.Err()
+}
+
+// LoadFile adds the passwords of a file in the format read by Load
+func (l *List) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer f.Close()
+
+	if err := l.Load(f); err != nil {
+		return fmt.Errorf("failed to read breached password list: %w", err)
+	}
+	return nil
+}
+
+// Contains reports whether password is in the list
+func (l *List) Contains(password string) bool {
+	_, ok := l.entries[strings.ToLower(password)]
+	return ok
+}
+
+// Len returns the number of passwords in the list
+func (l *List) Len() int {
+	return len(l.entries)
+}
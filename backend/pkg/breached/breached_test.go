@@ -0,0 +1,61 @@
+package breached
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBundledList(t *testing.T) {
+	l := New()
+
+	if l.Len() < 100 {
+		t.Errorf("Len() = %d, want the bundled list", l.Len())
+	}
+
+	for _, password := range []string{"password", "Password123!", "AZERTY123", "motdepasse"} {
+		if !l.Contains(password) {
+			t.Errorf("Contains(%q) = false, want true", password)
+		}
+	}
+	for _, password := range []string{"Tr0ub4dor&3-horse", "", "# Commonly used and breached passwords, compared case-insensitively."} {
+		if l.Contains(password) {
+			t.Errorf("Contains(%q) = true, want false", password)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	l := New()
+	before := l.Len()
+
+	if err := l.Load(strings.NewReader("# comment\n\n  Garage2025  \nflotte123\n")); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !l.Contains("garage2025") {
+		t.Error("Contains() = false for a loaded password")
+	}
+	if l.Len() != before+1 {
+		t.Errorf("Len() = %d, want %d (duplicates are merged)", l.Len(), before+1)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.txt")
+	if err := os.WriteFile(path, []byte("Entreprise2025\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	l := New()
+	if err := l.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if !l.Contains("entreprise2025") {
+		t.Error("Contains() = false for a password loaded from file")
+	}
+
+	if err := l.LoadFile(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("LoadFile() expected an error for a missing file")
+	}
+}
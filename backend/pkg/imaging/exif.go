@@ -0,0 +1,337 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"time"
+)
+
+// Metadata holds the EXIF fields we keep after stripping them from the file
+type Metadata struct {
+	TakenAt     *time.Time
+	Latitude    *float64
+	Longitude   *float64
+	Orientation int
+}
+
+// EXIF tag identifiers
+const (
+	tagOrientation        = 0x0112
+	tagDateTime           = 0x0132
+	tagExifIFDPointer     = 0x8769
+	tagGPSIFDPointer      = 0x8825
+	tagDateTimeOriginal   = 0x9003
+	tagOffsetTimeOriginal = 0x9011
+	tagGPSLatitudeRef     = 0x0001
+	tagGPSLatitude        = 0x0002
+	tagGPSLongitudeRef    = 0x0003
+	tagGPSLongitude       = 0x0004
+)
+
+// EXIF field types
+const (
+	typeByte      = 1
+	typeASCII     = 2
+	typeShort     = 3
+	typeLong      = 4
+	typeRational  = 5
+	typeUndefined = 7
+	typeSLong     = 9
+	typeSRational = 10
+)
+
+const exifDateLayout = "2006:01:02 15:04:05"
+
+var exifHeader = []byte("Exif\x00\x00")
+
+// ExtractMetadata reads capture time, GPS coordinates and orientation from the
+// EXIF block of a JPEG, PNG or WebP file. Missing or malformed EXIF data is not
+// an error: the returned metadata is simply empty.
+func ExtractMetadata(data []byte, mimeType string) Metadata {
+	var tiff []byte
+	switch mimeType {
+	case MimeTypeJPEG:
+		tiff = findJPEGExif(data)
+	case MimeTypePNG:
+		tiff = findPNGExif(data)
+	case MimeTypeWebP:
+		tiff = findWebPExif(data)
+	}
+
+	if len(tiff) == 0 {
+		return Metadata{Orientation: 1}
+	}
+
+	return parseTIFF(tiff)
+}
+
+// findJPEGExif returns the TIFF payload of the APP1 Exif segment
+func findJPEGExif(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil
+		}
+		marker := data[pos+1]
+		// Start of scan: no more metadata segments
+		if marker == 0xDA || marker == 0xD9 {
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if length < 2 || pos+2+length > len(data) {
+			return nil
+		}
+		segment := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, exifHeader) {
+			return segment[len(exifHeader):]
+		}
+		pos += 2 + length
+	}
+
+	return nil
+}
+
+// findPNGExif returns the payload of the eXIf chunk
+func findPNGExif(data []byte) []byte {
+	const signatureLen = 8
+	pos := signatureLen
+	for pos+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		chunkType := string(data[pos+4 : pos+8])
+		if length < 0 || pos+12+length > len(data) {
+			return nil
+		}
+		if chunkType == "eXIf" {
+			return data[pos+8 : pos+8+length]
+		}
+		if chunkType == "IDAT" || chunkType == "IEND" {
+			return nil
+		}
+		pos += 12 + length
+	}
+
+	return nil
+}
+
+// findWebPExif returns the payload of the EXIF chunk of a RIFF/WebP container
+func findWebPExif(data []byte) []byte {
+	for _, chunk := range riffChunks(data) {
+		if chunk.id == "EXIF" {
+			payload := chunk.payload
+			// Some encoders keep the JPEG "Exif\0\0" prefix
+			return bytes.TrimPrefix(payload, exifHeader)
+		}
+	}
+	return nil
+}
+
+// parseTIFF walks IFD0, the Exif sub-IFD and the GPS sub-IFD
+func parseTIFF(tiff []byte) Metadata {
+	meta := Metadata{Orientation: 1}
+
+	if len(tiff) < 8 {
+		return meta
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return meta
+	}
+
+	if order.Uint16(tiff[2:4]) != 42 {
+		return meta
+	}
+
+	r := tiffReader{data: tiff, order: order}
+	ifd0 := r.readIFD(order.Uint32(tiff[4:8]))
+
+	if v, ok := ifd0[tagOrientation]; ok {
+		if o := r.uint(v); o >= 1 && o <= 8 {
+			meta.Orientation = int(o)
+		}
+	}
+
+	var dateTime, offset string
+	if v, ok := ifd0[tagDateTime]; ok {
+		dateTime = r.ascii(v)
+	}
+
+	if v, ok := ifd0[tagExifIFDPointer]; ok {
+		exifIFD := r.readIFD(uint32(r.uint(v)))
+		if v, ok := exifIFD[tagDateTimeOriginal]; ok {
+			dateTime = r.ascii(v)
+		}
+		if v, ok := exifIFD[tagOffsetTimeOriginal]; ok {
+			offset = r.ascii(v)
+		}
+	}
+
+	if dateTime != "" {
+		meta.TakenAt = parseExifTime(dateTime, offset)
+	}
+
+	if v, ok := ifd0[tagGPSIFDPointer]; ok {
+		gps := r.readIFD(uint32(r.uint(v)))
+		meta.Latitude = r.coordinate(gps, tagGPSLatitude, tagGPSLatitudeRef, "S")
+		meta.Longitude = r.coordinate(gps, tagGPSLongitude, tagGPSLongitudeRef, "W")
+	}
+
+	return meta
+}
+
+// parseExifTime parses an EXIF timestamp, using the offset tag when present.
+// EXIF timestamps without offset are interpreted as UTC.
+func parseExifTime(value, offset string) *time.Time {
+	loc := time.UTC
+	if offset != "" {
+		if t, err := time.Parse("-07:00", offset); err == nil {
+			_, seconds := t.Zone()
+			loc = time.FixedZone(offset, seconds)
+		}
+	}
+
+	t, err := time.ParseInLocation(exifDateLayout, value, loc)
+	if err != nil || t.Year() < 1900 {
+		return nil
+	}
+
+	return &t
+}
+
+// tiffEntry is a raw IFD entry
+type tiffEntry struct {
+	fieldType uint16
+	count     uint32
+	value     []byte
+}
+
+// tiffReader reads IFD entries with bounds checks on every access
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+func typeSize(fieldType uint16) int {
+	switch fieldType {
+	case typeByte, typeASCII, typeUndefined:
+		return 1
+	case typeShort:
+		return 2
+	case typeLong, typeSLong:
+		return 4
+	case typeRational, typeSRational:
+		return 8
+	default:
+		return 0
+	}
+}
+
+func (r tiffReader) readIFD(offset uint32) map[uint16]tiffEntry {
+	entries := make(map[uint16]tiffEntry)
+
+	start := int(offset)
+	if start < 0 || start+2 > len(r.data) {
+		return entries
+	}
+
+	count := int(r.order.Uint16(r.data[start : start+2]))
+	for i := 0; i < count; i++ {
+		pos := start + 2 + i*12
+		if pos+12 > len(r.data) {
+			break
+		}
+
+		tag := r.order.Uint16(r.data[pos : pos+2])
+		fieldType := r.order.Uint16(r.data[pos+2 : pos+4])
+		n := r.order.Uint32(r.data[pos+4 : pos+8])
+
+		size := typeSize(fieldType)
+		if size == 0 || n == 0 || n > uint32(len(r.data)) {
+			continue
+		}
+		total := size * int(n)
+
+		var value []byte
+		if total <= 4 {
+			value = r.data[pos+8 : pos+8+total]
+		} else {
+			valueOffset := int(r.order.Uint32(r.data[pos+8 : pos+12]))
+			if valueOffset < 0 || valueOffset+total > len(r.data) {
+				continue
+			}
+			value = r.data[valueOffset : valueOffset+total]
+		}
+
+		entries[tag] = tiffEntry{fieldType: fieldType, count: n, value: value}
+	}
+
+	return entries
+}
+
+func (r tiffReader) uint(e tiffEntry) uint32 {
+	switch e.fieldType {
+	case typeShort:
+		return uint32(r.order.Uint16(e.value[:2]))
+	case typeLong, typeSLong:
+		return r.order.Uint32(e.value[:4])
+	case typeByte, typeUndefined:
+		return uint32(e.value[0])
+	default:
+		return 0
+	}
+}
+
+func (r tiffReader) ascii(e tiffEntry) string {
+	if e.fieldType != typeASCII {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(e.value), "\x00"))
+}
+
+func (r tiffReader) rationals(e tiffEntry) []float64 {
+	if e.fieldType != typeRational {
+		return nil
+	}
+
+	values := make([]float64, 0, e.count)
+	for i := 0; i+8 <= len(e.value); i += 8 {
+		num := r.order.Uint32(e.value[i : i+4])
+		den := r.order.Uint32(e.value[i+4 : i+8])
+		if den == 0 {
+			return nil
+		}
+		values = append(values, float64(num)/float64(den))
+	}
+
+	return values
+}
+
+// coordinate converts a degrees/minutes/seconds GPS tag into signed decimal degrees
+func (r tiffReader) coordinate(gps map[uint16]tiffEntry, valueTag, refTag uint16, negativeRef string) *float64 {
+	e, ok := gps[valueTag]
+	if !ok {
+		return nil
+	}
+
+	dms := r.rationals(e)
+	if len(dms) != 3 {
+		return nil
+	}
+
+	value := dms[0] + dms[1]/60 + dms[2]/3600
+	if ref, ok := gps[refTag]; ok && strings.EqualFold(r.ascii(ref), negativeRef) {
+		value = -value
+	}
+
+	return &value
+}
@@ -0,0 +1,296 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ifdField is a field used to build test EXIF blocks
+type ifdField struct {
+	tag       uint16
+	fieldType uint16
+	count     uint32
+	value     []byte
+}
+
+// buildIFD serializes an IFD at the given offset, returning the IFD bytes
+// followed by the out-of-line values.
+func buildIFD(offset uint32, fields []ifdField) []byte {
+	le := binary.LittleEndian
+	headerLen := 2 + len(fields)*12 + 4
+	var entries, values bytes.Buffer
+
+	_ = binary.Write(&entries, le, uint16(len(fields)))
+	for _, f := range fields {
+		_ = binary.Write(&entries, le, f.tag)
+		_ = binary.Write(&entries, le, f.fieldType)
+		_ = binary.Write(&entries, le, f.count)
+		if len(f.value) <= 4 {
+			inline := make([]byte, 4)
+			copy(inline, f.value)
+			entries.Write(inline)
+		} else {
+			_ = binary.Write(&entries, le, offset+uint32(headerLen+values.Len()))
+			values.Write(f.value)
+		}
+	}
+	_ = binary.Write(&entries, le, uint32(0))
+
+	return append(entries.Bytes(), values.Bytes()...)
+}
+
+func rational(parts ...uint32) []byte {
+	var buf bytes.Buffer
+	for _, p := range parts {
+		_ = binary.Write(&buf, binary.LittleEndian, p)
+	}
+	return buf.Bytes()
+}
+
+func u16(v uint16) []byte {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, v)
+	return b
+}
+
+func u32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+// buildExif creates a little-endian TIFF block with orientation, capture time and GPS
+func buildExif(orientation uint16) []byte {
+	const ifd0Offset = 8
+	const ifd0Size = 2 + 3*12 + 4
+	exifOffset := uint32(ifd0Offset + ifd0Size)
+
+	dateTime := []byte("2025:03:14 09:26:53\x00")
+	offsetTime := []byte("+01:00\x00")
+	exifIFD := buildIFD(exifOffset, []ifdField{
+		{tagDateTimeOriginal, typeASCII, uint32(len(dateTime)), dateTime},
+		{tagOffsetTimeOriginal, typeASCII, uint32(len(offsetTime)), offsetTime},
+	})
+
+	gpsOffset := exifOffset + uint32(len(exifIFD))
+	gpsIFD := buildIFD(gpsOffset, []ifdField{
+		{tagGPSLatitudeRef, typeASCII, 2, []byte("N\x00")},
+		{tagGPSLatitude, typeRational, 3, rational(48, 1, 51, 1, 2400, 100)},
+		{tagGPSLongitudeRef, typeASCII, 2, []byte("W\x00")},
+		{tagGPSLongitude, typeRational, 3, rational(2, 1, 21, 1, 0, 1)},
+	})
+
+	ifd0 := buildIFD(ifd0Offset, []ifdField{
+		{tagOrientation, typeShort, 1, u16(orientation)},
+		{tagExifIFDPointer, typeLong, 1, u32(exifOffset)},
+		{tagGPSIFDPointer, typeLong, 1, u32(gpsOffset)},
+	})
+
+	tiff := []byte{'I', 'I', 42, 0, ifd0Offset, 0, 0, 0}
+	tiff = append(tiff, ifd0...)
+	tiff = append(tiff, exifIFD...)
+	return append(tiff, gpsIFD...)
+}
+
+// testJPEG encodes a w x h JPEG, optionally with an APP1 EXIF segment
+func testJPEG(t *testing.T, w, h int, exif []byte) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	data := buf.Bytes()
+
+	if exif == nil {
+		return data
+	}
+
+	payload := append(append([]byte(nil), exifHeader...), exif...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{0xFF, 0xD8}, segment...)
+	return append(out, data[2:]...)
+}
+
+func TestDetectMimeType(t *testing.T) {
+	pngImg := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	var pngBuf bytes.Buffer
+	require.NoError(t, png.Encode(&pngBuf, pngImg))
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "jpeg", data: testJPEG(t, 4, 4, nil), want: MimeTypeJPEG},
+		{name: "png", data: pngBuf.Bytes(), want: MimeTypePNG},
+		{name: "gif", data: []byte("GIF89a\x01\x00\x01\x00"), want: MimeTypeGIF},
+		{name: "webp", data: []byte("RIFF\x1a\x00\x00\x00WEBPVP8 "), want: MimeTypeWebP},
+		{name: "pdf disguised as image", data: []byte("%PDF-1.7\n"), want: "application/pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectMimeType(tt.data))
+		})
+	}
+}
+
+func TestExtractMetadata(t *testing.T) {
+	data := testJPEG(t, 8, 8, buildExif(6))
+
+	meta := ExtractMetadata(data, MimeTypeJPEG)
+
+	require.NotNil(t, meta.TakenAt)
+	expected := time.Date(2025, 3, 14, 8, 26, 53, 0, time.UTC)
+	assert.True(t, meta.TakenAt.Equal(expected), "got %s", meta.TakenAt)
+
+	require.NotNil(t, meta.Latitude)
+	require.NotNil(t, meta.Longitude)
+	assert.InDelta(t, 48.85667, *meta.Latitude, 0.0001)
+	assert.InDelta(t, -2.35, *meta.Longitude, 0.0001)
+	assert.Equal(t, 6, meta.Orientation)
+}
+
+func TestExtractMetadata_NoExif(t *testing.T) {
+	meta := ExtractMetadata(testJPEG(t, 8, 8, nil), MimeTypeJPEG)
+
+	assert.Nil(t, meta.TakenAt)
+	assert.Nil(t, meta.Latitude)
+	assert.Nil(t, meta.Longitude)
+	assert.Equal(t, 1, meta.Orientation)
+}
+
+func TestExtractMetadata_Truncated(t *testing.T) {
+	exif := buildExif(1)
+	data := testJPEG(t, 8, 8, exif[:20])
+
+	assert.NotPanics(t, func() {
+		ExtractMetadata(data, MimeTypeJPEG)
+	})
+}
+
+func TestProcess_StripsExifAndAppliesOrientation(t *testing.T) {
+	data := testJPEG(t, 400, 200, buildExif(6))
+
+	result, err := Process(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, MimeTypeJPEG, result.MimeType)
+	assert.False(t, bytes.Contains(result.Data, exifHeader), "EXIF must be stripped")
+	assert.Empty(t, ExtractMetadata(result.Data, MimeTypeJPEG).Latitude)
+
+	// Orientation 6 rotates the image by 90 degrees
+	assert.Equal(t, 200, result.Width)
+	assert.Equal(t, 400, result.Height)
+
+	require.NotNil(t, result.Metadata.Latitude)
+	assert.InDelta(t, 48.85667, *result.Metadata.Latitude, 0.0001)
+
+	thumb, _, err := image.DecodeConfig(bytes.NewReader(result.Thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, ThumbnailSize, thumb.Height)
+	assert.Equal(t, ThumbnailSize/2, thumb.Width)
+
+	medium, _, err := image.DecodeConfig(bytes.NewReader(result.Medium))
+	require.NoError(t, err)
+	assert.Equal(t, 400, medium.Height, "small images are not upscaled")
+}
+
+func TestProcess_StripsGIFExtensions(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	anim := &gif.GIF{LoopCount: 0}
+	for i := 0; i < 2; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, 8, 8), palette)
+		frame.SetColorIndex(i, i, 1)
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, 50)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, anim))
+	encoded := buf.Bytes()
+
+	// Insert an XMP application extension before the first frame, after the
+	// header, the logical screen descriptor and the global color table
+	offset := 13
+	if flags := encoded[10]; flags&0x80 != 0 {
+		offset += 3 << (flags&0x07 + 1)
+	}
+	xmp := []byte(`<x:xmpmeta><exif:GPSLatitude>48,51.4N</exif:GPSLatitude></x:xmpmeta>`)
+	extension := append([]byte{0x21, 0xFF, 0x0B}, "XMP DataXMP"...)
+	extension = append(extension, byte(len(xmp)))
+	extension = append(extension, xmp...)
+	extension = append(extension, 0x00)
+	data := append(append(append([]byte{}, encoded[:offset]...), extension...), encoded[offset:]...)
+
+	_, err := gif.DecodeAll(bytes.NewReader(data))
+	require.NoError(t, err, "test GIF must be valid")
+
+	result, err := Process(data)
+	require.NoError(t, err)
+	assert.Equal(t, MimeTypeGIF, result.MimeType)
+	assert.False(t, bytes.Contains(result.Data, []byte("GPSLatitude")), "XMP must be stripped")
+
+	processed, err := gif.DecodeAll(bytes.NewReader(result.Data))
+	require.NoError(t, err)
+	assert.Len(t, processed.Image, 2, "the animation is kept")
+	assert.Equal(t, []int{50, 50}, processed.Delay)
+}
+
+func TestProcess_RejectsNonImages(t *testing.T) {
+	_, err := Process([]byte("%PDF-1.7\nnot an image"))
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	// Correct magic bytes but corrupted content
+	_, err = Process([]byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F'})
+	assert.ErrorIs(t, err, ErrInvalidImage)
+}
+
+func TestStripWebPMetadata(t *testing.T) {
+	chunk := func(id string, payload []byte) []byte {
+		out := []byte(id)
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(payload)))
+		out = append(out, payload...)
+		if len(payload)%2 == 1 {
+			out = append(out, 0)
+		}
+		return out
+	}
+
+	body := []byte("WEBP")
+	body = append(body, chunk("VP8X", []byte{vp8xFlagEXIF | vp8xFlagXMP, 0, 0, 0, 1, 0, 0, 1, 0, 0})...)
+	body = append(body, chunk("VP8 ", []byte{1, 2, 3})...)
+	body = append(body, chunk("EXIF", buildExif(1))...)
+	body = append(body, chunk("XMP ", []byte("<x:xmpmeta/>"))...)
+
+	data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	data = append(data, body...)
+
+	require.NotNil(t, ExtractMetadata(data, MimeTypeWebP).Latitude)
+
+	stripped := stripWebPMetadata(data)
+
+	ids := []string{}
+	for _, c := range riffChunks(stripped) {
+		ids = append(ids, c.id)
+	}
+	assert.Equal(t, []string{"VP8X", "VP8 "}, ids)
+	assert.Equal(t, byte(0), riffChunks(stripped)[0].payload[0]&(vp8xFlagEXIF|vp8xFlagXMP))
+	assert.Equal(t, uint32(len(stripped)-8), binary.LittleEndian.Uint32(stripped[4:8]))
+}
@@ -0,0 +1,136 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	_ "golang.org/x/image/webp" // register WebP decoder
+)
+
+const (
+	// ThumbnailSize is the longest side in pixels of the thumbnail variant
+	ThumbnailSize = 320
+	// MediumSize is the longest side in pixels of the medium variant
+	MediumSize = 1280
+	// VariantMimeType is the format used for generated variants
+	VariantMimeType = MimeTypeJPEG
+
+	// maxPixels guards against decompression bombs
+	maxPixels = 50_000_000
+
+	originalQuality = 90
+	variantQuality  = 80
+)
+
+var (
+	// ErrUnsupportedFormat is returned when the sniffed type is not a supported image
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	// ErrImageTooLarge is returned when the image dimensions exceed maxPixels
+	ErrImageTooLarge = errors.New("image dimensions exceed the allowed maximum")
+	// ErrInvalidImage is returned when the data cannot be decoded
+	ErrInvalidImage = errors.New("invalid or corrupted image")
+)
+
+// Result is a sanitized image together with its variants and metadata
+type Result struct {
+	MimeType  string
+	Data      []byte
+	Width     int
+	Height    int
+	Thumbnail []byte
+	Medium    []byte
+	Metadata  Metadata
+}
+
+// Process sniffs the real type of an uploaded image, extracts its EXIF
+// metadata, re-encodes it without any metadata and generates the thumbnail
+// and medium variants.
+func Process(data []byte) (*Result, error) {
+	mimeType := DetectMimeType(data)
+	if !IsSupportedMimeType(mimeType) {
+		return nil, ErrUnsupportedFormat
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	meta := ExtractMetadata(data, mimeType)
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	img = applyOrientation(img, meta.Orientation)
+
+	result := &Result{
+		MimeType: mimeType,
+		Width:    img.Bounds().Dx(),
+		Height:   img.Bounds().Dy(),
+		Metadata: meta,
+	}
+
+	switch mimeType {
+	case MimeTypeJPEG:
+		result.Data, err = encodeJPEG(img, originalQuality)
+	case MimeTypePNG:
+		var buf bytes.Buffer
+		err = png.Encode(&buf, img)
+		result.Data = buf.Bytes()
+	case MimeTypeGIF:
+		// All frames are re-encoded to keep the animation
+		result.Data, err = reencodeGIF(data)
+	case MimeTypeWebP:
+		if meta.Orientation > 1 {
+			// There is no WebP encoder, so a rotated WebP is stored as JPEG
+			result.MimeType = MimeTypeJPEG
+			result.Data, err = encodeJPEG(img, originalQuality)
+		} else {
+			result.Data = stripWebPMetadata(data)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-encode image: %w", err)
+	}
+
+	if result.Thumbnail, err = encodeJPEG(fit(img, ThumbnailSize), variantQuality); err != nil {
+		return nil, fmt.Errorf("failed to generate thumbnail: %w", err)
+	}
+	if result.Medium, err = encodeJPEG(fit(img, MediumSize), variantQuality); err != nil {
+		return nil, fmt.Errorf("failed to generate medium variant: %w", err)
+	}
+
+	return result, nil
+}
+
+// reencodeGIF rewrites a GIF with its frames, delays and loop count only.
+// Comments and application extensions, such as XMP which can carry a
+// location, are dropped.
+func reencodeGIF(data []byte) ([]byte, error) {
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,76 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// VP8X feature flags for the metadata chunks we remove
+const (
+	vp8xFlagEXIF = 0x08
+	vp8xFlagXMP  = 0x04
+)
+
+// riffChunk is a chunk of a RIFF/WebP container
+type riffChunk struct {
+	id      string
+	payload []byte
+}
+
+// riffChunks splits a WebP file into its top-level chunks
+func riffChunks(data []byte) []riffChunk {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil
+	}
+
+	var chunks []riffChunk
+	pos := 12
+	for pos+8 <= len(data) {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		if size < 0 || pos+8+size > len(data) {
+			break
+		}
+		chunks = append(chunks, riffChunk{id: id, payload: data[pos+8 : pos+8+size]})
+		pos += 8 + size + size%2
+	}
+
+	return chunks
+}
+
+// stripWebPMetadata rebuilds a WebP container without its EXIF and XMP chunks.
+// The image bitstream is copied untouched since there is no WebP encoder.
+func stripWebPMetadata(data []byte) []byte {
+	chunks := riffChunks(data)
+	if chunks == nil {
+		return data
+	}
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	for _, chunk := range chunks {
+		if chunk.id == "EXIF" || chunk.id == "XMP " {
+			continue
+		}
+
+		payload := chunk.payload
+		if chunk.id == "VP8X" && len(payload) > 0 {
+			payload = append([]byte(nil), payload...)
+			payload[0] &^= vp8xFlagEXIF | vp8xFlagXMP
+		}
+
+		body.WriteString(chunk.id)
+		_ = binary.Write(&body, binary.LittleEndian, uint32(len(payload)))
+		body.Write(payload)
+		if len(payload)%2 == 1 {
+			body.WriteByte(0)
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("RIFF")
+	_ = binary.Write(&out, binary.LittleEndian, uint32(body.Len()))
+	out.Write(body.Bytes())
+
+	return out.Bytes()
+}
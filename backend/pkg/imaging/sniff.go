@@ -0,0 +1,29 @@
+package imaging
+
+import (
+	"net/http"
+)
+
+// Supported image MIME types
+const (
+	MimeTypeJPEG = "image/jpeg"
+	MimeTypePNG  = "image/png"
+	MimeTypeGIF  = "image/gif"
+	MimeTypeWebP = "image/webp"
+)
+
+// DetectMimeType returns the MIME type of the data based on its magic bytes,
+// ignoring whatever the client claimed in its Content-Type header.
+func DetectMimeType(data []byte) string {
+	return http.DetectContentType(data)
+}
+
+// IsSupportedMimeType checks if the sniffed MIME type can be processed
+func IsSupportedMimeType(mimeType string) bool {
+	switch mimeType {
+	case MimeTypeJPEG, MimeTypePNG, MimeTypeGIF, MimeTypeWebP:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryMailer(t *testing.T) {
+	m := NewMemoryMailer()
+	ctx := context.Background()
+
+	m.Send(ctx, Message{To: "a@example.com", Subject: "first"})
+	m.Send(ctx, Message{To: "b@example.com", Subject: "second"})
+	m.Send(ctx, Message{To: "a@example.com", Subject: "third"})
+
+	if got := len(m.Messages()); got != 3 {
+		t.Fatalf("Messages() len = %d, want 3", got)
+	}
+
+	msg, ok := m.LastTo("a@example.com")
+	if !ok || msg.Subject != "third" {
+		t.Errorf("LastTo() = %+v, %v", msg, ok)
+	}
+
+	if _, ok := m.LastTo("c@example.com"); ok {
+		t.Error("LastTo() found a message for an unknown address")
+	}
+
+	m.Reset()
+	if got := len(m.Messages()); got != 0 {
+		t.Errorf("Messages() after Reset len = %d, want 0", got)
+	}
+}
+
+func TestBuildMessage(t *testing.T) {
+	date := time.Date(2025, 1, 20, 10, 0, 0, 0, time.UTC)
+	data := string(buildMessage("AutoParc <noreply@autoparc.fr>", Message{
+		To:      "jean@example.com\r\nBcc: evil@example.com",
+		Subject: "Réinitialisation",
+		Body:    "Bonjour,\nCliquez ici",
+	}, date))
+
+	headers, body, found := strings.Cut(data, "\r\n\r\n")
+	if !found {
+		t.Fatalf("buildMessage() has no header separator: %q", data)
+	}
+
+	if strings.Contains(headers, "\r\nBcc:") {
+		t.Error("buildMessage() allowed header injection")
+	}
+	if !strings.Contains(headers, "Subject: =?utf-8?q?R=C3=A9initialisation?=") {
+		t.Errorf("buildMessage() subject not encoded: %q", headers)
+	}
+	if !strings.Contains(headers, "Content-Type: text/plain; charset=utf-8") {
+		t.Errorf("buildMessage() missing content type: %q", headers)
+	}
+	if body != "Bonjour,\r\nCliquez ici" {
+		t.Errorf("buildMessage() body = %q", body)
+	}
+}
@@ -0,0 +1,84 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPConfig holds the SMTP server settings
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer sends messages through an SMTP server. STARTTLS is used when the
+// server offers it, which net/smtp requires before sending credentials.
+type SMTPMailer struct {
+	config SMTPConfig
+}
+
+// NewSMTPMailer creates an SMTP mailer
+func NewSMTPMailer(config SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{config: config}
+}
+
+// Send delivers the message. The context only bounds the time spent waiting
+// for the send to complete.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	addr := net.JoinHostPort(m.config.Host, m.config.Port)
+	data := buildMessage(m.config.From, msg, time.Now())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, m.config.From, []string{msg.To}, data)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send email: %w", ctx.Err())
+	}
+}
+
+// buildMessage formats msg as an RFC 5322 message with a UTF-8 plain text body
+func buildMessage(from string, msg Message, date time.Time) []byte {
+	var buf bytes.Buffer
+
+	// Header values must not contain line breaks
+	header := func(name, value string) {
+		value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+
+	header("From", from)
+	header("To", msg.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "8bit")
+	buf.WriteString("\r\n")
+
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	return buf.Bytes()
+}
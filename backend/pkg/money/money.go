@@ -0,0 +1,135 @@
+// Package money provides exact decimal monetary amounts with a currency.
+// Amounts are encoded as strings in JSON so clients never go through floats.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// DefaultCurrency is used when no currency is given
+	DefaultCurrency = "EUR"
+	// Places is the number of decimal places of monetary amounts
+	Places = 2
+)
+
+var (
+	// ErrCurrencyMismatch is returned when combining amounts in different currencies
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	// ErrInvalidCurrency is returned for codes that are not ISO 4217 shaped
+	ErrInvalidCurrency = errors.New("invalid currency code")
+
+	currencyRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// ValidateCurrency checks that code is a three-letter uppercase ISO 4217 code
+func ValidateCurrency(code string) error {
+	if !currencyRegex.MatchString(code) {
+		return ErrInvalidCurrency
+	}
+	return nil
+}
+
+// Money is an amount in a given currency
+type Money struct {
+	Amount   decimal.Decimal
+	Currency string
+}
+
+// New creates an amount in the given currency, defaulting to EUR
+func New(amount decimal.Decimal, currency string) Money {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Zero returns a zero amount in the given currency
+func Zero(currency string) Money {
+	return New(decimal.Zero, currency)
+}
+
+// Add returns m + other; both must share the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return Money{Amount: m.Amount.Add(other.Amount), Currency: m.Currency}, nil
+}
+
+// Sub returns m - other; both must share the same currency
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return Money{Amount: m.Amount.Sub(other.Amount), Currency: m.Currency}, nil
+}
+
+// Round rounds the amount to the cent, half away from zero
+func (m Money) Round() Money {
+	return Money{Amount: m.Amount.Round(Places), Currency: m.Currency}
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount.IsZero()
+}
+
+// IsNegative reports whether the amount is negative
+func (m Money) IsNegative() bool {
+	return m.Amount.IsNegative()
+}
+
+// String formats the amount as "1234.50 EUR"
+func (m Money) String() string {
+	return m.Amount.StringFixed(Places) + " " + m.Currency
+}
+
+type moneyJSON struct {
+	Amount   decimal.Decimal `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// MarshalJSON encodes the amount as a fixed two-decimal string
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{
+		Amount:   m.Amount.StringFixed(Places),
+		Currency: m.Currency,
+	})
+}
+
+// UnmarshalJSON accepts the amount as a string (or a number for
+// compatibility) and defaults the currency to EUR
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw moneyJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*m = New(raw.Amount, raw.Currency)
+	return nil
+}
+
+// Sum adds amounts that must all be in currency
+func Sum(currency string, values ...Money) (Money, error) {
+	total := Zero(currency)
+	for _, v := range values {
+		var err error
+		if total, err = total.Add(v); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// HasMaxPlaces reports whether d has at most places decimal places
+func HasMaxPlaces(d decimal.Decimal, places int32) bool {
+	return d.Equal(d.Truncate(places))
+}
@@ -0,0 +1,151 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func TestMoney_AddSub(t *testing.T) {
+	a := New(dec("0.1"), "EUR")
+	b := New(dec("0.2"), "EUR")
+
+	sum, err := a.Add(b)
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if !sum.Amount.Equal(dec("0.3")) {
+		t.Errorf("Add() = %s, want 0.3", sum.Amount)
+	}
+
+	diff, err := a.Sub(b)
+	if err != nil {
+		t.Fatalf("Sub() error = %v", err)
+	}
+	if diff.String() != "-0.10 EUR" {
+		t.Errorf("Sub() = %s, want -0.10 EUR", diff)
+	}
+
+	if _, err := a.Add(New(dec("1"), "USD")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add() error = %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(New(dec("1234.5"), "EUR"))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `{"amount":"1234.50","currency":"EUR"}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+
+	tests := []struct {
+		name         string
+		input        string
+		wantAmount   string
+		wantCurrency string
+	}{
+		{name: "string amount", input: `{"amount":"19.99","currency":"CHF"}`, wantAmount: "19.99", wantCurrency: "CHF"},
+		{name: "number amount", input: `{"amount":19.99,"currency":"EUR"}`, wantAmount: "19.99", wantCurrency: "EUR"},
+		{name: "default currency", input: `{"amount":"5"}`, wantAmount: "5", wantCurrency: DefaultCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Money
+			if err := json.Unmarshal([]byte(tt.input), &m); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !m.Amount.Equal(dec(tt.wantAmount)) || m.Currency != tt.wantCurrency {
+				t.Errorf("Unmarshal() = %s, want %s %s", m, tt.wantAmount, tt.wantCurrency)
+			}
+		})
+	}
+}
+
+func TestValidateCurrency(t *testing.T) {
+	for _, code := range []string{"EUR", "USD", "CHF"} {
+		if err := ValidateCurrency(code); err != nil {
+			t.Errorf("ValidateCurrency(%q) error = %v", code, err)
+		}
+	}
+	for _, code := range []string{"", "eur", "EURO", "€"} {
+		if err := ValidateCurrency(code); err == nil {
+			t.Errorf("ValidateCurrency(%q) expected error", code)
+		}
+	}
+}
+
+func TestNewPrice(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    Price
+		wantExcl string
+		wantVAT  string
+		wantIncl string
+	}{
+		{name: "from HT", price: NewPriceExclVAT(dec("100"), dec("20"), "EUR"), wantExcl: "100", wantVAT: "20", wantIncl: "120"},
+		{name: "from HT rounds VAT half away from zero", price: NewPriceExclVAT(dec("0.125"), dec("20"), "EUR"), wantExcl: "0.13", wantVAT: "0.03", wantIncl: "0.16"},
+		{name: "from TTC", price: NewPriceInclVAT(dec("120"), dec("20"), "EUR"), wantExcl: "100", wantVAT: "20", wantIncl: "120"},
+		{name: "from TTC keeps HT + TVA = TTC", price: NewPriceInclVAT(dec("10"), dec("5.5"), "EUR"), wantExcl: "9.48", wantVAT: "0.52", wantIncl: "10"},
+		{name: "zero rate", price: NewPriceInclVAT(dec("42.42"), dec("0"), "EUR"), wantExcl: "42.42", wantVAT: "0", wantIncl: "42.42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.price.ExclVAT.Equal(dec(tt.wantExcl)) {
+				t.Errorf("ExclVAT = %s, want %s", tt.price.ExclVAT, tt.wantExcl)
+			}
+			if !tt.price.VATAmount.Equal(dec(tt.wantVAT)) {
+				t.Errorf("VATAmount = %s, want %s", tt.price.VATAmount, tt.wantVAT)
+			}
+			if !tt.price.InclVAT.Equal(dec(tt.wantIncl)) {
+				t.Errorf("InclVAT = %s, want %s", tt.price.InclVAT, tt.wantIncl)
+			}
+		})
+	}
+}
+
+func TestPrice_JSON(t *testing.T) {
+	data, err := json.Marshal(NewPriceInclVAT(dec("120"), dec("20"), ""))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `{"amountExclVat":"100.00","vatRate":"20.00","vatAmount":"20.00","amountInclVat":"120.00","currency":"EUR"}`
+	if string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+
+	var p Price
+	if err := json.Unmarshal([]byte(`{"amountExclVat":"50","vatRate":"10"}`), &p); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !p.InclVAT.Equal(dec("55")) || p.Currency != DefaultCurrency {
+		t.Errorf("Unmarshal() = %+v", p)
+	}
+
+	if err := json.Unmarshal([]byte(`{"amountInclVat":"12"}`), &p); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !p.VATRate.Equal(DefaultVATRate) || !p.ExclVAT.Equal(dec("10")) {
+		t.Errorf("Unmarshal() default rate = %+v", p)
+	}
+
+	if err := json.Unmarshal([]byte(`1500.5`), &p); err != nil {
+		t.Fatalf("Unmarshal() bare number error = %v", err)
+	}
+	if !p.InclVAT.Equal(dec("1500.5")) || !p.VATRate.Equal(DefaultVATRate) || p.Currency != DefaultCurrency {
+		t.Errorf("Unmarshal() bare number = %+v", p)
+	}
+
+	if err := json.Unmarshal([]byte(`{"vatRate":"20"}`), &p); err == nil {
+		t.Error("Unmarshal() expected error without amount")
+	}
+}
@@ -0,0 +1,129 @@
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultVATRate is the standard French VAT rate, in percent
+var DefaultVATRate = decimal.NewFromInt(20)
+
+var hundred = decimal.NewFromInt(100)
+
+// Price is an amount split into its excluding-VAT (HT) and including-VAT (TTC)
+// parts. Both are kept so that stored TTC amounts are never recomputed.
+type Price struct {
+	ExclVAT   decimal.Decimal
+	VATRate   decimal.Decimal
+	VATAmount decimal.Decimal
+	InclVAT   decimal.Decimal
+	Currency  string
+}
+
+// NewPriceExclVAT builds a price from its amount excluding VAT. The VAT amount
+// is rounded to the cent, half away from zero.
+func NewPriceExclVAT(exclVAT, vatRate decimal.Decimal, currency string) Price {
+	exclVAT = exclVAT.Round(Places)
+	vatAmount := exclVAT.Mul(vatRate).Div(hundred).Round(Places)
+	return Price{
+		ExclVAT:   exclVAT,
+		VATRate:   vatRate,
+		VATAmount: vatAmount,
+		InclVAT:   exclVAT.Add(vatAmount),
+		Currency:  New(decimal.Zero, currency).Currency,
+	}
+}
+
+// NewPriceInclVAT builds a price from its amount including VAT. The amount
+// excluding VAT is rounded to the cent and the VAT is the difference, so that
+// HT + TVA = TTC always holds.
+func NewPriceInclVAT(inclVAT, vatRate decimal.Decimal, currency string) Price {
+	inclVAT = inclVAT.Round(Places)
+	exclVAT := inclVAT.Mul(hundred).Div(hundred.Add(vatRate)).Round(Places)
+	return Price{
+		ExclVAT:   exclVAT,
+		VATRate:   vatRate,
+		VATAmount: inclVAT.Sub(exclVAT),
+		InclVAT:   inclVAT,
+		Currency:  New(decimal.Zero, currency).Currency,
+	}
+}
+
+// Total returns the amount including VAT
+func (p Price) Total() Money {
+	return Money{Amount: p.InclVAT, Currency: p.Currency}
+}
+
+// Equal reports whether both prices have the same amounts, rate and currency
+func (p Price) Equal(other Price) bool {
+	return p.Currency == other.Currency &&
+		p.InclVAT.Equal(other.InclVAT) &&
+		p.ExclVAT.Equal(other.ExclVAT) &&
+		p.VATRate.Equal(other.VATRate)
+}
+
+type priceJSON struct {
+	AmountExclVAT *decimal.Decimal `json:"amountExclVat"`
+	VATRate       *decimal.Decimal `json:"vatRate"`
+	AmountInclVAT *decimal.Decimal `json:"amountInclVat"`
+	Currency      string           `json:"currency"`
+}
+
+// MarshalJSON encodes every amount as a fixed two-decimal string
+func (p Price) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		AmountExclVAT string `json:"amountExclVat"`
+		VATRate       string `json:"vatRate"`
+		VATAmount     string `json:"vatAmount"`
+		AmountInclVAT string `json:"amountInclVat"`
+		Currency      string `json:"currency"`
+	}{
+		AmountExclVAT: p.ExclVAT.StringFixed(Places),
+		VATRate:       p.VATRate.StringFixed(Places),
+		VATAmount:     p.VATAmount.StringFixed(Places),
+		AmountInclVAT: p.InclVAT.StringFixed(Places),
+		Currency:      p.Currency,
+	})
+}
+
+// UnmarshalJSON accepts either amountInclVat or amountExclVat; the other
+// amounts are derived. The VAT rate defaults to 20% and the currency to EUR.
+// A bare number is read as the amount including VAT, as costs were sent
+// before they carried VAT.
+func (p *Price) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] != '{' && string(trimmed) != "null" {
+		var amount decimal.Decimal
+		if err := json.Unmarshal(trimmed, &amount); err != nil {
+			return err
+		}
+		*p = NewPriceInclVAT(amount, DefaultVATRate, "")
+		p.InclVAT = amount
+		return nil
+	}
+
+	var raw priceJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	vatRate := DefaultVATRate
+	if raw.VATRate != nil {
+		vatRate = *raw.VATRate
+	}
+
+	switch {
+	case raw.AmountInclVAT != nil:
+		*p = NewPriceInclVAT(*raw.AmountInclVAT, vatRate, raw.Currency)
+		// Keep the submitted precision so Validate can reject it
+		p.InclVAT = *raw.AmountInclVAT
+	case raw.AmountExclVAT != nil:
+		*p = NewPriceExclVAT(*raw.AmountExclVAT, vatRate, raw.Currency)
+		p.ExclVAT = *raw.AmountExclVAT
+	default:
+		return errors.New("amountInclVat or amountExclVat is required")
+	}
+	return nil
+}
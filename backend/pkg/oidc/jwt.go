@@ -0,0 +1,235 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+)
+
+// Claims holds the standard claims of an ID token. Other claims, such as
+// provider specific group claims, are available through Strings.
+type Claims struct {
+	Issuer          string   `json:"iss"`
+	Subject         string   `json:"sub"`
+	Audience        audience `json:"aud"`
+	AuthorizedParty string   `json:"azp"`
+	ExpiresAt       int64    `json:"exp"`
+	IssuedAt        int64    `json:"iat"`
+	Nonce           string   `json:"nonce"`
+	Email           string   `json:"email"`
+	EmailVerified   bool     `json:"email_verified"`
+	Name            string   `json:"name"`
+	GivenName       string   `json:"given_name"`
+	FamilyName      string   `json:"family_name"`
+
+	raw map[string]json.RawMessage
+}
+
+// Strings returns a claim holding a string or a list of strings, as group
+// claims do depending on the provider
+func (c *Claims) Strings(name string) []string {
+	raw, ok := c.raw[name]
+	if !ok {
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil && single != "" {
+		return []string{single}
+	}
+	return nil
+}
+
+func parseClaims(payload []byte) (*Claims, error) {
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("invalid id token claims: %w", err)
+	}
+	if err := json.Unmarshal(payload, &claims.raw); err != nil {
+		return nil, fmt.Errorf("invalid id token claims: %w", err)
+	}
+	return &claims, nil
+}
+
+// audience is the aud claim, which is either a string or a list of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+func (a audience) contains(clientID string) bool {
+	for _, aud := range a {
+		if aud == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+
+	publicKey *rsa.PublicKey
+}
+
+// verifySignature checks the RS256 signature of a compact JWT and returns
+// its decoded payload. RS256 is the algorithm every provider must support;
+// others, "none" included, are rejected.
+func (p *Provider) verifySignature(ctx context.Context, token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed id token")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed id token header")
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("malformed id token header")
+	}
+	if header.Algorithm != "RS256" {
+		return nil, fmt.Errorf("unsupported id token algorithm %q", header.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed id token signature")
+	}
+
+	key, err := p.key(ctx, header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("invalid id token signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed id token payload")
+	}
+
+	return payload, nil
+}
+
+// key returns the signing key with the given ID, fetching the key set again
+// when the ID is unknown so that key rotation is picked up
+func (p *Provider) key(ctx context.Context, keyID string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	jwk, ok := p.keys[keyID]
+	p.mu.Unlock()
+	if ok {
+		return jwk.publicKey, nil
+	}
+
+	if err := p.fetchKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if jwk, ok := p.keys[keyID]; ok {
+		return jwk.publicKey, nil
+	}
+	// Providers with a single key may omit the key ID
+	if keyID == "" && len(p.keys) == 1 {
+		for _, jwk := range p.keys {
+			return jwk.publicKey, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown id token signing key %q", keyID)
+}
+
+func (p *Provider) fetchKeys(ctx context.Context) error {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, md.JWKSURI, nil)
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []*jsonWebKey `json:"keys"`
+	}
+	if err := p.do(req, &set); err != nil {
+		return fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+
+	keys := map[string]*jsonWebKey{}
+	for _, jwk := range set.Keys {
+		if jwk.KeyType != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		publicKey, err := jwk.rsaPublicKey()
+		if err != nil {
+			continue
+		}
+		jwk.publicKey = publicKey
+		keys[jwk.KeyID] = jwk
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	return nil
+}
+
+func (k *jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 || exponent.Int64() < 3 {
+		return nil, fmt.Errorf("invalid rsa exponent")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,163 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/pkg/oidc"
+	"github.com/goldenkiwi/autoparc/pkg/oidc/oidctest"
+)
+
+func newProvider(t *testing.T) (*oidctest.Server, *oidc.Provider) {
+	t.Helper()
+	server := oidctest.NewServer("autoparc", "s3cret")
+	t.Cleanup(server.Close)
+
+	provider := oidc.NewProvider(oidc.Config{
+		IssuerURL:    server.Issuer(),
+		ClientID:     "autoparc",
+		ClientSecret: "s3cret",
+		RedirectURL:  "http://localhost:8080/api/v1/auth/oidc/callback",
+		Scopes:       []string{"email", "profile"},
+	})
+	return server, provider
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	server, provider := newProvider(t)
+	ctx := context.Background()
+
+	server.SetUser(map[string]interface{}{
+		"sub":            "user-123",
+		"email":          "jean@example.com",
+		"email_verified": true,
+		"groups":         []string{"fleet", "fleet-admins"},
+	})
+
+	verifier, _ := oidc.RandomString()
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", oidc.CodeChallenge(verifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	if !strings.Contains(authURL, "scope=openid+email+profile") {
+		t.Errorf("AuthCodeURL() = %s, want the openid scope first", authURL)
+	}
+
+	callback, err := server.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	u, _ := url.Parse(callback)
+	if got := u.Query().Get("state"); got != "state-1" {
+		t.Errorf("callback state = %q, want state-1", got)
+	}
+	code := u.Query().Get("code")
+
+	// A wrong PKCE verifier must be rejected, and the code is then burnt
+	if _, err := provider.Exchange(ctx, code, "wrong-verifier"); err == nil {
+		t.Error("Exchange() accepted a wrong code verifier")
+	}
+
+	callback, _ = server.Authorize(authURL)
+	u, _ = url.Parse(callback)
+	token, err := provider.Exchange(ctx, u.Query().Get("code"), verifier)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+
+	if _, err := provider.VerifyIDToken(ctx, token.IDToken, "other-nonce"); err == nil {
+		t.Error("VerifyIDToken() accepted a wrong nonce")
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, token.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+	if claims.Subject != "user-123" || claims.Email != "jean@example.com" || !claims.EmailVerified {
+		t.Errorf("claims = %+v", claims)
+	}
+	if groups := claims.Strings("groups"); len(groups) != 2 || groups[1] != "fleet-admins" {
+		t.Errorf("Strings(groups) = %v", groups)
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	server, provider := newProvider(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		claims  map[string]interface{}
+		wantErr string
+	}{
+		{name: "valid", claims: map[string]interface{}{"sub": "1", "nonce": "n"}},
+		{name: "audience list with azp", claims: map[string]interface{}{"sub": "1", "nonce": "n", "aud": []string{"autoparc", "other"}, "azp": "autoparc"}},
+		{name: "audience list without azp", claims: map[string]interface{}{"sub": "1", "nonce": "n", "aud": []string{"autoparc", "other"}}, wantErr: "not intended"},
+		{name: "other audience", claims: map[string]interface{}{"sub": "1", "nonce": "n", "aud": "other"}, wantErr: "not intended"},
+		{name: "other issuer", claims: map[string]interface{}{"sub": "1", "nonce": "n", "iss": "https://evil.example.com"}, wantErr: "unexpected issuer"},
+		{name: "expired", claims: map[string]interface{}{"sub": "1", "nonce": "n", "exp": time.Now().Add(-time.Hour).Unix()}, wantErr: "expired"},
+		{name: "no subject", claims: map[string]interface{}{"nonce": "n"}, wantErr: "no subject"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.VerifyIDToken(ctx, server.SignIDToken(tt.claims), "n")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("VerifyIDToken() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("VerifyIDToken() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyIDToken_Signature(t *testing.T) {
+	server, provider := newProvider(t)
+	ctx := context.Background()
+
+	token := server.SignIDToken(map[string]interface{}{"sub": "1", "nonce": "n"})
+	parts := strings.Split(token, ".")
+
+	// Tampered payload
+	other := strings.Split(server.SignIDToken(map[string]interface{}{"sub": "2", "nonce": "n"}), ".")
+	if _, err := provider.VerifyIDToken(ctx, parts[0]+"."+other[1]+"."+parts[2], "n"); err == nil {
+		t.Error("VerifyIDToken() accepted a tampered payload")
+	}
+
+	// Unsigned token
+	if _, err := provider.VerifyIDToken(ctx, "eyJhbGciOiJub25lIn0."+parts[1]+".", "n"); err == nil {
+		t.Error("VerifyIDToken() accepted an unsigned token")
+	}
+
+	// Key rotation is picked up from the key set
+	if _, err := provider.VerifyIDToken(ctx, token, "n"); err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+	server.RotateKey()
+	if _, err := provider.VerifyIDToken(ctx, server.SignIDToken(map[string]interface{}{"sub": "1", "nonce": "n"}), "n"); err != nil {
+		t.Errorf("VerifyIDToken() after rotation error = %v", err)
+	}
+}
+
+func TestDiscovery_IssuerMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"issuer":"https://other.example.com","authorization_endpoint":"https://other.example.com/authorize",` +
+			`"token_endpoint":"https://other.example.com/token","jwks_uri":"https://other.example.com/jwks"}`))
+	}))
+	defer server.Close()
+
+	provider := oidc.NewProvider(oidc.Config{IssuerURL: server.URL, ClientID: "autoparc"})
+	_, err := provider.AuthCodeURL(context.Background(), "s", "n", "c")
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("AuthCodeURL() error = %v, want an issuer mismatch", err)
+	}
+}
@@ -0,0 +1,154 @@
+// Package plates recognizes and normalizes vehicle license plates. Each
+// country has one or more formats; a plate is normalized by the first format
+// of its country that matches it, whatever separators it was typed with.
+package plates
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// DefaultCountry is used when no country is given
+const DefaultCountry = "FR"
+
+var (
+	// ErrUnsupportedCountry is returned for countries without a registered format
+	ErrUnsupportedCountry = errors.New("unsupported plate country")
+	// ErrInvalidPlate is returned for plates matching none of the formats of their country
+	ErrInvalidPlate = errors.New("invalid license plate")
+
+	separatorRegex = regexp.MustCompile(`[\s\-.·]+`)
+)
+
+// Format is a license plate format of a country. Its pattern is matched
+// against the plate uppercased, with every run of separators (spaces,
+// dashes, dots) turned into a single space; the submatches are then laid out
+// with the canonical separators.
+type Format struct {
+	// Country is the ISO 3166-1 alpha-2 code of the issuing country
+	Country string
+	// Name identifies the format within its country
+	Name string
+	// Example is a plate in canonical form
+	Example string
+
+	pattern *regexp.Regexp
+	layout  string
+}
+
+// NewFormat creates a format. layout is a regexp.Expand template over the
+// submatches of pattern, such as "$1-$2-$3".
+func NewFormat(country, name, example, pattern, layout string) Format {
+	return Format{
+		Country: country,
+		Name:    name,
+		Example: example,
+		pattern: regexp.MustCompile(pattern),
+		layout:  layout,
+	}
+}
+
+// Normalize returns the canonical form of plate and whether it matches the format
+func (f Format) Normalize(plate string) (string, bool) {
+	cleaned := clean(plate)
+	match := f.pattern.FindStringSubmatchIndex(cleaned)
+	if match == nil {
+		return "", false
+	}
+	return string(f.pattern.ExpandString(nil, f.layout, cleaned, match)), true
+}
+
+// Registry holds the known plate formats, in matching order
+type Registry struct {
+	formats []Format
+}
+
+// NewRegistry creates a registry of the given formats
+func NewRegistry(formats ...Format) *Registry {
+	return &Registry{formats: formats}
+}
+
+// Register adds a format, tried after the ones already registered for its country
+func (r *Registry) Register(format Format) {
+	r.formats = append(r.formats, format)
+}
+
+// Supports reports whether a format is registered for country
+func (r *Registry) Supports(country string) bool {
+	for _, format := range r.formats {
+		if format.Country == country {
+			return true
+		}
+	}
+	return false
+}
+
+// Formats returns the formats registered for country, or all of them when
+// country is empty
+func (r *Registry) Formats(country string) []Format {
+	var formats []Format
+	for _, format := range r.formats {
+		if country == "" || format.Country == country {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// Normalize returns the canonical form of a plate issued in country (FR when
+// empty) along with the format it matched
+func (r *Registry) Normalize(plate, country string) (string, *Format, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country == "" {
+		country = DefaultCountry
+	}
+	if !r.Supports(country) {
+		return "", nil, fmt.Errorf("%w: %s", ErrUnsupportedCountry, country)
+	}
+
+	for i := range r.formats {
+		format := &r.formats[i]
+		if format.Country != country {
+			continue
+		}
+		if normalized, ok := format.Normalize(plate); ok {
+			return normalized, format, nil
+		}
+	}
+	return "", nil, fmt.Errorf("%w for country %s", ErrInvalidPlate, country)
+}
+
+// Default is the registry of the formats the fleet deals with: French SIV,
+// FNI and garage plates, German and Belgian plates.
+var Default = NewRegistry(
+	// W garage plates are tried first: the SIV pattern would not take a
+	// single letter, but FNI would read "1234 W 75" as an ordinary plate
+	NewFormat("FR", "W garage", "W-123-AB", `^W ?([0-9]{3}) ?([A-Z]{2})$`, "W-$1-$2"),
+	NewFormat("FR", "W garage (FNI)", "1234-W-75", `^([0-9]{1,4}) ?W ?([0-9]{2}|2A|2B|97[1-6])$`, "$1-W-$2"),
+	NewFormat("FR", "SIV", "AB-123-CD", `^([A-Z]{2}) ?([0-9]{3}) ?([A-Z]{2})$`, "$1-$2-$3"),
+	NewFormat("FR", "FNI", "1234-AB-75", `^([0-9]{1,4}) ?([A-Z]{1,3}) ?([0-9]{2}|2A|2B|97[1-6])$`, "$1-$2-$3"),
+	// The district code and the letters cannot be told apart without the
+	// separator between them, so it is required
+	NewFormat("DE", "Standard", "B-AB 1234", `^([A-ZÄÖÜ]{1,3}) ([A-Z]{1,2}) ?([1-9][0-9]{0,3}[EH]?)$`, "$1-$2 $3"),
+	NewFormat("BE", "Standard", "1-ABC-123", `^([1-9]) ?([A-Z]{3}) ?([0-9]{3})$`, "$1-$2-$3"),
+	NewFormat("BE", "Pre-2010", "ABC-123", `^([A-Z]{3}) ?([0-9]{3})$`, "$1-$2"),
+)
+
+// Compact strips a plate down to its uppercased letters and digits, so that
+// "ab-123-cd" and "AB 123 CD" compare equal
+func Compact(plate string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(plate) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func clean(plate string) string {
+	return separatorRegex.ReplaceAllString(strings.ToUpper(strings.TrimSpace(plate)), " ")
+}
@@ -0,0 +1,88 @@
+package plates
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegistry_Normalize(t *testing.T) {
+	tests := []struct {
+		plate   string
+		country string
+		want    string
+		format  string
+	}{
+		{"AB-123-CD", "FR", "AB-123-CD", "SIV"},
+		{"ab 123 cd", "", "AB-123-CD", "SIV"},
+		{"AB123CD", "fr", "AB-123-CD", "SIV"},
+		{"WW-456-ZZ", "FR", "WW-456-ZZ", "SIV"},
+		{"W 123 AB", "FR", "W-123-AB", "W garage"},
+		{"1234 AB 75", "FR", "1234-AB-75", "FNI"},
+		{"123abc2a", "FR", "123-ABC-2A", "FNI"},
+		{"56 W 974", "FR", "56-W-974", "W garage (FNI)"},
+		{"b ab 1234", "DE", "B-AB 1234", "Standard"},
+		{"M-XY-12E", "DE", "M-XY 12E", "Standard"},
+		{"KÖN X 7", "DE", "KÖN-X 7", "Standard"},
+		{"1.ABC.123", "BE", "1-ABC-123", "Standard"},
+		{"abc 123", "BE", "ABC-123", "Pre-2010"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.plate, func(t *testing.T) {
+			got, format, err := Default.Normalize(tt.plate, tt.country)
+			if err != nil {
+				t.Fatalf("Normalize(%q, %q) error = %v", tt.plate, tt.country, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q, %q) = %q, want %q", tt.plate, tt.country, got, tt.want)
+			}
+			if format.Name != tt.format {
+				t.Errorf("Normalize(%q, %q) format = %q, want %q", tt.plate, tt.country, format.Name, tt.format)
+			}
+		})
+	}
+}
+
+func TestRegistry_NormalizeInvalid(t *testing.T) {
+	tests := []struct {
+		plate   string
+		country string
+		want    error
+	}{
+		{"AB-1234-CD", "FR", ErrInvalidPlate},
+		{"", "FR", ErrInvalidPlate},
+		{"BAB1234", "DE", ErrInvalidPlate},
+		{"AB-123-CD", "BE", ErrInvalidPlate},
+		{"AB-123-CD", "XX", ErrUnsupportedCountry},
+	}
+
+	for _, tt := range tests {
+		_, _, err := Default.Normalize(tt.plate, tt.country)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Normalize(%q, %q) error = %v, want %v", tt.plate, tt.country, err, tt.want)
+		}
+	}
+}
+
+func TestRegistry_Examples(t *testing.T) {
+	for _, format := range Default.Formats("") {
+		got, ok := format.Normalize(format.Example)
+		if !ok || got != format.Example {
+			t.Errorf("%s %s: example %q normalizes to %q, %v", format.Country, format.Name, format.Example, got, ok)
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	tests := map[string]string{
+		"AB-123-CD":  "AB123CD",
+		"ab 123 cd":  "AB123CD",
+		"B-AB 1234":  "BAB1234",
+		" 1.abc.123": "1ABC123",
+	}
+	for plate, want := range tests {
+		if got := Compact(plate); got != want {
+			t.Errorf("Compact(%q) = %q, want %q", plate, got, want)
+		}
+	}
+}
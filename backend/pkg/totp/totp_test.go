@@ -0,0 +1,84 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B test secret, "12345678901234567890" in base32
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeAt_RFC6238(t *testing.T) {
+	// The RFC lists 8 digit codes; 6 digit codes are their last 6 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := CodeAt(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("CodeAt() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("CodeAt(T=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, _ := CodeAt(rfcSecret, Step(now))
+	previous, _ := CodeAt(rfcSecret, Step(now)-1)
+	old, _ := CodeAt(rfcSecret, Step(now)-2)
+
+	if step, ok := Validate(rfcSecret, code, now, 1); !ok || step != Step(now) {
+		t.Errorf("Validate(current) = %d, %v", step, ok)
+	}
+	if step, ok := Validate(rfcSecret, previous, now, 1); !ok || step != Step(now)-1 {
+		t.Errorf("Validate(previous) = %d, %v", step, ok)
+	}
+	if _, ok := Validate(rfcSecret, old, now, 1); ok {
+		t.Error("Validate() accepted a code outside the skew window")
+	}
+	if _, ok := Validate(rfcSecret, "12345", now, 1); ok {
+		t.Error("Validate() accepted a short code")
+	}
+	if _, ok := Validate("not base32!", code, now, 1); ok {
+		t.Error("Validate() accepted an invalid secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("GenerateSecret() length = %d, want 32", len(secret))
+	}
+	if _, err := CodeAt(secret, 1); err != nil {
+		t.Errorf("CodeAt() with generated secret error = %v", err)
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("AutoParc", "jean.dupont@autoparc.fr", "JBSWY3DPEHPK3PXP")
+
+	if !strings.HasPrefix(uri, "otpauth://totp/AutoParc:jean.dupont@autoparc.fr?") {
+		t.Errorf("ProvisioningURI() = %s", uri)
+	}
+	for _, param := range []string{"secret=JBSWY3DPEHPK3PXP", "issuer=AutoParc", "digits=6", "period=30"} {
+		if !strings.Contains(uri, param) {
+			t.Errorf("ProvisioningURI() missing %s: %s", param, uri)
+		}
+	}
+}
@@ -8,6 +8,10 @@ import (
 
 const bcryptCost = 12
 
+// MaxPasswordLength is the number of bytes bcrypt takes into account; longer
+// passwords are rejected rather than silently truncated
+const MaxPasswordLength = 72
+
 // HashPassword generates a bcrypt hash from a plain text password
 func HashPassword(password string) (string, error) {
 	if password == "" {
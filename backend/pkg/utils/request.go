@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+)
+
+// ClientIP returns the IP address of the client without the port. Every IP
+// recorded for a request, such as login attempts or API token use, comes
+// from here.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
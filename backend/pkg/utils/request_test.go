@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		want       string
+	}{
+		{name: "IPv4 with port", remoteAddr: "192.0.2.1:51234", want: "192.0.2.1"},
+		{name: "IPv6 with port", remoteAddr: "[2001:db8::1]:51234", want: "2001:db8::1"},
+		{name: "without port", remoteAddr: "192.0.2.1", want: "192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if got := ClientIP(req); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
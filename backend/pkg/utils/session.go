@@ -1,8 +1,12 @@
 package utils
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
+	"encoding/hex"
 )
 
 // GenerateSessionToken creates a cryptographically secure random token
@@ -14,3 +18,30 @@ func GenerateSessionToken() (string, error) {
 	}
 	return base64.URLEncoding.EncodeToString(b), nil
 }
+
+// HashSessionToken returns the hex encoded SHA-256 hash under which a session
+// token is stored. Tokens carry 256 bits of entropy so no salt is needed.
+func HashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckSessionTokenHash reports in constant time whether token hashes to hash
+func CheckSessionTokenHash(token, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashSessionToken(token)), []byte(hash)) == 1
+}
+
+// CSRFToken derives the CSRF token bound to a session token. It can only be
+// computed from the raw token, which never leaves the HttpOnly cookie, so
+// other sites cannot forge it and the stored token hash does not reveal it.
+func CSRFToken(sessionToken string) string {
+	mac := hmac.New(sha256.New, []byte(sessionToken))
+	mac.Write([]byte("csrf"))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// CheckCSRFToken reports in constant time whether csrfToken belongs to the
+// session token
+func CheckCSRFToken(sessionToken, csrfToken string) bool {
+	return hmac.Equal([]byte(CSRFToken(sessionToken)), []byte(csrfToken))
+}
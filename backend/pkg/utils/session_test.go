@@ -52,3 +52,63 @@ func TestGenerateSessionTokenMultiple(t *testing.T) {
 		t.Errorf("Expected %d unique tokens, got %d", iterations, len(tokens))
 	}
 }
+
+func TestHashSessionToken(t *testing.T) {
+	token, err := GenerateSessionToken()
+	if err != nil {
+		t.Fatalf("GenerateSessionToken() error = %v", err)
+	}
+
+	hash := HashSessionToken(token)
+	if len(hash) != 64 {
+		t.Errorf("HashSessionToken() length = %d, want 64", len(hash))
+	}
+	if hash == token {
+		t.Error("HashSessionToken() returned the raw token")
+	}
+	if HashSessionToken(token) != hash {
+		t.Error("HashSessionToken() should be deterministic")
+	}
+
+	// Known SHA-256 vector
+	if got := HashSessionToken("abc"); got != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("HashSessionToken(\"abc\") = %s", got)
+	}
+}
+
+func TestCheckSessionTokenHash(t *testing.T) {
+	hash := HashSessionToken("token")
+
+	if !CheckSessionTokenHash("token", hash) {
+		t.Error("CheckSessionTokenHash() should accept the matching token")
+	}
+	if CheckSessionTokenHash("other", hash) {
+		t.Error("CheckSessionTokenHash() should reject another token")
+	}
+	if CheckSessionTokenHash("token", "") {
+		t.Error("CheckSessionTokenHash() should reject an empty hash")
+	}
+}
+
+func TestCSRFToken(t *testing.T) {
+	token1, _ := GenerateSessionToken()
+	token2, _ := GenerateSessionToken()
+
+	csrf := CSRFToken(token1)
+	if csrf == "" || csrf == token1 || csrf == HashSessionToken(token1) {
+		t.Errorf("CSRFToken() = %q, should differ from the token and its hash", csrf)
+	}
+	if CSRFToken(token1) != csrf {
+		t.Error("CSRFToken() should be deterministic")
+	}
+
+	if !CheckCSRFToken(token1, csrf) {
+		t.Error("CheckCSRFToken() should accept the session's token")
+	}
+	if CheckCSRFToken(token2, csrf) {
+		t.Error("CheckCSRFToken() should reject a token of another session")
+	}
+	if CheckCSRFToken(token1, "") {
+		t.Error("CheckCSRFToken() should reject an empty token")
+	}
+}
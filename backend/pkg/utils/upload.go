@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// ParseUploadMetadata parses a tus Upload-Metadata header: comma-separated
+// "key base64value" pairs, where the value may be omitted.
+func ParseUploadMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.Fields(pair)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, fmt.Errorf("invalid metadata pair %q", pair)
+		}
+
+		value := ""
+		if len(parts) == 2 {
+			decoded, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid base64 value for key %q", parts[0])
+			}
+			value = string(decoded)
+		}
+		metadata[parts[0]] = value
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestParseUploadMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "empty header",
+			header: "",
+			want:   map[string]string{},
+		},
+		{
+			name:   "filename and description",
+			header: "filename cGhvdG8uanBn,description YXZhbnQgZ2F1Y2hl",
+			want:   map[string]string{"filename": "photo.jpg", "description": "avant gauche"},
+		},
+		{
+			name:   "key without value",
+			header: "filename cGhvdG8uanBn, is_confidential",
+			want:   map[string]string{"filename": "photo.jpg", "is_confidential": ""},
+		},
+		{
+			name:    "invalid base64",
+			header:  "filename not-base64!",
+			wantErr: true,
+		},
+		{
+			name:    "too many parts",
+			header:  "filename a b",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseUploadMetadata(tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUploadMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseUploadMetadata() = %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("ParseUploadMetadata()[%q] = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Package vin validates and decodes vehicle identification numbers (ISO 3779).
+// A VIN is 17 characters long, uses digits and capital letters except I, O
+// and Q, and starts with the world manufacturer identifier (WMI). The ninth
+// character is a check digit in North America and China, and is only checked
+// there; elsewhere manufacturers may use it freely.
+package vin
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// Length is the length of a VIN
+const Length = 17
+
+var (
+	// ErrInvalidLength is returned for VINs that are not 17 characters long
+	ErrInvalidLength = errors.New("VIN must be 17 characters long")
+	// ErrInvalidCharacter is returned for VINs with characters other than
+	// digits and capital letters, or with I, O or Q
+	ErrInvalidCharacter = errors.New("VIN contains an invalid character")
+	// ErrInvalidCheckDigit is returned when the check digit is required and wrong
+	ErrInvalidCheckDigit = errors.New("VIN check digit does not match")
+)
+
+// transliteration gives the value of each character in the check digit
+// computation; I, O and Q are not allowed
+var transliteration = map[rune]int{
+	'A': 1, 'B': 2, 'C': 3, 'D': 4, 'E': 5, 'F': 6, 'G': 7, 'H': 8,
+	'J': 1, 'K': 2, 'L': 3, 'M': 4, 'N': 5, 'P': 7, 'R': 9,
+	'S': 2, 'T': 3, 'U': 4, 'V': 5, 'W': 6, 'X': 7, 'Y': 8, 'Z': 9,
+}
+
+// weights of each position in the check digit computation
+var weights = [Length]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// Normalize uppercases a VIN and strips the spaces and dashes it may have
+// been typed with
+func Normalize(vin string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(vin) {
+		if !unicode.IsSpace(r) && r != '-' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Validate checks a normalized VIN: length, allowed characters and, where
+// it is mandatory, the check digit
+func Validate(vin string) error {
+	if len(vin) != Length {
+		return ErrInvalidLength
+	}
+	for _, r := range vin {
+		if _, ok := value(r); !ok {
+			return ErrInvalidCharacter
+		}
+	}
+	if checkDigitRequired(vin) && vin[8] != CheckDigit(vin) {
+		return ErrInvalidCheckDigit
+	}
+	return nil
+}
+
+// CheckDigit computes the check digit of a VIN, '0' to '9' or 'X'. vin must
+// be 17 valid characters.
+func CheckDigit(vin string) byte {
+	sum := 0
+	for i, r := range vin {
+		v, _ := value(r)
+		sum += v * weights[i]
+	}
+	if remainder := sum % 11; remainder < 10 {
+		return byte('0' + remainder)
+	}
+	return 'X'
+}
+
+// Info is what can be told about a vehicle from its VIN alone
+type Info struct {
+	VIN string `json:"vin"`
+	// WMI is the world manufacturer identifier, the first three characters
+	WMI    string `json:"wmi"`
+	Region string `json:"region"`
+	// Manufacturer is empty when the WMI is not known
+	Manufacturer string `json:"manufacturer,omitempty"`
+	// CheckDigitValid tells whether the ninth character is a correct check
+	// digit, which only North American and Chinese VINs are required to have
+	CheckDigitValid bool `json:"checkDigitValid"`
+}
+
+// Decode validates a VIN and decodes its manufacturer and region
+func Decode(vin string) (*Info, error) {
+	vin = Normalize(vin)
+	if err := Validate(vin); err != nil {
+		return nil, err
+	}
+
+	wmi := vin[:3]
+	return &Info{
+		VIN:             vin,
+		WMI:             wmi,
+		Region:          region(vin[0]),
+		Manufacturer:    manufacturers[wmi],
+		CheckDigitValid: vin[8] == CheckDigit(vin),
+	}, nil
+}
+
+// MatchesBrand reports whether brand names the decoded manufacturer,
+// ignoring case, accents and punctuation ("Mercedes" matches
+// "Mercedes-Benz"). It is true when the manufacturer is unknown.
+func (i *Info) MatchesBrand(brand string) bool {
+	if i.Manufacturer == "" {
+		return true
+	}
+	manufacturer, brand := comparable(i.Manufacturer), comparable(brand)
+	if brand == "" {
+		return false
+	}
+	return strings.HasPrefix(manufacturer, brand) || strings.HasPrefix(brand, manufacturer)
+}
+
+func value(r rune) (int, bool) {
+	if r >= '0' && r <= '9' {
+		return int(r - '0'), true
+	}
+	v, ok := transliteration[r]
+	return v, ok
+}
+
+// checkDigitRequired reports whether the VIN comes from North America or
+// China, where the ninth character must be the check digit
+func checkDigitRequired(vin string) bool {
+	return (vin[0] >= '1' && vin[0] <= '5') || vin[0] == 'L'
+}
+
+func region(c byte) string {
+	switch {
+	case c >= 'A' && c <= 'H':
+		return "Africa"
+	case c >= 'J' && c <= 'R':
+		return "Asia"
+	case c >= 'S' && c <= 'Z':
+		return "Europe"
+	case c >= '1' && c <= '5':
+		return "North America"
+	case c == '6' || c == '7':
+		return "Oceania"
+	default:
+		return "South America"
+	}
+}
+
+var accents = strings.NewReplacer("é", "e", "è", "e", "ë", "e", "š", "s", "ö", "o", "ü", "u", "ä", "a", "ç", "c")
+
+func comparable(name string) string {
+	var b strings.Builder
+	for _, r := range accents.Replace(strings.ToLower(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// manufacturers maps the WMIs of the makes found in the fleet and on the
+// European lease market to their brand
+var manufacturers = map[string]string{
+	"VF1": "Renault", "VF2": "Renault", "VF6": "Renault", "VF8": "Matra",
+	"VF3": "Peugeot", "VR3": "Peugeot",
+	"VF7": "Citroën", "VR7": "Citroën",
+	"VR1": "DS", "VXK": "Opel",
+	"UU1": "Dacia",
+	"VNK": "Toyota", "SB1": "Toyota", "JTD": "Toyota", "JTM": "Toyota",
+	"WVW": "Volkswagen", "WV1": "Volkswagen", "WV2": "Volkswagen", "WVG": "Volkswagen",
+	"WAU": "Audi", "WUA": "Audi",
+	"WBA": "BMW", "WBS": "BMW", "WBY": "BMW",
+	"WMW": "Mini",
+	"WDB": "Mercedes-Benz", "WDD": "Mercedes-Benz", "WDC": "Mercedes-Benz", "W1K": "Mercedes-Benz", "W1N": "Mercedes-Benz", "W1V": "Mercedes-Benz",
+	"WP0": "Porsche", "WP1": "Porsche",
+	"W0L": "Opel", "W0V": "Opel",
+	"WF0": "Ford", "1FA": "Ford", "1FT": "Ford",
+	"TMB": "Skoda",
+	"VSS": "Seat", "VSE": "Seat",
+	"ZFA": "Fiat", "ZFF": "Ferrari", "ZAR": "Alfa Romeo",
+	"YV1": "Volvo", "YV4": "Volvo",
+	"SJN": "Nissan", "VSK": "Nissan", "JN1": "Nissan",
+	"KMH": "Hyundai", "TMA": "Hyundai", "NLH": "Hyundai",
+	"KNA": "Kia", "U5Y": "Kia",
+	"SAL": "Land Rover", "SAJ": "Jaguar",
+	"JMZ": "Mazda", "JHM": "Honda", "JSA": "Suzuki", "TSM": "Suzuki",
+	"5YJ": "Tesla", "7SA": "Tesla", "LRW": "Tesla", "XP7": "Tesla",
+	"LGX": "BYD", "LSJ": "MG",
+}
@@ -0,0 +1,91 @@
+package vin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		vin  string
+		want error
+	}{
+		{"North American with check digit", "1M8GDM9AXKP042788", nil},
+		{"European without check digit", "VF1RFB00X56789012", nil},
+		{"European with wrong ninth character", "WVWZZZ1KZAW000001", nil},
+		{"Too short", "VF1RFB00X5678901", ErrInvalidLength},
+		{"Too long", "VF1RFB00X567890123", ErrInvalidLength},
+		{"Letter O", "VF1RFB00X5678901O", ErrInvalidCharacter},
+		{"Lowercase", "vf1rfb00x56789012", ErrInvalidCharacter},
+		{"Wrong North American check digit", "1M8GDM9A1KP042788", ErrInvalidCheckDigit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.vin); !errors.Is(err, tt.want) {
+				t.Errorf("Validate(%q) = %v, want %v", tt.vin, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDigit(t *testing.T) {
+	for vin, want := range map[string]byte{
+		"1M8GDM9AXKP042788": 'X',
+		"5YJ3E1EA2KF317000": '2',
+		"11111111111111111": '1',
+	} {
+		if got := CheckDigit(vin); got != want {
+			t.Errorf("CheckDigit(%q) = %c, want %c", vin, got, want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	info, err := Decode(" vf3-4c5fs 9es123456 ")
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if info.VIN != "VF34C5FS9ES123456" || info.WMI != "VF3" {
+		t.Errorf("Decode() VIN = %s, WMI = %s", info.VIN, info.WMI)
+	}
+	if info.Manufacturer != "Peugeot" || info.Region != "Europe" {
+		t.Errorf("Decode() manufacturer = %q, region = %q", info.Manufacturer, info.Region)
+	}
+
+	info, err = Decode("5YJ3E1EA2KF317000")
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if info.Manufacturer != "Tesla" || info.Region != "North America" || !info.CheckDigitValid {
+		t.Errorf("Decode() = %+v", info)
+	}
+
+	if _, err := Decode("ABC"); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Decode() error = %v, want ErrInvalidLength", err)
+	}
+}
+
+func TestInfo_MatchesBrand(t *testing.T) {
+	tests := []struct {
+		manufacturer string
+		brand        string
+		want         bool
+	}{
+		{"Mercedes-Benz", "Mercedes", true},
+		{"Mercedes-Benz", "mercedes benz", true},
+		{"Citroën", "CITROEN", true},
+		{"Skoda", "Škoda", true},
+		{"Renault", "Peugeot", false},
+		{"Renault", "", false},
+		{"", "Anything", true},
+	}
+
+	for _, tt := range tests {
+		info := &Info{Manufacturer: tt.manufacturer}
+		if got := info.MatchesBrand(tt.brand); got != tt.want {
+			t.Errorf("MatchesBrand(%q) with %q = %v, want %v", tt.brand, tt.manufacturer, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package integration
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/goldenkiwi/autoparc/pkg/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var tokenLinkRegex = regexp.MustCompile(`\?token=(\S+)`)
+
+// tokenFromMail extracts the token of the last link emailed to an address
+func tokenFromMail(t *testing.T, mail *mailer.MemoryMailer, to string) string {
+	t.Helper()
+
+	msg, ok := mail.LastTo(to)
+	require.True(t, ok, "no email sent to %s", to)
+
+	match := tokenLinkRegex.FindStringSubmatch(msg.Body)
+	require.NotNil(t, match, "no link in email: %s", msg.Body)
+
+	token, err := url.QueryUnescape(match[1])
+	require.NoError(t, err)
+	return token
+}
+
+func TestAccountIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	const adminID = "00000000-0000-0000-0000-000000000001"
+	mail := mailer.NewMemoryMailer()
+	accountConfig := &config.AccountConfig{
+		AppBaseURL:       "https://autoparc.example.com",
+		InvitationTTL:    72 * time.Hour,
+		PasswordResetTTL: time.Hour,
+	}
+	accountService := service.NewAccountService(
+		repository.NewUserRepository(testDB),
+		repository.NewSessionRepository(testDB),
+		repository.NewAccountTokenRepository(testDB),
+		repository.NewActionLogRepository(testDB),
+		newTestPasswordPolicy(testPasswordConfig()),
+		mail,
+		accountConfig,
+	)
+	authService := newTestAuthService(testLoginConfig())
+
+	t.Run("Invited employee sets their password", func(t *testing.T) {
+		ctx := testContext()
+
+		employee, err := accountService.InviteEmployee(ctx, models.InviteEmployeeRequest{
+			Email:     "Invited@autoparc.fr",
+			FirstName: "Invited",
+			LastName:  "Employee",
+		}, adminID)
+		require.NoError(t, err)
+		assert.Equal(t, "invited@autoparc.fr", employee.Email)
+		assert.Empty(t, employee.PasswordHash)
+
+		msg, ok := mail.LastTo("invited@autoparc.fr")
+		require.True(t, ok)
+		assert.Contains(t, msg.Body, "https://autoparc.example.com/accept-invitation?token=")
+		token := tokenFromMail(t, mail, "invited@autoparc.fr")
+
+		// The raw token is never stored
+		var matches int
+		require.NoError(t, testDB.QueryRow(`SELECT COUNT(*) FROM account_tokens WHERE token_hash = $1`, token).Scan(&matches))
+		assert.Zero(t, matches)
+
+		// A weak password is rejected without consuming the token
+		err = accountService.AcceptInvitation(ctx, models.SetPasswordRequest{Token: token, Password: "weak"})
+		assert.Error(t, err)
+
+		err = accountService.AcceptInvitation(ctx, models.SetPasswordRequest{Token: token, Password: "Invited123"})
+		require.NoError(t, err)
+
+		_, _, err = authService.Login(ctx, "invited@autoparc.fr", "Invited123", "127.0.0.1", "test-agent")
+		assert.NoError(t, err)
+
+		// Tokens are single-use
+		err = accountService.AcceptInvitation(ctx, models.SetPasswordRequest{Token: token, Password: "Another123"})
+		assert.EqualError(t, err, "invalid or expired token")
+	})
+
+	t.Run("Resending an invitation invalidates the previous link", func(t *testing.T) {
+		ctx := testContext()
+
+		employee, err := accountService.InviteEmployee(ctx, models.InviteEmployeeRequest{
+			Email:     "resend@autoparc.fr",
+			FirstName: "Resend",
+			LastName:  "Employee",
+		}, adminID)
+		require.NoError(t, err)
+		first := tokenFromMail(t, mail, "resend@autoparc.fr")
+
+		require.NoError(t, accountService.ResendInvitation(ctx, employee.ID, adminID))
+		second := tokenFromMail(t, mail, "resend@autoparc.fr")
+		assert.NotEqual(t, first, second)
+
+		err = accountService.AcceptInvitation(ctx, models.SetPasswordRequest{Token: first, Password: "Resend123"})
+		assert.EqualError(t, err, "invalid or expired token")
+		assert.NoError(t, accountService.AcceptInvitation(ctx, models.SetPasswordRequest{Token: second, Password: "Resend123"}))
+	})
+
+	t.Run("Forgotten password is reset and sessions revoked", func(t *testing.T) {
+		ctx := testContext()
+
+		_, session, err := authService.Login(ctx, "admin@autoparc.fr", "Admin123!", "127.0.0.1", "test-agent")
+		require.NoError(t, err)
+
+		require.NoError(t, accountService.RequestPasswordReset(ctx, "admin@autoparc.fr"))
+		token := tokenFromMail(t, mail, "admin@autoparc.fr")
+
+		// A reset token cannot be used as an invitation
+		err = accountService.AcceptInvitation(ctx, models.SetPasswordRequest{Token: token, Password: "NewAdmin123"})
+		assert.Error(t, err)
+
+		require.NoError(t, accountService.ResetPassword(ctx, models.SetPasswordRequest{Token: token, Password: "NewAdmin123"}))
+
+		_, err = authService.ValidateSession(ctx, session.SessionToken)
+		assert.Error(t, err)
+
+		_, _, err = authService.Login(ctx, "admin@autoparc.fr", "NewAdmin123", "127.0.0.1", "test-agent")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unknown email does not reveal anything", func(t *testing.T) {
+		ctx := testContext()
+		mail.Reset()
+
+		assert.NoError(t, accountService.RequestPasswordReset(ctx, "nobody@autoparc.fr"))
+		assert.Empty(t, mail.Messages())
+	})
+
+	t.Run("Expired token is rejected", func(t *testing.T) {
+		ctx := testContext()
+
+		require.NoError(t, accountService.RequestPasswordReset(ctx, "invited@autoparc.fr"))
+		token := tokenFromMail(t, mail, "invited@autoparc.fr")
+
+		_, err := testDB.Exec(`UPDATE account_tokens SET expires_at = NOW() - INTERVAL '1 minute' WHERE used_at IS NULL`)
+		require.NoError(t, err)
+
+		err = accountService.ResetPassword(ctx, models.SetPasswordRequest{Token: token, Password: "Expired123"})
+		assert.EqualError(t, err, "invalid or expired token")
+	})
+}
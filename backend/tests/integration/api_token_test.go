@@ -0,0 +1,155 @@
+package integration
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/middleware"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITokenIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	const adminID = "00000000-0000-0000-0000-000000000001"
+	actionLogRepo := repository.NewActionLogRepository(testDB)
+	authService := newTestAuthService(testLoginConfig())
+	apiTokenService := service.NewAPITokenService(
+		repository.NewUserRepository(testDB),
+		repository.NewAPITokenRepository(testDB),
+		actionLogRepo,
+	)
+
+	// Protected handler writing an audit entry like the real handlers do
+	handler := middleware.APIAuthMiddleware(authService, apiTokenService, "session_token")(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := r.Context().Value(middleware.UserContextKey).(*models.AdministrativeEmployee)
+			err := actionLogRepo.Create(r.Context(), &models.ActionLog{
+				ID:          uuid.New().String(),
+				EntityType:  models.EntityTypeCar,
+				EntityID:    uuid.New().String(),
+				ActionType:  models.ActionTypeUpdate,
+				PerformedBy: user.ID,
+				Changes:     json.RawMessage(`{}`),
+				Timestamp:   time.Now(),
+			})
+			require.NoError(t, err)
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+
+	call := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	t.Run("Validation", func(t *testing.T) {
+		ctx := testContext()
+
+		_, err := apiTokenService.Create(ctx, adminID, models.CreateAPITokenRequest{Name: "ERP"})
+		assert.EqualError(t, err, "at least one scope is required")
+
+		_, err = apiTokenService.Create(ctx, adminID, models.CreateAPITokenRequest{Name: "ERP", Scopes: []string{"cars:delete"}})
+		assert.EqualError(t, err, "invalid scope: cars:delete")
+
+		past := time.Now().Add(-time.Hour)
+		_, err = apiTokenService.Create(ctx, adminID, models.CreateAPITokenRequest{Name: "ERP", Scopes: []string{"read"}, ExpiresAt: &past})
+		assert.EqualError(t, err, "expiry must be in the future")
+	})
+
+	t.Run("Scopes and audit", func(t *testing.T) {
+		ctx := testContext()
+
+		token, err := apiTokenService.Create(ctx, adminID, models.CreateAPITokenRequest{
+			Name:   "ERP",
+			Scopes: []string{"cars:write", "read"},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, token.Token, models.APITokenPrefix)
+		assert.Equal(t, token.Token[:12], token.TokenPrefix)
+
+		// Only the hash is stored
+		var stored string
+		require.NoError(t, testDB.QueryRow(`SELECT token_hash FROM api_tokens WHERE id = $1`, token.ID).Scan(&stored))
+		assert.NotEqual(t, token.Token, stored)
+
+		assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/api/v1/cars", ""))
+		assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/api/v1/cars", models.APITokenPrefix+"unknown"))
+		assert.Equal(t, http.StatusNoContent, call(http.MethodGet, "/api/v1/repairs", token.Token))
+		assert.Equal(t, http.StatusForbidden, call(http.MethodPost, "/api/v1/repairs", token.Token))
+		assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/api/v1/auth/me", token.Token))
+
+		// No CSRF token is needed for writes with a bearer token
+		assert.Equal(t, http.StatusNoContent, call(http.MethodPut, "/api/v1/cars/123", token.Token))
+
+		// The owner is the performer and the token is recorded
+		var performedBy string
+		var apiTokenID sql.NullString
+		require.NoError(t, testDB.QueryRow(
+			`SELECT performed_by, api_token_id FROM action_logs WHERE entity_type = 'car' ORDER BY timestamp DESC LIMIT 1`,
+		).Scan(&performedBy, &apiTokenID))
+		assert.Equal(t, adminID, performedBy)
+		assert.Equal(t, token.ID, apiTokenID.String)
+
+		// Last use is tracked
+		tokens, err := apiTokenService.List(ctx, adminID)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		require.NotNil(t, tokens[0].LastUsedAt)
+		require.NotNil(t, tokens[0].LastUsedIP)
+		assert.Equal(t, "192.0.2.1", *tokens[0].LastUsedIP)
+
+		require.NoError(t, apiTokenService.Revoke(ctx, adminID, token.ID))
+		assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/api/v1/cars", token.Token))
+		assert.Error(t, apiTokenService.Revoke(ctx, adminID, token.ID))
+	})
+
+	t.Run("Nested routes need the scope of what they return", func(t *testing.T) {
+		ctx := testContext()
+
+		token, err := apiTokenService.Create(ctx, adminID, models.CreateAPITokenRequest{
+			Name:   "Fleet dashboard",
+			Scopes: []string{"cars:write", "fines:read"},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = apiTokenService.Revoke(testContext(), adminID, token.ID) })
+
+		assert.Equal(t, http.StatusNoContent, call(http.MethodGet, "/api/v1/cars/123/lifecycle", token.Token))
+		assert.Equal(t, http.StatusNoContent, call(http.MethodGet, "/api/v1/cars/123/fines", token.Token))
+		assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/api/v1/cars/123/leases", token.Token))
+		assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/api/v1/cars/123/fuel-transactions", token.Token))
+		assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/api/v1/cars/123/timeline", token.Token))
+		assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/api/v1/cars/123/assignment-history", token.Token))
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		ctx := testContext()
+
+		expiresAt := time.Now().Add(time.Hour)
+		token, err := apiTokenService.Create(ctx, adminID, models.CreateAPITokenRequest{
+			Name:      "Script",
+			Scopes:    []string{"read"},
+			ExpiresAt: &expiresAt,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, call(http.MethodGet, "/api/v1/cars", token.Token))
+
+		_, err = testDB.Exec(`UPDATE api_tokens SET expires_at = $2 WHERE id = $1`, token.ID, time.Now().Add(-time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/api/v1/cars", token.Token))
+	})
+}
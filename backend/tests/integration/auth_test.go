@@ -1,19 +1,76 @@
 package integration
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/models"
 	"github.com/goldenkiwi/autoparc/internal/repository"
 	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/goldenkiwi/autoparc/pkg/breached"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
 )
 
+// testLoginConfig returns a login configuration without delays so that
+// failed logins in one test do not throttle the next ones
+func testLoginConfig() *config.LoginConfig {
+	return &config.LoginConfig{
+		MaxFailedAttempts: 5,
+		LockoutDuration:   time.Minute,
+		ThrottleAfter:     5,
+	}
+}
+
+func testSessionConfig() *config.SessionConfig {
+	return &config.SessionConfig{
+		AbsoluteTimeout: 24 * time.Hour,
+		IdleTimeout:     2 * time.Hour,
+		CleanupInterval: time.Hour,
+	}
+}
+
+func testTwoFactorConfig() *config.TwoFactorConfig {
+	return &config.TwoFactorConfig{
+		Issuer:         "AutoParc",
+		PendingTimeout: 5 * time.Minute,
+	}
+}
+
+func testPasswordConfig() *config.PasswordConfig {
+	return &config.PasswordConfig{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		CheckBreached:    true,
+		HistorySize:      5,
+	}
+}
+
+func newTestPasswordPolicy(passwordConfig *config.PasswordConfig) *service.PasswordPolicy {
+	return service.NewPasswordPolicy(repository.NewUserRepository(testDB), passwordConfig, breached.New())
+}
+
+func newTestAuthService(loginConfig *config.LoginConfig) *service.AuthService {
+	return service.NewAuthService(
+		repository.NewUserRepository(testDB),
+		repository.NewSessionRepository(testDB),
+		repository.NewLoginAttemptRepository(testDB),
+		repository.NewActionLogRepository(testDB),
+		loginConfig,
+		testSessionConfig(),
+		testTwoFactorConfig(),
+		newTestPasswordPolicy(testPasswordConfig()),
+	)
+}
+
 func TestAuthIntegration(t *testing.T) {
 	cleanupDB(t)
 
-	userRepo := repository.NewUserRepository(testDB)
-	sessionRepo := repository.NewSessionRepository(testDB)
-	authService := service.NewAuthService(userRepo, sessionRepo)
+	authService := newTestAuthService(testLoginConfig())
 
 	t.Run("Login with valid credentials", func(t *testing.T) {
 		ctx := testContext()
@@ -110,6 +167,27 @@ func TestAuthIntegration(t *testing.T) {
 		}
 	})
 
+	t.Run("Session token is never stored in plain text", func(t *testing.T) {
+		ctx := testContext()
+
+		_, session, err := authService.Login(ctx, "admin@autoparc.fr", "Admin123!", "127.0.0.1", "test-agent")
+		if err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+
+		var matches int
+		err = testDB.QueryRow(`SELECT COUNT(*) FROM sessions s WHERE row_to_json(s)::text LIKE '%' || $1 || '%'`, session.SessionToken).Scan(&matches)
+		if err != nil || matches != 0 {
+			t.Errorf("Expected raw token to be absent from sessions, got %d rows (%v)", matches, err)
+		}
+
+		var storedHash string
+		err = testDB.QueryRow(`SELECT token_hash FROM sessions WHERE id = $1`, session.ID).Scan(&storedHash)
+		if err != nil || storedHash != utils.HashSessionToken(session.SessionToken) {
+			t.Errorf("Expected stored SHA-256 hash, got %q (%v)", storedHash, err)
+		}
+	})
+
 	t.Run("Invalid session token", func(t *testing.T) {
 		ctx := testContext()
 
@@ -119,3 +197,200 @@ func TestAuthIntegration(t *testing.T) {
 		}
 	})
 }
+
+func TestLoginLockoutIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	const adminID = "00000000-0000-0000-0000-000000000001"
+	loginConfig := testLoginConfig()
+	loginConfig.MaxFailedAttempts = 3
+	loginConfig.IPMaxFailedAttempts = 10
+	loginConfig.IPWindow = time.Minute
+	authService := newTestAuthService(loginConfig)
+
+	t.Run("Account is locked after repeated failures", func(t *testing.T) {
+		ctx := testContext()
+
+		for i := 0; i < loginConfig.MaxFailedAttempts; i++ {
+			_, _, err := authService.Login(ctx, "admin@autoparc.fr", "WrongPassword123", "10.0.0.1", "test-agent")
+			if err == nil || err.Error() != "invalid credentials" {
+				t.Fatalf("Attempt %d: expected invalid credentials, got %v", i+1, err)
+			}
+		}
+
+		// Even the right password is rejected while the account is locked
+		_, _, err := authService.Login(ctx, "admin@autoparc.fr", "Admin123!", "10.0.0.1", "test-agent")
+		var throttled *service.LoginThrottledError
+		if !errors.As(err, &throttled) {
+			t.Fatalf("Expected LoginThrottledError, got %v", err)
+		}
+		if throttled.RetryAfter <= 0 || throttled.RetryAfter > loginConfig.LockoutDuration {
+			t.Errorf("Unexpected retry after: %v", throttled.RetryAfter)
+		}
+
+		var lockLogs int
+		err = testDB.QueryRow(`SELECT COUNT(*) FROM action_logs WHERE entity_id = $1 AND action_type = 'lock'`, adminID).Scan(&lockLogs)
+		if err != nil || lockLogs != 1 {
+			t.Errorf("Expected one lock audit entry, got %d (%v)", lockLogs, err)
+		}
+	})
+
+	t.Run("Admin unlock restores access", func(t *testing.T) {
+		ctx := testContext()
+
+		if err := authService.UnlockAccount(ctx, adminID, adminID); err != nil {
+			t.Fatalf("UnlockAccount failed: %v", err)
+		}
+
+		if _, _, err := authService.Login(ctx, "admin@autoparc.fr", "Admin123!", "10.0.0.1", "test-agent"); err != nil {
+			t.Fatalf("Login after unlock failed: %v", err)
+		}
+	})
+
+	t.Run("Unknown emails are locked like accounts", func(t *testing.T) {
+		ctx := testContext()
+
+		for i := 0; i < loginConfig.MaxFailedAttempts; i++ {
+			_, _, err := authService.Login(ctx, "nobody@autoparc.fr", "WrongPassword123", "10.0.0.4", "test-agent")
+			if err == nil || err.Error() != "invalid credentials" {
+				t.Fatalf("Attempt %d: expected invalid credentials, got %v", i+1, err)
+			}
+		}
+
+		_, _, err := authService.Login(ctx, "Nobody@autoparc.fr", "WrongPassword123", "10.0.0.4", "test-agent")
+		var throttled *service.LoginThrottledError
+		if !errors.As(err, &throttled) {
+			t.Fatalf("Expected LoginThrottledError, got %v", err)
+		}
+		if throttled.RetryAfter <= 0 || throttled.RetryAfter > loginConfig.LockoutDuration {
+			t.Errorf("Unexpected retry after: %v", throttled.RetryAfter)
+		}
+	})
+
+	t.Run("IP is throttled after too many failures", func(t *testing.T) {
+		ctx := testContext()
+
+		for i := 0; i < loginConfig.IPMaxFailedAttempts; i++ {
+			authService.Login(ctx, fmt.Sprintf("unknown%d@autoparc.fr", i), "password", "10.0.0.2", "test-agent")
+		}
+
+		_, _, err := authService.Login(ctx, "admin@autoparc.fr", "Admin123!", "10.0.0.2", "test-agent")
+		var throttled *service.LoginThrottledError
+		if !errors.As(err, &throttled) {
+			t.Fatalf("Expected LoginThrottledError, got %v", err)
+		}
+
+		// Other addresses are not affected
+		if _, _, err := authService.Login(ctx, "admin@autoparc.fr", "Admin123!", "10.0.0.3", "test-agent"); err != nil {
+			t.Fatalf("Login from another IP failed: %v", err)
+		}
+	})
+}
+
+func TestSessionManagementIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	const adminID = "00000000-0000-0000-0000-000000000001"
+	authService := newTestAuthService(testLoginConfig())
+	sessionRepo := repository.NewSessionRepository(testDB)
+
+	login := func(t *testing.T) *models.Session {
+		_, session, err := authService.Login(testContext(), "admin@autoparc.fr", "Admin123!", "127.0.0.1", "test-agent")
+		if err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+		return session
+	}
+
+	t.Run("Session expiry is capped by the idle timeout", func(t *testing.T) {
+		session := login(t)
+
+		if !session.ExpiresAt.Equal(session.LastSeenAt.Add(2 * time.Hour)) {
+			t.Errorf("Expected idle expiry, got %v", session.ExpiresAt)
+		}
+		if session.AbsoluteExpiresAt.Before(session.ExpiresAt) {
+			t.Errorf("Absolute expiry %v before expiry %v", session.AbsoluteExpiresAt, session.ExpiresAt)
+		}
+	})
+
+	t.Run("Activity slides the expiry", func(t *testing.T) {
+		ctx := testContext()
+		session := login(t)
+
+		// Pretend the session has been idle for an hour
+		lastSeen := time.Now().Add(-time.Hour)
+		if err := sessionRepo.Touch(ctx, session.ID, lastSeen, lastSeen.Add(2*time.Hour)); err != nil {
+			t.Fatalf("Touch failed: %v", err)
+		}
+
+		_, touched, err := authService.AuthenticateSession(ctx, session.SessionToken)
+		if err != nil {
+			t.Fatalf("AuthenticateSession failed: %v", err)
+		}
+		if !touched.ExpiresAt.After(lastSeen.Add(2 * time.Hour)) {
+			t.Errorf("Expected expiry to slide, got %v", touched.ExpiresAt)
+		}
+	})
+
+	t.Run("List and revoke sessions", func(t *testing.T) {
+		ctx := testContext()
+		current := login(t)
+		other := login(t)
+
+		sessions, err := authService.ListSessions(ctx, adminID, current.ID)
+		if err != nil {
+			t.Fatalf("ListSessions failed: %v", err)
+		}
+		currentCount := 0
+		for _, s := range sessions {
+			if s.Current {
+				currentCount++
+				if s.ID != current.ID {
+					t.Errorf("Wrong session flagged as current: %s", s.ID)
+				}
+			}
+		}
+		if currentCount != 1 {
+			t.Errorf("Expected exactly one current session, got %d", currentCount)
+		}
+
+		if err := authService.RevokeSession(ctx, adminID, other.ID); err != nil {
+			t.Fatalf("RevokeSession failed: %v", err)
+		}
+		if _, err := authService.ValidateSession(ctx, other.SessionToken); err == nil {
+			t.Error("Expected revoked session to be invalid")
+		}
+		if err := authService.RevokeSession(ctx, adminID, other.ID); err == nil {
+			t.Error("Expected error when revoking an unknown session")
+		}
+
+		login(t)
+		if _, err := authService.RevokeOtherSessions(ctx, adminID, current.ID); err != nil {
+			t.Fatalf("RevokeOtherSessions failed: %v", err)
+		}
+		sessions, err = authService.ListSessions(ctx, adminID, current.ID)
+		if err != nil || len(sessions) != 1 || sessions[0].ID != current.ID {
+			t.Errorf("Expected only the current session to remain, got %d (%v)", len(sessions), err)
+		}
+
+		revoked, err := authService.RevokeAllSessions(ctx, adminID, adminID)
+		if err != nil || revoked != 1 {
+			t.Errorf("Expected one revoked session, got %d (%v)", revoked, err)
+		}
+	})
+
+	t.Run("Expired sessions are purged", func(t *testing.T) {
+		ctx := testContext()
+		session := login(t)
+
+		past := time.Now().Add(-time.Minute)
+		if err := sessionRepo.Touch(ctx, session.ID, past, past); err != nil {
+			t.Fatalf("Touch failed: %v", err)
+		}
+
+		deleted, err := sessionRepo.DeleteExpired(ctx)
+		if err != nil || deleted != 1 {
+			t.Errorf("Expected one purged session, got %d (%v)", deleted, err)
+		}
+	})
+}
@@ -0,0 +1,230 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCarLifecycleIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	carRepo := repository.NewCarRepository(testDB)
+	insuranceRepo := repository.NewInsuranceRepository(testDB)
+	actionLogRepo := repository.NewActionLogRepository(testDB)
+	operatorRepo := repository.NewOperatorRepository(testDB)
+	accidentRepo := repository.NewAccidentRepository(testDB)
+	repairRepo := repository.NewRepairRepository(testDB)
+	carService := service.NewCarService(carRepo, insuranceRepo, actionLogRepo, accidentRepo, repairRepo)
+	operatorService := service.NewOperatorService(operatorRepo, carRepo, actionLogRepo)
+	lifecycleService := service.NewCarLifecycleService(carRepo, operatorRepo, accidentRepo, repairRepo, actionLogRepo)
+
+	ctx := testContext()
+	companies, err := insuranceRepo.FindAll(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, companies)
+	userID := "00000000-0000-0000-0000-000000000001"
+
+	car, err := carService.CreateCar(ctx, &models.CreateCarRequest{
+		LicensePlate:       "LC-100-AA",
+		Brand:              "Peugeot",
+		Model:              "208",
+		GreyCardNumber:     "GC-LC100",
+		InsuranceCompanyID: companies[0].ID,
+		RentalStartDate:    time.Now(),
+		Status:             models.CarStatusActive,
+	}, userID)
+	require.NoError(t, err)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	daysAgo := func(days int) *time.Time {
+		day := today.AddDate(0, 0, -days)
+		return &day
+	}
+
+	accident := &models.Accident{
+		ID:           uuid.New().String(),
+		CarID:        car.ID,
+		AccidentDate: daysAgo(11).Add(9 * time.Hour),
+		Location:     "Lyon, France",
+		Description:  "Rear-ended at a light",
+		Status:       models.AccidentStatusDeclared,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	require.NoError(t, accidentRepo.Create(ctx, accident))
+	t.Cleanup(func() {
+		_, _ = testDB.Exec("DELETE FROM accidents WHERE id = $1", accident.ID)
+	})
+
+	t.Run("A new car starts with its acquisition", func(t *testing.T) {
+		events, err := lifecycleService.GetEvents(testContext(), car.ID)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, models.CarEventAcquisition, events[0].Type)
+		assert.Equal(t, models.CarStatusActive, events[0].Status)
+		assert.Nil(t, events[0].Price)
+	})
+
+	t.Run("Record the acquisition", func(t *testing.T) {
+		price := money.New(decimal.NewFromInt(18500), money.DefaultCurrency)
+		seller := "Garage Martin"
+		event, err := lifecycleService.RecordEvent(testContext(), car.ID, &models.CreateCarLifecycleEventRequest{
+			Type:         models.CarEventAcquisition,
+			EventDate:    daysAgo(60),
+			Price:        &price,
+			Counterparty: &seller,
+		}, userID)
+		require.NoError(t, err)
+		assert.Equal(t, models.CarStatusActive, event.PreviousStatus)
+		assert.Equal(t, models.CarStatusActive, event.Status)
+
+		events, err := lifecycleService.GetEvents(testContext(), car.ID)
+		require.NoError(t, err)
+		require.Len(t, events, 1, "the acquisition recorded with the car is completed")
+		assert.Equal(t, event.ID, events[0].ID)
+		require.NotNil(t, events[0].Counterparty)
+		assert.Equal(t, seller, *events[0].Counterparty)
+	})
+
+	t.Run("Immobilize the car after an accident", func(t *testing.T) {
+		reason := models.ImmobilizationReasonAccident
+		_, err := lifecycleService.RecordEvent(testContext(), car.ID, &models.CreateCarLifecycleEventRequest{
+			Type:       models.CarEventImmobilization,
+			EventDate:  daysAgo(10),
+			Reason:     &reason,
+			AccidentID: &accident.ID,
+		}, userID)
+		require.NoError(t, err)
+
+		updated, err := carService.GetCar(testContext(), car.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.CarStatusMaintenance, updated.Status)
+		require.NotNil(t, updated.ImmobilizationReason)
+		assert.Equal(t, models.ImmobilizationReasonAccident, *updated.ImmobilizationReason)
+
+		reason = models.ImmobilizationReasonWaitingForParts
+		_, err = lifecycleService.RecordEvent(testContext(), car.ID, &models.CreateCarLifecycleEventRequest{
+			Type:      models.CarEventImmobilization,
+			EventDate: daysAgo(8),
+			Reason:    &reason,
+		}, userID)
+		require.NoError(t, err)
+
+		updated, err = carService.GetCar(testContext(), car.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ImmobilizationReasonWaitingForParts, *updated.ImmobilizationReason)
+	})
+
+	t.Run("Events cannot be out of order", func(t *testing.T) {
+		_, err := lifecycleService.RecordEvent(testContext(), car.ID, &models.CreateCarLifecycleEventRequest{
+			Type:      models.CarEventRelease,
+			EventDate: daysAgo(9),
+		}, userID)
+		assert.ErrorContains(t, err, "cannot be before the last status change")
+
+		_, err = lifecycleService.RecordEvent(testContext(), car.ID, &models.CreateCarLifecycleEventRequest{
+			Type:      models.CarEventRelease,
+			EventDate: daysAgo(-2),
+		}, userID)
+		assert.ErrorContains(t, err, "cannot be in the future")
+	})
+
+	t.Run("Release the car and assign it", func(t *testing.T) {
+		event, err := lifecycleService.RecordEvent(testContext(), car.ID, &models.CreateCarLifecycleEventRequest{
+			Type:      models.CarEventRelease,
+			EventDate: daysAgo(5),
+		}, userID)
+		require.NoError(t, err)
+		assert.Equal(t, models.CarStatusActive, event.Status)
+
+		updated, err := carService.GetCar(testContext(), car.ID)
+		require.NoError(t, err)
+		assert.Nil(t, updated.ImmobilizationReason)
+
+		_, err = lifecycleService.RecordEvent(testContext(), car.ID, &models.CreateCarLifecycleEventRequest{
+			Type: models.CarEventRelease,
+		}, userID)
+		assert.ErrorContains(t, err, "only an immobilized car")
+
+		operator, err := operatorService.CreateOperator(testContext(), &models.CreateOperatorRequest{
+			EmployeeNumber: "LIFE001",
+			FirstName:      "Claire",
+			LastName:       "Moreau",
+		}, userID)
+		require.NoError(t, err)
+		_, err = operatorService.AssignOperatorToCar(testContext(), car.ID, &models.AssignOperatorRequest{
+			OperatorID: operator.ID,
+			StartDate:  daysAgo(4).Format("2006-01-02"),
+		}, userID)
+		require.NoError(t, err)
+	})
+
+	t.Run("Status changes and deletion are recorded", func(t *testing.T) {
+		maintenance := models.CarStatusMaintenance
+		_, err := carService.UpdateCar(testContext(), car.ID, &models.UpdateCarRequest{Status: &maintenance}, userID)
+		require.NoError(t, err)
+
+		updated, err := carService.GetCar(testContext(), car.ID)
+		require.NoError(t, err)
+		require.NotNil(t, updated.ImmobilizationReason)
+		assert.Equal(t, models.ImmobilizationReasonOther, *updated.ImmobilizationReason)
+
+		require.NoError(t, carService.DeleteCar(testContext(), car.ID, userID))
+		require.NoError(t, carService.DeleteCar(testContext(), car.ID, userID), "deleting a retired car does nothing")
+
+		updated, err = carService.GetCar(testContext(), car.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.CarStatusRetired, updated.Status)
+		assert.Nil(t, updated.ImmobilizationReason)
+
+		price := money.New(decimal.NewFromInt(9000), money.DefaultCurrency)
+		_, err = lifecycleService.RecordEvent(testContext(), car.ID, &models.CreateCarLifecycleEventRequest{
+			Type:  models.CarEventSale,
+			Price: &price,
+		}, userID)
+		assert.ErrorContains(t, err, "already retired")
+
+		events, err := lifecycleService.GetEvents(testContext(), car.ID)
+		require.NoError(t, err)
+		require.Len(t, events, 6)
+		assert.Equal(t, models.CarEventImmobilization, events[4].Type)
+		assert.Equal(t, models.CarEventRetirement, events[5].Type)
+		assert.Equal(t, models.CarStatusMaintenance, events[5].PreviousStatus)
+		require.NotNil(t, events[0].Price)
+		assert.Equal(t, "18500.00 EUR", events[0].Price.String())
+	})
+
+	t.Run("Timeline merges the history of the car", func(t *testing.T) {
+		timeline, err := lifecycleService.GetTimeline(testContext(), car.ID)
+		require.NoError(t, err)
+
+		var types []models.CarTimelineEntryType
+		for _, entry := range timeline {
+			types = append(types, entry.Type)
+		}
+		assert.Equal(t, []models.CarTimelineEntryType{
+			models.CarTimelineLifecycle,
+			models.CarTimelineAccident,
+			models.CarTimelineLifecycle,
+			models.CarTimelineLifecycle,
+			models.CarTimelineLifecycle,
+			models.CarTimelineAssignmentStart,
+			models.CarTimelineLifecycle,
+			models.CarTimelineLifecycle,
+		}, types)
+		assert.Equal(t, "Acquisition from Garage Martin for 18500.00 EUR", timeline[0].Summary)
+		assert.Equal(t, "Assigned to Claire Moreau", timeline[5].Summary)
+
+		_, err = lifecycleService.GetTimeline(testContext(), uuid.New().String())
+		assert.ErrorContains(t, err, "not found")
+	})
+}
@@ -0,0 +1,231 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/internal/service"
+)
+
+func TestCarSearchIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	carRepo := repository.NewCarRepository(testDB)
+	insuranceRepo := repository.NewInsuranceRepository(testDB)
+	actionLogRepo := repository.NewActionLogRepository(testDB)
+	accidentRepo := repository.NewAccidentRepository(testDB)
+	repairRepo := repository.NewRepairRepository(testDB)
+	carService := service.NewCarService(carRepo, insuranceRepo, actionLogRepo, accidentRepo, repairRepo)
+
+	ctx := testContext()
+	companies, err := insuranceRepo.FindAll(ctx)
+	if err != nil || len(companies) == 0 {
+		t.Fatal("No insurance companies found in seed data")
+	}
+	insuranceCompanyID := companies[0].ID
+	userID := "00000000-0000-0000-0000-000000000001"
+
+	january := time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2025, time.June, 15, 0, 0, 0, 0, time.UTC)
+
+	cars := map[string]*models.Car{}
+	for _, req := range []*models.CreateCarRequest{
+		{LicensePlate: "SR-100-AA", Brand: "Renault", Model: "Clio", GreyCardNumber: "GCS100", RentalStartDate: january, Status: models.CarStatusActive},
+		{LicensePlate: "SR-200-BB", Brand: "Renault", Model: "Megane", GreyCardNumber: "GCS200", RentalStartDate: june, Status: models.CarStatusMaintenance},
+		{LicensePlate: "SR-300-CC", Brand: "Peugeot", Model: "208", GreyCardNumber: "GCS300", RentalStartDate: june, Status: models.CarStatusRetired},
+	} {
+		req.InsuranceCompanyID = insuranceCompanyID
+		car, err := carService.CreateCar(ctx, req, userID)
+		if err != nil {
+			t.Fatalf("CreateCar failed: %v", err)
+		}
+		cars[car.LicensePlate] = car
+	}
+
+	// One open and one closed accident
+	_, err = testDB.Exec(`
+		INSERT INTO accidents (car_id, accident_date, location, description, status)
+		VALUES ($1, NOW(), 'Paris', 'Rear-end collision', 'declared'),
+		       ($2, NOW(), 'Lyon', 'Scratched door', 'closed')
+	`, cars["SR-100-AA"].ID, cars["SR-200-BB"].ID)
+	if err != nil {
+		t.Fatalf("Failed to seed accidents: %v", err)
+	}
+
+	plates := func(t *testing.T, filters *models.CarFilters) []string {
+		t.Helper()
+		filters.Page = 1
+		filters.Limit = 100
+		response, err := carService.GetCars(testContext(), filters)
+		if err != nil {
+			t.Fatalf("GetCars failed: %v", err)
+		}
+		result := make([]string, 0, len(response.Cars))
+		for _, car := range response.Cars {
+			result = append(result, car.LicensePlate)
+		}
+		return result
+	}
+
+	t.Run("Filter by several statuses", func(t *testing.T) {
+		got := plates(t, &models.CarFilters{
+			Statuses:  []models.CarStatus{models.CarStatusActive, models.CarStatusMaintenance},
+			SortBy:    "licensePlate",
+			SortOrder: "asc",
+		})
+		if strings.Join(got, ",") != "SR-100-AA,SR-200-BB" {
+			t.Errorf("Expected SR-100-AA and SR-200-BB, got %v", got)
+		}
+	})
+
+	t.Run("Filter by brand and model ignoring case", func(t *testing.T) {
+		got := plates(t, &models.CarFilters{Brand: "renault", Model: "MEGANE"})
+		if len(got) != 1 || got[0] != "SR-200-BB" {
+			t.Errorf("Expected SR-200-BB, got %v", got)
+		}
+	})
+
+	t.Run("Filter by rental start date range", func(t *testing.T) {
+		from := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2025, time.June, 15, 0, 0, 0, 0, time.UTC)
+		got := plates(t, &models.CarFilters{RentalStartFrom: &from, RentalStartTo: &to, SortBy: "licensePlate", SortOrder: "asc"})
+		if strings.Join(got, ",") != "SR-200-BB,SR-300-CC" {
+			t.Errorf("Expected SR-200-BB and SR-300-CC, got %v", got)
+		}
+	})
+
+	t.Run("Filter by open accident", func(t *testing.T) {
+		yes := true
+		got := plates(t, &models.CarFilters{HasOpenAccident: &yes})
+		if len(got) != 1 || got[0] != "SR-100-AA" {
+			t.Errorf("Expected SR-100-AA, got %v", got)
+		}
+
+		no := false
+		got = plates(t, &models.CarFilters{HasOpenAccident: &no, Brand: "Renault"})
+		if len(got) != 1 || got[0] != "SR-200-BB" {
+			t.Errorf("Expected SR-200-BB, got %v", got)
+		}
+	})
+
+	t.Run("Filter by assignment", func(t *testing.T) {
+		no := false
+		got := plates(t, &models.CarFilters{Assigned: &no})
+		if len(got) != 3 {
+			t.Errorf("Expected 3 unassigned cars, got %v", got)
+		}
+	})
+
+	t.Run("Reject invalid status", func(t *testing.T) {
+		_, err := carService.GetCars(testContext(), &models.CarFilters{
+			Statuses: []models.CarStatus{"sold"},
+			Page:     1,
+			Limit:    20,
+		})
+		if err == nil || !strings.Contains(err.Error(), "invalid status") {
+			t.Errorf("Expected invalid status error, got %v", err)
+		}
+	})
+
+	t.Run("Reject inverted date range", func(t *testing.T) {
+		_, err := carService.GetCars(testContext(), &models.CarFilters{
+			RentalStartFrom: &june,
+			RentalStartTo:   &january,
+			Page:            1,
+			Limit:           20,
+		})
+		if err == nil || !strings.Contains(err.Error(), "invalid rental start date range") {
+			t.Errorf("Expected invalid date range error, got %v", err)
+		}
+	})
+}
+
+func TestCarLicensePlateFormatsIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	carRepo := repository.NewCarRepository(testDB)
+	insuranceRepo := repository.NewInsuranceRepository(testDB)
+	actionLogRepo := repository.NewActionLogRepository(testDB)
+	accidentRepo := repository.NewAccidentRepository(testDB)
+	repairRepo := repository.NewRepairRepository(testDB)
+	carService := service.NewCarService(carRepo, insuranceRepo, actionLogRepo, accidentRepo, repairRepo)
+
+	ctx := testContext()
+	companies, err := insuranceRepo.FindAll(ctx)
+	if err != nil || len(companies) == 0 {
+		t.Fatal("No insurance companies found in seed data")
+	}
+	userID := "00000000-0000-0000-0000-000000000001"
+
+	create := func(plate, country string) (*models.Car, error) {
+		return carService.CreateCar(testContext(), &models.CreateCarRequest{
+			LicensePlate:       plate,
+			PlateCountry:       country,
+			Brand:              "Volkswagen",
+			Model:              "Golf",
+			GreyCardNumber:     "GC-" + plate,
+			InsuranceCompanyID: companies[0].ID,
+			RentalStartDate:    time.Now(),
+			Status:             models.CarStatusActive,
+		}, userID)
+	}
+
+	t.Run("Normalize plates of every supported format", func(t *testing.T) {
+		tests := []struct {
+			plate, country, want, wantCountry string
+		}{
+			{"pf 123 ab", "", "PF-123-AB", "FR"},
+			{"4567 xy 69", "FR", "4567-XY-69", "FR"},
+			{"w 321 ab", "FR", "W-321-AB", "FR"},
+			{"b ab 1234", "de", "B-AB 1234", "DE"},
+			{"1.abc.123", "BE", "1-ABC-123", "BE"},
+		}
+		for _, tt := range tests {
+			car, err := create(tt.plate, tt.country)
+			if err != nil {
+				t.Fatalf("CreateCar(%q, %q) failed: %v", tt.plate, tt.country, err)
+			}
+			if car.LicensePlate != tt.want || car.PlateCountry != tt.wantCountry {
+				t.Errorf("CreateCar(%q, %q) = %s (%s), want %s (%s)", tt.plate, tt.country, car.LicensePlate, car.PlateCountry, tt.want, tt.wantCountry)
+			}
+		}
+	})
+
+	t.Run("Reject plates not matching their country", func(t *testing.T) {
+		_, err := create("B-AB 1234", "FR")
+		if err == nil || !strings.Contains(err.Error(), "invalid license plate format") {
+			t.Errorf("Expected invalid license plate error, got %v", err)
+		}
+
+		_, err = create("AB-123-CD", "XX")
+		if err == nil || !strings.Contains(err.Error(), "invalid plate country") {
+			t.Errorf("Expected invalid plate country error, got %v", err)
+		}
+	})
+
+	t.Run("Reject duplicates typed differently", func(t *testing.T) {
+		if _, err := create("PF123AB", "FR"); err == nil {
+			t.Error("Expected error for duplicate license plate")
+		}
+	})
+
+	t.Run("Search plates ignoring separators", func(t *testing.T) {
+		for search, want := range map[string]string{
+			"pf123":     "PF-123-AB",
+			"BAB 12":    "B-AB 1234",
+			"1 abc-123": "1-ABC-123",
+			"4567XY":    "4567-XY-69",
+		} {
+			response, err := carService.GetCars(testContext(), &models.CarFilters{Search: search, Page: 1, Limit: 20})
+			if err != nil {
+				t.Fatalf("GetCars(%q) failed: %v", search, err)
+			}
+			if len(response.Cars) != 1 || response.Cars[0].LicensePlate != want {
+				t.Errorf("GetCars(%q) = %v, want %s", search, response.Cars, want)
+			}
+		}
+	})
+}
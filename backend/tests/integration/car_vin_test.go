@@ -0,0 +1,144 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/internal/service"
+)
+
+func TestCarVINIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	carRepo := repository.NewCarRepository(testDB)
+	insuranceRepo := repository.NewInsuranceRepository(testDB)
+	actionLogRepo := repository.NewActionLogRepository(testDB)
+	accidentRepo := repository.NewAccidentRepository(testDB)
+	repairRepo := repository.NewRepairRepository(testDB)
+	carService := service.NewCarService(carRepo, insuranceRepo, actionLogRepo, accidentRepo, repairRepo)
+
+	ctx := testContext()
+	companies, err := insuranceRepo.FindAll(ctx)
+	if err != nil || len(companies) == 0 {
+		t.Fatal("No insurance companies found in seed data")
+	}
+	userID := "00000000-0000-0000-0000-000000000001"
+
+	create := func(plate, vinNumber, brand string) (*models.Car, error) {
+		return carService.CreateCar(testContext(), &models.CreateCarRequest{
+			LicensePlate:       plate,
+			VIN:                vinNumber,
+			Brand:              brand,
+			Model:              "208",
+			GreyCardNumber:     "GC-" + plate,
+			InsuranceCompanyID: companies[0].ID,
+			RentalStartDate:    time.Now(),
+			Status:             models.CarStatusActive,
+		}, userID)
+	}
+
+	var peugeot *models.Car
+
+	t.Run("Fill in the brand from the VIN", func(t *testing.T) {
+		car, err := create("VN-100-AA", "vf3 4c5fs9es123456", "")
+		if err != nil {
+			t.Fatalf("CreateCar failed: %v", err)
+		}
+		if car.VIN == nil || *car.VIN != "VF34C5FS9ES123456" {
+			t.Errorf("Expected normalized VIN, got %v", car.VIN)
+		}
+		if car.Brand != "Peugeot" {
+			t.Errorf("Expected brand Peugeot, got %s", car.Brand)
+		}
+		peugeot = car
+	})
+
+	t.Run("Reject a VIN of another manufacturer", func(t *testing.T) {
+		_, err := create("VN-200-AA", "VF1RFB00X56789012", "Peugeot")
+		if err == nil || !strings.Contains(err.Error(), "does not match brand") {
+			t.Errorf("Expected brand mismatch error, got %v", err)
+		}
+	})
+
+	t.Run("Reject invalid VINs", func(t *testing.T) {
+		for _, number := range []string{"VF1RFB00X5678901", "VF1RFB00X5678901O", "1M8GDM9A1KP042788"} {
+			_, err := create("VN-300-AA", number, "")
+			if err == nil || !strings.Contains(err.Error(), "invalid VIN") {
+				t.Errorf("Expected invalid VIN error for %s, got %v", number, err)
+			}
+		}
+	})
+
+	t.Run("Reject duplicate VIN", func(t *testing.T) {
+		_, err := create("VN-400-AA", "VF34C5FS9ES123456", "Peugeot")
+		if err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Errorf("Expected duplicate VIN error, got %v", err)
+		}
+	})
+
+	t.Run("Create cars without VIN", func(t *testing.T) {
+		for _, plate := range []string{"VN-500-AA", "VN-600-AA"} {
+			car, err := create(plate, "", "Renault")
+			if err != nil {
+				t.Fatalf("CreateCar failed: %v", err)
+			}
+			if car.VIN != nil {
+				t.Errorf("Expected no VIN, got %s", *car.VIN)
+			}
+		}
+	})
+
+	t.Run("Look up a car by VIN", func(t *testing.T) {
+		response, err := carService.GetCars(testContext(), &models.CarFilters{VIN: "vf3-4c5fs9es123456", Page: 1, Limit: 20})
+		if err != nil {
+			t.Fatalf("GetCars failed: %v", err)
+		}
+		if len(response.Cars) != 1 || response.Cars[0].ID != peugeot.ID {
+			t.Errorf("Expected the Peugeot, got %v", response.Cars)
+		}
+
+		_, err = carService.GetCars(testContext(), &models.CarFilters{VIN: "ABC", Page: 1, Limit: 20})
+		if err == nil || !strings.Contains(err.Error(), "invalid VIN") {
+			t.Errorf("Expected invalid VIN error, got %v", err)
+		}
+	})
+
+	t.Run("Check brand and VIN agree on update", func(t *testing.T) {
+		renault := "Renault"
+		_, err := carService.UpdateCar(testContext(), peugeot.ID, &models.UpdateCarRequest{Brand: &renault}, userID)
+		if err == nil || !strings.Contains(err.Error(), "does not match brand") {
+			t.Errorf("Expected brand mismatch error, got %v", err)
+		}
+
+		renaultVIN := "VF1RFB00X56789012"
+		car, err := carService.UpdateCar(testContext(), peugeot.ID, &models.UpdateCarRequest{Brand: &renault, VIN: &renaultVIN}, userID)
+		if err != nil {
+			t.Fatalf("UpdateCar failed: %v", err)
+		}
+		if car.Brand != "Renault" || car.VIN == nil || *car.VIN != renaultVIN {
+			t.Errorf("Expected Renault with VIN %s, got %s %v", renaultVIN, car.Brand, car.VIN)
+		}
+
+		empty := ""
+		car, err = carService.UpdateCar(testContext(), peugeot.ID, &models.UpdateCarRequest{VIN: &empty}, userID)
+		if err != nil {
+			t.Fatalf("UpdateCar failed: %v", err)
+		}
+		if car.VIN != nil {
+			t.Errorf("Expected VIN to be cleared, got %s", *car.VIN)
+		}
+	})
+
+	t.Run("Decode a VIN", func(t *testing.T) {
+		info, err := carService.DecodeVIN("WVWZZZ1KZAW000001")
+		if err != nil {
+			t.Fatalf("DecodeVIN failed: %v", err)
+		}
+		if info.Manufacturer != "Volkswagen" || info.Region != "Europe" {
+			t.Errorf("Expected a European Volkswagen, got %+v", info)
+		}
+	})
+}
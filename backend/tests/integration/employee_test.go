@@ -15,8 +15,9 @@ func TestEmployeeIntegration(t *testing.T) {
 	cleanupDB(t)
 
 	userRepo := repository.NewUserRepository(testDB)
+	sessionRepo := repository.NewSessionRepository(testDB)
 	actionLogRepo := repository.NewActionLogRepository(testDB)
-	employeeService := service.NewEmployeeService(userRepo, actionLogRepo)
+	employeeService := service.NewEmployeeService(userRepo, sessionRepo, actionLogRepo, newTestPasswordPolicy(testPasswordConfig()))
 
 	// Get admin user for performedBy
 	adminUser, err := userRepo.GetByEmail(context.Background(), "admin@autoparc.fr")
@@ -118,8 +119,7 @@ func TestEmployeeIntegration(t *testing.T) {
 		employeeID := employee.ID
 
 		// Setup auth service for login tests
-		sessionRepo := repository.NewSessionRepository(testDB)
-		authService := service.NewAuthService(userRepo, sessionRepo)
+		authService := newTestAuthService(testLoginConfig())
 
 		// Login with original password
 		user, session, err := authService.Login(ctx, "auth.test@autoparc.fr", "AuthTest123", "127.0.0.1", "test-agent")
@@ -137,6 +137,10 @@ func TestEmployeeIntegration(t *testing.T) {
 		err = employeeService.ChangePassword(ctx, employeeID, changeReq, employeeID)
 		assert.NoError(t, err)
 
+		// Sessions opened with the old password are revoked
+		_, err = authService.ValidateSession(ctx, session.SessionToken)
+		assert.Error(t, err)
+
 		// Login with new password
 		user2, session2, err := authService.Login(ctx, "auth.test@autoparc.fr", "NewAuthTest456", "127.0.0.1", "test-agent")
 		assert.NoError(t, err)
@@ -151,6 +155,9 @@ func TestEmployeeIntegration(t *testing.T) {
 		err = employeeService.DeleteEmployee(ctx, employeeID, adminID)
 		assert.NoError(t, err)
 
+		_, err = authService.ValidateSession(ctx, session2.SessionToken)
+		assert.Error(t, err)
+
 		// Login should fail after deletion
 		_, _, err = authService.Login(ctx, "auth.test@autoparc.fr", "NewAuthTest456", "127.0.0.1", "test-agent")
 		assert.Error(t, err)
@@ -0,0 +1,230 @@
+package integration
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuelIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	carRepo := repository.NewCarRepository(testDB)
+	insuranceRepo := repository.NewInsuranceRepository(testDB)
+	actionLogRepo := repository.NewActionLogRepository(testDB)
+	operatorRepo := repository.NewOperatorRepository(testDB)
+	carService := service.NewCarService(carRepo, insuranceRepo, actionLogRepo, repository.NewAccidentRepository(testDB), repository.NewRepairRepository(testDB))
+	operatorService := service.NewOperatorService(operatorRepo, carRepo, actionLogRepo)
+	fuelService := service.NewFuelService(repository.NewFuelRepository(testDB), carRepo, operatorRepo, actionLogRepo, &config.FuelConfig{Location: time.UTC})
+
+	ctx := testContext()
+	companies, err := insuranceRepo.FindAll(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, companies)
+	userID := "00000000-0000-0000-0000-000000000001"
+
+	newCar := func(plate string, fuel models.FuelType, tank int) *models.Car {
+		car, err := carService.CreateCar(testContext(), &models.CreateCarRequest{
+			LicensePlate:       plate,
+			Brand:              "Peugeot",
+			Model:              "308",
+			GreyCardNumber:     "GC-" + plate,
+			FuelType:           &fuel,
+			TankCapacity:       &tank,
+			InsuranceCompanyID: companies[0].ID,
+			RentalStartDate:    time.Now(),
+			Status:             models.CarStatusActive,
+		}, userID)
+		require.NoError(t, err)
+		return car
+	}
+
+	diesel := newCar("FU-100-AA", models.FuelTypeDiesel, 50)
+	petrol := newCar("FU-200-AA", models.FuelTypePetrol, 45)
+	require.NotNil(t, diesel.FuelType)
+	assert.Equal(t, models.FuelTypeDiesel, *diesel.FuelType)
+	assert.Equal(t, 50, *diesel.TankCapacity)
+
+	today := time.Now().Truncate(24 * time.Hour)
+	operator, err := operatorService.CreateOperator(testContext(), &models.CreateOperatorRequest{
+		EmployeeNumber: "FUEL001",
+		FirstName:      "Paul",
+		LastName:       "Durand",
+	}, userID)
+	require.NoError(t, err)
+	_, err = operatorService.AssignOperatorToCar(testContext(), diesel.ID, &models.AssignOperatorRequest{
+		OperatorID: operator.ID,
+		StartDate:  today.AddDate(0, 0, -3).Format("2006-01-02"),
+	}, userID)
+	require.NoError(t, err)
+
+	var carCard, operatorCard *models.FuelCard
+
+	t.Run("Create fuel cards", func(t *testing.T) {
+		var err error
+		carCard, err = fuelService.CreateFuelCard(testContext(), &models.CreateFuelCardRequest{
+			CardNumber: "7077 0000 0000 0001",
+			Provider:   "TotalEnergies",
+			CarID:      &diesel.ID,
+		}, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "7077000000000001", carCard.CardNumber)
+		assert.Equal(t, models.FuelCardStatusActive, carCard.Status)
+		require.NotNil(t, carCard.LicensePlate)
+		assert.Equal(t, "FU-100-AA", *carCard.LicensePlate)
+
+		operatorCard, err = fuelService.CreateFuelCard(testContext(), &models.CreateFuelCardRequest{
+			CardNumber: "7077000000000002",
+			Provider:   "TotalEnergies",
+			OperatorID: &operator.ID,
+		}, userID)
+		require.NoError(t, err)
+		require.NotNil(t, operatorCard.OperatorName)
+		assert.Equal(t, "Paul Durand", *operatorCard.OperatorName)
+
+		_, err = fuelService.CreateFuelCard(testContext(), &models.CreateFuelCardRequest{
+			CardNumber: "7077-0000-0000-0001",
+			Provider:   "Shell",
+		}, userID)
+		assert.ErrorContains(t, err, "already exists")
+
+		_, err = fuelService.CreateFuelCard(testContext(), &models.CreateFuelCardRequest{
+			CardNumber: "7077000000000003",
+			Provider:   "Shell",
+			CarID:      &diesel.ID,
+			OperatorID: &operator.ID,
+		}, userID)
+		assert.ErrorContains(t, err, "not both")
+	})
+
+	statement := func(lines ...string) []byte {
+		header := "Date;Carte;Immatriculation;Station;Produit;Quantité;Montant TTC;Kilométrage;Référence"
+		return []byte(header + "\n" + strings.Join(lines, "\n") + "\n")
+	}
+	at := func(daysAgo, hour int) string {
+		return today.AddDate(0, 0, -daysAgo).Add(time.Duration(hour) * time.Hour).Format("02/01/2006 15:04")
+	}
+	data := statement(
+		// Before the operator was assigned
+		fmt.Sprintf("%s;7077000000000001;;Total Lyon;Gazole;40,00;72,00;10000;T-1", at(5, 10)),
+		fmt.Sprintf("%s;7077000000000001;;Total Lyon;Gazole;42,00;75,60;10600;T-2", at(2, 10)),
+		// The operator's card, petrol in the diesel car, beyond its tank
+		fmt.Sprintf("%s;7077000000000002;;Total Lyon;SP95-E10;55,00;99,00;11200;T-3", at(1, 9)),
+		// Unknown card, matched by plate
+		fmt.Sprintf("%s;7077999999999999;fu200aa;Esso Paris;SP98;30,00;57,00;;T-4", at(1, 12)),
+		fmt.Sprintf("%s;7077999999999999;;Esso Paris;SP98;20,00;38,00;;T-5", at(1, 13)),
+		fmt.Sprintf("%s;7077999999999999;;Esso Paris;SP98;vingt;38,00;;T-6", at(1, 14)),
+	)
+
+	t.Run("Import a statement", func(t *testing.T) {
+		result, err := fuelService.ImportTransactions(testContext(), data, userID)
+		require.NoError(t, err)
+		assert.Equal(t, 5, result.Imported)
+		assert.Equal(t, 0, result.Duplicates)
+		assert.Equal(t, 4, result.Flagged)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, 7, result.Errors[0].Line)
+		assert.Contains(t, result.Errors[0].Error, "invalid volume")
+	})
+
+	t.Run("Importing again skips known transactions", func(t *testing.T) {
+		result, err := fuelService.ImportTransactions(testContext(), data, userID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.Imported)
+		assert.Equal(t, 5, result.Duplicates)
+	})
+
+	t.Run("Transactions are matched to cars with consumption and anomalies", func(t *testing.T) {
+		transactions, err := fuelService.GetFuelTransactions(testContext(), &models.FuelTransactionFilters{CarID: diesel.ID})
+		require.NoError(t, err)
+		require.Len(t, transactions, 3)
+
+		// Latest first
+		byOperatorCard, second, first := transactions[0], transactions[1], transactions[2]
+
+		assert.Equal(t, []models.FuelAnomaly{models.FuelAnomalyUnassignedCar}, first.Anomalies)
+		assert.Nil(t, first.OperatorID)
+		assert.Nil(t, first.Consumption)
+		require.NotNil(t, first.CardID)
+		assert.Equal(t, carCard.ID, *first.CardID)
+
+		assert.Empty(t, second.Anomalies)
+		require.NotNil(t, second.OperatorID)
+		assert.Equal(t, operator.ID, *second.OperatorID)
+		require.NotNil(t, second.Consumption)
+		// 42 l over 600 km
+		assert.Equal(t, "7", second.Consumption.String())
+		assert.Equal(t, models.FuelTypeDiesel, second.FuelType)
+		assert.Equal(t, "75.60 EUR", second.Amount.String())
+
+		assert.Equal(t, []models.FuelAnomaly{models.FuelAnomalyFuelTypeMismatch, models.FuelAnomalyTankVolumeExceeded}, byOperatorCard.Anomalies)
+		require.NotNil(t, byOperatorCard.CardID)
+		assert.Equal(t, operatorCard.ID, *byOperatorCard.CardID)
+		require.NotNil(t, byOperatorCard.Consumption)
+		assert.Equal(t, "9.17", byOperatorCard.Consumption.String())
+
+		byPlate, err := fuelService.GetFuelTransactions(testContext(), &models.FuelTransactionFilters{CarID: petrol.ID})
+		require.NoError(t, err)
+		require.Len(t, byPlate, 1)
+		assert.Nil(t, byPlate[0].CardID)
+		assert.Equal(t, []models.FuelAnomaly{models.FuelAnomalyUnassignedCar}, byPlate[0].Anomalies)
+	})
+
+	t.Run("Filter transactions by anomaly", func(t *testing.T) {
+		unmatched, err := fuelService.GetFuelTransactions(testContext(), &models.FuelTransactionFilters{Anomaly: models.FuelAnomalyUnmatchedCar})
+		require.NoError(t, err)
+		require.Len(t, unmatched, 1)
+		assert.Nil(t, unmatched[0].CarID)
+		assert.Equal(t, "T-5", unmatched[0].Reference)
+
+		flagged, err := fuelService.GetFuelTransactions(testContext(), &models.FuelTransactionFilters{Flagged: true})
+		require.NoError(t, err)
+		assert.Len(t, flagged, 4)
+
+		from := today.AddDate(0, 0, -1)
+		recent, err := fuelService.GetFuelTransactions(testContext(), &models.FuelTransactionFilters{From: &from})
+		require.NoError(t, err)
+		assert.Len(t, recent, 3)
+
+		_, err = fuelService.GetFuelTransactions(testContext(), &models.FuelTransactionFilters{Anomaly: "stolen"})
+		assert.ErrorContains(t, err, "invalid anomaly")
+	})
+
+	t.Run("Give a card to an operator and block it", func(t *testing.T) {
+		blocked := models.FuelCardStatusBlocked
+		card, err := fuelService.UpdateFuelCard(testContext(), carCard.ID, &models.UpdateFuelCardRequest{
+			OperatorID: &operator.ID,
+			Status:     &blocked,
+		}, userID)
+		require.NoError(t, err)
+		assert.Nil(t, card.CarID)
+		require.NotNil(t, card.OperatorID)
+		assert.Equal(t, operator.ID, *card.OperatorID)
+		assert.Equal(t, models.FuelCardStatusBlocked, card.Status)
+
+		cards, err := fuelService.GetFuelCards(testContext(), &models.FuelCardFilters{OperatorID: operator.ID})
+		require.NoError(t, err)
+		assert.Len(t, cards, 2)
+	})
+
+	t.Run("Delete a card keeps its transactions", func(t *testing.T) {
+		require.NoError(t, fuelService.DeleteFuelCard(testContext(), operatorCard.ID, userID))
+
+		_, err := fuelService.GetFuelCard(testContext(), operatorCard.ID)
+		assert.ErrorContains(t, err, "not found")
+
+		transactions, err := fuelService.GetFuelTransactions(testContext(), &models.FuelTransactionFilters{CarID: diesel.ID})
+		require.NoError(t, err)
+		assert.Len(t, transactions, 3)
+		assert.Nil(t, transactions[0].CardID)
+		assert.Equal(t, "7077000000000002", transactions[0].CardNumber)
+	})
+}
@@ -0,0 +1,204 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaseIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	carRepo := repository.NewCarRepository(testDB)
+	insuranceRepo := repository.NewInsuranceRepository(testDB)
+	actionLogRepo := repository.NewActionLogRepository(testDB)
+	operatorRepo := repository.NewOperatorRepository(testDB)
+	leaseRepo := repository.NewLeaseRepository(testDB)
+	carService := service.NewCarService(carRepo, insuranceRepo, actionLogRepo, repository.NewAccidentRepository(testDB), repository.NewRepairRepository(testDB))
+	operatorService := service.NewOperatorService(operatorRepo, carRepo, actionLogRepo)
+	leaseService := service.NewLeaseService(leaseRepo, carRepo, operatorRepo, actionLogRepo, &config.LeaseConfig{ExpiryAlertDays: 30})
+
+	ctx := testContext()
+	companies, err := insuranceRepo.FindAll(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, companies)
+	userID := "00000000-0000-0000-0000-000000000001"
+
+	newCar := func(plate string) *models.Car {
+		car, err := carService.CreateCar(testContext(), &models.CreateCarRequest{
+			LicensePlate:       plate,
+			Brand:              "Peugeot",
+			Model:              "308",
+			GreyCardNumber:     "GC-" + plate,
+			InsuranceCompanyID: companies[0].ID,
+			RentalStartDate:    time.Now(),
+			Status:             models.CarStatusActive,
+		}, userID)
+		require.NoError(t, err)
+		return car
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	leaseRequest := func(carID, number string, start, end time.Time) *models.CreateLeaseContractRequest {
+		return &models.CreateLeaseContractRequest{
+			CarID:             carID,
+			Lessor:            "Arval",
+			ContractNumber:    number,
+			StartDate:         start,
+			EndDate:           end,
+			MonthlyRent:       money.New(decimal.RequireFromString("389.90"), ""),
+			ContractedMileage: 40000,
+			ExcessKmPrice:     decimal.RequireFromString("0.085"),
+			StartMileage:      10,
+		}
+	}
+
+	soon := newCar("LS-100-AA")
+	later := newCar("LS-200-AA")
+
+	var soonLease *models.LeaseContract
+
+	t.Run("Create lease contracts", func(t *testing.T) {
+		var err error
+		soonLease, err = leaseService.CreateLease(testContext(), leaseRequest(soon.ID, "LC-1", today.AddDate(-2, 0, 0), today.AddDate(0, 0, 10)), userID)
+		require.NoError(t, err)
+		assert.Equal(t, models.LeaseStatusActive, soonLease.Status)
+		assert.Equal(t, "389.90 EUR", soonLease.MonthlyRent.String())
+		assert.Equal(t, "LS-100-AA", soonLease.LicensePlate)
+		assert.Nil(t, soonLease.MileageProjection)
+
+		_, err = leaseService.CreateLease(testContext(), leaseRequest(later.ID, "LC-2", today.AddDate(-1, 0, 0), today.AddDate(2, 0, 0)), userID)
+		require.NoError(t, err)
+	})
+
+	t.Run("Reject a second active contract for a car", func(t *testing.T) {
+		_, err := leaseService.CreateLease(testContext(), leaseRequest(soon.ID, "LC-3", today, today.AddDate(1, 0, 0)), userID)
+		assert.ErrorContains(t, err, "already has an active lease contract")
+	})
+
+	t.Run("Reject a duplicate contract number", func(t *testing.T) {
+		other := newCar("LS-300-AA")
+		_, err := leaseService.CreateLease(testContext(), leaseRequest(other.ID, "LC-1", today, today.AddDate(1, 0, 0)), userID)
+		assert.ErrorContains(t, err, "already exists for this lessor")
+	})
+
+	t.Run("List contracts ending soon", func(t *testing.T) {
+		expiring, err := leaseService.GetExpiringLeases(testContext(), 0)
+		require.NoError(t, err)
+		require.Len(t, expiring, 1)
+		assert.Equal(t, soonLease.ID, expiring[0].ID)
+		assert.Equal(t, 10, expiring[0].DaysRemaining)
+
+		_, err = leaseService.GetExpiringLeases(testContext(), 365*2)
+		assert.ErrorContains(t, err, "invalid days")
+
+		expiring, err = leaseService.GetExpiringLeases(testContext(), 365)
+		require.NoError(t, err)
+		assert.Len(t, expiring, 1)
+	})
+
+	t.Run("Record mileage and project the excess", func(t *testing.T) {
+		lease, err := leaseService.RecordMileage(testContext(), soonLease.ID, &models.RecordMileageRequest{Mileage: 45010}, userID)
+		require.NoError(t, err)
+		require.NotNil(t, lease.MileageProjection)
+		assert.Equal(t, 45000, lease.MileageProjection.DrivenKm)
+		assert.Greater(t, lease.MileageProjection.ProjectedKm, 45000)
+		assert.True(t, lease.MileageProjection.ExcessCost.Amount.IsPositive())
+
+		_, err = leaseService.RecordMileage(testContext(), soonLease.ID, &models.RecordMileageRequest{Mileage: 45000}, userID)
+		assert.ErrorContains(t, err, "below the last recorded mileage")
+
+		yesterday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+		_, err = leaseService.RecordMileage(testContext(), soonLease.ID, &models.RecordMileageRequest{Mileage: 45100, Date: &yesterday}, userID)
+		assert.ErrorContains(t, err, "before the last mileage reading")
+
+		tomorrow := yesterday.AddDate(0, 0, 2)
+		_, err = leaseService.RecordMileage(testContext(), soonLease.ID, &models.RecordMileageRequest{Mileage: 45100, Date: &tomorrow}, userID)
+		assert.ErrorContains(t, err, "in the future")
+	})
+
+	t.Run("Update the terms of a contract", func(t *testing.T) {
+		mileage := 60000
+		lease, err := leaseService.UpdateLease(testContext(), soonLease.ID, &models.UpdateLeaseContractRequest{ContractedMileage: &mileage}, userID)
+		require.NoError(t, err)
+		assert.Equal(t, 60000, lease.ContractedMileage)
+		assert.Equal(t, 0, lease.MileageProjection.ExcessKm)
+
+		end := soonLease.StartDate
+		_, err = leaseService.UpdateLease(testContext(), soonLease.ID, &models.UpdateLeaseContractRequest{EndDate: &end}, userID)
+		assert.ErrorContains(t, err, "end date must be after start date")
+	})
+
+	t.Run("Return requires the car to be unassigned", func(t *testing.T) {
+		operator, err := operatorService.CreateOperator(testContext(), &models.CreateOperatorRequest{
+			EmployeeNumber: "LEASE001",
+			FirstName:      "Jeanne",
+			LastName:       "Martin",
+		}, userID)
+		require.NoError(t, err)
+
+		_, err = operatorService.AssignOperatorToCar(testContext(), soon.ID, &models.AssignOperatorRequest{
+			OperatorID: operator.ID,
+			StartDate:  today.Format("2006-01-02"),
+		}, userID)
+		require.NoError(t, err)
+
+		_, err = leaseService.ReturnLease(testContext(), soonLease.ID, &models.ReturnLeaseRequest{ReturnDate: today, ReturnMileage: 62010}, userID)
+		assert.ErrorContains(t, err, "must be unassigned")
+
+		err = operatorService.UnassignOperatorFromCar(testContext(), soon.ID, &models.UnassignOperatorRequest{
+			EndDate: today.Format("2006-01-02"),
+		}, userID)
+		require.NoError(t, err)
+	})
+
+	t.Run("Return the car and retire it", func(t *testing.T) {
+		_, err := leaseService.ReturnLease(testContext(), soonLease.ID, &models.ReturnLeaseRequest{ReturnDate: today, ReturnMileage: 45000}, userID)
+		assert.ErrorContains(t, err, "invalid return mileage")
+
+		lease, err := leaseService.ReturnLease(testContext(), soonLease.ID, &models.ReturnLeaseRequest{ReturnDate: today, ReturnMileage: 62010}, userID)
+		require.NoError(t, err)
+		assert.Equal(t, models.LeaseStatusReturned, lease.Status)
+		require.NotNil(t, lease.MileageProjection)
+		assert.True(t, lease.MileageProjection.Final)
+		assert.Equal(t, 2000, lease.MileageProjection.ExcessKm)
+		assert.Equal(t, "170.00 EUR", lease.MileageProjection.ExcessCost.String())
+
+		car, err := carService.GetCar(testContext(), soon.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.CarStatusRetired, car.Status)
+
+		_, err = leaseService.ReturnLease(testContext(), soonLease.ID, &models.ReturnLeaseRequest{ReturnDate: today, ReturnMileage: 62010}, userID)
+		assert.ErrorContains(t, err, "already returned")
+
+		expiring, err := leaseService.GetExpiringLeases(testContext(), 0)
+		require.NoError(t, err)
+		assert.Empty(t, expiring)
+	})
+
+	t.Run("Filter contracts", func(t *testing.T) {
+		leases, err := leaseService.GetLeases(testContext(), &models.LeaseFilters{Status: models.LeaseStatusActive})
+		require.NoError(t, err)
+		assert.Len(t, leases, 1)
+
+		leases, err = leaseService.GetLeases(testContext(), &models.LeaseFilters{CarID: soon.ID})
+		require.NoError(t, err)
+		assert.Len(t, leases, 1)
+
+		_, err = leaseService.GetLeases(testContext(), &models.LeaseFilters{Status: "ended"})
+		assert.ErrorContains(t, err, "invalid status")
+	})
+
+	t.Run("Retired cars cannot be leased", func(t *testing.T) {
+		_, err := leaseService.CreateLease(testContext(), leaseRequest(soon.ID, "LC-4", today, today.AddDate(1, 0, 0)), userID)
+		assert.ErrorContains(t, err, "cannot lease a retired car")
+	})
+}
@@ -0,0 +1,157 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/handlers"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListEnvelopeIntegration(t *testing.T) {
+	cleanupDB(t)
+	_, _ = testDB.Exec("DELETE FROM repairs")
+	_, _ = testDB.Exec("DELETE FROM garages")
+
+	garageRepo := repository.NewGarageRepository(testDB)
+	garageHandler := handlers.NewGarageHandler(garageRepo)
+	ctx := testContext()
+
+	for _, name := range []string{"Garage Alpha", "Garage Bravo", "Garage Charlie"} {
+		require.NoError(t, garageRepo.Create(ctx, &models.Garage{
+			ID:        uuid.New().String(),
+			Name:      name,
+			Phone:     "0123456789",
+			Address:   "1 rue de la Paix",
+			IsActive:  true,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}))
+	}
+
+	get := func(t *testing.T, target string, out interface{}) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		garageHandler.ListGarages(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), out))
+	}
+
+	t.Run("v1 keeps returning a bare array", func(t *testing.T) {
+		var garages []models.Garage
+		get(t, "/api/v1/garages?limit=2", &garages)
+		assert.Len(t, garages, 3)
+	})
+
+	t.Run("v2 paginates in the list envelope", func(t *testing.T) {
+		var first models.ListResponse[models.Garage]
+		get(t, "/api/v2/garages?limit=2", &first)
+		require.Len(t, first.Data, 2)
+		assert.Equal(t, "Garage Alpha", first.Data[0].Name)
+		assert.Equal(t, 2, first.Pagination.Limit)
+		require.NotNil(t, first.Pagination.Total)
+		assert.Equal(t, 3, *first.Pagination.Total)
+		assert.Equal(t, 2, *first.Pagination.TotalPages)
+		assert.Nil(t, first.Pagination.PrevCursor)
+		require.NotNil(t, first.Pagination.NextCursor)
+
+		var second models.ListResponse[models.Garage]
+		get(t, "/api/v2/garages?limit=2&cursor="+url.QueryEscape(*first.Pagination.NextCursor), &second)
+		require.Len(t, second.Data, 1)
+		assert.Equal(t, "Garage Charlie", second.Data[0].Name)
+		assert.Nil(t, second.Pagination.Total, "cursor pages are not counted")
+		assert.Nil(t, second.Pagination.NextCursor)
+		assert.NotNil(t, second.Pagination.PrevCursor)
+	})
+
+	t.Run("v2 accepts camelCase filters", func(t *testing.T) {
+		var list models.ListResponse[models.Garage]
+		get(t, "/api/v2/garages?isActive=false", &list)
+		assert.Empty(t, list.Data)
+		assert.NotNil(t, list.Data, "empty lists are [] rather than null")
+	})
+
+	t.Run("v2 rejects a malformed cursor", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/garages?cursor=%25%25", nil)
+		rec := httptest.NewRecorder()
+		garageHandler.ListGarages(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("v2 operators and assignments are camelCase", func(t *testing.T) {
+		operatorRepo := repository.NewOperatorRepository(testDB)
+		carRepo := repository.NewCarRepository(testDB)
+		operatorService := service.NewOperatorService(operatorRepo, carRepo, repository.NewActionLogRepository(testDB))
+		operatorHandler := handlers.NewOperatorHandler(operatorService)
+		userID := "00000000-0000-0000-0000-000000000001"
+
+		operator, err := operatorService.CreateOperator(ctx, &models.CreateOperatorRequest{
+			EmployeeNumber: "ENV001",
+			FirstName:      "Lucie",
+			LastName:       "Bernard",
+		}, userID)
+		require.NoError(t, err)
+		insuranceRepo := repository.NewInsuranceRepository(testDB)
+		companies, err := insuranceRepo.FindAll(ctx)
+		require.NoError(t, err)
+		require.NotEmpty(t, companies)
+		carService := service.NewCarService(carRepo, insuranceRepo, repository.NewActionLogRepository(testDB), repository.NewAccidentRepository(testDB), repository.NewRepairRepository(testDB))
+		car, err := carService.CreateCar(ctx, &models.CreateCarRequest{
+			LicensePlate:       "EN-001-AA",
+			Brand:              "Renault",
+			Model:              "Clio",
+			GreyCardNumber:     "GC-EN001",
+			InsuranceCompanyID: companies[0].ID,
+			RentalStartDate:    time.Now(),
+			Status:             models.CarStatusActive,
+		}, userID)
+		require.NoError(t, err)
+		_, err = operatorService.AssignOperatorToCar(ctx, car.ID, &models.AssignOperatorRequest{
+			OperatorID: operator.ID,
+			StartDate:  time.Now().Format("2006-01-02"),
+		}, userID)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/operators?limit=10", nil)
+		rec := httptest.NewRecorder()
+		operatorHandler.GetOperators(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var operators struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &operators))
+		require.Len(t, operators.Data, 1)
+		assert.Equal(t, "ENV001", operators.Data[0]["employeeNumber"])
+		assert.Equal(t, "Lucie", operators.Data[0]["firstName"])
+		assert.NotContains(t, operators.Data[0], "employee_number")
+		currentCar, ok := operators.Data[0]["currentCar"].(map[string]interface{})
+		require.True(t, ok, "current car is listed as currentCar")
+		assert.Equal(t, "EN-001-AA", currentCar["licensePlate"])
+
+		req = httptest.NewRequest(http.MethodGet, "/api/v2/operators/"+operator.ID+"/assignment-history", nil)
+		req.SetPathValue("id", operator.ID)
+		rec = httptest.NewRecorder()
+		operatorHandler.GetOperatorAssignmentHistory(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var history struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &history))
+		require.Len(t, history.Data, 1)
+		assert.Equal(t, car.ID, history.Data[0]["carId"])
+		assert.Contains(t, history.Data[0], "startDate")
+		assert.NotContains(t, history.Data[0], "start_date")
+	})
+}
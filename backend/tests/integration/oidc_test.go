@@ -0,0 +1,145 @@
+package integration
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/goldenkiwi/autoparc/pkg/oidc"
+	"github.com/goldenkiwi/autoparc/pkg/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	idp := oidctest.NewServer("autoparc", "s3cret")
+	defer idp.Close()
+
+	oidcConfig := &config.OIDCConfig{
+		IssuerURL:    idp.Issuer(),
+		ClientID:     "autoparc",
+		ClientSecret: "s3cret",
+		RedirectURL:  "http://localhost:8080/api/v1/auth/oidc/callback",
+		Scopes:       []string{"email", "profile"},
+		GroupsClaim:  "groups",
+	}
+	provider := oidc.NewProvider(oidc.Config{
+		IssuerURL:    oidcConfig.IssuerURL,
+		ClientID:     oidcConfig.ClientID,
+		ClientSecret: oidcConfig.ClientSecret,
+		RedirectURL:  oidcConfig.RedirectURL,
+		Scopes:       oidcConfig.Scopes,
+	})
+	userRepo := repository.NewUserRepository(testDB)
+	oidcService := service.NewOIDCService(
+		userRepo,
+		repository.NewActionLogRepository(testDB),
+		newTestAuthService(testLoginConfig()),
+		provider,
+		oidcConfig,
+	)
+
+	// login runs the whole flow for the user currently set at the provider
+	login := func(t *testing.T) (string, bool, error) {
+		ctx := testContext()
+		authURL, state, err := oidcService.BeginLogin(ctx)
+		require.NoError(t, err)
+
+		callback, err := idp.Authorize(authURL)
+		require.NoError(t, err)
+		u, err := url.Parse(callback)
+		require.NoError(t, err)
+
+		user, session, err := oidcService.CompleteLogin(ctx, state, u.Query().Get("state"), u.Query().Get("code"), "127.0.0.1", "test-agent")
+		if err != nil {
+			return "", false, err
+		}
+		return user.ID, session.MFAPending, nil
+	}
+
+	t.Run("Existing employee is linked by verified email", func(t *testing.T) {
+		idp.SetUser(map[string]interface{}{"sub": "admin-sub", "email": "Admin@AutoParc.fr", "email_verified": false})
+		_, _, err := login(t)
+		assert.EqualError(t, err, "identity provider did not return a verified email")
+
+		idp.SetUser(map[string]interface{}{"sub": "admin-sub", "email": "Admin@AutoParc.fr", "email_verified": true})
+		userID, pending, err := login(t)
+		require.NoError(t, err)
+		assert.Equal(t, "00000000-0000-0000-0000-000000000001", userID)
+		assert.False(t, pending)
+
+		// Once linked, the subject alone identifies the employee
+		idp.SetUser(map[string]interface{}{"sub": "admin-sub", "email": "renamed@example.com", "email_verified": true})
+		userID, _, err = login(t)
+		require.NoError(t, err)
+		assert.Equal(t, "00000000-0000-0000-0000-000000000001", userID)
+
+		// Another identity with the same email cannot take the account over
+		idp.SetUser(map[string]interface{}{"sub": "other-sub", "email": "admin@autoparc.fr", "email_verified": true})
+		_, _, err = login(t)
+		assert.EqualError(t, err, "employee already linked to another identity")
+	})
+
+	t.Run("Unknown users are refused without provisioning", func(t *testing.T) {
+		idp.SetUser(map[string]interface{}{"sub": "new-sub", "email": "new@example.com", "email_verified": true})
+		_, _, err := login(t)
+		assert.EqualError(t, err, "no employee matches this identity")
+	})
+
+	t.Run("Provisioning from group claims", func(t *testing.T) {
+		oidcConfig.AutoProvision = true
+		oidcConfig.GroupRoles = []config.OIDCGroupRole{{Group: "fleet-admins", Role: "admin"}}
+		defer func() {
+			oidcConfig.AutoProvision = false
+			oidcConfig.GroupRoles = nil
+		}()
+
+		idp.SetUser(map[string]interface{}{"sub": "sales-sub", "email": "sales@example.com", "email_verified": true, "groups": []string{"sales"}})
+		_, _, err := login(t)
+		assert.EqualError(t, err, "identity is not allowed to access AutoParc")
+
+		idp.SetUser(map[string]interface{}{
+			"sub":            "jane-sub",
+			"email":          "jane@example.com",
+			"email_verified": true,
+			"given_name":     "Jane",
+			"family_name":    "Martin",
+			"groups":         []string{"fleet-admins"},
+		})
+		userID, _, err := login(t)
+		require.NoError(t, err)
+
+		employee, err := userRepo.GetByID(testContext(), userID)
+		require.NoError(t, err)
+		assert.Equal(t, "jane@example.com", employee.Email)
+		assert.Equal(t, "Jane", employee.FirstName)
+		assert.Equal(t, "Martin", employee.LastName)
+		assert.Equal(t, "admin", employee.Role)
+
+		// The next login finds the provisioned employee
+		again, _, err := login(t)
+		require.NoError(t, err)
+		assert.Equal(t, userID, again)
+	})
+
+	t.Run("State must match", func(t *testing.T) {
+		idp.SetUser(map[string]interface{}{"sub": "admin-sub", "email": "admin@autoparc.fr", "email_verified": true})
+
+		ctx := testContext()
+		authURL, state, err := oidcService.BeginLogin(ctx)
+		require.NoError(t, err)
+		callback, err := idp.Authorize(authURL)
+		require.NoError(t, err)
+		u, _ := url.Parse(callback)
+
+		_, _, err = oidcService.CompleteLogin(ctx, state, "forged", u.Query().Get("code"), "127.0.0.1", "test-agent")
+		assert.EqualError(t, err, "invalid login state")
+
+		_, _, err = oidcService.CompleteLogin(ctx, nil, u.Query().Get("state"), u.Query().Get("code"), "127.0.0.1", "test-agent")
+		assert.EqualError(t, err, "invalid login state")
+	})
+}
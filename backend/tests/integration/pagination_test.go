@@ -0,0 +1,97 @@
+package integration
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorPaginationIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	carRepo := repository.NewCarRepository(testDB)
+	insuranceRepo := repository.NewInsuranceRepository(testDB)
+	actionLogRepo := repository.NewActionLogRepository(testDB)
+	accidentRepo := repository.NewAccidentRepository(testDB)
+	repairRepo := repository.NewRepairRepository(testDB)
+	carService := service.NewCarService(carRepo, insuranceRepo, actionLogRepo, accidentRepo, repairRepo)
+
+	ctx := testContext()
+	companies, err := insuranceRepo.FindAll(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, companies)
+	userID := "00000000-0000-0000-0000-000000000001"
+
+	// Five cars sharing the same brand, so sorting by brand relies on the ID
+	// tiebreaker for a stable order
+	for i := 1; i <= 5; i++ {
+		_, err := carService.CreateCar(ctx, &models.CreateCarRequest{
+			LicensePlate:       fmt.Sprintf("PG-%03d-AA", i),
+			Brand:              "Dacia",
+			Model:              "Sandero",
+			GreyCardNumber:     fmt.Sprintf("GCPG%03d", i),
+			InsuranceCompanyID: companies[0].ID,
+			RentalStartDate:    time.Now(),
+			Status:             models.CarStatusActive,
+		}, userID)
+		require.NoError(t, err)
+	}
+
+	list := func(t *testing.T, cursor *string) *models.CarListResponse {
+		t.Helper()
+		filters := &models.CarFilters{Limit: 2, SortBy: "brand", SortOrder: "asc"}
+		if cursor != nil {
+			decoded, err := models.DecodeCursor(*cursor)
+			require.NoError(t, err)
+			filters.Cursor = decoded
+		}
+		response, err := carService.GetCars(testContext(), filters)
+		require.NoError(t, err)
+		return response
+	}
+
+	plates := func(cars []*models.Car) []string {
+		result := make([]string, len(cars))
+		for i, car := range cars {
+			result[i] = car.LicensePlate
+		}
+		return result
+	}
+
+	t.Run("Walk forward and back", func(t *testing.T) {
+		first := list(t, nil)
+		assert.Equal(t, 5, first.TotalCount)
+		assert.Nil(t, first.PrevCursor)
+		require.NotNil(t, first.NextCursor)
+
+		second := list(t, first.NextCursor)
+		assert.Zero(t, second.TotalCount, "cursor pages are not counted")
+		third := list(t, second.NextCursor)
+		assert.Len(t, third.Cars, 1)
+		assert.Nil(t, third.NextCursor)
+
+		seen := append(append(plates(first.Cars), plates(second.Cars)...), plates(third.Cars)...)
+		assert.ElementsMatch(t, []string{"PG-001-AA", "PG-002-AA", "PG-003-AA", "PG-004-AA", "PG-005-AA"}, seen)
+
+		back := list(t, third.PrevCursor)
+		assert.Equal(t, plates(second.Cars), plates(back.Cars))
+		back = list(t, back.PrevCursor)
+		assert.Equal(t, plates(first.Cars), plates(back.Cars))
+		assert.Nil(t, back.PrevCursor)
+	})
+
+	t.Run("Reject cursor from another sort", func(t *testing.T) {
+		first := list(t, nil)
+		cursor, err := models.DecodeCursor(*first.NextCursor)
+		require.NoError(t, err)
+
+		_, err = carService.GetCars(testContext(), &models.CarFilters{Limit: 2, SortBy: "model", Cursor: cursor})
+		assert.ErrorIs(t, err, models.ErrInvalidCursor)
+	})
+}
@@ -0,0 +1,139 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/middleware"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/goldenkiwi/autoparc/pkg/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicyIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	const adminID = "00000000-0000-0000-0000-000000000001"
+	userRepo := repository.NewUserRepository(testDB)
+	sessionRepo := repository.NewSessionRepository(testDB)
+	actionLogRepo := repository.NewActionLogRepository(testDB)
+
+	passwordConfig := testPasswordConfig()
+	passwordConfig.HistorySize = 2
+	policy := newTestPasswordPolicy(passwordConfig)
+	employeeService := service.NewEmployeeService(userRepo, sessionRepo, actionLogRepo, policy)
+
+	employee, err := employeeService.CreateEmployee(testContext(), service.CreateEmployeeRequest{
+		Email:     "policy@autoparc.fr",
+		Password:  "FirstPass123",
+		FirstName: "Policy",
+		LastName:  "Test",
+	}, adminID)
+	require.NoError(t, err)
+
+	t.Run("Breached passwords are rejected on create", func(t *testing.T) {
+		_, err := employeeService.CreateEmployee(testContext(), service.CreateEmployeeRequest{
+			Email:     "breached@autoparc.fr",
+			Password:  "Azerty123",
+			FirstName: "Breached",
+			LastName:  "Test",
+		}, adminID)
+		assert.EqualError(t, err, "password must not be a commonly used password")
+	})
+
+	t.Run("Recent passwords cannot be reused", func(t *testing.T) {
+		ctx := testContext()
+		change := func(current, next string) error {
+			return employeeService.ChangePassword(ctx, employee.ID, service.ChangePasswordRequest{
+				CurrentPassword: current,
+				NewPassword:     next,
+			}, employee.ID)
+		}
+
+		assert.EqualError(t, change("FirstPass123", "FirstPass123"), "password must differ from the last 2 passwords")
+		require.NoError(t, change("FirstPass123", "SecondPass123"))
+		assert.Error(t, change("SecondPass123", "FirstPass123"))
+		require.NoError(t, change("SecondPass123", "ThirdPass123"))
+
+		// Only the last two passwords are kept
+		hashes, err := userRepo.RecentPasswordHashes(ctx, employee.ID, 10)
+		require.NoError(t, err)
+		assert.Len(t, hashes, 2)
+		assert.NoError(t, change("ThirdPass123", "FirstPass123"))
+	})
+
+	t.Run("Reset rejects reuse without burning the token", func(t *testing.T) {
+		ctx := testContext()
+		mail := mailer.NewMemoryMailer()
+		accountService := service.NewAccountService(
+			userRepo,
+			sessionRepo,
+			repository.NewAccountTokenRepository(testDB),
+			actionLogRepo,
+			policy,
+			mail,
+			&config.AccountConfig{AppBaseURL: "https://autoparc.example.com", PasswordResetTTL: time.Hour},
+		)
+
+		require.NoError(t, accountService.RequestPasswordReset(ctx, "policy@autoparc.fr"))
+		token := tokenFromMail(t, mail, "policy@autoparc.fr")
+
+		err := accountService.ResetPassword(ctx, models.SetPasswordRequest{Token: token, Password: "FirstPass123"})
+		assert.EqualError(t, err, "password must differ from the last 2 passwords")
+		assert.NoError(t, accountService.ResetPassword(ctx, models.SetPasswordRequest{Token: token, Password: "FourthPass123"}))
+	})
+
+	t.Run("Expired password must be changed at next login", func(t *testing.T) {
+		ctx := testContext()
+
+		_, err := testDB.Exec(`UPDATE administrative_employees SET password_changed_at = NOW() - INTERVAL '100 days' WHERE id = $1`, employee.ID)
+		require.NoError(t, err)
+
+		expiringConfig := testPasswordConfig()
+		expiringConfig.MaxAge = 90 * 24 * time.Hour
+		authService := service.NewAuthService(
+			userRepo,
+			sessionRepo,
+			repository.NewLoginAttemptRepository(testDB),
+			actionLogRepo,
+			testLoginConfig(),
+			testSessionConfig(),
+			testTwoFactorConfig(),
+			newTestPasswordPolicy(expiringConfig),
+		)
+
+		_, session, err := authService.Login(ctx, "policy@autoparc.fr", "FourthPass123", "127.0.0.1", "test-agent")
+		require.NoError(t, err)
+		assert.True(t, session.PasswordChangeRequired)
+
+		ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+		call := func(mw func(http.Handler) http.Handler, token string) int {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/me", nil)
+			req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+			rec := httptest.NewRecorder()
+			mw(ok).ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		assert.Equal(t, http.StatusForbidden, call(middleware.AuthMiddleware(authService, "session_token"), session.SessionToken))
+		assert.Equal(t, http.StatusNoContent, call(middleware.PasswordChangeMiddleware(authService, "session_token"), session.SessionToken))
+
+		require.NoError(t, employeeService.ChangePassword(ctx, employee.ID, service.ChangePasswordRequest{
+			CurrentPassword: "FourthPass123",
+			NewPassword:     "FifthPass123",
+		}, employee.ID))
+
+		_, session, err = authService.Login(ctx, "policy@autoparc.fr", "FifthPass123", "127.0.0.1", "test-agent")
+		require.NoError(t, err)
+		assert.False(t, session.PasswordChangeRequired)
+		assert.Equal(t, http.StatusNoContent, call(middleware.AuthMiddleware(authService, "session_token"), session.SessionToken))
+	})
+}
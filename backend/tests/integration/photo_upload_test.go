@@ -0,0 +1,184 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/handlers"
+	"github.com/goldenkiwi/autoparc/internal/middleware"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for x := 0; x < 16; x++ {
+		for y := 0; y < 16; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 16), G: uint8(y * 16), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestPhotoUploadIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	accidentRepo := repository.NewAccidentRepository(testDB)
+	carRepo := repository.NewCarRepository(testDB)
+	insuranceRepo := repository.NewInsuranceRepository(testDB)
+	accidentHandler := handlers.NewAccidentHandler(
+		accidentRepo,
+		repository.NewAccidentPhotoRepository(testDB),
+		repository.NewPhotoUploadRepository(testDB),
+		&config.UploadConfig{MaxFileSize: 1 << 20, MaxFilesPerRequest: 2, ResumableExpiry: time.Hour},
+	)
+	ctx := testContext()
+
+	companies, err := insuranceRepo.FindAll(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, companies)
+	car := &models.Car{
+		ID:                 uuid.New().String(),
+		LicensePlate:       "PU-100-AA",
+		Brand:              "Citroën",
+		Model:              "C3",
+		GreyCardNumber:     "GC-PU100",
+		InsuranceCompanyID: companies[0].ID,
+		Status:             models.CarStatusActive,
+		CreatedBy:          "00000000-0000-0000-0000-000000000001",
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+	require.NoError(t, carRepo.Create(ctx, car))
+	accident := &models.Accident{
+		ID:           uuid.New().String(),
+		CarID:        car.ID,
+		AccidentDate: time.Now().Add(-24 * time.Hour),
+		Location:     "Nantes, France",
+		Description:  "Scratched door",
+		Status:       models.AccidentStatusDeclared,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	require.NoError(t, accidentRepo.Create(ctx, accident))
+	t.Cleanup(func() {
+		_, _ = testDB.Exec("DELETE FROM accidents WHERE id = $1", accident.ID)
+	})
+
+	owner := &models.AdministrativeEmployee{ID: "00000000-0000-0000-0000-000000000001"}
+	other := &models.AdministrativeEmployee{ID: uuid.New().String()}
+	photosURL := "/api/v1/accidents/" + accident.ID + "/photos"
+	photoData := testPNG(t)
+
+	serve := func(handler http.HandlerFunc, user *models.AdministrativeEmployee, req *http.Request) *httptest.ResponseRecorder {
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, user))
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	batch := func(files map[string][]byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		for name, data := range files {
+			part, err := writer.CreateFormFile("files", name)
+			require.NoError(t, err)
+			_, err = part.Write(data)
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, photosURL+"/batch", &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return serve(accidentHandler.UploadPhotosBatch, owner, req)
+	}
+
+	t.Run("Batch upload rejects too many files", func(t *testing.T) {
+		rec := batch(map[string][]byte{"a.png": photoData, "b.png": photoData, "c.png": photoData})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Too many files")
+	})
+
+	t.Run("Batch upload reports each file", func(t *testing.T) {
+		rec := batch(map[string][]byte{"door.png": photoData, "notes.txt": []byte("not an image")})
+		require.Equal(t, http.StatusMultiStatus, rec.Code, rec.Body.String())
+
+		var response models.BatchUploadResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.Uploaded)
+		assert.Equal(t, 1, response.Failed)
+		for _, result := range response.Results {
+			if result.Filename == "notes.txt" {
+				assert.False(t, result.Success)
+				assert.Contains(t, result.Error, "Only images are allowed")
+			} else {
+				assert.True(t, result.Success)
+				assert.NotNil(t, result.Photo)
+			}
+		}
+	})
+
+	t.Run("Resumable upload", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, photosURL+"/uploads", nil)
+		req.Header.Set("Upload-Length", strconv.Itoa(len(photoData)))
+		req.Header.Set("Upload-Metadata", "filename "+base64.StdEncoding.EncodeToString([]byte("bumper.png")))
+		rec := serve(accidentHandler.CreatePhotoUpload, owner, req)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		assert.Equal(t, "0", rec.Header().Get("Upload-Offset"))
+		uploadURL := rec.Header().Get("Location")
+		require.NotEmpty(t, uploadURL)
+
+		offsetOf := func(user *models.AdministrativeEmployee) *httptest.ResponseRecorder {
+			return serve(accidentHandler.GetPhotoUploadOffset, user, httptest.NewRequest(http.MethodHead, uploadURL, nil))
+		}
+		patch := func(user *models.AdministrativeEmployee, offset int, chunk []byte) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPatch, uploadURL, bytes.NewReader(chunk))
+			req.Header.Set("Content-Type", "application/offset+octet-stream")
+			req.Header.Set("Upload-Offset", strconv.Itoa(offset))
+			return serve(accidentHandler.AppendPhotoUpload, user, req)
+		}
+		half := len(photoData) / 2
+
+		assert.Equal(t, http.StatusNotFound, offsetOf(other).Code, "other employees cannot see the upload")
+		assert.Equal(t, http.StatusNotFound, patch(other, 0, photoData[:half]).Code, "other employees cannot write to the upload")
+
+		rec = patch(owner, 5, photoData[:half])
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, "0", rec.Header().Get("Upload-Offset"))
+
+		rec = patch(owner, 0, photoData[:half])
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		assert.Equal(t, strconv.Itoa(half), rec.Header().Get("Upload-Offset"))
+
+		rec = offsetOf(owner)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, strconv.Itoa(half), rec.Header().Get("Upload-Offset"))
+		assert.Equal(t, strconv.Itoa(len(photoData)), rec.Header().Get("Upload-Length"))
+
+		rec = patch(owner, half, photoData[half:])
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var photo map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &photo))
+		assert.Equal(t, "bumper.png", photo["filename"])
+
+		assert.Equal(t, http.StatusNotFound, offsetOf(owner).Code, "completed uploads cannot be resumed")
+	})
+}
@@ -0,0 +1,128 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goldenkiwi/autoparc/internal/handlers"
+	"github.com/goldenkiwi/autoparc/internal/middleware"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/goldenkiwi/autoparc/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	const adminID = "00000000-0000-0000-0000-000000000001"
+	userRepo := repository.NewUserRepository(testDB)
+	sessionRepo := repository.NewSessionRepository(testDB)
+	employeeService := service.NewEmployeeService(
+		userRepo,
+		sessionRepo,
+		repository.NewActionLogRepository(testDB),
+		newTestPasswordPolicy(testPasswordConfig()),
+	)
+	authService := newTestAuthService(testLoginConfig())
+
+	employee, err := employeeService.CreateEmployee(testContext(), service.CreateEmployeeRequest{
+		Email:     "profile@autoparc.fr",
+		Password:  "ProfilePass123",
+		FirstName: "Profile",
+		LastName:  "Test",
+		Role:      "viewer",
+	}, adminID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultEmployeePreferences(), employee.Preferences)
+
+	t.Run("Employees update their own profile", func(t *testing.T) {
+		ctx := testContext()
+
+		updated, err := employeeService.UpdateProfile(ctx, employee.ID, models.UpdateProfileRequest{
+			FirstName:   " Camille ",
+			Preferences: &models.EmployeePreferences{Language: "en", DefaultPageSize: 50},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Camille", updated.FirstName)
+		assert.Equal(t, "Test", updated.LastName)
+		assert.Equal(t, models.EmployeePreferences{Language: "en", DefaultPageSize: 50}, updated.Preferences)
+
+		stored, err := userRepo.FindByID(ctx, employee.ID)
+		require.NoError(t, err)
+		assert.Equal(t, updated.Preferences, stored.Preferences)
+
+		_, err = employeeService.UpdateProfile(ctx, employee.ID, models.UpdateProfileRequest{
+			Preferences: &models.EmployeePreferences{Language: "de"},
+		})
+		assert.EqualError(t, err, "invalid language: de")
+
+		_, err = employeeService.UpdateProfile(ctx, employee.ID, models.UpdateProfileRequest{
+			Preferences: &models.EmployeePreferences{DefaultPageSize: 1000},
+		})
+		assert.EqualError(t, err, "invalid page size: 1000")
+	})
+
+	t.Run("Own password change keeps the current session", func(t *testing.T) {
+		ctx := testContext()
+
+		_, current, err := authService.Login(ctx, "profile@autoparc.fr", "ProfilePass123", "127.0.0.1", "test-agent")
+		require.NoError(t, err)
+		_, other, err := authService.Login(ctx, "profile@autoparc.fr", "ProfilePass123", "127.0.0.2", "other-agent")
+		require.NoError(t, err)
+
+		err = employeeService.ChangeOwnPassword(ctx, employee.ID, current.ID, service.ChangePasswordRequest{NewPassword: "ChangedPass123"})
+		assert.EqualError(t, err, "current password is incorrect")
+
+		require.NoError(t, employeeService.ChangeOwnPassword(ctx, employee.ID, current.ID, service.ChangePasswordRequest{
+			CurrentPassword: "ProfilePass123",
+			NewPassword:     "ChangedPass123",
+		}))
+
+		_, _, err = authService.AuthenticateSession(ctx, current.SessionToken)
+		assert.NoError(t, err)
+		_, _, err = authService.AuthenticateSession(ctx, other.SessionToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("Only admins change other employees", func(t *testing.T) {
+		ctx := testContext()
+
+		_, session, err := authService.Login(ctx, "profile@autoparc.fr", "ChangedPass123", "127.0.0.1", "test-agent")
+		require.NoError(t, err)
+
+		employeeHandler := handlers.NewEmployeeHandler(employeeService)
+		authMux := http.NewServeMux()
+		authMux.HandleFunc("PUT /api/v1/employees/{id}", employeeHandler.UpdateEmployee)
+		authMux.HandleFunc("DELETE /api/v1/employees/{id}", employeeHandler.DeleteEmployee)
+		authMux.HandleFunc("POST /api/v1/employees/{id}/change-password", employeeHandler.ChangePassword)
+		authMux.HandleFunc("PUT /api/v1/auth/me", employeeHandler.UpdateMe)
+		handler := middleware.AuthMiddleware(authService, "session_token")(authMux)
+
+		call := func(method, path, body string) int {
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req.AddCookie(&http.Cookie{Name: "session_token", Value: session.SessionToken})
+			req.Header.Set(middleware.CSRFHeader, utils.CSRFToken(session.SessionToken))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		assert.Equal(t, http.StatusForbidden, call(http.MethodPut, "/api/v1/employees/"+adminID, `{"firstName":"Pirate"}`))
+		assert.Equal(t, http.StatusForbidden, call(http.MethodDelete, "/api/v1/employees/"+adminID, ""))
+		assert.Equal(t, http.StatusForbidden, call(http.MethodPost, "/api/v1/employees/"+adminID+"/change-password", `{"newPassword":"Hijacked123"}`))
+		assert.Equal(t, http.StatusForbidden, call(http.MethodPut, "/api/v1/employees/"+employee.ID, `{"role":"admin"}`))
+
+		// Their own password still goes through the generic route
+		assert.Equal(t, http.StatusOK, call(http.MethodPost, "/api/v1/employees/"+employee.ID+"/change-password",
+			`{"currentPassword":"ChangedPass123","newPassword":"AgainPass123"}`))
+
+		admin, err := userRepo.GetByID(ctx, adminID)
+		require.NoError(t, err)
+		assert.Equal(t, "Admin", admin.FirstName)
+	})
+}
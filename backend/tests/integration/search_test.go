@@ -0,0 +1,142 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	carRepo := repository.NewCarRepository(testDB)
+	insuranceRepo := repository.NewInsuranceRepository(testDB)
+	actionLogRepo := repository.NewActionLogRepository(testDB)
+	accidentRepo := repository.NewAccidentRepository(testDB)
+	repairRepo := repository.NewRepairRepository(testDB)
+	garageRepo := repository.NewGarageRepository(testDB)
+	carService := service.NewCarService(carRepo, insuranceRepo, actionLogRepo, accidentRepo, repairRepo)
+	searchService := service.NewSearchService(repository.NewSearchRepository(testDB))
+
+	ctx := testContext()
+	companies, err := insuranceRepo.FindAll(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, companies)
+	userID := "00000000-0000-0000-0000-000000000001"
+
+	car, err := carService.CreateCar(ctx, &models.CreateCarRequest{
+		LicensePlate:       "FT-482-KZ",
+		Brand:              "Citroën",
+		Model:              "Berlingo",
+		GreyCardNumber:     "GCFTS001",
+		InsuranceCompanyID: companies[0].ID,
+		RentalStartDate:    time.Now(),
+		Status:             models.CarStatusActive,
+	}, userID)
+	require.NoError(t, err)
+
+	_, err = testDB.Exec(`
+		INSERT INTO car_operators (employee_number, first_name, last_name, department)
+		VALUES ($1, 'Hélène', 'Vasseur', 'Logistique')
+	`, "FTS-"+uuid.New().String()[:8])
+	require.NoError(t, err)
+
+	garage := &models.Garage{
+		ID:        uuid.New().String(),
+		Name:      "Carrosserie Sévigné",
+		Phone:     "0123456789",
+		Address:   "12 rue de Sévigné, Paris",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, garageRepo.Create(ctx, garage))
+
+	_, err = testDB.Exec(`
+		INSERT INTO accidents (car_id, accident_date, location, description, insurance_claim_number)
+		VALUES ($1, NOW(), 'Boulevard Haussmann', 'Rétroviseur arraché', 'SIN-7731')
+	`, car.ID)
+	require.NoError(t, err)
+
+	_, err = testDB.Exec(`
+		INSERT INTO repairs (car_id, garage_id, repair_type, description, start_date, invoice_number)
+		VALUES ($1, $2, 'accident', 'Remplacement du rétroviseur', CURRENT_DATE, 'FAC-90210')
+	`, car.ID, garage.ID)
+	require.NoError(t, err)
+
+	_, err = testDB.Exec(`
+		INSERT INTO garages (name, phone, address, specialization)
+		VALUES ('Vitrage Express', '0123456789', '3 quai de Loire, Nantes', '<img src=x onerror=alert(1)> Vitrage & pare-brise')
+	`)
+	require.NoError(t, err)
+
+	search := func(t *testing.T, query string, types ...models.SearchResultType) []models.SearchResult {
+		t.Helper()
+		response, err := searchService.Search(testContext(), &models.SearchFilters{Query: query, Types: types})
+		require.NoError(t, err)
+		return response.Results
+	}
+
+	t.Run("Find a car by plate", func(t *testing.T) {
+		for _, query := range []string{"FT-482-KZ", "ft482kz", "FT-482"} {
+			results := search(t, query, models.SearchResultCar)
+			require.Len(t, results, 1, query)
+			assert.Equal(t, car.ID, results[0].ID)
+			assert.Equal(t, "FT-482-KZ", results[0].Title)
+			assert.Contains(t, results[0].Highlight, "<mark>")
+		}
+	})
+
+	t.Run("Ignore accents and match prefixes", func(t *testing.T) {
+		results := search(t, "helene vass", models.SearchResultOperator)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Hélène Vasseur", results[0].Title)
+
+		results = search(t, "sevigne", models.SearchResultGarage)
+		require.Len(t, results, 1)
+		assert.Equal(t, garage.ID, results[0].ID)
+	})
+
+	t.Run("Find accidents and repairs by their numbers", func(t *testing.T) {
+		results := search(t, "SIN-7731")
+		require.Len(t, results, 1)
+		assert.Equal(t, models.SearchResultAccident, results[0].Type)
+		require.NotNil(t, results[0].CarID)
+		assert.Equal(t, car.ID, *results[0].CarID)
+
+		results = search(t, "FAC-90210")
+		require.Len(t, results, 1)
+		assert.Equal(t, models.SearchResultRepair, results[0].Type)
+		assert.Contains(t, results[0].Subtitle, "Carrosserie Sévigné")
+	})
+
+	t.Run("Search across types", func(t *testing.T) {
+		// The accident and the repair both mention the mirror
+		results := search(t, "retroviseur")
+		assert.Len(t, results, 2)
+		for _, result := range results {
+			assert.Contains(t, []models.SearchResultType{models.SearchResultAccident, models.SearchResultRepair}, result.Type)
+			assert.Contains(t, strings.ToLower(result.Highlight), "<mark>rétroviseur</mark>")
+		}
+	})
+
+	t.Run("Reject empty query", func(t *testing.T) {
+		_, err := searchService.Search(testContext(), &models.SearchFilters{Query: " - "})
+		assert.Error(t, err)
+	})
+
+	t.Run("Highlights escape the stored text", func(t *testing.T) {
+		results := search(t, "vitrage", models.SearchResultGarage)
+		require.Len(t, results, 1)
+		assert.Contains(t, results[0].Highlight, "<mark>")
+		assert.NotContains(t, results[0].Highlight, "<img")
+		assert.Contains(t, results[0].Highlight, "&lt;img src=x onerror=alert(1)&gt;")
+		assert.Contains(t, results[0].Highlight, "&amp;")
+	})
+}
@@ -106,6 +106,16 @@ func cleanupDB(t *testing.T) {
 	// Delete in order to respect foreign key constraints
 	_, _ = testDB.Exec("DELETE FROM action_logs")
 	_, _ = testDB.Exec("DELETE FROM sessions")
+	_, _ = testDB.Exec("DELETE FROM login_attempts")
+	_, _ = testDB.Exec("DELETE FROM account_tokens")
+	_, _ = testDB.Exec("DELETE FROM two_factor_recovery_codes")
+	_, _ = testDB.Exec("DELETE FROM api_tokens")
+	_, _ = testDB.Exec("DELETE FROM password_history")
+	_, _ = testDB.Exec("DELETE FROM car_lifecycle_events")
+	_, _ = testDB.Exec("DELETE FROM traffic_fines")
+	_, _ = testDB.Exec("DELETE FROM fuel_transactions")
+	_, _ = testDB.Exec("DELETE FROM fuel_cards")
+	_, _ = testDB.Exec("DELETE FROM lease_contracts")
 	_, _ = testDB.Exec("DELETE FROM cars")
 	_, _ = testDB.Exec("DELETE FROM insurance_companies")
 	_, _ = testDB.Exec("DELETE FROM administrative_employees")
@@ -0,0 +1,278 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/models"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/goldenkiwi/autoparc/pkg/mailer"
+	"github.com/goldenkiwi/autoparc/pkg/money"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrafficFineIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	carRepo := repository.NewCarRepository(testDB)
+	insuranceRepo := repository.NewInsuranceRepository(testDB)
+	actionLogRepo := repository.NewActionLogRepository(testDB)
+	operatorRepo := repository.NewOperatorRepository(testDB)
+	carService := service.NewCarService(carRepo, insuranceRepo, actionLogRepo, repository.NewAccidentRepository(testDB), repository.NewRepairRepository(testDB))
+	operatorService := service.NewOperatorService(operatorRepo, carRepo, actionLogRepo)
+	mail := mailer.NewMemoryMailer()
+	fineService := service.NewTrafficFineService(
+		repository.NewTrafficFineRepository(testDB),
+		carRepo,
+		operatorRepo,
+		repository.NewUserRepository(testDB),
+		actionLogRepo,
+		mail,
+		&config.FineConfig{ReminderDays: 10, Location: paris},
+	)
+
+	ctx := testContext()
+	companies, err := insuranceRepo.FindAll(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, companies)
+	userID := "00000000-0000-0000-0000-000000000001"
+
+	newCar := func(plate string) *models.Car {
+		car, err := carService.CreateCar(testContext(), &models.CreateCarRequest{
+			LicensePlate:       plate,
+			Brand:              "Renault",
+			Model:              "Clio",
+			GreyCardNumber:     "GC-" + plate,
+			InsuranceCompanyID: companies[0].ID,
+			RentalStartDate:    time.Now(),
+			Status:             models.CarStatusActive,
+		}, userID)
+		require.NoError(t, err)
+		return car
+	}
+
+	assigned := newCar("FI-100-AA")
+	unassigned := newCar("FI-200-AA")
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	operator, err := operatorService.CreateOperator(testContext(), &models.CreateOperatorRequest{
+		EmployeeNumber: "FINE001",
+		FirstName:      "Paul",
+		LastName:       "Durand",
+	}, userID)
+	require.NoError(t, err)
+	_, err = operatorService.AssignOperatorToCar(testContext(), assigned.ID, &models.AssignOperatorRequest{
+		OperatorID: operator.ID,
+		StartDate:  today.AddDate(0, 0, -3).Format("2006-01-02"),
+	}, userID)
+	require.NoError(t, err)
+
+	amount := money.New(decimal.NewFromInt(135), money.DefaultCurrency)
+	var resolved, unresolved *models.TrafficFine
+
+	t.Run("Record a fine resolves the driver", func(t *testing.T) {
+		var err error
+		resolved, err = fineService.CreateFine(testContext(), &models.CreateTrafficFineRequest{
+			NoticeNumber: "1234 5678 901",
+			LicensePlate: "fi100aa",
+			OffenseAt:    today.AddDate(0, 0, -1).Add(8 * time.Hour),
+			Location:     "A7, Valence",
+			Amount:       amount,
+		}, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "12345678901", resolved.NoticeNumber)
+		assert.Equal(t, assigned.ID, resolved.CarID)
+		assert.Equal(t, "FI-100-AA", resolved.LicensePlate)
+		assert.Equal(t, models.FineStatusPending, resolved.Status)
+		assert.Equal(t, today.AddDate(0, 0, models.DesignationPeriodDays).Format("2006-01-02"), resolved.DesignationDeadline.Format("2006-01-02"))
+		require.NotNil(t, resolved.Driver)
+		assert.Equal(t, operator.ID, resolved.Driver.ID)
+		assert.Equal(t, "FINE001", resolved.Driver.EmployeeNumber)
+	})
+
+	t.Run("Record a fine without driver", func(t *testing.T) {
+		noticeDate := today.AddDate(0, 0, -40)
+		var err error
+		unresolved, err = fineService.CreateFine(testContext(), &models.CreateTrafficFineRequest{
+			NoticeNumber: "2234567890",
+			LicensePlate: "FI-200-AA",
+			OffenseAt:    today.AddDate(0, 0, -42).Add(17 * time.Hour),
+			Location:     "Paris, boulevard périphérique",
+			Amount:       amount,
+			NoticeDate:   &noticeDate,
+		}, userID)
+		require.NoError(t, err)
+		assert.Equal(t, unassigned.ID, unresolved.CarID)
+		assert.Nil(t, unresolved.OperatorID)
+		assert.Nil(t, unresolved.Driver)
+	})
+
+	t.Run("Invalid fines are rejected", func(t *testing.T) {
+		_, err := fineService.CreateFine(testContext(), &models.CreateTrafficFineRequest{
+			NoticeNumber: "12345678901",
+			LicensePlate: "FI-100-AA",
+			OffenseAt:    today.AddDate(0, 0, -2),
+			Location:     "Lyon",
+			Amount:       amount,
+		}, userID)
+		assert.ErrorContains(t, err, "already exists")
+
+		_, err = fineService.CreateFine(testContext(), &models.CreateTrafficFineRequest{
+			NoticeNumber: "3234567890",
+			LicensePlate: "ZZ-999-ZZ",
+			OffenseAt:    today.AddDate(0, 0, -2),
+			Location:     "Lyon",
+			Amount:       amount,
+		}, userID)
+		assert.ErrorContains(t, err, "no car with license plate")
+
+		_, err = fineService.CreateFine(testContext(), &models.CreateTrafficFineRequest{
+			NoticeNumber: "3234567890",
+			LicensePlate: "FI-100-AA",
+			OffenseAt:    time.Now().Add(time.Hour),
+			Location:     "Lyon",
+			Amount:       amount,
+		}, userID)
+		assert.ErrorContains(t, err, "cannot be in the future")
+	})
+
+	t.Run("Due fines", func(t *testing.T) {
+		due, err := fineService.GetDueFines(testContext(), 0)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		assert.Equal(t, unresolved.ID, due[0].ID)
+		assert.Equal(t, 5, due[0].DaysRemaining)
+
+		due, err = fineService.GetDueFines(testContext(), models.DesignationPeriodDays)
+		require.NoError(t, err)
+		assert.Len(t, due, 2)
+
+		_, err = fineService.GetDueFines(testContext(), 90)
+		assert.ErrorContains(t, err, "invalid days")
+	})
+
+	t.Run("Designation export lists the fines with a driver", func(t *testing.T) {
+		data, err := fineService.ExportDesignations(testContext(), &models.TrafficFineFilters{})
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[1], "12345678901;FI-100-AA;"))
+		assert.Contains(t, lines[1], ";FINE001;Durand;Paul;")
+	})
+
+	t.Run("Reminders are sent once to the employee who recorded the fine", func(t *testing.T) {
+		reminded, err := fineService.SendDesignationReminders(testContext(), today)
+		require.NoError(t, err)
+		assert.Equal(t, 1, reminded)
+
+		msg, ok := mail.LastTo("admin@autoparc.fr")
+		require.True(t, ok)
+		assert.Contains(t, msg.Body, "Avis 2234567890")
+		assert.NotContains(t, msg.Body, "12345678901")
+
+		fine, err := fineService.GetFine(testContext(), unresolved.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, fine.RemindedAt)
+
+		reminded, err = fineService.SendDesignationReminders(testContext(), today)
+		require.NoError(t, err)
+		assert.Equal(t, 0, reminded)
+		assert.Len(t, mail.Messages(), 1)
+	})
+
+	t.Run("Designate the driver", func(t *testing.T) {
+		_, err := fineService.DesignateDriver(testContext(), unresolved.ID, &models.DesignateDriverRequest{}, userID)
+		assert.ErrorContains(t, err, "driver is required")
+
+		fine, err := fineService.DesignateDriver(testContext(), resolved.ID, &models.DesignateDriverRequest{}, userID)
+		require.NoError(t, err)
+		assert.Equal(t, models.FineStatusDesignated, fine.Status)
+		assert.NotNil(t, fine.DesignatedAt)
+		require.NotNil(t, fine.OperatorID)
+		assert.Equal(t, operator.ID, *fine.OperatorID)
+
+		_, err = fineService.DesignateDriver(testContext(), resolved.ID, &models.DesignateDriverRequest{}, userID)
+		assert.ErrorContains(t, err, "only pending fines")
+
+		_, err = fineService.UpdateFine(testContext(), resolved.ID, &models.UpdateTrafficFineRequest{OperatorID: &operator.ID}, userID)
+		assert.ErrorContains(t, err, "already designated")
+
+		fines, err := fineService.GetFines(testContext(), &models.TrafficFineFilters{OperatorID: operator.ID})
+		require.NoError(t, err)
+		assert.Len(t, fines, 1)
+	})
+
+	t.Run("Update a fine", func(t *testing.T) {
+		noticeDate := today.AddDate(0, 0, -5)
+		paid := models.FineStatusPaid
+		fine, err := fineService.UpdateFine(testContext(), unresolved.ID, &models.UpdateTrafficFineRequest{
+			NoticeDate: &noticeDate,
+			Status:     &paid,
+		}, userID)
+		require.NoError(t, err)
+		assert.Equal(t, models.FineStatusPaid, fine.Status)
+		assert.Equal(t, today.AddDate(0, 0, 40).Format("2006-01-02"), fine.DesignationDeadline.Format("2006-01-02"))
+
+		designated := models.FineStatusDesignated
+		_, err = fineService.UpdateFine(testContext(), unresolved.ID, &models.UpdateTrafficFineRequest{Status: &designated}, userID)
+		assert.ErrorContains(t, err, "use the designation")
+	})
+
+	t.Run("Delete a fine", func(t *testing.T) {
+		require.NoError(t, fineService.DeleteFine(testContext(), unresolved.ID, userID))
+
+		_, err := fineService.GetFine(testContext(), unresolved.ID)
+		assert.ErrorContains(t, err, "not found")
+	})
+
+	t.Run("The driver is resolved on the local day of the offense", func(t *testing.T) {
+		car := newCar("FI-300-AA")
+		var operators []*models.CarOperator
+		for _, number := range []string{"FINE002", "FINE003"} {
+			operator, err := operatorService.CreateOperator(testContext(), &models.CreateOperatorRequest{
+				EmployeeNumber: number,
+				FirstName:      "Marie",
+				LastName:       "Lefèvre",
+			}, userID)
+			require.NoError(t, err)
+			operators = append(operators, operator)
+		}
+
+		// The car changes hands two days ago
+		_, err := operatorService.AssignOperatorToCar(testContext(), car.ID, &models.AssignOperatorRequest{
+			OperatorID: operators[0].ID,
+			StartDate:  today.AddDate(0, 0, -5).Format("2006-01-02"),
+		}, userID)
+		require.NoError(t, err)
+		require.NoError(t, operatorService.UnassignOperatorFromCar(testContext(), car.ID, &models.UnassignOperatorRequest{
+			EndDate: today.AddDate(0, 0, -3).Format("2006-01-02"),
+		}, userID))
+		_, err = operatorService.AssignOperatorToCar(testContext(), car.ID, &models.AssignOperatorRequest{
+			OperatorID: operators[1].ID,
+			StartDate:  today.AddDate(0, 0, -2).Format("2006-01-02"),
+		}, userID)
+		require.NoError(t, err)
+
+		// 23:30 UTC is already the next day in Paris
+		offenseAt, err := time.Parse(time.RFC3339, today.AddDate(0, 0, -3).Format("2006-01-02")+"T23:30:00Z")
+		require.NoError(t, err)
+		fine, err := fineService.CreateFine(testContext(), &models.CreateTrafficFineRequest{
+			NoticeNumber: "4234567890",
+			LicensePlate: "FI-300-AA",
+			OffenseAt:    offenseAt,
+			Location:     "Paris, place de la Concorde",
+			Amount:       amount,
+		}, userID)
+		require.NoError(t, err)
+		require.NotNil(t, fine.OperatorID)
+		assert.Equal(t, operators[1].ID, *fine.OperatorID)
+	})
+}
@@ -0,0 +1,151 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goldenkiwi/autoparc/internal/config"
+	"github.com/goldenkiwi/autoparc/internal/repository"
+	"github.com/goldenkiwi/autoparc/internal/service"
+	"github.com/goldenkiwi/autoparc/pkg/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTwoFactorService(authService *service.AuthService, twoFactorConfig *config.TwoFactorConfig) *service.TwoFactorService {
+	return service.NewTwoFactorService(
+		repository.NewUserRepository(testDB),
+		repository.NewSessionRepository(testDB),
+		repository.NewTwoFactorRepository(testDB),
+		repository.NewActionLogRepository(testDB),
+		authService,
+		twoFactorConfig,
+	)
+}
+
+func TestTwoFactorIntegration(t *testing.T) {
+	cleanupDB(t)
+
+	const adminID = "00000000-0000-0000-0000-000000000001"
+	authService := newTestAuthService(testLoginConfig())
+	twoFactorService := newTestTwoFactorService(authService, testTwoFactorConfig())
+
+	var secret, enrollCode string
+	var recoveryCodes []string
+
+	t.Run("Enrollment", func(t *testing.T) {
+		ctx := testContext()
+
+		user, session, err := authService.Login(ctx, "admin@autoparc.fr", "Admin123!", "127.0.0.1", "test-agent")
+		require.NoError(t, err)
+		assert.False(t, session.MFAPending)
+
+		setup, err := twoFactorService.Setup(ctx, user)
+		require.NoError(t, err)
+		assert.Contains(t, setup.ProvisioningURI, "otpauth://totp/AutoParc:admin@autoparc.fr?")
+		secret = setup.Secret
+
+		_, err = twoFactorService.Enable(ctx, user, "000000")
+		assert.EqualError(t, err, "invalid two-factor code")
+
+		enrollCode, err = totp.CodeAt(secret, totp.Step(time.Now()))
+		require.NoError(t, err)
+		recoveryCodes, err = twoFactorService.Enable(ctx, user, enrollCode)
+		require.NoError(t, err)
+		assert.Len(t, recoveryCodes, 10)
+
+		// Recovery codes are only stored hashed
+		var matches int
+		require.NoError(t, testDB.QueryRow(
+			`SELECT COUNT(*) FROM two_factor_recovery_codes WHERE code_hash = $1 OR code_hash = $2`,
+			recoveryCodes[0], strings.ReplaceAll(recoveryCodes[0], "-", ""),
+		).Scan(&matches))
+		assert.Zero(t, matches)
+	})
+
+	t.Run("Login requires the second factor", func(t *testing.T) {
+		ctx := testContext()
+
+		user, pending, err := authService.Login(ctx, "admin@autoparc.fr", "Admin123!", "127.0.0.1", "test-agent")
+		require.NoError(t, err)
+		assert.True(t, user.TwoFactorEnabled)
+		assert.True(t, pending.MFAPending)
+		assert.True(t, pending.AbsoluteExpiresAt.Before(time.Now().Add(6*time.Minute)))
+
+		// The code used for enrollment cannot be replayed
+		_, err = twoFactorService.Verify(ctx, user, pending, enrollCode)
+		assert.EqualError(t, err, "invalid two-factor code")
+
+		next, _ := totp.CodeAt(secret, totp.Step(time.Now())+1)
+		session, err := twoFactorService.Verify(ctx, user, pending, next)
+		require.NoError(t, err)
+		assert.False(t, session.MFAPending)
+		assert.NotEqual(t, pending.SessionToken, session.SessionToken)
+
+		// The pending session is gone
+		_, err = authService.ValidateSession(ctx, pending.SessionToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("Recovery codes are single-use", func(t *testing.T) {
+		ctx := testContext()
+
+		user, pending, err := authService.Login(ctx, "admin@autoparc.fr", "Admin123!", "127.0.0.1", "test-agent")
+		require.NoError(t, err)
+
+		session, err := twoFactorService.Verify(ctx, user, pending, strings.ToLower(recoveryCodes[0]))
+		require.NoError(t, err)
+		assert.False(t, session.MFAPending)
+
+		_, pending, err = authService.Login(ctx, "admin@autoparc.fr", "Admin123!", "127.0.0.1", "test-agent")
+		require.NoError(t, err)
+		_, err = twoFactorService.Verify(ctx, user, pending, recoveryCodes[0])
+		assert.EqualError(t, err, "invalid two-factor code")
+	})
+
+	t.Run("Admin reset", func(t *testing.T) {
+		ctx := testContext()
+
+		require.NoError(t, twoFactorService.Reset(ctx, adminID, adminID))
+
+		user, session, err := authService.Login(ctx, "admin@autoparc.fr", "Admin123!", "127.0.0.1", "test-agent")
+		require.NoError(t, err)
+		assert.False(t, user.TwoFactorEnabled)
+		assert.False(t, session.MFAPending)
+	})
+
+	t.Run("Role enforcement", func(t *testing.T) {
+		ctx := testContext()
+
+		twoFactorConfig := testTwoFactorConfig()
+		twoFactorConfig.RequiredRoles = []string{"admin"}
+		enforcedAuth := service.NewAuthService(
+			repository.NewUserRepository(testDB),
+			repository.NewSessionRepository(testDB),
+			repository.NewLoginAttemptRepository(testDB),
+			repository.NewActionLogRepository(testDB),
+			testLoginConfig(),
+			testSessionConfig(),
+			twoFactorConfig,
+			newTestPasswordPolicy(testPasswordConfig()),
+		)
+		enforced := newTestTwoFactorService(enforcedAuth, twoFactorConfig)
+
+		user, pending, err := enforcedAuth.Login(ctx, "admin@autoparc.fr", "Admin123!", "127.0.0.1", "test-agent")
+		require.NoError(t, err)
+		assert.False(t, user.TwoFactorEnabled)
+		assert.True(t, pending.MFAPending, "enrollment must be required")
+
+		setup, err := enforced.Setup(ctx, user)
+		require.NoError(t, err)
+		code, _ := totp.CodeAt(setup.Secret, totp.Step(time.Now()))
+		_, err = enforced.Enable(ctx, user, code)
+		require.NoError(t, err)
+
+		user.TwoFactorEnabled = true
+		next, _ := totp.CodeAt(setup.Secret, totp.Step(time.Now())+1)
+		err = enforced.Disable(ctx, user, next)
+		assert.EqualError(t, err, "two-factor authentication is required for this role")
+	})
+}
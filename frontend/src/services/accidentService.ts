@@ -1,4 +1,4 @@
-import { apiGet, apiPost, apiPut, apiDelete } from './api'
+import { apiGet, apiPost, apiPut, apiDelete, mutationHeaders } from './api'
 import type { Accident, AccidentPhoto, PaginatedResponse, CreateAccidentRequest, UpdateAccidentRequest, AccidentFilters, AccidentStatus } from '@/types'
 
 const API_BASE_URL = '/api/v1'
@@ -55,6 +55,7 @@ export async function uploadPhoto(accidentId: string, file: File, description?:
   const response = await fetch(`${API_BASE_URL}/accidents/${accidentId}/photos`, {
     method: 'POST',
     credentials: 'include',
+    headers: mutationHeaders(),
     body: formData,
   })
 
@@ -1,5 +1,5 @@
 import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest'
-import { apiGet, apiPost, apiPut, apiDelete, ApiClientError } from './api'
+import { apiGet, apiPost, apiPut, apiDelete, ApiClientError, getCsrfToken, setCsrfToken } from './api'
 
 global.fetch = vi.fn()
 
@@ -143,6 +143,36 @@ describe('API Client', () => {
     })
   })
 
+  describe('CSRF token', () => {
+    afterEach(() => {
+      setCsrfToken(null)
+    })
+
+    it('should store the token returned by the server and send it on mutations', async () => {
+      vi.mocked(fetch).mockResolvedValue({
+        ok: true,
+        status: 200,
+        headers: new Headers({ 'X-CSRF-Token': 'csrf-123' }),
+        json: async () => ({}),
+      } as Response)
+
+      await apiGet('/auth/me')
+      expect(getCsrfToken()).toBe('csrf-123')
+
+      await apiPost('/test', { name: 'Test' })
+
+      expect(fetch).toHaveBeenLastCalledWith('/api/v1/test', {
+        method: 'POST',
+        credentials: 'include',
+        headers: {
+          'Content-Type': 'application/json',
+          'X-CSRF-Token': 'csrf-123',
+        },
+        body: JSON.stringify({ name: 'Test' }),
+      })
+    })
+  })
+
   describe('ApiClientError', () => {
     it('should create error with correct properties', () => {
       const errorData = { error: 'Test error', message: 'Test error', code: 'TEST_ERROR' }
@@ -1,6 +1,24 @@
 import type { ApiError } from '@/types'
 
 const API_BASE_URL = '/api/v1'
+const CSRF_HEADER = 'X-CSRF-Token'
+
+// CSRF token of the current session, issued by /auth/login and /auth/me
+let csrfToken: string | null = null
+
+export function getCsrfToken(): string | null {
+  return csrfToken
+}
+
+export function setCsrfToken(token: string | null): void {
+  csrfToken = token
+}
+
+// Headers for state-changing requests, which the backend rejects without
+// the CSRF token of the session
+export function mutationHeaders(headers: Record<string, string> = {}): Record<string, string> {
+  return csrfToken ? { ...headers, [CSRF_HEADER]: csrfToken } : headers
+}
 
 export class ApiClientError extends Error {
   constructor(
@@ -14,6 +32,11 @@ export class ApiClientError extends Error {
 }
 
 async function handleResponse<T>(response: Response): Promise<T> {
+  const token = response.headers?.get(CSRF_HEADER)
+  if (token) {
+    csrfToken = token
+  }
+
   if (!response.ok) {
     let errorData: ApiError | undefined
     
@@ -55,9 +78,9 @@ export async function apiPost<T, D = unknown>(
   const response = await fetch(`${API_BASE_URL}${endpoint}`, {
     method: 'POST',
     credentials: 'include',
-    headers: {
+    headers: mutationHeaders({
       'Content-Type': 'application/json',
-    },
+    }),
     body: data ? JSON.stringify(data) : undefined,
   })
   
@@ -71,9 +94,9 @@ export async function apiPut<T, D = unknown>(
   const response = await fetch(`${API_BASE_URL}${endpoint}`, {
     method: 'PUT',
     credentials: 'include',
-    headers: {
+    headers: mutationHeaders({
       'Content-Type': 'application/json',
-    },
+    }),
     body: data ? JSON.stringify(data) : undefined,
   })
   
@@ -84,9 +107,9 @@ export async function apiDelete<T>(endpoint: string): Promise<T> {
   const response = await fetch(`${API_BASE_URL}${endpoint}`, {
     method: 'DELETE',
     credentials: 'include',
-    headers: {
+    headers: mutationHeaders({
       'Content-Type': 'application/json',
-    },
+    }),
   })
   
   return handleResponse<T>(response)
@@ -1,4 +1,4 @@
-import { apiGet, apiPost } from './api'
+import { apiGet, apiPost, setCsrfToken } from './api'
 import type { User, LoginCredentials, LoginResponse, BackendLoginResponse, BackendUser } from '@/types'
 import { transformUser } from '@/utils/apiTransformers'
 
@@ -9,7 +9,8 @@ export async function login(credentials: LoginCredentials): Promise<LoginRespons
 }
 
 export async function logout(): Promise<void> {
-  return apiPost<void>('/auth/logout')
+  await apiPost<void>('/auth/logout')
+  setCsrfToken(null)
 }
 
 export async function getCurrentUser(): Promise<User> {
@@ -0,0 +1,14 @@
+-- Drop processed image metadata and variants
+ALTER TABLE accident_photos
+    DROP CONSTRAINT IF EXISTS check_gps_longitude,
+    DROP CONSTRAINT IF EXISTS check_gps_latitude;
+
+ALTER TABLE accident_photos
+    DROP COLUMN IF EXISTS variant_mime_type,
+    DROP COLUMN IF EXISTS medium_data,
+    DROP COLUMN IF EXISTS thumbnail_data,
+    DROP COLUMN IF EXISTS gps_longitude,
+    DROP COLUMN IF EXISTS gps_latitude,
+    DROP COLUMN IF EXISTS taken_at,
+    DROP COLUMN IF EXISTS height,
+    DROP COLUMN IF EXISTS width;
@@ -0,0 +1,19 @@
+-- Add processed image metadata and resized variants to accident_photos
+ALTER TABLE accident_photos
+    ADD COLUMN width INTEGER,
+    ADD COLUMN height INTEGER,
+    ADD COLUMN taken_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN gps_latitude DOUBLE PRECISION,
+    ADD COLUMN gps_longitude DOUBLE PRECISION,
+    ADD COLUMN thumbnail_data BYTEA,
+    ADD COLUMN medium_data BYTEA,
+    ADD COLUMN variant_mime_type VARCHAR(100);
+
+ALTER TABLE accident_photos
+    ADD CONSTRAINT check_gps_latitude CHECK (gps_latitude IS NULL OR gps_latitude BETWEEN -90 AND 90),
+    ADD CONSTRAINT check_gps_longitude CHECK (gps_longitude IS NULL OR gps_longitude BETWEEN -180 AND 180);
+
+COMMENT ON COLUMN accident_photos.file_data IS 'Original image re-encoded without EXIF metadata, gzip compressed';
+COMMENT ON COLUMN accident_photos.thumbnail_data IS 'Thumbnail variant (longest side 320px), not compressed';
+COMMENT ON COLUMN accident_photos.medium_data IS 'Medium variant (longest side 1280px), not compressed';
+COMMENT ON COLUMN accident_photos.taken_at IS 'Capture time extracted from EXIF before stripping';
@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_photo_uploads_expires_at;
+DROP INDEX IF EXISTS idx_photo_uploads_accident_id;
+
+-- Drop table
+DROP TABLE IF EXISTS photo_uploads;
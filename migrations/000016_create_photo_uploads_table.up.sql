@@ -0,0 +1,22 @@
+-- Create photo_uploads table for resumable (chunked) photo uploads
+CREATE TABLE photo_uploads (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    accident_id UUID NOT NULL REFERENCES accidents(id) ON DELETE CASCADE,
+    filename VARCHAR(255) NOT NULL,
+    description TEXT,
+    upload_length BIGINT NOT NULL,
+    upload_offset BIGINT NOT NULL DEFAULT 0,
+    data BYTEA NOT NULL DEFAULT ''::bytea,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_by UUID REFERENCES administrative_employees(id),
+    CONSTRAINT check_upload_length CHECK (upload_length > 0),
+    CONSTRAINT check_upload_offset CHECK (upload_offset >= 0 AND upload_offset <= upload_length)
+);
+
+-- Create indexes for performance
+CREATE INDEX idx_photo_uploads_accident_id ON photo_uploads(accident_id);
+CREATE INDEX idx_photo_uploads_expires_at ON photo_uploads(expires_at);
+
+-- Add comment to table
+COMMENT ON TABLE photo_uploads IS 'Stores in-progress resumable photo uploads until all chunks are received';
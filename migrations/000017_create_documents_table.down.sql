@@ -0,0 +1,7 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_documents_expiry_date;
+DROP INDEX IF EXISTS idx_documents_document_type;
+DROP INDEX IF EXISTS idx_documents_entity;
+
+-- Drop table
+DROP TABLE IF EXISTS documents;
@@ -0,0 +1,38 @@
+-- Create documents table for files attached to cars, repairs, operators and accidents
+CREATE TABLE documents (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    document_group_id UUID NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id UUID NOT NULL,
+    document_type VARCHAR(50) NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    file_data BYTEA NOT NULL,
+    file_size INTEGER NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    compression_type VARCHAR(50) DEFAULT 'gzip',
+    description TEXT,
+    expiry_date DATE,
+    uploaded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    uploaded_by UUID REFERENCES administrative_employees(id),
+    CONSTRAINT check_document_file_size CHECK (file_size > 0),
+    CONSTRAINT check_document_version CHECK (version > 0),
+    CONSTRAINT check_document_entity_type CHECK (entity_type IN ('car', 'repair', 'operator', 'accident')),
+    CONSTRAINT check_document_type CHECK (document_type IN (
+        'registration_certificate', 'insurance_certificate', 'technical_inspection',
+        'invoice', 'quote', 'driving_licence', 'identity_document', 'other'
+    )),
+    CONSTRAINT check_document_mime_type CHECK (mime_type IN (
+        'application/pdf', 'image/jpeg', 'image/png', 'image/webp', 'image/gif'
+    )),
+    CONSTRAINT unique_document_version UNIQUE (document_group_id, version)
+);
+
+-- Create indexes for performance
+CREATE INDEX idx_documents_entity ON documents(entity_type, entity_id);
+CREATE INDEX idx_documents_document_type ON documents(document_type);
+CREATE INDEX idx_documents_expiry_date ON documents(expiry_date) WHERE expiry_date IS NOT NULL;
+
+-- Add comments to table
+COMMENT ON TABLE documents IS 'Stores versioned documents (PDF and images) attached to any entity, with gzip compression in BYTEA format';
+COMMENT ON COLUMN documents.document_group_id IS 'Shared by all versions of the same document';
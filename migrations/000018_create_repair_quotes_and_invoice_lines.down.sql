@@ -0,0 +1,9 @@
+-- Drop invoice lines
+DROP INDEX IF EXISTS idx_repair_invoice_lines_repair_id;
+DROP TABLE IF EXISTS repair_invoice_lines;
+
+-- Drop quotes
+DROP INDEX IF EXISTS idx_repair_quotes_garage_id;
+DROP INDEX IF EXISTS idx_repair_quotes_repair_id;
+DROP INDEX IF EXISTS idx_repair_quotes_accepted;
+DROP TABLE IF EXISTS repair_quotes;
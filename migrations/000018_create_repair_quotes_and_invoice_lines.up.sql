@@ -0,0 +1,44 @@
+-- Create repair_quotes table for garage quotes
+CREATE TABLE repair_quotes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    repair_id UUID NOT NULL REFERENCES repairs(id) ON DELETE CASCADE,
+    garage_id UUID NOT NULL REFERENCES garages(id),
+    quote_number VARCHAR(100),
+    quote_date DATE NOT NULL,
+    amount NUMERIC(12,2) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES administrative_employees(id),
+    CONSTRAINT check_quote_amount CHECK (amount >= 0),
+    CONSTRAINT check_quote_status CHECK (status IN ('pending', 'accepted', 'rejected'))
+);
+
+-- A repair has at most one accepted quote
+CREATE UNIQUE INDEX idx_repair_quotes_accepted ON repair_quotes(repair_id) WHERE status = 'accepted';
+CREATE INDEX idx_repair_quotes_repair_id ON repair_quotes(repair_id);
+CREATE INDEX idx_repair_quotes_garage_id ON repair_quotes(garage_id);
+
+-- Create repair_invoice_lines table for invoice details
+CREATE TABLE repair_invoice_lines (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    repair_id UUID NOT NULL REFERENCES repairs(id) ON DELETE CASCADE,
+    description TEXT NOT NULL,
+    quantity NUMERIC(10,3) NOT NULL,
+    unit_price NUMERIC(12,4) NOT NULL,
+    vat_rate NUMERIC(5,2) NOT NULL DEFAULT 20.00,
+    category VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES administrative_employees(id),
+    CONSTRAINT check_invoice_line_quantity CHECK (quantity > 0),
+    CONSTRAINT check_invoice_line_unit_price CHECK (unit_price >= 0),
+    CONSTRAINT check_invoice_line_vat_rate CHECK (vat_rate >= 0 AND vat_rate <= 100),
+    CONSTRAINT check_invoice_line_category CHECK (category IN ('parts', 'labour'))
+);
+
+CREATE INDEX idx_repair_invoice_lines_repair_id ON repair_invoice_lines(repair_id);
+
+-- Add comments to tables
+COMMENT ON TABLE repair_quotes IS 'Stores garage quotes for repairs; amount includes VAT';
+COMMENT ON TABLE repair_invoice_lines IS 'Stores invoice line items (parts and labour) for repairs; totals are computed, not stored';
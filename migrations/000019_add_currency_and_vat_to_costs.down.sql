@@ -0,0 +1,16 @@
+-- Drop report index
+DROP INDEX IF EXISTS idx_repairs_start_date;
+
+-- Drop currency from invoice lines and quotes
+ALTER TABLE repair_invoice_lines DROP CONSTRAINT IF EXISTS check_invoice_line_currency;
+ALTER TABLE repair_invoice_lines DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE repair_quotes DROP CONSTRAINT IF EXISTS check_quote_currency;
+ALTER TABLE repair_quotes DROP COLUMN IF EXISTS currency;
+
+-- Restore repair cost column
+ALTER TABLE repairs DROP CONSTRAINT IF EXISTS check_repair_cost_currency;
+ALTER TABLE repairs DROP CONSTRAINT IF EXISTS check_repair_cost_vat_rate;
+ALTER TABLE repairs DROP COLUMN IF EXISTS cost_currency;
+ALTER TABLE repairs DROP COLUMN IF EXISTS cost_vat_rate;
+ALTER TABLE repairs ALTER COLUMN cost TYPE DECIMAL(10,2);
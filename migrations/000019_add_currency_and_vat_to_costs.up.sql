@@ -0,0 +1,21 @@
+-- Store repair costs as exact NUMERIC amounts including VAT, with their VAT rate and currency
+ALTER TABLE repairs ALTER COLUMN cost TYPE NUMERIC(12,2);
+ALTER TABLE repairs ADD COLUMN cost_vat_rate NUMERIC(5,2) NOT NULL DEFAULT 20.00;
+ALTER TABLE repairs ADD COLUMN cost_currency CHAR(3) NOT NULL DEFAULT 'EUR';
+ALTER TABLE repairs ADD CONSTRAINT check_repair_cost_vat_rate CHECK (cost_vat_rate >= 0 AND cost_vat_rate <= 100);
+ALTER TABLE repairs ADD CONSTRAINT check_repair_cost_currency CHECK (cost_currency ~ '^[A-Z]{3}$');
+
+-- Add currency to quotes and invoice lines
+ALTER TABLE repair_quotes ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'EUR';
+ALTER TABLE repair_quotes ADD CONSTRAINT check_quote_currency CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE repair_invoice_lines ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'EUR';
+ALTER TABLE repair_invoice_lines ADD CONSTRAINT check_invoice_line_currency CHECK (currency ~ '^[A-Z]{3}$');
+
+-- Cost reports group repairs by period
+CREATE INDEX idx_repairs_start_date ON repairs(start_date);
+
+-- Add comments to columns
+COMMENT ON COLUMN repairs.cost IS 'Repair cost including VAT (TTC)';
+COMMENT ON COLUMN repairs.cost_vat_rate IS 'VAT rate in percent applied to the repair cost';
+COMMENT ON COLUMN repairs.cost_currency IS 'ISO 4217 currency code of the repair cost';
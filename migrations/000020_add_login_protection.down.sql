@@ -0,0 +1,10 @@
+-- Drop login_attempts table
+DROP INDEX IF EXISTS idx_login_attempts_attempted_at;
+DROP INDEX IF EXISTS idx_login_attempts_email;
+DROP INDEX IF EXISTS idx_login_attempts_ip_address;
+DROP TABLE IF EXISTS login_attempts;
+
+-- Drop lockout columns
+ALTER TABLE administrative_employees DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE administrative_employees DROP COLUMN IF EXISTS last_failed_login_at;
+ALTER TABLE administrative_employees DROP COLUMN IF EXISTS failed_login_count;
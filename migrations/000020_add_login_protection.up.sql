@@ -0,0 +1,21 @@
+-- Track consecutive failed logins and temporary lockouts per account
+ALTER TABLE administrative_employees ADD COLUMN failed_login_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE administrative_employees ADD COLUMN last_failed_login_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE administrative_employees ADD COLUMN locked_until TIMESTAMP WITH TIME ZONE;
+
+-- Create login_attempts table for per-IP throttling and auditing
+CREATE TABLE login_attempts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    email VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    user_agent TEXT,
+    succeeded BOOLEAN NOT NULL,
+    attempted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_login_attempts_ip_address ON login_attempts(ip_address, attempted_at) WHERE NOT succeeded;
+CREATE INDEX idx_login_attempts_email ON login_attempts(LOWER(email), attempted_at);
+CREATE INDEX idx_login_attempts_attempted_at ON login_attempts(attempted_at);
+
+-- Add comment to table
+COMMENT ON TABLE login_attempts IS 'Stores login attempts, including those for unknown emails, for throttling and auditing';
@@ -0,0 +1,3 @@
+-- Drop session activity columns
+ALTER TABLE sessions DROP COLUMN IF EXISTS absolute_expires_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS last_seen_at;
@@ -0,0 +1,12 @@
+-- Track session activity for idle timeouts; expires_at now slides with
+-- activity and absolute_expires_at caps the session lifetime
+ALTER TABLE sessions ADD COLUMN last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE sessions ADD COLUMN absolute_expires_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE sessions SET absolute_expires_at = expires_at, last_seen_at = created_at;
+
+ALTER TABLE sessions ALTER COLUMN absolute_expires_at SET NOT NULL;
+
+-- Add comments to columns
+COMMENT ON COLUMN sessions.expires_at IS 'Sliding expiry, renewed on activity up to absolute_expires_at';
+COMMENT ON COLUMN sessions.absolute_expires_at IS 'Hard expiry of the session regardless of activity';
@@ -0,0 +1,8 @@
+-- Revert to plain text session tokens; hashed sessions cannot be restored
+DELETE FROM sessions;
+
+ALTER INDEX idx_sessions_token_hash RENAME TO idx_sessions_token;
+ALTER TABLE sessions ALTER COLUMN token_hash TYPE VARCHAR(255);
+ALTER TABLE sessions RENAME COLUMN token_hash TO session_token;
+
+COMMENT ON COLUMN sessions.session_token IS NULL;
@@ -0,0 +1,11 @@
+-- Store session tokens as SHA-256 hashes instead of plain text.
+-- Existing sessions cannot be converted without their raw tokens being
+-- reused, so every user is logged out.
+DELETE FROM sessions;
+
+ALTER TABLE sessions RENAME COLUMN session_token TO token_hash;
+ALTER TABLE sessions ALTER COLUMN token_hash TYPE CHAR(64);
+ALTER INDEX idx_sessions_token RENAME TO idx_sessions_token_hash;
+
+-- Add comments to columns
+COMMENT ON COLUMN sessions.token_hash IS 'Hex encoded SHA-256 hash of the session token; the raw token is only known to the client';
@@ -0,0 +1,2 @@
+-- Drop account_tokens table
+DROP TABLE IF EXISTS account_tokens;
@@ -0,0 +1,20 @@
+-- Create account_tokens table for employee invitations and password resets.
+-- Tokens are single-use and stored as SHA-256 hashes like session tokens.
+CREATE TABLE account_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES administrative_employees(id) ON DELETE CASCADE,
+    purpose VARCHAR(20) NOT NULL CHECK (purpose IN ('invitation', 'password_reset')),
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES administrative_employees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_account_tokens_user_id ON account_tokens(user_id, purpose) WHERE used_at IS NULL;
+CREATE INDEX idx_account_tokens_expires_at ON account_tokens(expires_at);
+
+-- Add comments to table and columns
+COMMENT ON TABLE account_tokens IS 'Single-use tokens sent by email to accept an invitation or reset a password';
+COMMENT ON COLUMN account_tokens.token_hash IS 'Hex encoded SHA-256 hash of the token; the raw token is only sent by email';
+COMMENT ON COLUMN account_tokens.used_at IS 'Set when the token is consumed or superseded by a newer one';
@@ -0,0 +1,7 @@
+-- Drop two-factor authentication
+ALTER TABLE sessions DROP COLUMN IF EXISTS mfa_pending;
+DROP TABLE IF EXISTS two_factor_recovery_codes;
+ALTER TABLE administrative_employees DROP COLUMN IF EXISTS totp_last_used_step;
+ALTER TABLE administrative_employees DROP COLUMN IF EXISTS totp_enabled_at;
+ALTER TABLE administrative_employees DROP COLUMN IF EXISTS totp_enabled;
+ALTER TABLE administrative_employees DROP COLUMN IF EXISTS totp_secret;
@@ -0,0 +1,26 @@
+-- TOTP two-factor authentication for administrative employees
+ALTER TABLE administrative_employees ADD COLUMN totp_secret VARCHAR(64);
+ALTER TABLE administrative_employees ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE administrative_employees ADD COLUMN totp_enabled_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE administrative_employees ADD COLUMN totp_last_used_step BIGINT;
+
+-- Create two_factor_recovery_codes table
+CREATE TABLE two_factor_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES administrative_employees(id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, code_hash)
+);
+
+CREATE INDEX idx_two_factor_recovery_codes_user_id ON two_factor_recovery_codes(user_id);
+
+-- Sessions awaiting the second factor only grant access to the 2FA endpoints
+ALTER TABLE sessions ADD COLUMN mfa_pending BOOLEAN NOT NULL DEFAULT false;
+
+-- Add comments to columns
+COMMENT ON COLUMN administrative_employees.totp_secret IS 'Base32 TOTP secret; set during enrollment, active once totp_enabled is true';
+COMMENT ON COLUMN administrative_employees.totp_last_used_step IS 'Last accepted TOTP time step, so that a code cannot be replayed';
+COMMENT ON TABLE two_factor_recovery_codes IS 'Single-use recovery codes, stored as SHA-256 hashes';
+COMMENT ON COLUMN sessions.mfa_pending IS 'True until the second factor has been verified';
@@ -0,0 +1,3 @@
+-- Drop api_tokens table and the action_logs reference to it
+ALTER TABLE action_logs DROP COLUMN IF EXISTS api_token_id;
+DROP TABLE IF EXISTS api_tokens;
@@ -0,0 +1,29 @@
+-- Create api_tokens table for machine clients (ERP, scripts) calling the API
+-- with an Authorization: Bearer header. Tokens act on behalf of the employee
+-- who created them, restricted to their scopes, and are stored hashed.
+CREATE TABLE api_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES administrative_employees(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_prefix VARCHAR(16) NOT NULL,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    scopes TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    last_used_ip VARCHAR(45),
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
+
+-- Record which token performed an action; performed_by stays the token owner
+ALTER TABLE action_logs ADD COLUMN api_token_id UUID REFERENCES api_tokens(id) ON DELETE SET NULL;
+
+-- Add comments to table and columns
+COMMENT ON TABLE api_tokens IS 'Personal access tokens used by machine clients through Authorization: Bearer';
+COMMENT ON COLUMN api_tokens.token_prefix IS 'First characters of the token, shown to help identify it';
+COMMENT ON COLUMN api_tokens.token_hash IS 'Hex encoded SHA-256 hash of the token; the raw token is only shown once at creation';
+COMMENT ON COLUMN api_tokens.scopes IS 'Space separated scopes, e.g. "read" or "cars:write repairs:read"';
+COMMENT ON COLUMN api_tokens.expires_at IS 'NULL for tokens that never expire';
+COMMENT ON COLUMN action_logs.api_token_id IS 'API token used to perform the action, NULL for browser sessions';
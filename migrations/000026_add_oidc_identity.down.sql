@@ -0,0 +1,4 @@
+-- Remove SSO identities from employees
+DROP INDEX IF EXISTS idx_administrative_employees_oidc_identity;
+ALTER TABLE administrative_employees DROP COLUMN IF EXISTS oidc_subject;
+ALTER TABLE administrative_employees DROP COLUMN IF EXISTS oidc_issuer;
@@ -0,0 +1,12 @@
+-- Link employees to their identity at the OpenID Connect provider used for
+-- single sign-on. The subject is only unique within its issuer.
+ALTER TABLE administrative_employees ADD COLUMN oidc_issuer VARCHAR(255);
+ALTER TABLE administrative_employees ADD COLUMN oidc_subject VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_administrative_employees_oidc_identity
+    ON administrative_employees(oidc_issuer, oidc_subject)
+    WHERE oidc_subject IS NOT NULL;
+
+-- Add comments to columns
+COMMENT ON COLUMN administrative_employees.oidc_issuer IS 'Issuer of the SSO identity linked to the employee';
+COMMENT ON COLUMN administrative_employees.oidc_subject IS 'Subject (sub claim) of the SSO identity linked to the employee';
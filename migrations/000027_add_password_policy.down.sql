@@ -0,0 +1,4 @@
+-- Remove password policy tracking
+ALTER TABLE sessions DROP COLUMN IF EXISTS password_change_required;
+DROP TABLE IF EXISTS password_history;
+ALTER TABLE administrative_employees DROP COLUMN IF EXISTS password_changed_at;
@@ -0,0 +1,25 @@
+-- Track password changes for the password policy: the date of the last
+-- change drives expiry, and previous hashes prevent reuse.
+ALTER TABLE administrative_employees ADD COLUMN password_changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS password_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES administrative_employees(id) ON DELETE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_password_history_user_id ON password_history(user_id, created_at DESC);
+
+-- Current passwords count as the most recent ones
+INSERT INTO password_history (user_id, password_hash)
+SELECT id, password_hash FROM administrative_employees;
+
+-- Add comments to table and columns
+COMMENT ON COLUMN administrative_employees.password_changed_at IS 'Date the password was last set, used for password expiry';
+COMMENT ON TABLE password_history IS 'Previous password hashes of employees, to prevent reuse';
+COMMENT ON COLUMN password_history.password_hash IS 'Bcrypt hash of a password the employee has used';
+
+-- Sessions opened with an expired password only allow changing it
+ALTER TABLE sessions ADD COLUMN password_change_required BOOLEAN NOT NULL DEFAULT false;
+COMMENT ON COLUMN sessions.password_change_required IS 'Session opened with an expired password, limited to changing it';
@@ -0,0 +1,3 @@
+-- Remove employee interface preferences
+ALTER TABLE administrative_employees DROP COLUMN IF EXISTS default_page_size;
+ALTER TABLE administrative_employees DROP COLUMN IF EXISTS language;
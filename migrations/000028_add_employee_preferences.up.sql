@@ -0,0 +1,9 @@
+-- Interface preferences chosen by each employee from their profile
+ALTER TABLE administrative_employees ADD COLUMN language VARCHAR(5) NOT NULL DEFAULT 'fr'
+    CHECK (language IN ('fr', 'en'));
+ALTER TABLE administrative_employees ADD COLUMN default_page_size INTEGER NOT NULL DEFAULT 20
+    CHECK (default_page_size IN (10, 20, 50, 100));
+
+-- Add comments to columns
+COMMENT ON COLUMN administrative_employees.language IS 'Interface language of the employee';
+COMMENT ON COLUMN administrative_employees.default_page_size IS 'Number of rows shown per page in lists';
@@ -0,0 +1,10 @@
+-- Remove car search indexes
+DROP INDEX IF EXISTS idx_repairs_in_progress_car_id;
+DROP INDEX IF EXISTS idx_accidents_open_car_id;
+DROP INDEX IF EXISTS idx_cars_status_license_plate;
+DROP INDEX IF EXISTS idx_cars_rental_start_date;
+DROP INDEX IF EXISTS idx_cars_model_lower;
+DROP INDEX IF EXISTS idx_cars_brand_lower;
+DROP INDEX IF EXISTS idx_cars_model_trgm;
+DROP INDEX IF EXISTS idx_cars_brand_trgm;
+DROP INDEX IF EXISTS idx_cars_license_plate_trgm;
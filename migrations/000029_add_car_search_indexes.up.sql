@@ -0,0 +1,22 @@
+-- Indexes supporting the car list filters and sorts
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Free-text search matches anywhere in the plate, brand or model (ILIKE '%...%')
+CREATE INDEX idx_cars_license_plate_trgm ON cars USING GIN (license_plate gin_trgm_ops);
+CREATE INDEX idx_cars_brand_trgm ON cars USING GIN (brand gin_trgm_ops);
+CREATE INDEX idx_cars_model_trgm ON cars USING GIN (model gin_trgm_ops);
+
+-- Brand and model filters ignore case
+CREATE INDEX idx_cars_brand_lower ON cars(LOWER(brand));
+CREATE INDEX idx_cars_model_lower ON cars(LOWER(model));
+
+-- Rental start date range filter and sort, and sort by status
+CREATE INDEX idx_cars_rental_start_date ON cars(rental_start_date);
+CREATE INDEX idx_cars_status_license_plate ON cars(status, license_plate);
+
+-- Cars with an open accident or a repair in progress
+CREATE INDEX idx_accidents_open_car_id ON accidents(car_id) WHERE status <> 'closed';
+CREATE INDEX idx_repairs_in_progress_car_id ON repairs(car_id) WHERE status = 'in_progress';
+
+-- Assigned / unassigned cars and the department of their current operator
+-- use idx_unique_active_car and idx_operators_department
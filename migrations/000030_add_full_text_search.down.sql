@@ -0,0 +1,14 @@
+-- Remove the full-text search columns and configuration
+DROP INDEX IF EXISTS idx_repairs_search_vector;
+DROP INDEX IF EXISTS idx_accidents_search_vector;
+DROP INDEX IF EXISTS idx_garages_search_vector;
+DROP INDEX IF EXISTS idx_car_operators_search_vector;
+DROP INDEX IF EXISTS idx_cars_search_vector;
+
+ALTER TABLE repairs DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE accidents DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE garages DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE car_operators DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE cars DROP COLUMN IF EXISTS search_vector;
+
+DROP TEXT SEARCH CONFIGURATION IF EXISTS autoparc_french;
@@ -0,0 +1,53 @@
+-- Full-text search across the fleet. Every searchable table gets a generated
+-- tsvector column built with a French configuration that also strips accents,
+-- so "Sévigné" and "sevigne" match. Weights rank identifiers (plates, names,
+-- claim and invoice numbers) above free text.
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+CREATE TEXT SEARCH CONFIGURATION autoparc_french (COPY = french);
+ALTER TEXT SEARCH CONFIGURATION autoparc_french
+    ALTER MAPPING FOR hword, hword_part, word WITH unaccent, french_stem;
+
+-- Plates are indexed both as written and without dashes ("AB123CD")
+ALTER TABLE cars ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('autoparc_french', license_plate || ' ' || replace(license_plate, '-', '')), 'A') ||
+    setweight(to_tsvector('autoparc_french', brand || ' ' || model), 'B') ||
+    setweight(to_tsvector('autoparc_french', coalesce(grey_card_number, '')), 'B')
+) STORED;
+
+ALTER TABLE car_operators ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('autoparc_french', first_name || ' ' || last_name || ' ' || employee_number), 'A') ||
+    setweight(to_tsvector('autoparc_french', coalesce(email, '') || ' ' || coalesce(department, '')), 'B')
+) STORED;
+
+ALTER TABLE garages ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('autoparc_french', name), 'A') ||
+    setweight(to_tsvector('autoparc_french', coalesce(contact_person, '') || ' ' || coalesce(specialization, '')), 'B') ||
+    setweight(to_tsvector('autoparc_french', address), 'C')
+) STORED;
+
+ALTER TABLE accidents ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('autoparc_french', coalesce(police_report_number, '') || ' ' || coalesce(insurance_claim_number, '')), 'A') ||
+    setweight(to_tsvector('autoparc_french', location), 'B') ||
+    setweight(to_tsvector('autoparc_french', description || ' ' || coalesce(damages_description, '')), 'C')
+) STORED;
+
+ALTER TABLE repairs ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('autoparc_french', coalesce(invoice_number, '')), 'A') ||
+    setweight(to_tsvector('autoparc_french', description), 'B') ||
+    setweight(to_tsvector('autoparc_french', coalesce(notes, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_cars_search_vector ON cars USING GIN (search_vector);
+CREATE INDEX idx_car_operators_search_vector ON car_operators USING GIN (search_vector);
+CREATE INDEX idx_garages_search_vector ON garages USING GIN (search_vector);
+CREATE INDEX idx_accidents_search_vector ON accidents USING GIN (search_vector);
+CREATE INDEX idx_repairs_search_vector ON repairs USING GIN (search_vector);
+
+-- Add comments
+COMMENT ON TEXT SEARCH CONFIGURATION autoparc_french IS 'French stemming without accents, used by the global search';
+COMMENT ON COLUMN cars.search_vector IS 'Full-text search document: plate, brand, model and grey card number';
+COMMENT ON COLUMN car_operators.search_vector IS 'Full-text search document: name, employee number, email and department';
+COMMENT ON COLUMN garages.search_vector IS 'Full-text search document: name, contact, specialization and address';
+COMMENT ON COLUMN accidents.search_vector IS 'Full-text search document: police report and claim numbers, location and descriptions';
+COMMENT ON COLUMN repairs.search_vector IS 'Full-text search document: invoice number, description and notes';
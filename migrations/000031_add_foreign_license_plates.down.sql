@@ -0,0 +1,23 @@
+-- Restore the French SIV-only license plates. Fails if cars with other
+-- plate formats exist.
+DROP INDEX IF EXISTS idx_cars_search_vector;
+ALTER TABLE cars DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS idx_cars_license_plate_compact_trgm;
+ALTER TABLE cars DROP CONSTRAINT IF EXISTS cars_plate_country_license_plate_key;
+ALTER TABLE cars DROP COLUMN IF EXISTS plate_country;
+
+ALTER TABLE cars DROP CONSTRAINT IF EXISTS cars_license_plate_check;
+ALTER TABLE cars ALTER COLUMN license_plate TYPE VARCHAR(11);
+ALTER TABLE cars ADD CONSTRAINT cars_license_plate_check
+    CHECK (license_plate ~ '^[A-Z]{2}-[0-9]{3}-[A-Z]{2}$');
+ALTER TABLE cars ADD CONSTRAINT cars_license_plate_key UNIQUE (license_plate);
+
+ALTER TABLE cars ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('autoparc_french', license_plate || ' ' || replace(license_plate, '-', '')), 'A') ||
+    setweight(to_tsvector('autoparc_french', brand || ' ' || model), 'B') ||
+    setweight(to_tsvector('autoparc_french', coalesce(grey_card_number, '')), 'B')
+) STORED;
+CREATE INDEX idx_cars_search_vector ON cars USING GIN (search_vector);
+
+COMMENT ON COLUMN cars.search_vector IS 'Full-text search document: plate, brand, model and grey card number';
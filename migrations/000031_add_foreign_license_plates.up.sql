@@ -0,0 +1,38 @@
+-- Accept license plates other than the French SIV format: legacy FNI and W
+-- garage plates, and foreign (German, Belgian) lease cars. Formats are
+-- validated and normalized by the application; the database only checks the
+-- canonical shape (uppercase letters and digits separated by single dashes or
+-- spaces) and records the issuing country.
+
+-- The generated search document depends on license_plate and must be dropped
+-- before its type can change; it is rebuilt below
+DROP INDEX IF EXISTS idx_cars_search_vector;
+ALTER TABLE cars DROP COLUMN search_vector;
+
+ALTER TABLE cars DROP CONSTRAINT IF EXISTS cars_license_plate_check;
+ALTER TABLE cars DROP CONSTRAINT IF EXISTS cars_license_plate_key;
+ALTER TABLE cars ALTER COLUMN license_plate TYPE VARCHAR(16);
+ALTER TABLE cars ADD CONSTRAINT cars_license_plate_check
+    CHECK (license_plate ~ '^[A-Z0-9ÄÖÜ]+([ -][A-Z0-9ÄÖÜ]+)*$');
+
+ALTER TABLE cars ADD COLUMN plate_country CHAR(2) NOT NULL DEFAULT 'FR'
+    CHECK (plate_country ~ '^[A-Z]{2}$');
+
+-- The same plate can be issued by two countries
+ALTER TABLE cars ADD CONSTRAINT cars_plate_country_license_plate_key UNIQUE (plate_country, license_plate);
+
+-- Plate search ignores separators: "AB123CD" finds "AB-123-CD"
+CREATE INDEX idx_cars_license_plate_compact_trgm ON cars
+    USING GIN (regexp_replace(license_plate, '[^[:alnum:]]', '', 'g') gin_trgm_ops);
+
+ALTER TABLE cars ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('autoparc_french', license_plate || ' ' || regexp_replace(license_plate, '[^[:alnum:]]', '', 'g')), 'A') ||
+    setweight(to_tsvector('autoparc_french', brand || ' ' || model), 'B') ||
+    setweight(to_tsvector('autoparc_french', coalesce(grey_card_number, '')), 'B')
+) STORED;
+CREATE INDEX idx_cars_search_vector ON cars USING GIN (search_vector);
+
+-- Add comments
+COMMENT ON COLUMN cars.license_plate IS 'License plate in the canonical form of its format, e.g. AB-123-CD, 1234-AB-75 or B-AB 1234';
+COMMENT ON COLUMN cars.plate_country IS 'ISO 3166-1 alpha-2 code of the country that issued the plate';
+COMMENT ON COLUMN cars.search_vector IS 'Full-text search document: plate, brand, model and grey card number';
@@ -0,0 +1,3 @@
+-- Remove the vehicle identification number
+DROP INDEX IF EXISTS idx_cars_vin;
+ALTER TABLE cars DROP COLUMN IF EXISTS vin;
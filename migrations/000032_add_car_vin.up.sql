@@ -0,0 +1,10 @@
+-- Vehicle identification number (ISO 3779). Optional, since cars registered
+-- before it was recorded have none; check digits and manufacturers are
+-- validated by the application.
+ALTER TABLE cars ADD COLUMN vin VARCHAR(17)
+    CHECK (vin ~ '^[A-HJ-NPR-Z0-9]{17}$');
+
+CREATE UNIQUE INDEX idx_cars_vin ON cars(vin) WHERE vin IS NOT NULL;
+
+-- Add comments
+COMMENT ON COLUMN cars.vin IS 'Vehicle identification number, 17 characters without I, O or Q';
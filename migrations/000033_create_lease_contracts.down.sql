@@ -0,0 +1,3 @@
+-- Drop lease_contracts table
+DROP TRIGGER IF EXISTS update_lease_contracts_updated_at ON lease_contracts;
+DROP TABLE IF EXISTS lease_contracts;
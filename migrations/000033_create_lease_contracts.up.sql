@@ -0,0 +1,55 @@
+-- Create lease_contracts table for leased cars. A car has at most one active
+-- contract; returning the car closes the contract and retires the car.
+CREATE TABLE lease_contracts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    car_id UUID NOT NULL REFERENCES cars(id),
+    lessor VARCHAR(255) NOT NULL,
+    contract_number VARCHAR(100) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    monthly_rent NUMERIC(12,2) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'EUR',
+    contracted_mileage INTEGER NOT NULL,
+    excess_km_price NUMERIC(10,4) NOT NULL DEFAULT 0,
+    start_mileage INTEGER NOT NULL DEFAULT 0,
+    current_mileage INTEGER,
+    mileage_recorded_at DATE,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    return_date DATE,
+    return_mileage INTEGER,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES administrative_employees(id),
+    CONSTRAINT check_lease_dates CHECK (end_date > start_date),
+    CONSTRAINT check_lease_monthly_rent CHECK (monthly_rent >= 0),
+    CONSTRAINT check_lease_currency CHECK (currency ~ '^[A-Z]{3}$'),
+    CONSTRAINT check_lease_contracted_mileage CHECK (contracted_mileage > 0),
+    CONSTRAINT check_lease_excess_km_price CHECK (excess_km_price >= 0),
+    CONSTRAINT check_lease_start_mileage CHECK (start_mileage >= 0),
+    CONSTRAINT check_lease_current_mileage CHECK (current_mileage IS NULL OR current_mileage >= start_mileage),
+    CONSTRAINT check_lease_status CHECK (status IN ('active', 'returned')),
+    CONSTRAINT check_lease_return CHECK (
+        (status = 'active' AND return_date IS NULL AND return_mileage IS NULL) OR
+        (status = 'returned' AND return_date IS NOT NULL AND return_mileage IS NOT NULL AND return_mileage >= start_mileage)
+    ),
+    CONSTRAINT unique_lease_contract_number UNIQUE (lessor, contract_number)
+);
+
+-- A car has at most one active contract
+CREATE UNIQUE INDEX idx_lease_contracts_active_car ON lease_contracts(car_id) WHERE status = 'active';
+CREATE INDEX idx_lease_contracts_car_id ON lease_contracts(car_id);
+-- Contracts ending soon
+CREATE INDEX idx_lease_contracts_active_end_date ON lease_contracts(end_date) WHERE status = 'active';
+
+CREATE TRIGGER update_lease_contracts_updated_at
+    BEFORE UPDATE ON lease_contracts
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments
+COMMENT ON TABLE lease_contracts IS 'Lease contracts of rented cars';
+COMMENT ON COLUMN lease_contracts.monthly_rent IS 'Monthly rent including VAT (TTC)';
+COMMENT ON COLUMN lease_contracts.contracted_mileage IS 'Kilometres allowed over the whole contract';
+COMMENT ON COLUMN lease_contracts.excess_km_price IS 'Price charged per kilometre beyond the contracted mileage';
+COMMENT ON COLUMN lease_contracts.current_mileage IS 'Latest odometer reading, taken on mileage_recorded_at';
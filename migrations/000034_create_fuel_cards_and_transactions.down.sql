@@ -0,0 +1,7 @@
+-- Drop fuel cards and transactions
+DROP TABLE IF EXISTS fuel_transactions;
+DROP TRIGGER IF EXISTS update_fuel_cards_updated_at ON fuel_cards;
+DROP TABLE IF EXISTS fuel_cards;
+
+ALTER TABLE cars DROP COLUMN IF EXISTS tank_capacity;
+ALTER TABLE cars DROP COLUMN IF EXISTS fuel_type;
@@ -0,0 +1,78 @@
+-- Fuel cards and the fuel transactions imported from the card providers.
+-- Cars get the fuel type and tank capacity fill-ups are checked against.
+ALTER TABLE cars ADD COLUMN fuel_type VARCHAR(20)
+    CHECK (fuel_type IN ('petrol', 'diesel', 'hybrid', 'electric', 'lpg'));
+ALTER TABLE cars ADD COLUMN tank_capacity INTEGER
+    CHECK (tank_capacity > 0);
+
+-- A card is given either to a car or to an operator. PINs are never stored.
+CREATE TABLE fuel_cards (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    card_number VARCHAR(32) UNIQUE NOT NULL,
+    provider VARCHAR(100) NOT NULL,
+    car_id UUID REFERENCES cars(id) ON DELETE SET NULL,
+    operator_id UUID REFERENCES car_operators(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    expiry_date DATE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES administrative_employees(id),
+    CONSTRAINT check_fuel_card_number CHECK (card_number ~ '^[0-9A-Z]+$'),
+    CONSTRAINT check_fuel_card_status CHECK (status IN ('active', 'blocked', 'cancelled')),
+    CONSTRAINT check_fuel_card_holder CHECK (car_id IS NULL OR operator_id IS NULL)
+);
+
+CREATE INDEX idx_fuel_cards_car_id ON fuel_cards(car_id);
+CREATE INDEX idx_fuel_cards_operator_id ON fuel_cards(operator_id);
+
+CREATE TRIGGER update_fuel_cards_updated_at
+    BEFORE UPDATE ON fuel_cards
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Transactions keep the card number and plate as imported, so rows that
+-- match no card or car are still recorded. The provider reference makes
+-- re-importing the same file a no-op.
+CREATE TABLE fuel_transactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    card_number VARCHAR(32) NOT NULL,
+    reference VARCHAR(100) NOT NULL,
+    card_id UUID REFERENCES fuel_cards(id) ON DELETE SET NULL,
+    car_id UUID REFERENCES cars(id) ON DELETE SET NULL,
+    operator_id UUID REFERENCES car_operators(id) ON DELETE SET NULL,
+    license_plate VARCHAR(20),
+    transacted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    station VARCHAR(255),
+    product VARCHAR(100) NOT NULL,
+    fuel_type VARCHAR(20) NOT NULL,
+    volume NUMERIC(8,2) NOT NULL,
+    amount NUMERIC(12,2) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'EUR',
+    mileage INTEGER,
+    consumption NUMERIC(6,2),
+    anomalies TEXT NOT NULL DEFAULT '',
+    imported_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    imported_by UUID REFERENCES administrative_employees(id),
+    CONSTRAINT check_fuel_transaction_fuel_type CHECK (fuel_type IN ('petrol', 'diesel', 'electric', 'lpg', 'adblue', 'other')),
+    CONSTRAINT check_fuel_transaction_volume CHECK (volume >= 0),
+    CONSTRAINT check_fuel_transaction_currency CHECK (currency ~ '^[A-Z]{3}$'),
+    CONSTRAINT check_fuel_transaction_mileage CHECK (mileage IS NULL OR mileage >= 0),
+    CONSTRAINT unique_fuel_transaction_reference UNIQUE (card_number, reference)
+);
+
+CREATE INDEX idx_fuel_transactions_car_id ON fuel_transactions(car_id, transacted_at);
+CREATE INDEX idx_fuel_transactions_card_id ON fuel_transactions(card_id);
+CREATE INDEX idx_fuel_transactions_transacted_at ON fuel_transactions(transacted_at);
+CREATE INDEX idx_fuel_transactions_anomalies ON fuel_transactions(transacted_at) WHERE anomalies <> '';
+
+-- Add comments
+COMMENT ON COLUMN cars.fuel_type IS 'Fuel the car runs on: petrol, diesel, hybrid (petrol), electric or lpg';
+COMMENT ON COLUMN cars.tank_capacity IS 'Fuel tank capacity in litres';
+COMMENT ON TABLE fuel_cards IS 'Fleet fuel cards, given to a car or to an operator';
+COMMENT ON TABLE fuel_transactions IS 'Fuel card transactions imported from the providers';
+COMMENT ON COLUMN fuel_transactions.volume IS 'Litres delivered (kWh for charging sessions)';
+COMMENT ON COLUMN fuel_transactions.amount IS 'Amount including VAT (TTC)';
+COMMENT ON COLUMN fuel_transactions.mileage IS 'Odometer reading entered at the pump';
+COMMENT ON COLUMN fuel_transactions.consumption IS 'Litres per 100 km since the previous fill-up with a mileage';
+COMMENT ON COLUMN fuel_transactions.anomalies IS 'Space separated anomaly flags, e.g. "fuel_type_mismatch unassigned_car"';
@@ -0,0 +1,3 @@
+-- Drop traffic fines
+DROP TRIGGER IF EXISTS update_traffic_fines_updated_at ON traffic_fines;
+DROP TABLE IF EXISTS traffic_fines;
@@ -0,0 +1,49 @@
+-- Traffic fines (avis de contravention) received for fleet cars. The
+-- driver must be designated to ANTAI within 45 days of the notice.
+CREATE TABLE traffic_fines (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    notice_number VARCHAR(30) UNIQUE NOT NULL,
+    car_id UUID NOT NULL REFERENCES cars(id),
+    license_plate VARCHAR(16) NOT NULL,
+    offense_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    location VARCHAR(255) NOT NULL,
+    offense VARCHAR(255),
+    amount NUMERIC(10,2) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'EUR',
+    notice_date DATE NOT NULL,
+    designation_deadline DATE NOT NULL,
+    operator_id UUID REFERENCES car_operators(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    designated_at TIMESTAMP WITH TIME ZONE,
+    reminded_at TIMESTAMP WITH TIME ZONE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES administrative_employees(id),
+    CONSTRAINT check_fine_notice_number CHECK (notice_number ~ '^[0-9A-Z]+$'),
+    CONSTRAINT check_fine_amount CHECK (amount >= 0),
+    CONSTRAINT check_fine_currency CHECK (currency ~ '^[A-Z]{3}$'),
+    CONSTRAINT check_fine_deadline CHECK (designation_deadline >= notice_date),
+    CONSTRAINT check_fine_status CHECK (status IN ('pending', 'designated', 'paid', 'contested')),
+    CONSTRAINT check_fine_designation CHECK (
+        status <> 'designated' OR (operator_id IS NOT NULL AND designated_at IS NOT NULL)
+    )
+);
+
+CREATE INDEX idx_traffic_fines_car_id ON traffic_fines(car_id);
+CREATE INDEX idx_traffic_fines_operator_id ON traffic_fines(operator_id);
+CREATE INDEX idx_traffic_fines_pending_deadline ON traffic_fines(designation_deadline) WHERE status = 'pending';
+
+CREATE TRIGGER update_traffic_fines_updated_at
+    BEFORE UPDATE ON traffic_fines
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments
+COMMENT ON TABLE traffic_fines IS 'Traffic fines received for fleet cars and the designation of their driver';
+COMMENT ON COLUMN traffic_fines.notice_number IS 'ANTAI notice number (numéro d''avis)';
+COMMENT ON COLUMN traffic_fines.license_plate IS 'Plate as printed on the notice';
+COMMENT ON COLUMN traffic_fines.notice_date IS 'Date the notice was sent, from which the designation period runs';
+COMMENT ON COLUMN traffic_fines.operator_id IS 'Driver holding the car at the time of the offense, resolved from the assignments or set by hand';
+COMMENT ON COLUMN traffic_fines.status IS 'pending until the driver is designated, the fine is paid by the company or contested';
+COMMENT ON COLUMN traffic_fines.reminded_at IS 'When the deadline reminder was sent, so it is sent once';
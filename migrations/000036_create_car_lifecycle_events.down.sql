@@ -0,0 +1,5 @@
+-- Drop car lifecycle events
+DROP TABLE IF EXISTS car_lifecycle_events;
+
+ALTER TABLE cars DROP CONSTRAINT IF EXISTS check_car_immobilization_reason;
+ALTER TABLE cars DROP COLUMN IF EXISTS immobilization_reason;
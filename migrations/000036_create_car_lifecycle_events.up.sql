@@ -0,0 +1,100 @@
+-- Lifecycle of cars: acquisition, commissioning, immobilization and release,
+-- and disposal by sale, return to the lessor or retirement. Each event
+-- records the status change it caused.
+ALTER TABLE cars ADD COLUMN immobilization_reason VARCHAR(20)
+    CHECK (immobilization_reason IN ('accident', 'breakdown', 'waiting_for_parts', 'other'));
+ALTER TABLE cars ADD CONSTRAINT check_car_immobilization_reason
+    CHECK (immobilization_reason IS NULL OR status = 'maintenance');
+
+CREATE TABLE car_lifecycle_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    car_id UUID NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL,
+    event_date DATE NOT NULL,
+    previous_status VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    reason VARCHAR(20),
+    accident_id UUID REFERENCES accidents(id) ON DELETE SET NULL,
+    price NUMERIC(12,2),
+    currency CHAR(3),
+    counterparty VARCHAR(255),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES administrative_employees(id),
+    CONSTRAINT check_car_event_type CHECK (event_type IN (
+        'acquisition', 'commissioning', 'immobilization', 'release', 'sale', 'return', 'retirement'
+    )),
+    CONSTRAINT check_car_event_status CHECK (
+        previous_status IN ('active', 'maintenance', 'retired') AND status IN ('active', 'maintenance', 'retired')
+    ),
+    CONSTRAINT check_car_event_reason CHECK (
+        (event_type = 'immobilization') = (reason IS NOT NULL)
+        AND (reason IS NULL OR reason IN ('accident', 'breakdown', 'waiting_for_parts', 'other'))
+    ),
+    CONSTRAINT check_car_event_price CHECK (
+        (price IS NULL) = (currency IS NULL)
+        AND (price IS NULL OR (price >= 0 AND currency ~ '^[A-Z]{3}$'))
+        AND (price IS NULL OR event_type IN ('acquisition', 'sale', 'return'))
+    )
+);
+
+CREATE INDEX idx_car_lifecycle_events_car_id ON car_lifecycle_events(car_id, event_date);
+
+-- Backfill the history of existing cars from the audit log. Every change of
+-- status logged on a car becomes the event recording it; a car retired by
+-- returning its lease is a return to the lessor, and a car put in
+-- maintenance is immobilized for an unspecified reason.
+INSERT INTO car_lifecycle_events (
+    car_id, event_type, event_date, previous_status, status, reason, counterparty, created_at, created_by
+)
+SELECT
+    l.entity_id,
+    CASE
+        WHEN l.changes->'status'->>'new' = 'maintenance' THEN 'immobilization'
+        WHEN l.changes->'status'->>'new' = 'retired' AND lc.id IS NOT NULL THEN 'return'
+        WHEN l.changes->'status'->>'new' = 'retired' THEN 'retirement'
+        WHEN l.changes->'status'->>'old' = 'maintenance' THEN 'release'
+        ELSE 'commissioning'
+    END,
+    CASE
+        WHEN l.changes->'status'->>'new' = 'retired' AND lc.return_date IS NOT NULL THEN lc.return_date
+        ELSE l.timestamp::date
+    END,
+    l.changes->'status'->>'old',
+    l.changes->'status'->>'new',
+    CASE WHEN l.changes->'status'->>'new' = 'maintenance' THEN 'other' END,
+    CASE WHEN l.changes->'status'->>'new' = 'retired' THEN lc.lessor END,
+    l.timestamp,
+    l.performed_by
+FROM action_logs l
+JOIN cars c ON c.id = l.entity_id
+LEFT JOIN lease_contracts lc ON lc.id::text = l.changes->>'leaseContractId'
+WHERE l.entity_type = 'car'
+    AND l.changes->'status'->>'old' IN ('active', 'maintenance', 'retired')
+    AND l.changes->'status'->>'new' IN ('active', 'maintenance', 'retired')
+    AND l.changes->'status'->>'old' <> l.changes->'status'->>'new';
+
+-- Then the acquisition of every car, when it was entered, in the status it
+-- had before its first logged change
+INSERT INTO car_lifecycle_events (car_id, event_type, event_date, previous_status, status, created_at, created_by)
+SELECT c.id, 'acquisition', c.created_at::date, initial.status, initial.status, c.created_at, c.created_by
+FROM cars c
+CROSS JOIN LATERAL (
+    SELECT COALESCE((
+        SELECT e.previous_status FROM car_lifecycle_events e
+        WHERE e.car_id = c.id
+        ORDER BY e.event_date, e.created_at
+        LIMIT 1
+    ), c.status) AS status
+) initial;
+
+-- Cars already in maintenance were immobilized for an unspecified reason
+UPDATE cars SET immobilization_reason = 'other' WHERE status = 'maintenance';
+
+-- Add comments
+COMMENT ON TABLE car_lifecycle_events IS 'Steps in the life of fleet cars and the status changes they caused';
+COMMENT ON COLUMN car_lifecycle_events.event_date IS 'Date the event happened, which may precede its recording';
+COMMENT ON COLUMN car_lifecycle_events.reason IS 'Why an immobilized car is off the road';
+COMMENT ON COLUMN car_lifecycle_events.price IS 'Purchase price of an acquisition, or disposal price of a sale or return';
+COMMENT ON COLUMN car_lifecycle_events.counterparty IS 'Seller, buyer or lessor of the car';
+COMMENT ON COLUMN cars.immobilization_reason IS 'Why the car is in maintenance, from its last immobilization';